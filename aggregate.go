@@ -0,0 +1,196 @@
+package mcla
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+)
+
+// PrimarySelector picks the main error from the results of a whole log.
+// The results are ordered by their position in the log, and the selector may return nil
+type PrimarySelector func(results []*ErrorResult) *ErrorResult
+
+var (
+	// SelectFirstError picks the first top level error in the log
+	SelectFirstError PrimarySelector = selectFirstError
+	// SelectLastError picks the last top level error in the log, which is usually the one that stopped the game
+	SelectLastError PrimarySelector = selectLastError
+	// SelectBestMatch picks the error which have the highest confidence solution
+	SelectBestMatch PrimarySelector = selectBestMatch
+	// SelectRootCause picks the deepest cause of the last top level error.
+	// It is the default selector
+	SelectRootCause PrimarySelector = selectRootCause
+)
+
+// topLevelResults returns the results that are not a cause or a suppressed error of another result
+func topLevelResults(results []*ErrorResult) (tops []*ErrorResult) {
+	causes := make(map[*JavaError]struct{}, len(results))
+	for _, res := range results {
+		for _, c := range errorChain(res.Error)[1:] {
+			causes[c] = struct{}{}
+		}
+	}
+	for _, res := range results {
+		if _, ok := causes[res.Error]; !ok {
+			tops = append(tops, res)
+		}
+	}
+	return
+}
+
+func selectFirstError(results []*ErrorResult) *ErrorResult {
+	tops := topLevelResults(results)
+	if len(tops) == 0 {
+		return nil
+	}
+	return tops[0]
+}
+
+func selectLastError(results []*ErrorResult) *ErrorResult {
+	tops := topLevelResults(results)
+	if len(tops) == 0 {
+		return nil
+	}
+	return tops[len(tops)-1]
+}
+
+func selectBestMatch(results []*ErrorResult) (best *ErrorResult) {
+	var bestMatch float32
+	for _, res := range results {
+		for _, m := range res.Matched {
+			if m.Match > bestMatch {
+				best, bestMatch = res, m.Match
+			}
+		}
+	}
+	return
+}
+
+func selectRootCause(results []*ErrorResult) *ErrorResult {
+	last := selectLastError(results)
+	if last == nil {
+		return nil
+	}
+	byError := make(map[*JavaError]*ErrorResult, len(results))
+	for _, res := range results {
+		byError[res.Error] = res
+	}
+	for je := last.Error.CausedBy; je != nil; je = je.CausedBy {
+		if res, ok := byError[je]; ok {
+			last = res
+		}
+	}
+	return last
+}
+
+// terminalResults removes the recovered results, unless all results are recovered
+func terminalResults(results []*ErrorResult) []*ErrorResult {
+	terminals := make([]*ErrorResult, 0, len(results))
+	for _, res := range results {
+		if !res.Recovered {
+			terminals = append(terminals, res)
+		}
+	}
+	if len(terminals) == 0 {
+		return results
+	}
+	return terminals
+}
+
+// LogAnalysis is the aggregated result of a whole log
+type LogAnalysis struct {
+	Results []*ErrorResult `json:"results"`
+	Primary *ErrorResult   `json:"primary,omitempty"`
+	// CollapsedLines is how many repeated lines were skipped, see Analyzer.CollapseRepeatedLines
+	CollapsedLines int64 `json:"collapsedLines,omitempty"`
+	// StoppedEarly reports the log is not fully scanned because of Analyzer.StopOnConfidence,
+	// and the confident result is the primary one
+	StoppedEarly bool `json:"stoppedEarly,omitempty"`
+	// Unmatched are the errors whose best match is below Analyzer.UnmatchedThreshold,
+	// they are still included in Results
+	Unmatched []*JavaError `json:"unmatched,omitempty"`
+	// Incomplete reports the log is truncated, the results are the errors found before the end.
+	// AnalyzeLog returns ErrCrashReportIncomplete together with such an analysis
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Launcher is what the launcher reported, it's only set by AnalyzeLauncherLog when a launcher is detected
+	Launcher *LauncherInfo `json:"launcher,omitempty"`
+	// CrashReport is the parsed crash report, it's only set by AnalyzeCrashReport
+	CrashReport *CrashReport `json:"crashReport,omitempty"`
+}
+
+// unmatchedErrors returns the errors of the results whose best match is below the threshold
+func unmatchedErrors(results []*ErrorResult, threshold float32) (unmatched []*JavaError) {
+	for _, res := range results {
+		var best float32
+		for _, m := range res.Matched {
+			best = max(best, m.Match)
+		}
+		if best < threshold {
+			unmatched = append(unmatched, res.Error)
+		}
+	}
+	return
+}
+
+// AnalyzeLog collects all results of DoLogStream, sorts them by their position in the log,
+// and flags the primary result chosen by the analyzer's PrimarySelector.
+// The recovered results are not passed to the selector unless all results are recovered.
+// If the log is truncated, both the partial analysis and ErrCrashReportIncomplete are returned
+func (a *Analyzer) AnalyzeLog(c context.Context, r io.Reader) (analysis *LogAnalysis, err error) {
+	stream := a.StartLogStream(c, r)
+	results := make([]*ErrorResult, 0, 5)
+	for res := range stream.Results {
+		results = append(results, res)
+	}
+	stream.UpdateRepeatCounts()
+	stopped, incomplete := false, false
+	if err = context.Cause(stream.Context()); err != nil {
+		switch {
+		case errors.Is(err, ErrStoppedOnConfidence):
+			stopped, err = true, nil
+		case errors.Is(err, ErrCrashReportIncomplete):
+			// keep the error, so it's returned together with the partial analysis
+			incomplete = true
+		default:
+			return
+		}
+	}
+	var confident *ErrorResult
+	if stopped && len(results) > 0 {
+		confident = results[len(results)-1]
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Error.LineNo < results[j].Error.LineNo
+	})
+	analysis = &LogAnalysis{
+		Results:        results,
+		CollapsedLines: stream.CollapsedLines(),
+		StoppedEarly:   stopped,
+		Incomplete:     incomplete,
+	}
+	a.selectPrimary(analysis, confident)
+	return
+}
+
+// selectPrimary collects the unmatched errors of the analysis, and flags the primary result.
+// The confident result which stopped the stream is preferred, it can be nil
+func (a *Analyzer) selectPrimary(analysis *LogAnalysis, confident *ErrorResult) {
+	threshold := a.UnmatchedThreshold
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+	analysis.Unmatched = unmatchedErrors(analysis.Results, threshold)
+	selector := a.PrimarySelector
+	if selector == nil {
+		selector = SelectRootCause
+	}
+	primary := confident
+	if primary == nil {
+		primary = selector(terminalResults(analysis.Results))
+	}
+	if primary != nil {
+		primary.Primary = true
+		analysis.Primary = primary
+	}
+}
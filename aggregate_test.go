@@ -0,0 +1,380 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"errors"
+	"strings"
+)
+
+const multiErrorLog = `[12:00:00] [main/INFO]: Loading config
+[12:00:01] [main/ERROR]: Failed to load config file
+java.io.FileNotFoundException: config/foo.toml (No such file or directory)
+	at java.io.FileInputStream.open0(Native Method)
+	at java.io.FileInputStream.open(FileInputStream.java:216)
+[12:00:05] [Render thread/INFO]: Loading textures
+[12:01:00] [Render thread/FATAL]: Unreported exception thrown!
+java.lang.IllegalStateException: Failed to create model
+	at net.minecraft.client.Minecraft.<init>(Minecraft.java:100)
+Caused by: java.lang.NullPointerException: Cannot read field "value" because "x" is null
+	at com.example.mod.Foo.bar(Foo.java:42)
+	... 1 more
+`
+
+func TestAnalyzeLogPrimarySelector(t *testing.T) {
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:   "java.io.FileNotFoundException",
+				Message: "config/foo.toml (No such file or directory)",
+			},
+		},
+	}
+	datas := []struct {
+		Name     string
+		Selector PrimarySelector
+		Expect   string
+	}{
+		{"default", nil, "java.lang.NullPointerException"},
+		{"SelectRootCause", SelectRootCause, "java.lang.NullPointerException"},
+		{"SelectFirstError", SelectFirstError, "java.io.FileNotFoundException"},
+		{"SelectLastError", SelectLastError, "java.lang.IllegalStateException"},
+		{"SelectBestMatch", SelectBestMatch, "java.io.FileNotFoundException"},
+	}
+	for _, d := range datas {
+		analyzer := NewAnalyzer(db)
+		analyzer.PrimarySelector = d.Selector
+		analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(multiErrorLog))
+		if err != nil {
+			t.Fatalf("%s: AnalyzeLog: %v", d.Name, err)
+		}
+		if len(analysis.Results) != 3 {
+			t.Fatalf("%s: Expect 3 results, got %d", d.Name, len(analysis.Results))
+		}
+		if analysis.Primary == nil {
+			t.Errorf("%s: Expect a primary result, got nil", d.Name)
+			continue
+		}
+		if analysis.Primary.Error.Class != d.Expect {
+			t.Errorf("%s: Expect primary error %q, got %q", d.Name, d.Expect, analysis.Primary.Error.Class)
+		}
+		primaries := 0
+		for _, res := range analysis.Results {
+			if res.Primary {
+				primaries++
+			}
+		}
+		if primaries != 1 || !analysis.Primary.Primary {
+			t.Errorf("%s: Expect exactly the primary result to be flagged, got %d flagged", d.Name, primaries)
+		}
+	}
+}
+
+func TestAnalyzeLogStopOnConfidence(t *testing.T) {
+	var log strings.Builder
+	log.WriteString("[12:00:00] [Server thread/ERROR]: Encountered an unexpected exception\n")
+	log.WriteString("java.lang.OutOfMemoryError: Java heap space\n")
+	log.WriteString("\tat java.util.Arrays.copyOf(Arrays.java:3512)\n")
+	for i := 0; i < 10000; i++ {
+		log.WriteString("[12:00:01] [Server thread/ERROR]: Failed to load config file\n")
+		log.WriteString("java.io.FileNotFoundException: config/foo.toml (No such file or directory)\n")
+		log.WriteString("\tat java.io.FileInputStream.open0(Native Method)\n")
+	}
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:   "java.lang.OutOfMemoryError",
+				Message: "Java heap space",
+			},
+		},
+	}
+	analyzer := NewAnalyzer(db)
+	analyzer.StopOnConfidence = 0.95
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log.String()))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if !analysis.StoppedEarly {
+		t.Errorf("Expect the analysis to stop early")
+	}
+	if len(analysis.Results) != 1 {
+		t.Errorf("Expect only the confident result, got %d results", len(analysis.Results))
+	}
+	if analysis.Primary == nil || analysis.Primary.Error.Class != "java.lang.OutOfMemoryError" {
+		t.Errorf("Expect the confident result to be the primary one, got %#v", analysis.Primary)
+	}
+
+	analyzer.StopOnConfidence = 0
+	if analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(log.String())); err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if analysis.StoppedEarly || len(analysis.Results) != 10001 {
+		t.Errorf("Expect the whole log to be scanned without StopOnConfidence, got %d results", len(analysis.Results))
+	}
+}
+
+const recoveredWarningsLog = `[12:00:00] [Worker-Main-1/WARN]: Failed to load texture minecraft:textures/foo.png
+java.io.FileNotFoundException: minecraft:textures/foo.png
+	at net.minecraft.server.packs.VanillaPackResources.getResource(VanillaPackResources.java:95)
+[12:00:02] [Server thread/WARN]: Can't keep up! Is the server overloaded?
+java.util.concurrent.TimeoutException: Tick took too long
+	at net.minecraft.server.MinecraftServer.tickServer(MinecraftServer.java:834)
+[12:01:00] [Render thread/FATAL]: Unreported exception thrown!
+java.lang.IllegalStateException: Failed to create model
+	at net.minecraft.client.Minecraft.<init>(Minecraft.java:100)
+[12:01:01] [Render thread/WARN]: Failed to save options
+java.io.IOException: Stream closed
+	at net.minecraft.client.Options.save(Options.java:1012)
+`
+
+func TestAnalyzeLogRecoveredWarnings(t *testing.T) {
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:   "java.io.FileNotFoundException",
+				Message: "minecraft:textures/foo.png",
+			},
+		},
+	}
+	for _, selector := range []PrimarySelector{nil, SelectBestMatch, SelectFirstError} {
+		analyzer := NewAnalyzer(db)
+		analyzer.PrimarySelector = selector
+		analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(recoveredWarningsLog))
+		if err != nil {
+			t.Fatalf("AnalyzeLog: %v", err)
+		}
+		if len(analysis.Results) != 4 {
+			t.Fatalf("Expect 4 results, got %d", len(analysis.Results))
+		}
+		for i, expect := range []bool{true, true, false, true} {
+			if res := analysis.Results[i]; res.Recovered != expect {
+				t.Errorf("Expect result %d (%s, level %q) recovered == %v", i, res.Error.Class, res.Error.Level, expect)
+			}
+		}
+		if analysis.Primary == nil || analysis.Primary.Error.Class != "java.lang.IllegalStateException" {
+			t.Errorf("Expect the fatal error to be the primary one, got %#v", analysis.Primary)
+		}
+	}
+
+	analyzer := NewAnalyzer(db)
+	analyzer.RecoveredLevels = []string{}
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(recoveredWarningsLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if analysis.Primary == nil || analysis.Primary.Error.Class != "java.io.IOException" {
+		t.Errorf("Expect the last error to be the primary one when no level is recovered, got %#v", analysis.Primary)
+	}
+}
+
+const duplicateCauseLog = `[12:00:00] [Render thread/FATAL]: Unreported exception thrown!
+java.lang.RuntimeException: Failed to tick
+	at net.minecraft.client.Minecraft.tick(Minecraft.java:100)
+Caused by: java.lang.RuntimeException: Failed to tick
+	at net.minecraft.client.Minecraft.tick(Minecraft.java:100)
+Caused by: java.lang.NullPointerException: null
+	at com.example.mod.Foo.bar(Foo.java:42)
+	... 1 more
+`
+
+func TestAnalyzeLogDuplicateCause(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(duplicateCauseLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 2 {
+		t.Fatalf("Expect the duplicate cause to be skipped, got %d results", len(analysis.Results))
+	}
+	if c := analysis.Results[0].Error.Class; c != "java.lang.RuntimeException" {
+		t.Errorf("Expect the first result to be the parent, got %q", c)
+	}
+	if c := analysis.Results[1].Error.Class; c != "java.lang.NullPointerException" {
+		t.Errorf("Expect the second result to be the real cause, got %q", c)
+	}
+	if analysis.Primary != analysis.Results[1] {
+		t.Errorf("Expect the root cause to be the primary one, got %#v", analysis.Primary)
+	}
+
+	analyzer.KeepDuplicateCauses = true
+	if analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(duplicateCauseLog)); err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 3 {
+		t.Errorf("Expect the duplicate cause to be kept, got %d results", len(analysis.Results))
+	}
+}
+
+const summaryOnlyLog = `---- Minecraft Crash Report ----
+// Who set us up the TNT?
+
+Time: 2024-01-01 12:00:00
+Description: Mixin apply for mod examplemod failed examplemod.mixins.json:MixinFoo
+`
+
+func TestAnalyzeLogSummaryOnly(t *testing.T) {
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:   "org.spongepowered.asm.mixin.transformer.throwables.MixinTransformerError",
+				Message: "Mixin apply for mod * failed *",
+			},
+			{
+				Error:   "java.lang.OutOfMemoryError",
+				Message: "Java heap space",
+			},
+		},
+	}
+	analyzer := NewAnalyzer(db)
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(summaryOnlyLog))
+	// the partial paste is a truncated crash report
+	if !errors.Is(err, ErrCrashReportIncomplete) {
+		t.Fatalf("Expect err == %v, got %v", ErrCrashReportIncomplete, err)
+	}
+	if len(analysis.Results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+	}
+	res := analysis.Results[0]
+	if !res.SummaryOnly {
+		t.Errorf("Expect the result to be summary only")
+	}
+	if res.Error.LineNo != 5 {
+		t.Errorf("Expect line %d, got %d", 5, res.Error.LineNo)
+	}
+	if len(res.Matched) == 0 || !sameDesc(res.Matched[0].ErrorDesc, db.errors[0]) {
+		t.Errorf("Expect the summary to match %q, got %#v", db.errors[0].Message, res.Matched)
+	}
+
+	analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(summaryOnlyLog+"\n"+multiErrorLog))
+	if !errors.Is(err, ErrCrashReportIncomplete) {
+		t.Fatalf("Expect err == %v, got %v", ErrCrashReportIncomplete, err)
+	}
+	for _, res := range analysis.Results {
+		if res.SummaryOnly {
+			t.Errorf("Expect no summary only result when there are exceptions, got %#v", res.Error)
+		}
+	}
+}
+
+func TestAnalyzeLogResultFilter(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analyzer.ResultFilter = func(res *ErrorResult) bool {
+		return res.Error.Level == "FATAL"
+	}
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(recoveredWarningsLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+	}
+	if res := analysis.Results[0]; res.Error.Class != "java.lang.IllegalStateException" {
+		t.Errorf("Expect only the fatal error %q, got %q", "java.lang.IllegalStateException", res.Error.Class)
+	}
+}
+
+func TestAnalyzeLogUnmatched(t *testing.T) {
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:   "java.io.FileNotFoundException",
+				Message: "config/foo.toml (No such file or directory)",
+			},
+		},
+	}
+	analyzer := NewAnalyzer(db)
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(multiErrorLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 3 {
+		t.Fatalf("Expect 3 results, got %d", len(analysis.Results))
+	}
+	expects := []string{"java.lang.IllegalStateException", "java.lang.NullPointerException"}
+	if len(analysis.Unmatched) != len(expects) {
+		t.Fatalf("Expect %d unmatched errors, got %d", len(expects), len(analysis.Unmatched))
+	}
+	for i, expect := range expects {
+		if je := analysis.Unmatched[i]; je.Class != expect {
+			t.Errorf("Expect unmatched error %d == %q, got %q", i, expect, je.Class)
+		}
+	}
+
+	analyzer.UnmatchedThreshold = 1.1
+	if analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(multiErrorLog)); err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Unmatched) != 3 {
+		t.Errorf("Expect all errors unmatched with a higher threshold, got %d", len(analysis.Unmatched))
+	}
+}
+
+func TestAnalyzeLogIncomplete(t *testing.T) {
+	const report = `---- Minecraft Crash Report ----
+// Who set us up the TNT?
+
+Time: 2024-01-02 12:00:00
+Description: Ticking entity
+
+java.lang.NullPointerException: Cannot invoke "net.minecraft.world.entity.Entity.getId()" because "entity" is null
+	at com.example.mod.Foo.bar(Foo.java:42)
+	at net.minecraft.server.level.ServerLevel.tickNonPassenger(ServerLevel.java:693)
+
+
+A detailed walkthrough of the error, its code path and all known details is as follows:
+---------------------------------------------------------------------------------------
+
+-- Head --
+Thread: Server thread
+Stacktrace:
+	at com.example.mod.Foo.bar(Foo.java:42)
+`
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:   "java.lang.NullPointerException",
+				Message: `Cannot invoke "net.minecraft.world.entity.Entity.getId()" because "entity" is null`,
+			},
+		},
+	}
+	analyzer := NewAnalyzer(db)
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(report))
+	if !errors.Is(err, ErrCrashReportIncomplete) {
+		t.Fatalf("Expect err == %v, got %v", ErrCrashReportIncomplete, err)
+	}
+	if analysis == nil {
+		t.Fatalf("Expect the partial analysis, got nil")
+	}
+	if !analysis.Incomplete {
+		t.Errorf("Expect analysis.Incomplete == true")
+	}
+	if len(analysis.Results) != 1 || analysis.Results[0].Error.Class != "java.lang.NullPointerException" {
+		t.Fatalf("Expect the NullPointerException before the end, got %d results", len(analysis.Results))
+	}
+	if analysis.Primary != analysis.Results[0] {
+		t.Errorf("Expect the partial result to be the primary one")
+	}
+
+	multi, err := analyzer.AnalyzeLogs(context.Background(), []LabeledLog{
+		{Label: "client", Reader: strings.NewReader(report)},
+		{Label: "server", Reader: strings.NewReader(multiErrorLog)},
+	})
+	if !errors.Is(err, ErrCrashReportIncomplete) {
+		t.Fatalf("AnalyzeLogs: Expect err == %v, got %v", ErrCrashReportIncomplete, err)
+	}
+	if multi == nil || multi.Logs["client"] == nil || !multi.Logs["client"].Incomplete || multi.Logs["server"] == nil || multi.Logs["server"].Incomplete {
+		t.Fatalf("AnalyzeLogs: Expect only the client log to be incomplete, got %#v", multi)
+	}
+
+	// the complete report is not flagged
+	full := report + "\n-- System Details --\nDetails:\n\tMinecraft Version: 1.20.1\n"
+	analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(full))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if analysis.Incomplete || len(analysis.Results) != 1 {
+		t.Errorf("Expect a complete analysis with 1 result, got incomplete=%v with %d results", analysis.Incomplete, len(analysis.Results))
+	}
+}
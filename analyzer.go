@@ -1,26 +1,93 @@
 package mcla
 
 import (
+	"bufio"
 	"bytes"
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"log"
 	"regexp"
+	"runtime"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/kmcsr/go-ringbuf"
+	"unicode/utf8"
 )
 
 type SolutionPossibility struct {
 	ErrorDesc *ErrorDesc `json:"errorDesc"`
 	Match     float32    `json:"match"`
+	Source    string     `json:"source,omitempty"` // the database file of the ErrorDesc
+	ID        string     `json:"id,omitempty"`     // the stable id of the ErrorDesc
+	// Captures are the groups captured by the message of the ErrorDesc if it's a regular expression,
+	// the keys are the group names, or the group indexes for the unnamed groups.
+	// Some built-in hard coded checks capture the snippets they extract from the error, e.g. the class and the method
+	Captures map[string]string `json:"captures,omitempty"`
+	// Description is the description of the ErrorDesc in Analyzer.Locale, see ErrorDesc.LocalizedDescription
+	Description string `json:"description,omitempty"`
+	// Severity is the severity of the ErrorDesc, SeverityUnknown if the description does not set it
+	Severity Severity `json:"severity"`
+	// Links are the links of the ErrorDesc
+	Links []Link `json:"links,omitempty"`
+	// Detail is how Match is computed, it's only set by Analyzer.ExplainMatches for the descriptions from DB
+	Detail *MatchDetail `json:"detail,omitempty"`
+}
+
+// MatchDetail is the breakdown of a match score, see Analyzer.ExplainMatches.
+// Before the signals, the context and the specificity are applied, the score is Class + Message
+type MatchDetail struct {
+	// Class is the score from the error type after weighting, it's 0 if the error type is ignored
+	Class float32 `json:"class"`
+	// MessageSimilarity is the similarity of the messages in [0, 1], it's 0 if the description does not have a message
+	MessageSimilarity float32 `json:"messageSimilarity"`
+	// Message is the score from the message after weighting
+	Message float32 `json:"message"`
+	// Wildcard reports the error type of the description is a wildcard, i.e. `*`, `*.*` or `pkg.*`
+	Wildcard bool `json:"wildcard,omitempty"`
+	// Specificity is the penalty multiplied for a generic description, see the comment of descSpecificity
+	Specificity float32 `json:"specificity"`
 }
 
 type ErrorResult struct {
 	Error   *JavaError            `json:"error"`
 	Matched []SolutionPossibility `json:"matched"`
 	File    string                `json:"file,omitempty"`
+	Primary bool                  `json:"primary,omitempty"`
+
+	Location *CrashLocation `json:"location,omitempty"`
+	// Recovered reports the error is logged at a level in Analyzer.RecoveredLevels, so the game probably kept running
+	Recovered bool     `json:"recovered,omitempty"`
+	Category  Category `json:"category"`
+	// SummaryOnly reports the result is derived from a `Description:` or `Reason:` line only,
+	// since the log does not contain any structured exception. Its Error only has the Message and LineNo
+	SummaryOnly bool `json:"summaryOnly,omitempty"`
+	// Mixin is the mod which registered the failing mixin, only set for the mixin errors
+	Mixin *MixinSource `json:"mixin,omitempty"`
+	// MixinLogs are the recent mixin log lines before the error, oldest first, see Analyzer.RecentMixinLogs
+	MixinLogs []string `json:"mixinLogs,omitempty"`
+	// Logs are the recent log lines of the other categories before the error by their categories, oldest first,
+	// see Analyzer.LogRules and Analyzer.RecentLogs
+	Logs map[string][]string `json:"logs,omitempty"`
+	// StartLine and EndLine are the first and the last line of the error in the log, including its causes,
+	// and StartOffset and EndOffset are the byte offsets of the span, the EndOffset is exclusive.
+	// They are copied from the error, so a viewer can highlight the error without parsing it again
+	StartLine   int   `json:"startLine,omitempty"`
+	EndLine     int   `json:"endLine,omitempty"`
+	StartOffset int64 `json:"startOffset,omitempty"`
+	EndOffset   int64 `json:"endOffset,omitempty"`
+	// Incomplete reports the error reaches the end of the log, so it may be cut off, see JavaError.Truncated.
+	// If the log ends in a crash report, the stream's context is also canceled with ErrCrashReportIncomplete
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Count is how many times the error is repeated in the log, and LastLine is the line of the last one,
+	// the first one is StartLine. They are only set when Analyzer.DedupErrors is enabled,
+	// and a result sent by a stream only counts itself until LogStream.UpdateRepeatCounts is called
+	Count    int `json:"count,omitempty"`
+	LastLine int `json:"lastLine,omitempty"`
 }
 
 var (
@@ -28,180 +95,995 @@ var (
 )
 
 type Analyzer struct {
+	// DB is the database of the error descriptions.
+	// It should not be assigned after the Analyzer is used, call SetDB to switch the database instead
 	DB ErrorDB
+	// PrimarySelector decides which result is the primary one in AnalyzeLog, default is SelectRootCause
+	PrimarySelector PrimarySelector
+	// CorrelationWindow is the max time difference to link errors in AnalyzeLogs, default is 5 seconds
+	CorrelationWindow time.Duration
+	// MinFuzzyLength is the minimum length in characters both messages must have to be compared fuzzily.
+	// Shorter messages only match when one contains the other, since a few characters are similar to almost anything.
+	// Default is 8, a negative value disables the guard
+	MinFuzzyLength int
+	// CollapseRepeatedLines skips the lines which are exactly same as the previous line before parsing,
+	// so a log spammed by a logging loop costs much less. The line numbers are not affected
+	CollapseRepeatedLines bool
+	// SkipFramePrefixes are the class prefixes skipped when locating a crash, default is DefaultSkipFramePrefixes
+	SkipFramePrefixes []string
+	// StopOnConfidence stops scanning the log once a result has a match greater than or equal to it,
+	// the stream's context is then canceled with ErrStoppedOnConfidence.
+	// It's useful when only the answer is needed, but the errors after that result will be missed.
+	// Zero disables it
+	StopOnConfidence float32
+	// RecoveredLevels are the log levels of the errors which are not the cause of the crash,
+	// AnalyzeLog only picks the primary result from them when there are no other results.
+	// Default is DefaultRecoveredLevels
+	RecoveredLevels []string
+	// DescID decides the stable ids of the descriptions in DB, default is DefaultDescID
+	DescID DescIDFunc
+	// KeepDuplicateCauses analyzes a cause even if it has the same fingerprint as its parent.
+	// By default, such a cause is skipped since it only produces a duplicate result
+	KeepDuplicateCauses bool
+	// StopWords are the words removed from both the error message and the description message
+	// before they are compared fuzzily, so the distinctive words dominate the score.
+	// They are compared case insensitively. Default is DefaultStopWords, an empty slice disables it
+	StopWords []string
+	// OnErrorsRefresh is called with the difference after the descriptions are reloaded from DB,
+	// it is not called for the first load. It can be nil
+	OnErrorsRefresh func(delta *ErrorsDelta)
+	// ResultFilter drops the results which it returns false for before they are emitted,
+	// the dropped results cannot stop the stream by StopOnConfidence. It can be nil
+	ResultFilter func(res *ErrorResult) bool
+	// ResultCacheSize is the max number of errors whose matches of the descriptions are memoized by the Analyzer,
+	// so a repeated error does not match all descriptions again. The errors are identified by their class, message,
+	// stacktrace and context lines without the timestamps, and the metadata of the log.
+	// The least recently used error is evicted when it's full, and the cache is cleared when the descriptions are reloaded.
+	// The options which affect the matching should not be changed after the cache is used. Zero disables the cache
+	ResultCacheSize int
+	// SimilarityCacheSize is the max number of message similarities cached during a single stream run,
+	// which speeds up the logs with many repeated errors. The cache is cleared when it's full.
+	// Zero disables the cache
+	SimilarityCacheSize int
+	// UnmatchedThreshold is the score an error's best match must reach to be excluded from LogAnalysis.Unmatched,
+	// default is 0.5
+	UnmatchedThreshold float32
+	// Anonymize redacts the home directories, usernames, IP addresses and UUIDs in the results,
+	// after the errors are matched. The errors are modified in place
+	Anonymize bool
+	// ReadBufferSize is the size of the buffer wrapped around the log reader of a stream,
+	// so an unbuffered reader, e.g. a file or a network connection, is not read in many small pieces.
+	// Default is DefaultReadBufferSize, a negative value disables it
+	ReadBufferSize int
+	// ResultBufferSize is the capacity of the result channel of a stream, default is 3.
+	// A larger buffer lets the analysis run ahead of a slow consumer, at the cost of holding more results in memory
+	ResultBufferSize int
+	// DropPolicy decides what happens when the result channel is full, default is DropNone which waits for the consumer.
+	// The other policies keep the analysis running for the consumers which prefer responsiveness,
+	// but the dropped results are lost, so they should not be used when all results are needed, e.g. in AnalyzeLog.
+	// The result which stops the stream by StopOnConfidence is never dropped
+	DropPolicy DropPolicy
+	// CacheTTL is how long the descriptions loaded from DB are used before they are reloaded automatically,
+	// default is one hour. A negative value disables the automatic reload, so the descriptions are only
+	// reloaded by UpdateErrors, which keeps the results deterministic in a batch job
+	CacheTTL time.Duration
+	// FuzzyMessageMatch also compares the messages after masking the paths, UUIDs, hex ids and numbers,
+	// and by the edit distance of the words, so the messages which only differ in such parts still match.
+	// A message which only matches after masking scores at most 0.95, so it ranks below an exact match
+	FuzzyMessageMatch bool
+	// OnInvalidDesc is called with the descriptions skipped when loading DB since they are invalid,
+	// e.g. the message is not a valid regular expression. It's also called with an *InvalidLinkErr for a malformed link,
+	// in that case only the link is skipped. Default logs them with the standard logger
+	OnInvalidDesc func(e *ErrorDesc, err error)
+	// MatchWeights are the weights of the error type and the message when matching, default is DefaultMatchWeights
+	MatchWeights MatchWeights
+	// MatchWorkers is how many goroutines match the descriptions against an error at the same time,
+	// default is 1 which matches them sequentially, and a negative value uses GOMAXPROCS.
+	// The matches are in the same order either way
+	MatchWorkers int
+	// DedupErrors merges the repeated errors in DoLogStream, which have the same class, the same message
+	// after masking the numbers, ids and paths, and the same top frame, including their causes.
+	// Each distinct error is analyzed once and sent as soon as it's first seen, the repeats are not sent.
+	// AnalyzeLog and LogStream.UpdateRepeatCounts set ErrorResult.Count and ErrorResult.LastLine to the repeats
+	DedupErrors bool
+	// MinMatch drops the matches which score lower than it, default is 0 which keeps all nonzero matches
+	MinMatch float32
+	// MaxResults keeps at most this many matches of an error, the best ones are kept.
+	// Default is 0 which keeps all matches
+	MaxResults int
+	// Tokenizer splits the messages into the tokens compared by the message matching,
+	// e.g. NGramTokenizer for the localized logs. Default compares the characters like RuneTokenizer
+	Tokenizer Tokenizer
+	// Locale selects the translation of the descriptions in SolutionPossibility.Description, e.g. "zh-CN",
+	// see ErrorDesc.LocalizedDescription. Default is empty which uses ErrorDesc.Description
+	Locale string
+	// OnProgress is called with how much of the log a stream has read, at most once per 64 KiB.
+	// It's called on the goroutine which reads the log, so it should return quickly.
+	// After the whole log is read, it's called once more with Read == Total.
+	// The size of the log is known if the reader has a `Len() int` or `Size() int64` method, or it's an io.Seeker.
+	// It can be nil
+	OnProgress func(p Progress)
+	// KeepANSI does not strip the ANSI escape sequences (e.g. the colors `\x1b[31m` of a console) from the log lines
+	// before they are parsed. Stripping only costs a byte search for the lines without ESC,
+	// so it's only useful for the logs which are known clean. The byte offsets always refer to the original log
+	KeepANSI bool
+	// ExplainMatches fills SolutionPossibility.Detail with the breakdown of each score, for debugging the ranking.
+	// Default is false which keeps the results small
+	ExplainMatches bool
+	// MixinLogPatterns match the mixin log lines recorded for ErrorResult.MixinLogs and RecentMixinLogs,
+	// the first capture group is recorded, or the whole match if the pattern has no group.
+	// A line is recorded by the first pattern which matches it.
+	// Default is DefaultMixinLogPatterns, an empty slice disables the recording
+	MixinLogPatterns []*regexp.Regexp
+	// LogRules record the other interesting log lines in ErrorResult.Logs and RecentLogs by their categories,
+	// the mixin log lines are always recorded by MixinLogPatterns. A line may be recorded in several categories,
+	// but only once in each. Default is DefaultLogRules, an empty slice disables them
+	LogRules []LogRule
+
+	rulesMux       sync.RWMutex
+	hardCodedRules []HardCodedRule
 
 	errMux        sync.RWMutex
 	lastUpdateErr time.Time
 	cachedErrors  []*ErrorDesc
+	loading       *errorsLoad // the running load of the descriptions, it's nil if there is none
+	invalidations uint64      // the count of InvalidateCache calls
+	loadedGen     uint64      // the invalidations before the last successful load started
+	results       resultCache
 
-	recentMixinLogs *ringbuf.RingBuffer[string]
+	logsMux  sync.Mutex
+	lastLogs *recentLogs // the recent logs of the last started stream, see RecentLogs
 }
 
 func NewAnalyzer(db ErrorDB) (a *Analyzer) {
 	return &Analyzer{
-		DB:              db,
-		recentMixinLogs: ringbuf.NewRingBuffer[string](64),
+		DB: db,
 	}
 }
 
+// UpdateErrors reloads the error descriptions from DB now.
+// It returns an error if the descriptions cannot be loaded or their ids are not unique,
+// in that case the previously loaded descriptions are kept
 func (a *Analyzer) UpdateErrors() (err error) {
+	return a.UpdateErrorsContext(context.Background())
+}
+
+// UpdateErrorsContext is same as UpdateErrors, but stops loading when the context is done.
+// The loading can only be stopped between the descriptions unless DB implements ContextErrorDB
+func (a *Analyzer) UpdateErrorsContext(ctx context.Context) (err error) {
+	return a.loadErrors(ctx, true)
+}
+
+// InvalidateCache marks the loaded descriptions as stale, so they are reloaded from DB before the next analysis,
+// regardless of CacheTTL. The descriptions are not dropped, so the running analyses keep using them,
+// and the analyses started during the reload wait for it.
+// A load which is running when the cache is invalidated does not count as the reload.
+// The files cached by DB are not touched, e.g. call ghdb.ErrDB.PurgeCache first to pick up the upstream changes.
+// It's safe to call concurrently with the analyses and DoLogStream
+func (a *Analyzer) InvalidateCache() {
 	a.errMux.Lock()
 	defer a.errMux.Unlock()
-	return a.updateErrorsLocked()
+	a.invalidations++
+}
+
+// SetDB switches the database of the error descriptions, it's safe to call concurrently with the analyses and DoLogStream.
+// The descriptions loaded from the previous database are dropped, and they are loaded from the new database
+// before the next analysis as if it's the first load, so OnErrorsRefresh is not called for it.
+// The analyses which are running keep using the descriptions they have read
+func (a *Analyzer) SetDB(db ErrorDB) {
+	a.errMux.Lock()
+	defer a.errMux.Unlock()
+	a.DB = db
+	a.cachedErrors = nil
+	a.lastUpdateErr = time.Time{}
+	a.invalidations++
+	a.results.clear()
+}
+
+// currentDB returns DB, it's safe to call concurrently with SetDB
+func (a *Analyzer) currentDB() ErrorDB {
+	a.errMux.RLock()
+	defer a.errMux.RUnlock()
+	return a.DB
+}
+
+const defaultCacheTTL = time.Hour
+
+// errorsLoad is a running load of the descriptions, the concurrent loads wait for it instead of loading again
+type errorsLoad struct {
+	done    chan struct{} // closed when the load is finished
+	err     error
+	stopped bool   // the load is stopped by the context of its caller
+	gen     uint64 // the invalidations when the load started
+}
+
+// errorsStale reports if the descriptions should be reloaded, the caller must hold errMux
+func (a *Analyzer) errorsStale() bool {
+	if a.lastUpdateErr.IsZero() || a.loadedGen != a.invalidations {
+		return true
+	}
+	ttl := a.CacheTTL
+	if ttl == 0 {
+		ttl = defaultCacheTTL
+	}
+	if ttl < 0 {
+		return false
+	}
+	return time.Now().After(a.lastUpdateErr.Add(ttl))
+}
+
+// loadErrors reloads the descriptions when they are stale or force is true.
+// Only one load runs at the same time, the others wait for its result. DB is not iterated under errMux,
+// so the descriptions loaded before can still be read during the load
+func (a *Analyzer) loadErrors(ctx context.Context, force bool) error {
+	for {
+		a.errMux.Lock()
+		if l := a.loading; l != nil {
+			a.errMux.Unlock()
+			select {
+			case <-l.done:
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+			a.errMux.RLock()
+			invalidated := l.gen != a.invalidations
+			a.errMux.RUnlock()
+			if !force && !l.stopped && !invalidated {
+				return l.err
+			}
+			// the running load may not see the changes which the caller wants to reload,
+			// or it's stopped by the context of another caller
+			continue
+		}
+		if !force && !a.errorsStale() {
+			a.errMux.Unlock()
+			return nil
+		}
+		l := &errorsLoad{done: make(chan struct{}), gen: a.invalidations}
+		a.loading = l
+		db := a.DB
+		a.errMux.Unlock()
+
+		errors, err := a.readErrors(ctx, db)
+		var delta *ErrorsDelta
+		a.errMux.Lock()
+		if err == nil {
+			if !a.lastUpdateErr.IsZero() {
+				delta = diffErrors(a.cachedErrors, errors)
+			}
+			a.lastUpdateErr = time.Now()
+			a.cachedErrors = errors
+			a.loadedGen = l.gen
+			a.results.clear()
+		}
+		a.loading = nil
+		a.errMux.Unlock()
+		l.err, l.stopped = err, err != nil && ctx.Err() != nil
+		close(l.done)
+		a.notifyRefresh(delta)
+		return err
+	}
 }
 
-func (a *Analyzer) updateErrorsLocked() (err error) {
-	errors := make([]*ErrorDesc, 0, 64)
-	if err = a.DB.ForEachErrors(func(e *ErrorDesc) error {
+// readErrors reads all descriptions from DB and assigns their ids
+func (a *Analyzer) readErrors(ctx context.Context, db ErrorDB) (errors []*ErrorDesc, err error) {
+	errors = make([]*ErrorDesc, 0, 64)
+	if err = forEachErrors(ctx, db, func(e *ErrorDesc) error {
 		errors = append(errors, e)
 		return nil
 	}); err != nil {
-		return
+		return nil, err
+	}
+	if err = a.assignDescIDs(errors); err != nil {
+		return nil, err
 	}
-	a.lastUpdateErr = time.Now()
-	a.cachedErrors = errors
+	for i, e := range errors {
+		if len(e.Links) > 0 {
+			errors[i] = a.checkLinks(e)
+		}
+	}
+	// the invalid descriptions are skipped after the ids are assigned, so the ids do not depend on them
+	errors = slices.DeleteFunc(errors, func(e *ErrorDesc) bool {
+		if er := checkDescPatterns(e); er != nil {
+			a.invalidDesc(e, er)
+			return true
+		}
+		return false
+	})
 	return
 }
 
-func (a *Analyzer) getErrors() []*ErrorDesc {
+// checkDescPatterns returns the error of the first invalid regular expression of the description,
+// which are the regex messages, the MustNotMatch pattern, the context pattern and the signal patterns
+func checkDescPatterns(e *ErrorDesc) error {
+	if e.MessageIsRegex {
+		for _, message := range append([]string{e.Message}, e.Messages...) {
+			if _, err := compilePattern(messagePattern(message)); err != nil {
+				return err
+			}
+		}
+	}
+	if e.MustNotMatch != "" {
+		if _, err := compilePattern(e.MustNotMatch); err != nil {
+			return fmt.Errorf("mustNotMatch: %w", err)
+		}
+	}
+	if e.Context != "" {
+		if _, err := compilePattern(e.Context); err != nil {
+			return fmt.Errorf("context: %w", err)
+		}
+	}
+	for i, s := range e.Signals {
+		if _, err := compilePattern(s.Pattern); err != nil {
+			return fmt.Errorf("signals[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (a *Analyzer) invalidDesc(e *ErrorDesc, err error) {
+	if a.OnInvalidDesc != nil {
+		a.OnInvalidDesc(e, err)
+		return
+	}
+	var linkErr *InvalidLinkErr
+	if errors.As(err, &linkErr) {
+		log.Printf("mcla: skipped the invalid link of the error description %s: %v", e.ID, err)
+		return
+	}
+	log.Printf("mcla: skipped the invalid error description %s: %v", e.ID, err)
+}
+
+// notifyRefresh is called with the difference after a reload, the delta is nil if it's the first load or the load failed
+func (a *Analyzer) notifyRefresh(delta *ErrorsDelta) {
+	if delta != nil && a.OnErrorsRefresh != nil {
+		a.OnErrorsRefresh(delta)
+	}
+}
+
+// getErrors returns the loaded descriptions, and reloads them first if they are stale.
+// If the reload fails, the descriptions loaded before are returned
+func (a *Analyzer) getErrors(ctx context.Context) []*ErrorDesc {
+	errors, _ := a.getErrorsGen(ctx)
+	return errors
+}
+
+// getErrorsGen is same as getErrors, and also returns the generation of the result cache which the descriptions belong to
+func (a *Analyzer) getErrorsGen(ctx context.Context) (errors []*ErrorDesc, gen uint64) {
 	a.errMux.RLock()
-	needUpdate := a.lastUpdateErr.IsZero() || time.Now().After(a.lastUpdateErr.Add(time.Hour))
+	errors, gen, stale := a.cachedErrors, a.results.generation(), a.errorsStale()
 	a.errMux.RUnlock()
-	if needUpdate {
-		a.errMux.Lock()
-		if a.lastUpdateErr.IsZero() || time.Now().After(a.lastUpdateErr.Add(time.Hour)) {
-			a.updateErrorsLocked()
-		}
-		a.errMux.Unlock()
+	if !stale {
+		return
 	}
-	return a.cachedErrors
+	a.loadErrors(ctx, false)
+	a.errMux.RLock()
+	defer a.errMux.RUnlock()
+	return a.cachedErrors, a.results.generation()
 }
 
+// DoError matches the error against the descriptions, the matches are sorted by Match descending.
+// The ties are ordered by how specific the error type is, e.g. the exact class before `*.Name`,
+// then by the error type and the message of the descriptions
 func (a *Analyzer) DoError(jerr *JavaError) (matched []SolutionPossibility, err error) {
-	e, _ := a.HardCodedChecks(jerr)
-	if e != nil {
+	return a.DoErrorWithMetadata(jerr, nil)
+}
+
+// DoErrorContext is same as DoError, but stops loading the descriptions and matching them when the context is done
+func (a *Analyzer) DoErrorContext(ctx context.Context, jerr *JavaError) (matched []SolutionPossibility, err error) {
+	return a.doError(ctx, jerr, nil, nil, nil)
+}
+
+// DoErrorWithMetadata is same as DoError, but skips the descriptions which are scoped out by the metadata.
+// The metadata can be nil
+func (a *Analyzer) DoErrorWithMetadata(jerr *JavaError, meta *LogMetadata) (matched []SolutionPossibility, err error) {
+	return a.doError(context.Background(), jerr, meta, nil, nil)
+}
+
+// matchCheckInterval is how many descriptions are matched between the checks of the context
+const matchCheckInterval = 64
+
+// doError is same as DoErrorWithMetadata, the cache and the recent logs before the error can be nil
+func (a *Analyzer) doError(ctx context.Context, jerr *JavaError, meta *LogMetadata, cache *similarityCache, logs logSnapshot) (matched []SolutionPossibility, err error) {
+	e, leading, _ := a.hardCodedChecks(jerr, meta, logs)
+	if e != nil && !leading {
 		return []SolutionPossibility{
 			SolutionPossibility{
-				ErrorDesc: e,
-				Match:     1,
+				ErrorDesc:   e,
+				Match:       1,
+				ID:          e.ID,
+				Captures:    hardCodedCaptures(e),
+				Description: e.LocalizedDescription(a.Locale),
+				Severity:    severityOf(e),
+				Links:       e.Links,
 			},
 		}, nil
 	}
-	epkg, ecls := rsplit(jerr.Class, '.')
-	for _, e := range a.getErrors() {
-		sol := SolutionPossibility{
+	descs, gen := a.getErrorsGen(ctx)
+	if matched, err = a.matchDescsCached(ctx, jerr, meta, descs, gen, cache); err != nil {
+		return nil, err
+	}
+	if len(descs) == 0 && ctx.Err() != nil { // the loading is stopped
+		return nil, context.Cause(ctx)
+	}
+	if e, _ := a.hardCodedFallbackChecks(jerr); e != nil {
+		matched = append(matched, SolutionPossibility{
 			ErrorDesc: e,
+			Match:     fallbackMatch,
+			ID:        e.ID,
+			Captures:  hardCodedCaptures(e),
+		})
+	}
+	sortMatches(matched)
+	if e != nil {
+		matched = slices.Insert(matched, 0, SolutionPossibility{
+			ErrorDesc: e,
+			Match:     1,
+			ID:        e.ID,
+			Captures:  hardCodedCaptures(e),
+		})
+	}
+	matched = a.limitMatches(matched)
+	if matched == nil {
+		matched = make([]SolutionPossibility, 0)
+	}
+	for i := range matched {
+		matched[i].Description = matched[i].ErrorDesc.LocalizedDescription(a.Locale)
+		matched[i].Severity = severityOf(matched[i].ErrorDesc)
+		matched[i].Links = matched[i].ErrorDesc.Links
+	}
+	return
+}
+
+// sortMatches sorts the matches by Match descending, the ties are ordered by errorTypeSpecificity, the error type and then
+// the message of the descriptions, and the ones which are still same keep the order of the descriptions
+func sortMatches(matched []SolutionPossibility) {
+	slices.SortStableFunc(matched, func(x, y SolutionPossibility) int {
+		if c := cmp.Compare(y.Match, x.Match); c != 0 {
+			return c
 		}
-		epkg2, ecls2 := rsplit(e.Error, '.')
-		ignoreErrorTyp := len(ecls2) == 0 || ecls2 == "*"
-		if !ignoreErrorTyp && ecls2 == ecls { // error type weight: 10%
-			if epkg2 == "*" || epkg == epkg2 {
-				sol.Match = 0.1 // 10%
-			} else {
-				sol.Match = 0.05 // 5%
+		xExact, xLiteral := errorTypeSpecificity(x.ErrorDesc.Error)
+		yExact, yLiteral := errorTypeSpecificity(y.ErrorDesc.Error)
+		if xExact != yExact {
+			if xExact {
+				return -1
 			}
+			return 1
+		}
+		if c := cmp.Compare(yLiteral, xLiteral); c != 0 {
+			return c
+		}
+		if c := strings.Compare(x.ErrorDesc.Error, y.ErrorDesc.Error); c != 0 {
+			return c
+		}
+		return strings.Compare(x.ErrorDesc.Message, y.ErrorDesc.Message)
+	})
+}
+
+// limitMatches applies MinMatch and MaxResults to the sorted matches
+func (a *Analyzer) limitMatches(matched []SolutionPossibility) []SolutionPossibility {
+	if a.MinMatch > 0 {
+		matched = slices.DeleteFunc(matched, func(m SolutionPossibility) bool {
+			return m.Match < a.MinMatch
+		})
+	}
+	if a.MaxResults > 0 && len(matched) > a.MaxResults {
+		matched = matched[:a.MaxResults]
+	}
+	return matched
+}
+
+// minParallelDescs is the least descriptions to match in parallel, fewer ones are not worth the goroutines
+const minParallelDescs = 256
+
+// matchDescs matches the descriptions with MatchWorkers goroutines, each of them matches a contiguous part of descs,
+// and the parts are joined in order, so the matches are in the order of descs
+func (a *Analyzer) matchDescs(ctx context.Context, jerr *JavaError, meta *LogMetadata, descs []*ErrorDesc, cache *similarityCache) (matched []SolutionPossibility, err error) {
+	workers := a.MatchWorkers
+	if workers < 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers <= 1 || len(descs) < minParallelDescs {
+		return a.matchDescs0(ctx, jerr, meta, descs, cache)
+	}
+	size := (len(descs) + workers - 1) / workers
+	parts := make([][]SolutionPossibility, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		start := i * size
+		if start >= len(descs) {
+			break
 		}
-		if len(e.Message) == 0 { // when ignore error message, error type provide 100% score weight
-			sol.Match /= 10.0 / 100
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parts[i], errs[i] = a.matchDescs0(ctx, jerr, meta, descs[start:min(start+size, len(descs))], cache)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, part := range parts {
+		matched = append(matched, part...)
+	}
+	return
+}
+
+func (a *Analyzer) matchDescs0(ctx context.Context, jerr *JavaError, meta *LogMetadata, descs []*ErrorDesc, cache *similarityCache) (matched []SolutionPossibility, err error) {
+	for i, e := range descs {
+		if i%matchCheckInterval == 0 && ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		if !metadataAllows(e, meta) {
+			continue
+		}
+		var detail *MatchDetail
+		if a.ExplainMatches {
+			detail = new(MatchDetail)
+		}
+		if match := a.matchErrorDesc(jerr, e, cache, detail); match != 0 { // have any matches
+			matched = append(matched, SolutionPossibility{
+				ErrorDesc: e,
+				Match:     match,
+				Source:    e.Source,
+				ID:        e.ID,
+				Captures:  messageCaptures(jerr, e),
+				Detail:    detail,
+			})
+		}
+	}
+	return
+}
+
+// matchErrorDesc scores how well the description matches the error.
+// The MustNotMatch pattern is evaluated first, if it matches either the message or the stacktrace,
+// the description is rejected without checking the error type or message.
+// The detail is filled with the breakdown of the score if it's not nil
+func (a *Analyzer) matchErrorDesc(jerr *JavaError, e *ErrorDesc, cache *similarityCache, detail *MatchDetail) (match float32) {
+	if e.MustNotMatch != "" && mustNotMatch(jerr, e.MustNotMatch) {
+		return 0
+	}
+	weights := a.MatchWeights.normalized()
+	epkg, ecls := rsplit(jerr.Class, '.')
+	epkg2, ecls2 := rsplit(e.Error, '.')
+	// a summary does not have the error type, so the message provides 100% score weight
+	ignoreErrorTyp := len(ecls2) == 0 || (ecls2 == "*" && (epkg2 == "" || epkg2 == "*")) || jerr.Class == ""
+	exactClass := false
+	switch {
+	case ignoreErrorTyp:
+	case ecls2 == "*": // `pkg.*` matches the classes in the package and its subpackages
+		if strings.HasPrefix(jerr.Class, epkg2+".") {
+			match = weights.ClassName
+		}
+	case ecls2 == ecls: // error type weight: 10% by default
+		if epkg2 == "*" || epkg == epkg2 {
+			match, exactClass = weights.ExactClass, true
 		} else {
-			jemsg, _ := split(jerr.Message, '\n')
-			matches := lineMatchPercent(jemsg, e.Message) // error message weight: 90%
-			if ignoreErrorTyp {
-				sol.Match = matches // or when ignore error type, it provide 100% score weight
-			} else {
-				sol.Match += matches * 0.9
-			}
+			match = weights.ClassName
+		}
+	}
+	if !e.hasMessage() { // when ignore error message, error type provide 100% score weight
+		if exactClass {
+			match = 1
+		} else if match > 0 {
+			match /= weights.ExactClass
+		}
+		if detail != nil {
+			detail.Class = match
+		}
+	} else {
+		jemsg, _ := split(jerr.Message, '\n')
+		matches, ok := cache.get(jemsg, e)
+		if !ok {
+			matches = e.bestMessageMatch(func(message string) float32 {
+				if e.MessageIsRegex {
+					return regexMatchPercent(jemsg, message)
+				}
+				return a.messageMatchPercent(jemsg, message) // error message weight: 90% by default
+			})
+			cache.put(jemsg, e, matches)
+		}
+		msgScore := matches // when ignore error type, it provide 100% score weight
+		if !ignoreErrorTyp {
+			msgScore *= weights.Message
 		}
-		if sol.Match != 0 { // have any matches
-			matched = append(matched, sol)
+		if detail != nil {
+			detail.Class, detail.MessageSimilarity, detail.Message = match, matches, msgScore
 		}
+		match += msgScore
 	}
-	if matched == nil {
-		matched = make([]SolutionPossibility, 0)
+	match = applySignals(jerr, e, match)
+	if e.Context != "" && match > 0 { // context weight: 20%
+		match *= 0.8
+		if contextMatches(jerr, e.Context) {
+			match += 0.2
+		}
+	}
+	specificity := descSpecificity(e) // see descSpecificity for the penalty of generic descriptions
+	if detail != nil {
+		detail.Wildcard = ecls2 == "*"
+		detail.Specificity = specificity
 	}
+	match *= specificity
 	return
 }
 
+func contextMatches(jerr *JavaError, pattern string) bool {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+	for _, line := range jerr.Context {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+const defaultMinFuzzyLength = 8
+
+func (a *Analyzer) messageMatchPercent(text, match string) float32 {
+	minLen := a.MinFuzzyLength
+	if minLen == 0 {
+		minLen = defaultMinFuzzyLength
+	}
+	if minLen > 0 && min(utf8.RuneCountInString(text), utf8.RuneCountInString(match)) < minLen {
+		return substringMatchPercent(text, match)
+	}
+	if prefix, ok := strings.CutSuffix(match, " *"); ok && strings.HasPrefix(text, prefix) {
+		return 1.0
+	}
+	stopWords := a.StopWords
+	if stopWords == nil {
+		stopWords = DefaultStopWords
+	}
+	if len(stopWords) > 0 {
+		text2, match2 := removeStopWords(text, stopWords), removeStopWords(match, stopWords)
+		if text2 != "" && match2 != "" {
+			text, match = text2, match2
+		}
+	}
+	if a.FuzzyMessageMatch {
+		return fuzzyMatchPercent(text, match, a.Tokenizer)
+	}
+	return tokenizedMatchPercent(text, match, a.Tokenizer)
+}
+
+func mustNotMatch(jerr *JavaError, pattern string) bool {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+	if re.MatchString(jerr.Message) {
+		return true
+	}
+	for _, s := range jerr.Stacktrace {
+		if re.MatchString(s.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// DoLogStream analyzes the errors one by one in the order they appear in the log.
+// Nothing is buffered besides the current error, the scanner will wait until the results are received,
+// so the memory usage does not grow with the size of the log.
+// The error which reaches the end of the log is still sent with ErrorResult.Incomplete set,
+// and if the log ends in a crash report, the context is canceled with ErrCrashReportIncomplete after all results are sent.
 func (a *Analyzer) DoLogStream(c context.Context, r io.Reader) (<-chan *ErrorResult, context.Context) {
-	result := make(chan *ErrorResult, 3)
+	return a.doLogStream(c, r, new(streamState))
+}
+
+// DefaultReadBufferSize is the default of Analyzer.ReadBufferSize
+const DefaultReadBufferSize = 64 * 1024
+
+func (a *Analyzer) bufferReader(r io.Reader) io.Reader {
+	size := a.ReadBufferSize
+	if size == 0 {
+		size = DefaultReadBufferSize
+	}
+	if size < 0 {
+		return r
+	}
+	return bufio.NewReaderSize(r, size)
+}
+
+// streamState is shared between a running stream and its LogStream handle
+type streamState struct {
+	gate      *pauseGate
+	collapsed atomic.Int64
+	dropped   atomic.Int64
+	dedup     *errorDeduper // nil unless Analyzer.DedupErrors is enabled
+}
+
+func (a *Analyzer) doLogStream(c context.Context, r io.Reader, st *streamState) (<-chan *ErrorResult, context.Context) {
+	size := a.ResultBufferSize
+	if size <= 0 {
+		size = 3
+	}
+	result := make(chan *ErrorResult, size)
 	ctx, cancel := context.WithCancelCause(c)
+	var progress *progressReader
+	if a.OnProgress != nil {
+		progress = newProgressReader(r, a.OnProgress)
+		r = progress
+	}
+	r = a.bufferReader(r)
+	gate := st.gate
+	if a.DedupErrors {
+		st.dedup = newErrorDeduper()
+	}
+	dedup := st.dedup
+	if gate != nil {
+		r = &gatedReader{r: r, gate: gate, ctx: ctx}
+	}
 	go func() {
 		defer close(result)
-		var wg sync.WaitGroup
 		recorder := a.newLogRecorder()
 		defer recorder.Close()
-		resCh, errCh := ScanJavaErrorsIntoChan(io.TeeReader(r, recorder))
-	LOOP:
+		cache := newSimilarityCache(a.SimilarityCacheSize)
+		var (
+			summaries []summaryLine // only accessed by the scanner until resCh is closed
+			found     bool          // if any structured exception is found, the exit code of the process is not one
+		)
+		resCh, errCh := scanJavaErrorsIntoChan(ctx, io.TeeReader(r, recorder), &scanOptions{
+			collapseRepeated: a.CollapseRepeatedLines,
+			collapsed:        &st.collapsed,
+			summary: func(s summaryLine) {
+				summaries = append(summaries, s)
+			},
+			checkCrashReport: true,
+			keepANSI:         a.KeepANSI,
+		})
+		// incomplete is set when the log is truncated, the remaining results are still sent
+		// before the context is canceled with it
+		var incomplete error
+		defer func() {
+			if incomplete != nil {
+				cancel(incomplete)
+			}
+		}()
+		send := func(res *ErrorResult) bool {
+			if a.ResultFilter != nil && !a.ResultFilter(res) {
+				return true
+			}
+			if err := gate.wait(ctx); err != nil {
+				return false
+			}
+			confident := a.isConfident(res)
+			if confident || !a.dropResult(result, res, &st.dropped) {
+				select {
+				case result <- res:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			if confident {
+				cancel(ErrStoppedOnConfidence)
+				return false
+			}
+			return true
+		}
 		for {
 			select {
-			case jerr := <-resCh:
-				if jerr == nil {
-					break LOOP
-				}
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-					for jerr != nil {
-						res := &ErrorResult{
-							Error: jerr,
-						}
-						var err error
-						if res.Matched, err = a.DoError(jerr); err != nil {
+			case jerr, ok := <-resCh:
+				if !ok {
+					select {
+					case err := <-errCh:
+						if !errors.Is(err, ErrCrashReportIncomplete) {
 							cancel(err)
 							return
 						}
-						select {
-						case result <- res:
-						case <-ctx.Done():
+						incomplete = err
+					default:
+					}
+					if progress != nil {
+						progress.done()
+					}
+					if found {
+						return
+					}
+					// fallback for the partial pastes which only have the summary of the crash
+					results, err := a.summaryResults(ctx, summaries, cache, recorder.logs)
+					if err != nil {
+						cancel(err)
+						return
+					}
+					for _, res := range results {
+						if !send(res) {
 							return
 						}
-						jerr = jerr.CausedBy
 					}
-				}()
+					return
+				}
+				found = found || jerr.Class != processExitClass
+				if dedup != nil && dedup.repeat(jerr) {
+					continue
+				}
+				results, err := a.analyzeChain(ctx, jerr, nil, cache, recorder.logs)
+				if err != nil {
+					cancel(err)
+					return
+				}
+				if dedup != nil {
+					dedup.add(jerr, results)
+				}
+				for _, res := range results {
+					if !send(res) {
+						return
+					}
+				}
 			case err := <-errCh:
+				if errors.Is(err, ErrCrashReportIncomplete) {
+					// wait for the results before the error
+					incomplete, errCh = err, nil
+					continue
+				}
 				cancel(err)
 				return
 			case <-ctx.Done():
 				return
 			}
 		}
-		wg.Wait()
 	}()
 	return result, ctx
 }
 
+// dropResult sends the result without blocking by the analyzer's DropPolicy,
+// it returns false if the policy is DropNone and the result should be sent by the caller
+func (a *Analyzer) dropResult(result chan *ErrorResult, res *ErrorResult, dropped *atomic.Int64) bool {
+	switch a.DropPolicy {
+	case DropNewest:
+		select {
+		case result <- res:
+		default:
+			dropped.Add(1)
+		}
+		return true
+	case DropOldest:
+		for {
+			select {
+			case result <- res:
+				return true
+			default:
+			}
+			select {
+			case <-result:
+				dropped.Add(1)
+			default: // the consumer took one in the meantime
+			}
+		}
+	}
+	return false
+}
+
+// causeChain returns the error and its suppressed errors and causes which should be analyzed
+func (a *Analyzer) causeChain(jerr *JavaError) (chain []*JavaError) {
+	visited := make(map[*JavaError]struct{})
+	var walk func(parent, je *JavaError)
+	walk = func(parent, je *JavaError) {
+		for ; je != nil; parent, je = je, je.CausedBy {
+			if _, ok := visited[je]; ok { // the cause chain is a cycle
+				return
+			}
+			visited[je] = struct{}{}
+			if parent == nil || a.KeepDuplicateCauses || je.Fingerprint() != parent.Fingerprint() {
+				chain = append(chain, je)
+			}
+			for _, s := range je.Suppressed {
+				walk(je, s)
+			}
+		}
+	}
+	walk(nil, jerr)
+	return
+}
+
+// analyzeError makes the result of a single error without its causes, the metadata and the recent logs of the stream can be nil
+func (a *Analyzer) analyzeError(ctx context.Context, jerr *JavaError, meta *LogMetadata, cache *similarityCache, logs *recentLogs) (res *ErrorResult, err error) {
+	snap := logs.before(jerr.LineNo)
+	res = &ErrorResult{
+		Error:     jerr,
+		Location:  a.LocateCrash(jerr, nil),
+		Recovered: a.isRecovered(jerr),
+		Mixin:     a.attributeMixin(jerr, snap),
+		MixinLogs: snap[LogCategoryMixin],
+		Logs:      snap.others(),
+
+		StartLine:   jerr.LineNo,
+		EndLine:     jerr.EndLineNo,
+		StartOffset: jerr.Offset,
+		EndOffset:   jerr.EndOffset,
+		Incomplete:  jerr.Truncated,
+	}
+	if res.Matched, err = a.doError(ctx, jerr, meta, cache, snap); err != nil {
+		return nil, err
+	}
+	res.Category = categorize(jerr, res.Matched)
+	return
+}
+
+// ErrStoppedOnConfidence is the cause of the stream's context when it's stopped by Analyzer.StopOnConfidence
+var ErrStoppedOnConfidence = errors.New("mcla: stopped on a confident result")
+
+func (a *Analyzer) isConfident(res *ErrorResult) bool {
+	if a.StopOnConfidence <= 0 {
+		return false
+	}
+	for _, m := range res.Matched {
+		if m.Match >= a.StopOnConfidence {
+			return true
+		}
+	}
+	return false
+}
+
 type logRecorder struct {
-	a      *Analyzer
-	closed bool
-	buf    []byte
+	a        *Analyzer
+	logs     *recentLogs
+	mux      sync.Mutex // the recorder may be closed while the scanner is still writing
+	closed   bool
+	buf      []byte
+	overflow bool // if the current line is too long and have been dropped
+	last     []byte
+	lineNo   int // the number of the last complete line
+	rules    []LogRule
+	matched  []string // the categories which the current line is recorded in
 }
 
-func (a *Analyzer) newLogRecorder() io.WriteCloser {
-	a.recentMixinLogs.Clear()
+// newLogRecorder records the recent logs of a stream, they are also returned by RecentLogs until the next stream starts
+func (a *Analyzer) newLogRecorder() *logRecorder {
+	logs := new(recentLogs)
+	a.logsMux.Lock()
+	a.lastLogs = logs
+	a.logsMux.Unlock()
 	return &logRecorder{
-		a: a,
+		a:     a,
+		logs:  logs,
+		rules: a.logRules(),
 	}
 }
 
 func (r *logRecorder) Write(buf []byte) (int, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.closed {
+		return len(buf), nil
+	}
 	r.buf = append(r.buf, buf...)
 	i := 0
-	for  {
+	for {
 		j := i + bytes.IndexByte(r.buf[i:], '\n')
 		if j < i {
 			break
 		}
-		r.record(r.buf[i:j])
+		r.lineNo++
+		if r.overflow {
+			r.overflow = false
+		} else {
+			r.record(r.buf[i:j])
+		}
 		i = j + 1
 	}
 	if i > 0 {
 		n := copy(r.buf, r.buf[i:])
 		r.buf = r.buf[:n]
 	}
+	if len(r.buf) > maxLineSize { // drop the overlong line, so the buffer will not grow forever
+		r.buf = r.buf[:0]
+		r.overflow = true
+	}
 	return len(buf), nil
 }
 
 func (r *logRecorder) Close() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	if r.closed {
 		return nil
 	}
@@ -210,13 +1092,30 @@ func (r *logRecorder) Close() error {
 	return nil
 }
 
-var (
-	mixinLogRe = regexp.MustCompile(`^\[[^\]]*\]\s*\[[^\]]*\]\s*\[mixin/[^\]]*\]:\s*(.+)$`)
-)
-
 func (r *logRecorder) record(buf []byte) {
-	matches := mixinLogRe.FindSubmatch(buf)
-	if matches != nil {
-		r.a.recentMixinLogs.Push((string)(matches[1]))
+	if r.a.CollapseRepeatedLines {
+		if r.last != nil && bytes.Equal(buf, r.last) {
+			return
+		}
+		r.last = append(r.last[:0], buf...)
+	}
+	if !r.a.KeepANSI {
+		buf = stripANSI(buf)
+	}
+	r.matched = r.matched[:0]
+	for _, rule := range r.rules {
+		if slices.Contains(r.matched, rule.Category) {
+			continue
+		}
+		matches := rule.Pattern.FindSubmatch(buf)
+		if matches == nil {
+			continue
+		}
+		text := matches[0]
+		if len(matches) > 1 {
+			text = matches[1]
+		}
+		r.matched = append(r.matched, rule.Category)
+		r.logs.push(rule.Category, recordedLog{lineNo: r.lineNo, text: (string)(text)})
 	}
 }
@@ -0,0 +1,708 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type memErrorDB struct {
+	errors    []*ErrorDesc
+	solutions []*SolutionDesc
+}
+
+var _ ErrorDB = (*memErrorDB)(nil)
+
+func (db *memErrorDB) ForEachErrors(callback func(*ErrorDesc) error) (err error) {
+	for _, e := range db.errors {
+		if err = callback(e); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (db *memErrorDB) GetSolution(id int) (sol *SolutionDesc, err error) {
+	if id <= 0 || id > len(db.solutions) {
+		return nil, nil
+	}
+	return db.solutions[id-1], nil
+}
+
+// sameDesc reports whether got is desc as loaded by an analyzer,
+// which copies the descriptions without an id to assign their ids
+func sameDesc(got, desc *ErrorDesc) bool {
+	return got == desc || got != nil && got.ID == DefaultDescID(desc)
+}
+
+func findMatch(matched []SolutionPossibility, desc *ErrorDesc) (match float32, ok bool) {
+	for _, m := range matched {
+		if sameDesc(m.ErrorDesc, desc) {
+			return m.Match, true
+		}
+	}
+	return 0, false
+}
+
+func TestDoErrorMustNotMatch(t *testing.T) {
+	desc := &ErrorDesc{
+		Error:        "java.lang.NullPointerException",
+		Message:      "Cannot invoke \"net.minecraft.client.renderer.RenderType.m_110405_()\" because \"renderType\" is null",
+		MustNotMatch: `(?i)optifine`,
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+
+	jerr := &JavaError{
+		Class:   "java.lang.NullPointerException",
+		Message: desc.Message,
+		Stacktrace: Stacktrace{
+			{Raw: "at net.minecraft.client.renderer.LevelRenderer.renderLevel(LevelRenderer.java:1219)", Class: "net.minecraft.client.renderer.LevelRenderer", Method: "renderLevel"},
+		},
+	}
+	matched, err := analyzer.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if match, ok := findMatch(matched, desc); !ok || match != 1 {
+		t.Errorf("Expect description to match with 1, got %v (found=%v)", match, ok)
+	}
+
+	jerr.Stacktrace = append(jerr.Stacktrace, StackInfo{
+		Raw:    "at net.optifine.render.ChunkVisibility.getMaxChunkY(ChunkVisibility.java:39)",
+		Class:  "net.optifine.render.ChunkVisibility",
+		Method: "getMaxChunkY",
+	})
+	if matched, err = analyzer.DoError(jerr); err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if match, ok := findMatch(matched, desc); ok {
+		t.Errorf("Expect description to be suppressed by MustNotMatch, got match %v", match)
+	}
+}
+
+func TestInvalidDescPatterns(t *testing.T) {
+	valid := &ErrorDesc{Error: "java.lang.NullPointerException", MustNotMatch: `(?i)optifine`}
+	invalids := []*ErrorDesc{
+		{Error: "java.lang.NullPointerException", MustNotMatch: `(unclosed`},
+		{Error: "java.lang.NullPointerException", Context: `[unclosed`},
+		{Error: "java.lang.NullPointerException", Signals: []Signal{{Pattern: `optifine`, Weight: 0.5}, {Pattern: `*optifine`, Weight: 0.5}}},
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: append([]*ErrorDesc{valid}, invalids...)})
+	var skipped []*ErrorDesc
+	analyzer.OnInvalidDesc = func(e *ErrorDesc, err error) {
+		skipped = append(skipped, e)
+	}
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	if !slices.EqualFunc(skipped, invalids, sameDesc) {
+		t.Errorf("Expect the descriptions with the invalid patterns to be skipped, got %#v", skipped)
+	}
+	matched, err := analyzer.DoError(&JavaError{Class: "java.lang.NullPointerException"})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 1 || !sameDesc(matched[0].ErrorDesc, valid) {
+		t.Errorf("Expect only the valid description to match, got %#v", matched)
+	}
+}
+
+// repeatReader generates a log by repeating the chunk count times without storing it
+type repeatReader struct {
+	chunk []byte
+	off   int
+	count int
+	read  int64
+}
+
+func (r *repeatReader) Read(buf []byte) (n int, err error) {
+	for n < len(buf) {
+		if r.count <= 0 {
+			if n == 0 {
+				err = io.EOF
+			}
+			return
+		}
+		m := copy(buf[n:], r.chunk[r.off:])
+		n += m
+		r.off += m
+		if r.off == len(r.chunk) {
+			r.off = 0
+			r.count--
+		}
+	}
+	r.read += (int64)(n)
+	return
+}
+
+func TestDoLogStreamBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping huge stream in short mode")
+	}
+	var chunk strings.Builder
+	chunk.WriteString("[12:00:00] [Server thread/INFO] [mixin/]: Mixing SomeMixin from some.mixins.json into net.minecraft.Foo\n")
+	chunk.WriteString("[12:00:01] [Server thread/ERROR]: Exception ticking world\n")
+	chunk.WriteString("java.lang.IllegalStateException: Something went wrong\n")
+	for i := 0; i < 30; i++ {
+		chunk.WriteString("\tat net.minecraft.server.level.ServerLevel.tick(ServerLevel.java:100) ~[server.jar:?]\n")
+	}
+	chunk.WriteString("Caused by: java.lang.NullPointerException: null\n")
+	chunk.WriteString("\tat com.example.mod.Foo.bar(Foo.java:42) ~[example.jar:?]\n")
+	chunk.WriteString("\t... 30 more\n")
+	// a line looks like an error class without a stacktrace must not accumulate the following lines
+	chunk.WriteString("net.minecraft.server.MinecraftServer\n")
+	for i := 0; i < 100; i++ {
+		chunk.WriteString("[12:00:02] [Server thread/INFO]: Saving chunks for level 'ServerLevel[world]'/minecraft:overworld\n")
+	}
+
+	const totalSize = 64 * 1024 * 1024
+	r := &repeatReader{
+		chunk: ([]byte)(chunk.String()),
+		count: totalSize / chunk.Len(),
+	}
+	analyzer := NewAnalyzer(&memErrorDB{})
+
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	baseline := stats.HeapAlloc
+	var maxHeap uint64
+
+	resCh, ctx := analyzer.DoLogStream(context.Background(), r)
+	count := 0
+	for range resCh {
+		if count++; count%10000 == 0 {
+			runtime.GC()
+			runtime.ReadMemStats(&stats)
+			maxHeap = max(maxHeap, stats.HeapAlloc)
+		}
+	}
+	if err := context.Cause(ctx); err != nil {
+		t.Fatalf("DoLogStream: %v", err)
+	}
+	if expect := r.count; expect != 0 {
+		t.Fatalf("Expect the whole stream to be read, %d chunks left", expect)
+	}
+	if count == 0 {
+		t.Fatalf("Expect some results, got none")
+	}
+	const limit = 16 * 1024 * 1024
+	if maxHeap > baseline && maxHeap-baseline > limit {
+		t.Errorf("Heap grew by %d bytes after reading %d bytes, expect less than %d", maxHeap-baseline, r.read, limit)
+	}
+}
+
+func TestDoErrorMinFuzzyLength(t *testing.T) {
+	desc := &ErrorDesc{
+		Error:   "*",
+		Message: "Cannot invoke method on a null reference",
+	}
+	short := &ErrorDesc{
+		Error:   "*",
+		Message: "null",
+	}
+	jerr := &JavaError{
+		Class:   "java.lang.NullPointerException",
+		Message: "is null",
+	}
+
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc, short}})
+	analyzer.MinFuzzyLength = -1
+	matched, err := analyzer.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if _, ok := findMatch(matched, desc); !ok {
+		t.Fatalf("Expect the short message to fuzzy match the unrelated description without the guard")
+	}
+
+	analyzer = NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc, short}})
+	if matched, err = analyzer.DoError(jerr); err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if match, ok := findMatch(matched, desc); ok {
+		t.Errorf("Expect the short message to not match the unrelated description, got %v", match)
+	}
+	if match, ok := findMatch(matched, short); !ok || match != 4.0/7 {
+		t.Errorf("Expect the short message to match the contained message with %v, got %v", 4.0/7, match)
+	}
+}
+
+func TestDoErrorStopWords(t *testing.T) {
+	right := &ErrorDesc{
+		Error:   "java.lang.IllegalStateException",
+		Message: "Failed to load the texture for the block",
+	}
+	wrong := &ErrorDesc{
+		Error:   "java.lang.IllegalStateException",
+		Message: "Failed to load the model for the entity",
+	}
+	jerr := &JavaError{
+		Class:   "java.lang.IllegalStateException",
+		Message: "Failed to load the texture for the block",
+	}
+	matchOf := func(stopWords []string) (r, w float32) {
+		analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{right, wrong}})
+		analyzer.StopWords = stopWords
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		r, _ = findMatch(matched, right)
+		w, _ = findMatch(matched, wrong)
+		return
+	}
+	r1, w1 := matchOf([]string{})
+	r2, w2 := matchOf(nil)
+	if r1 != 1 || r2 != 1 {
+		t.Errorf("Expect the same message to match with 1, got %v and %v", r1, r2)
+	}
+	if w2 >= w1 {
+		t.Errorf("Expect the stop words to lower the score of the different message, got %v with and %v without", w2, w1)
+	}
+	if r2-w2 <= r1-w1 {
+		t.Errorf("Expect the stop words to widen the gap between the messages, got %v with and %v without", r2-w2, r1-w1)
+	}
+}
+
+func TestDoErrorClassWildcards(t *testing.T) {
+	const message = "Chunk file at [3, 4] is in the wrong location"
+	exact := &ErrorDesc{Error: "net.minecraft.server.level.ChunkLoadException", Message: message}
+	subpackage := &ErrorDesc{Error: "net.minecraft.*", Message: message}
+	other := &ErrorDesc{Error: "com.example.*", Message: message}
+	bare := &ErrorDesc{Error: "*", Message: message}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{exact, subpackage, other, bare}})
+
+	matched, err := analyzer.DoError(&JavaError{Class: "net.minecraft.server.level.ChunkLoadException", Message: message})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	e, _ := findMatch(matched, exact)
+	s, _ := findMatch(matched, subpackage)
+	o, _ := findMatch(matched, other)
+	b, _ := findMatch(matched, bare)
+	if e != 1 || b != 1 {
+		t.Errorf("Expect the exact class and the bare wildcard to match with 1, got %v and %v", e, b)
+	}
+	if !(e > s && s > o) {
+		t.Errorf("Expect exact > pkg.* > other package, got %v, %v and %v", e, s, o)
+	}
+
+	// net.minecraftforge is not a subpackage of net.minecraft
+	if matched, err = analyzer.DoError(&JavaError{Class: "net.minecraftforge.fml.LoadingFailedException", Message: message}); err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	s, _ = findMatch(matched, subpackage)
+	o, _ = findMatch(matched, other)
+	if s != o {
+		t.Errorf("Expect net.minecraft.* to not match net.minecraftforge, got %v and %v", s, o)
+	}
+}
+
+func TestExplainMatches(t *testing.T) {
+	exact := &ErrorDesc{Error: "java.lang.IllegalStateException", Message: "Not building!"}
+	wildcard := &ErrorDesc{Error: "java.lang.*", Message: "Not built"}
+	typeOnly := &ErrorDesc{Error: "java.lang.IllegalStateException"}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{exact, wildcard, typeOnly}})
+	jerr := &JavaError{Class: "java.lang.IllegalStateException", Message: "Not building!"}
+
+	matched, err := analyzer.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	for _, m := range matched {
+		if m.Detail != nil {
+			t.Errorf("Expect no detail by default, got %#v", m.Detail)
+		}
+	}
+
+	analyzer.ExplainMatches = true
+	if matched, err = analyzer.DoError(jerr); err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	details := make(map[string]SolutionPossibility)
+	for _, m := range matched {
+		if m.Detail == nil {
+			t.Fatalf("Expect the detail of %s, got nil", m.ErrorDesc.Error)
+		}
+		details[m.ID] = m
+	}
+	weights := DefaultMatchWeights
+	if m := details[DefaultDescID(exact)]; *m.Detail != (MatchDetail{Class: weights.ExactClass, MessageSimilarity: 1, Message: weights.Message, Specificity: 1}) {
+		t.Errorf("Unexpected detail of the exact description: %#v", m.Detail)
+	}
+	m := details[DefaultDescID(wildcard)]
+	if d := m.Detail; !d.Wildcard || d.Class != weights.ClassName || d.MessageSimilarity <= 0 || d.MessageSimilarity >= 1 {
+		t.Errorf("Unexpected detail of the wildcard description: %#v", d)
+	}
+	if d := m.Detail; d.Message != d.MessageSimilarity*weights.Message || m.Match != (d.Class+d.Message)*d.Specificity {
+		t.Errorf("Expect Match == (Class + Message) * Specificity, got %v for %#v", m.Match, d)
+	}
+	m = details[DefaultDescID(typeOnly)]
+	if d := m.Detail; d.Class != 1 || d.MessageSimilarity != 0 || d.Specificity >= 1 || m.Match != d.Specificity {
+		t.Errorf("Unexpected detail of the description without a message: %v for %#v", m.Match, d)
+	}
+}
+
+type slowErrorDB struct {
+	memErrorDB
+	loads atomic.Int32
+}
+
+func (db *slowErrorDB) ForEachErrors(callback func(*ErrorDesc) error) error {
+	db.loads.Add(1)
+	time.Sleep(20 * time.Millisecond)
+	return db.memErrorDB.ForEachErrors(callback)
+}
+
+func TestColdAnalyzerSingleLoad(t *testing.T) {
+	desc := &ErrorDesc{
+		Error:   "java.lang.RuntimeException",
+		Message: "Something went wrong",
+	}
+	db := &slowErrorDB{memErrorDB: memErrorDB{errors: []*ErrorDesc{desc}}}
+	analyzer := NewAnalyzer(db)
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			matched, err := analyzer.DoError(&JavaError{
+				Class:   "java.lang.RuntimeException",
+				Message: "Something went wrong",
+			})
+			if err != nil {
+				t.Errorf("DoError: %v", err)
+				return
+			}
+			if _, ok := findMatch(matched, desc); !ok {
+				t.Errorf("Expect the description to match, got %#v", matched)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := db.loads.Load(); n != 1 {
+		t.Errorf("Expect the database to be loaded once, got %d", n)
+	}
+
+	// UpdateErrors always reloads
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	if n := db.loads.Load(); n != 2 {
+		t.Errorf("Expect UpdateErrors to reload the database, got %d loads", n)
+	}
+}
+
+func TestAnalyzerCacheTTL(t *testing.T) {
+	jerr := &JavaError{Class: "java.lang.RuntimeException", Message: "Something went wrong"}
+	datas := []struct {
+		Name   string
+		TTL    time.Duration
+		Expect int32
+	}{
+		{"default", 0, 1},
+		{"short", time.Millisecond, 2},
+		{"never", -1, 1},
+	}
+	for _, d := range datas {
+		db := new(slowErrorDB)
+		analyzer := NewAnalyzer(db)
+		analyzer.CacheTTL = d.TTL
+		analyzer.DoError(jerr)
+		time.Sleep(5 * time.Millisecond)
+		analyzer.DoError(jerr)
+		if n := db.loads.Load(); n != d.Expect {
+			t.Errorf("%s: Expect %d loads, got %d", d.Name, d.Expect, n)
+		}
+		if err := analyzer.UpdateErrors(); err != nil {
+			t.Fatalf("%s: UpdateErrors: %v", d.Name, err)
+		}
+		if n := db.loads.Load(); n != d.Expect+1 {
+			t.Errorf("%s: Expect UpdateErrors to reload, got %d loads", d.Name, n)
+		}
+	}
+}
+
+func TestAnalyzerInvalidateCache(t *testing.T) {
+	jerr := &JavaError{Class: "java.lang.RuntimeException", Message: "Something went wrong"}
+	db := new(slowErrorDB)
+	analyzer := NewAnalyzer(db)
+	analyzer.CacheTTL = -1
+	analyzer.DoError(jerr)
+	analyzer.DoError(jerr)
+	if n := db.loads.Load(); n != 1 {
+		t.Errorf("Expect 1 load before the invalidation, got %d", n)
+	}
+	analyzer.InvalidateCache()
+	if n := db.loads.Load(); n != 1 {
+		t.Errorf("Expect InvalidateCache to not load by itself, got %d loads", n)
+	}
+	analyzer.DoError(jerr)
+	analyzer.DoError(jerr)
+	if n := db.loads.Load(); n != 2 {
+		t.Errorf("Expect the invalidated descriptions to be reloaded once, got %d loads", n)
+	}
+}
+
+type blockingErrorDB struct {
+	memErrorDB
+	stopped atomic.Bool
+}
+
+var _ ContextErrorDB = (*blockingErrorDB)(nil)
+
+func (db *blockingErrorDB) ForEachErrorsContext(ctx context.Context, callback func(*ErrorDesc) error) error {
+	<-ctx.Done() // a stuck fetch
+	db.stopped.Store(true)
+	return context.Cause(ctx)
+}
+
+func TestDoErrorCancel(t *testing.T) {
+	jerr := &JavaError{Class: "java.lang.RuntimeException", Message: "Something went wrong"}
+	db := &memErrorDB{errors: []*ErrorDesc{{Error: "java.lang.RuntimeException", Message: "Something went wrong"}}}
+	analyzer := NewAnalyzer(db)
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := analyzer.DoErrorContext(ctx, jerr); err != context.Canceled {
+		t.Errorf("Expect the matching to be interrupted with %v, got %v", context.Canceled, err)
+	}
+	if err := analyzer.UpdateErrorsContext(ctx); err != context.Canceled {
+		t.Errorf("Expect the loading to be interrupted with %v, got %v", context.Canceled, err)
+	}
+	if matched, err := analyzer.DoErrorContext(context.Background(), jerr); err != nil || len(matched) != 1 {
+		t.Errorf("Expect the loaded descriptions to be kept, got %d matches, %v", len(matched), err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := NewAnalyzer(new(blockingErrorDB)).DoErrorContext(ctx, jerr); err != context.DeadlineExceeded {
+		t.Errorf("Expect the stuck loading to be interrupted with %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestSetDB(t *testing.T) {
+	jerr := &JavaError{Class: "java.lang.RuntimeException", Message: "Something went wrong"}
+	db1 := &memErrorDB{errors: []*ErrorDesc{{Error: "java.lang.RuntimeException", Message: "Something went wrong", Solutions: []int{1}}}}
+	db2 := &memErrorDB{errors: []*ErrorDesc{{Error: "java.lang.RuntimeException", Message: "Something went wrong", Solutions: []int{2}}}}
+	analyzer := NewAnalyzer(db1)
+	analyzer.ResultCacheSize = 16
+	solutionOf := func() int {
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		if len(matched) != 1 {
+			t.Fatalf("Expect 1 match, got %d", len(matched))
+		}
+		return matched[0].ErrorDesc.Solutions[0]
+	}
+	if id := solutionOf(); id != 1 {
+		t.Errorf("Expect solution == %d, got %d", 1, id)
+	}
+	analyzer.SetDB(db2)
+	if id := solutionOf(); id != 2 {
+		t.Errorf("Expect solution == %d after SetDB, got %d", 2, id)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := analyzer.DoError(jerr); err != nil {
+					t.Errorf("DoError: %v", err)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		if i%2 == 0 {
+			analyzer.SetDB(db1)
+		} else {
+			analyzer.SetDB(db2)
+		}
+	}
+	wg.Wait()
+	if id := solutionOf(); id != 2 {
+		t.Errorf("Expect solution == %d at last, got %d", 2, id)
+	}
+}
+
+func TestDoLogStreamAbortsUpdate(t *testing.T) {
+	db := new(blockingErrorDB)
+	analyzer := NewAnalyzer(db)
+	ctx, cancel := context.WithCancel(context.Background())
+	results, _ := analyzer.DoLogStream(ctx, strings.NewReader(contextLog))
+	time.AfterFunc(50*time.Millisecond, cancel)
+	select {
+	case <-drainChan(results):
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expect the stream to stop after it's cancelled")
+	}
+	if !db.stopped.Load() {
+		t.Errorf("Expect the in-flight update to be aborted")
+	}
+}
+
+func drainChan[T any](ch <-chan T) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range ch {
+		}
+	}()
+	return done
+}
+
+// manyDescsDB has thousands of descriptions with different messages, so the matching is CPU bound
+func manyDescsDB(n int) *memErrorDB {
+	db := &memErrorDB{}
+	for i := 0; i < n; i++ {
+		db.errors = append(db.errors, &ErrorDesc{
+			Error:   "java.lang.IllegalStateException",
+			Message: fmt.Sprintf("Mod mod_%d failed to load the resource assets/mod_%d/textures/block_%d.png", i, i%37, i%101),
+		})
+	}
+	return db
+}
+
+func TestParallelMatch(t *testing.T) {
+	db := manyDescsDB(1000)
+	jerr := &JavaError{Class: "java.lang.IllegalStateException", Message: "Mod mod_42 failed to load the resource assets/mod_5/textures/block_42.png"}
+	sequential := NewAnalyzer(db)
+	expect, err := sequential.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	for _, workers := range []int{-1, 3, 8} {
+		parallel := NewAnalyzer(db)
+		parallel.MatchWorkers = workers
+		got, err := parallel.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		if len(got) != len(expect) {
+			t.Fatalf("workers=%d: Expect %d matches, got %d", workers, len(expect), len(got))
+		}
+		for i, m := range got {
+			if m.ID != expect[i].ID || m.Match != expect[i].Match {
+				t.Errorf("workers=%d: Expect match %d == %s=%v, got %s=%v", workers, i, expect[i].ID, expect[i].Match, m.ID, m.Match)
+			}
+		}
+	}
+}
+
+func BenchmarkParallelMatch(b *testing.B) {
+	db := manyDescsDB(3000)
+	jerr := &JavaError{Class: "java.lang.IllegalStateException", Message: "Mod mod_42 failed to load the resource assets/mod_5/textures/block_42.png"}
+	for _, workers := range []int{1, -1} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			analyzer := NewAnalyzer(db)
+			analyzer.MatchWorkers = workers
+			if err := analyzer.UpdateErrors(); err != nil {
+				b.Fatalf("UpdateErrors: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := analyzer.DoError(jerr); err != nil {
+					b.Fatalf("DoError: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchOrderTies(t *testing.T) {
+	// the first two descriptions only differ in the package pattern, so they score the same
+	db := &memErrorDB{errors: []*ErrorDesc{
+		{Error: "*.ZipException", Message: "invalid entry size"},
+		{Error: "java.util.zip.ZipException", Message: "invalid entry size"},
+		{Error: "*.ZipException", Message: "invalid entry"},
+	}}
+	jerr := &JavaError{Class: "java.util.zip.ZipException", Message: "invalid entry size"}
+	matched, err := NewAnalyzer(db).DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 3 {
+		t.Fatalf("Expect 3 matches, got %d", len(matched))
+	}
+	if matched[0].Match != matched[1].Match {
+		t.Fatalf("Expect the first two matches to tie, got %v and %v", matched[0].Match, matched[1].Match)
+	}
+	// the tie is broken by the specificity of the error type, the exact class is the first
+	for i, expect := range []*ErrorDesc{db.errors[1], db.errors[0], db.errors[2]} {
+		if !sameDesc(matched[i].ErrorDesc, expect) {
+			t.Errorf("Expect match %d == %s %q, got %s %q", i, expect.Error, expect.Message, matched[i].ErrorDesc.Error, matched[i].ErrorDesc.Message)
+		}
+	}
+	if matched[2].Match >= matched[1].Match {
+		t.Errorf("Expect the partial match to be the last, got %v", matched[2].Match)
+	}
+}
+
+func TestMatchLimits(t *testing.T) {
+	db := &memErrorDB{errors: []*ErrorDesc{
+		{Error: "java.lang.RuntimeException", Message: "Something else happened entirely here"},
+		{Error: "java.lang.RuntimeException", Message: "Something went wrong while loading"},
+		{Error: "java.lang.RuntimeException", Message: "Something went wrong"},
+		{Error: "java.lang.RuntimeException", Message: ""},
+	}}
+	jerr := &JavaError{Class: "java.lang.RuntimeException", Message: "Something went wrong"}
+	analyzer := NewAnalyzer(db)
+	all, err := analyzer.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(all) < 3 {
+		t.Fatalf("Expect at least 3 matches without limits, got %d", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Match > all[i-1].Match {
+			t.Errorf("Expect the matches sorted by Match descending, got %v before %v", all[i-1].Match, all[i].Match)
+		}
+	}
+
+	analyzer.MaxResults = 2
+	top, err := analyzer.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(top) != 2 {
+		t.Fatalf("Expect 2 matches, got %d", len(top))
+	}
+	best := slices.MaxFunc(all, func(x, y SolutionPossibility) int { return cmp.Compare(x.Match, y.Match) })
+	if top[0].ID != best.ID || top[0].Match < top[1].Match {
+		t.Errorf("Expect the best matches in descending order, got %v and %v", top[0].Match, top[1].Match)
+	}
+
+	analyzer.MaxResults = 0
+	analyzer.MinMatch = top[1].Match
+	kept, err := analyzer.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	for _, m := range kept {
+		if m.Match < analyzer.MinMatch {
+			t.Errorf("Expect the matches under %v to be dropped, got %v", analyzer.MinMatch, m.Match)
+		}
+	}
+	if len(kept) < 2 || len(kept) >= len(all) {
+		t.Errorf("Expect MinMatch to drop the weak matches only, got %d of %d", len(kept), len(all))
+	}
+}
@@ -0,0 +1,216 @@
+package mcla
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+)
+
+var (
+	// matches `C:\Users\Steve\`, `C:/Users/Steve/`, `/home/steve/` and `/Users/steve/`
+	homeDirRe = regexp.MustCompile(`(?i)((?:\b[a-z]:[\\/]+(?:Users|Documents and Settings)|/home|/Users)[\\/]+)([^\\/\s"'<>:;,)\]]+)`)
+	// matches `Setting user: Steve` and `--username Steve`
+	usernameRe = regexp.MustCompile(`(?i)(Setting user:\s*|--username[\s=]+)([\w$.-]+)`)
+	ipv4Re     = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Re     = regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}`)
+	uuidRe     = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+)
+
+const (
+	redactedUser = "<user>"
+	redactedIP   = "<ip>"
+	redactedUUID = "<uuid>"
+)
+
+// anonymizer redacts the personal information, the usernames found in the home directories
+// and the login lines are also redacted when they appear alone
+type anonymizer struct {
+	users map[string]struct{}
+}
+
+// collect finds the usernames in the text
+func (an *anonymizer) collect(text string) {
+	for _, re := range []*regexp.Regexp{homeDirRe, usernameRe} {
+		for _, m := range re.FindAllStringSubmatch(text, -1) {
+			if len(m[2]) >= 3 { // a short name may be part of any word
+				an.users[m[2]] = struct{}{}
+			}
+		}
+	}
+}
+
+func (an *anonymizer) redact(text string) string {
+	if text == "" {
+		return text
+	}
+	text = homeDirRe.ReplaceAllString(text, "${1}"+redactedUser)
+	text = usernameRe.ReplaceAllString(text, "${1}"+redactedUser)
+	for user := range an.users {
+		text = replaceWord(text, user, redactedUser)
+	}
+	text = uuidRe.ReplaceAllString(text, redactedUUID)
+	text = ipv4Re.ReplaceAllStringFunc(text, redactIP)
+	text = ipv6Re.ReplaceAllStringFunc(text, redactIP)
+	return text
+}
+
+// redactIP keeps the loopback and unspecified addresses, since they are not personal and useful for diagnosis
+func redactIP(s string) string {
+	ip := net.ParseIP(s)
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+		return s
+	}
+	return redactedIP
+}
+
+// replaceWord replaces the word which is not a part of a longer word
+func replaceWord(text, word, repl string) string {
+	isWordByte := func(b byte) bool {
+		return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(text, word)
+		if i < 0 {
+			break
+		}
+		j := i + len(word)
+		b.WriteString(text[:i])
+		if (i > 0 && isWordByte(text[i-1])) || (j < len(text) && isWordByte(text[j])) {
+			b.WriteString(word)
+		} else {
+			b.WriteString(repl)
+		}
+		text = text[j:]
+	}
+	if b.Len() == 0 {
+		return text
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// newAnonymizer collects the usernames in the error and its causes
+func newAnonymizer(jerr *JavaError) *anonymizer {
+	an := &anonymizer{users: make(map[string]struct{})}
+	for _, je := range errorChain(jerr) {
+		an.collect(je.Message)
+		for _, s := range je.Stacktrace {
+			an.collect(s.Raw)
+		}
+		for _, line := range je.Context {
+			an.collect(line)
+		}
+	}
+	return an
+}
+
+// errorChain returns the error and all its suppressed errors and causes in the order they are printed,
+// the errors which are already visited are skipped, so it stops at a cycle
+func errorChain(jerr *JavaError) (chain []*JavaError) {
+	visited := make(map[*JavaError]struct{})
+	var walk func(je *JavaError)
+	walk = func(je *JavaError) {
+		for ; je != nil; je = je.CausedBy {
+			if _, ok := visited[je]; ok {
+				return
+			}
+			visited[je] = struct{}{}
+			chain = append(chain, je)
+			for _, s := range je.Suppressed {
+				walk(s)
+			}
+		}
+	}
+	walk(jerr)
+	return
+}
+
+// anonymizeError redacts the message, stacktrace and context of the error and its causes in place
+func (an *anonymizer) anonymizeError(jerr *JavaError) {
+	var context []string // the causes share the context of the top level error
+	if jerr.Context != nil {
+		context = make([]string, len(jerr.Context))
+		for i, line := range jerr.Context {
+			context[i] = an.redact(line)
+		}
+	}
+	for _, je := range errorChain(jerr) {
+		je.Message = an.redact(je.Message)
+		for i := range je.Stacktrace {
+			je.Stacktrace[i].Raw = an.redact(je.Stacktrace[i].Raw)
+		}
+		je.Context = context
+	}
+}
+
+// anonymizeResult redacts the snippets copied from the error into the captures, the hard coded descriptions and the logs,
+// the descriptions from the database are not changed
+func (an *anonymizer) anonymizeResult(res *ErrorResult) {
+	for i, m := range res.Matched {
+		if m.Captures != nil {
+			captures := make(map[string]string, len(m.Captures))
+			for name, v := range m.Captures {
+				captures[name] = an.redact(v)
+			}
+			res.Matched[i].Captures = captures
+		}
+		if m.ErrorDesc == nil || !strings.HasPrefix(m.ID, "hardcoded.") {
+			continue
+		}
+		desc := *m.ErrorDesc
+		desc.Message = an.redact(desc.Message)
+		desc.Description = an.redact(desc.Description)
+		if desc.Data != nil {
+			data := make(map[string]any, len(desc.Data))
+			for k, v := range desc.Data {
+				if s, ok := v.(string); ok {
+					v = an.redact(s)
+				}
+				data[k] = v
+			}
+			desc.Data = data
+		}
+		res.Matched[i].ErrorDesc = &desc
+		res.Matched[i].Description = an.redact(m.Description)
+	}
+	if res.MixinLogs != nil {
+		logs := make([]string, len(res.MixinLogs))
+		for i, line := range res.MixinLogs {
+			logs[i] = an.redact(line)
+		}
+		res.MixinLogs = logs
+	}
+	if res.Logs != nil {
+		logs := make(map[string][]string, len(res.Logs))
+		for category, lines := range res.Logs {
+			redacted := make([]string, len(lines))
+			for i, line := range lines {
+				redacted[i] = an.redact(line)
+			}
+			logs[category] = redacted
+		}
+		res.Logs = logs
+	}
+}
+
+// analyzeChain analyzes the error and its causes, the results are anonymized if Analyzer.Anonymize is set
+func (a *Analyzer) analyzeChain(ctx context.Context, jerr *JavaError, meta *LogMetadata, cache *similarityCache, logs *recentLogs) (results []*ErrorResult, err error) {
+	for _, je := range a.causeChain(jerr) {
+		var res *ErrorResult
+		if res, err = a.analyzeError(ctx, je, meta, cache, logs); err != nil {
+			return
+		}
+		results = append(results, res)
+	}
+	if a.Anonymize {
+		// the errors are only anonymized after all of them are matched, so the scores are not affected
+		an := newAnonymizer(jerr)
+		an.anonymizeError(jerr)
+		for _, res := range results {
+			an.anonymizeResult(res)
+		}
+	}
+	return
+}
@@ -0,0 +1,112 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"encoding/json"
+	"maps"
+	"strings"
+)
+
+const personalLog = `[12:00:00] [main/INFO]: Setting user: Steve_123
+[12:00:01] [main/INFO]: Loading world for player 1b2c3d4e-5f60-4718-9abc-def012345678
+[12:00:02] [main/ERROR]: Failed to load config file C:\Users\alice\AppData\Roaming\.minecraft\config\foo.toml
+java.io.FileNotFoundException: C:\Users\alice\AppData\Roaming\.minecraft\config\foo.toml (Access is denied)
+	at java.io.FileInputStream.open0(Native Method)
+	at com.example.mod.Config.load(Config.java:10) ~[file:/C:/Users/alice/AppData/Roaming/.minecraft/mods/examplemod.jar:?]
+Caused by: java.lang.IllegalStateException: Steve_123 from /192.168.1.20:51234 is not allowed, see /home/alice/server.log
+	at com.example.mod.Auth.check(Auth.java:20)
+[12:00:05] [Server thread/INFO]: Starting Minecraft server on 10.0.0.8:25565 (local 127.0.0.1)
+java.net.BindException: Cannot assign requested address: bind
+	at sun.nio.ch.Net.bind0(Native Method)
+[12:00:05] [Server thread/WARN]: **** FAILED TO BIND TO PORT!
+[12:00:05] [Server thread/WARN]: Perhaps a server is already running on that port?
+[12:00:06] [Server thread/INFO]: Network interface fe80::1c2b:3dff:fe4e:5f60
+[12:00:06] [Server thread/ERROR]: Stopping
+java.lang.IllegalStateException: Server stopped
+	at net.minecraft.server.MinecraftServer.run(MinecraftServer.java:100)
+`
+
+func TestAnonymize(t *testing.T) {
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:   "java.io.FileNotFoundException",
+				Message: "* (Access is denied)",
+			},
+			{
+				Error:          "java.lang.IllegalStateException",
+				Message:        `(?P<player>\w+) from (?P<address>\S+) is not allowed, see (?P<file>\S+)`,
+				MessageIsRegex: true,
+			},
+		},
+	}
+	plain, err := NewAnalyzer(db).AnalyzeLog(context.Background(), strings.NewReader(personalLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	analyzer := NewAnalyzer(db)
+	analyzer.Anonymize = true
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(personalLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != len(plain.Results) {
+		t.Fatalf("Expect %d results, got %d", len(plain.Results), len(analysis.Results))
+	}
+	for i, res := range analysis.Results {
+		want := plain.Results[i]
+		if res.Error.Class != want.Error.Class || res.Error.LineNo != want.Error.LineNo || len(res.Error.Stacktrace) != len(want.Error.Stacktrace) {
+			t.Errorf("Expect the structure of error %d to be kept, got %s at line %d", i, res.Error.Class, res.Error.LineNo)
+		}
+		if len(res.Matched) != len(want.Matched) {
+			t.Errorf("Expect %d matches for error %d, got %d", len(want.Matched), i, len(res.Matched))
+			continue
+		}
+		for j, m := range res.Matched {
+			if m.ID != want.Matched[j].ID || m.Match != want.Matched[j].Match {
+				t.Errorf("Expect match %s=%v, got %s=%v", want.Matched[j].ID, want.Matched[j].Match, m.ID, m.Match)
+			}
+		}
+	}
+	if m := analysis.Results[0].Matched; len(m) == 0 || !sameDesc(m[0].ErrorDesc, db.errors[0]) {
+		t.Errorf("Expect the database description to be matched, got %#v", m)
+	}
+
+	buf, err := json.Marshal(analysis)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	output := string(buf)
+	for _, secret := range []string{"alice", "Steve_123", "192.168.1.20", "10.0.0.8", "1b2c3d4e-5f60-4718-9abc-def012345678", "fe80::1c2b:3dff:fe4e:5f60"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expect %q to be redacted in the output", secret)
+		}
+	}
+	for _, kept := range []string{
+		`C:\\Users\\\u003cuser\u003e\\AppData\\Roaming\\.minecraft\\config\\foo.toml (Access is denied)`,
+		"file:/C:/Users/\\u003cuser\\u003e/AppData",
+		"/home/\\u003cuser\\u003e/server.log",
+		"\\u003cuser\\u003e from /\\u003cip\\u003e:51234",
+		"127.0.0.1",
+		"com.example.mod.Config",
+	} {
+		if !strings.Contains(output, kept) {
+			t.Errorf("Expect %q in the output", kept)
+		}
+	}
+	if m := analysis.Results[1].Matched; len(m) == 0 || !sameDesc(m[0].ErrorDesc, db.errors[1]) {
+		t.Errorf("Expect the regex description to be matched, got %#v", m)
+	} else if expect := map[string]string{"player": "<user>", "address": "/<ip>:51234", "file": "/home/<user>/server.log"}; !maps.Equal(m[0].Captures, expect) {
+		t.Errorf("Expect the captures to be redacted as %q, got %q", expect, m[0].Captures)
+	}
+	bind := analysis.Results[2].Matched[0]
+	if bind.ErrorDesc.Data["address"] != "<ip>" || !strings.Contains(bind.ErrorDesc.Description, "<ip>:25565") {
+		t.Errorf("Expect the address to be redacted in the hard coded description, got %v %q", bind.ErrorDesc.Data, bind.ErrorDesc.Description)
+	}
+	if expect := map[string]string{"address": "<ip>", "port": "25565"}; !maps.Equal(bind.Captures, expect) {
+		t.Errorf("Expect the captures to be redacted as %q, got %q", expect, bind.Captures)
+	}
+}
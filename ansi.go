@@ -0,0 +1,59 @@
+package mcla
+
+import (
+	"bytes"
+)
+
+const escByte = 0x1b
+
+// stripANSI removes the ANSI escape sequences from the line, e.g. the colors `\x1b[31m` written by the consoles.
+// It removes the CSI sequences `ESC [ ... final`, the OSC sequences `ESC ] ... BEL` or `ESC ] ... ESC \`,
+// and the other two-byte `ESC x` sequences. The line is returned as is if it does not contain ESC
+func stripANSI(line []byte) []byte {
+	i := bytes.IndexByte(line, escByte)
+	if i < 0 {
+		return line
+	}
+	out := make([]byte, i, len(line))
+	copy(out, line[:i])
+	for i < len(line) {
+		c := line[i]
+		if c != escByte {
+			out = append(out, c)
+			i++
+			continue
+		}
+		i = skipEscape(line, i)
+	}
+	return out
+}
+
+// skipEscape returns the index after the escape sequence which starts at i
+func skipEscape(line []byte, i int) int {
+	i++ // ESC
+	if i >= len(line) {
+		return i
+	}
+	switch line[i] {
+	case '[': // CSI: parameter and intermediate bytes in 0x20-0x3f, then a final byte in 0x40-0x7e
+		for i++; i < len(line); i++ {
+			if c := line[i]; c >= 0x40 && c <= 0x7e {
+				return i + 1
+			} else if c < 0x20 || c > 0x3f {
+				return i // malformed, keep the byte
+			}
+		}
+		return i
+	case ']': // OSC: terminated by BEL or ST (ESC \)
+		for i++; i < len(line); i++ {
+			if line[i] == 0x07 {
+				return i + 1
+			}
+			if line[i] == escByte && i+1 < len(line) && line[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return i
+	}
+	return i + 1
+}
@@ -0,0 +1,65 @@
+package mcla_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+const colorizedLog = "\x1b[32m[12:00:00] [main/INFO] [mixin/]: \x1b[0mLoaded 12 mixins from examplemod.mixins.json\n" +
+	"\x1b[31m[12:00:01] [Render thread/ERROR]: Unreported exception thrown!\x1b[0m\n" +
+	"\x1b[1;31mjava.lang.IllegalStateException\x1b[0m: \x1b[31mNot building!\x1b[0m\n" +
+	"\x1b[31m\tat com.example.mod.Renderer.render(Renderer.java:10)\x1b[0m\n" +
+	"\x1b]0;Minecraft\x07\x1b[31mCaused by: java.lang.NullPointerException: \x1b[4mbuffer\x1b[24m is null\x1b[0m\n" +
+	"\x1b[31m\tat com.example.mod.Buffer.begin(Buffer.java:20)\x1b[0m\n"
+
+func TestStripANSI(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	var results []*ErrorResult
+	result, _ := analyzer.DoLogStream(context.Background(), strings.NewReader(colorizedLog))
+	for res := range result {
+		results = append(results, res)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expect 2 results, got %d", len(results))
+	}
+	jerr := results[0].Error
+	for _, je := range []*JavaError{jerr, jerr.CausedBy} {
+		if je == nil {
+			t.Fatalf("Expect the cause to be parsed")
+		}
+		if strings.ContainsRune(je.Class, '\x1b') || strings.ContainsRune(je.Message, '\x1b') {
+			t.Errorf("Expect no escape bytes, got %q: %q", je.Class, je.Message)
+		}
+		for _, s := range je.Stacktrace {
+			if strings.ContainsRune(s.Raw, '\x1b') {
+				t.Errorf("Expect no escape bytes in the stacktrace, got %q", s.Raw)
+			}
+		}
+	}
+	if expect := "java.lang.IllegalStateException"; jerr.Class != expect {
+		t.Errorf("Expect Class == %q, got %q", expect, jerr.Class)
+	}
+	if expect := "Not building!"; jerr.Message != expect {
+		t.Errorf("Expect Message == %q, got %q", expect, jerr.Message)
+	}
+	if expect := "buffer is null"; jerr.CausedBy.Message != expect {
+		t.Errorf("Expect the cause Message == %q, got %q", expect, jerr.CausedBy.Message)
+	}
+	if expect := (int64)(strings.Index(colorizedLog, "\x1b[1;31mjava")); jerr.Offset != expect {
+		t.Errorf("Expect the offset in the original log %d, got %d", expect, jerr.Offset)
+	}
+	if logs := analyzer.RecentMixinLogs(); len(logs) != 1 || logs[0] != "Loaded 12 mixins from examplemod.mixins.json" {
+		t.Errorf("Expect the colorized mixin log to be recorded, got %q", logs)
+	}
+
+	analyzer.KeepANSI = true
+	result, _ = analyzer.DoLogStream(context.Background(), strings.NewReader(colorizedLog))
+	for res := range result {
+		if res.Error.Class == "java.lang.IllegalStateException" {
+			t.Errorf("Expect the escape sequences to be kept with KeepANSI, got %q", res.Error.Class)
+		}
+	}
+}
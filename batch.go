@@ -0,0 +1,105 @@
+package mcla
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io/fs"
+	"slices"
+)
+
+// AnalyzeFiles analyzes the files in the filesystem which match any of the glob patterns, e.g. "crashes/crash-*.txt",
+// all files are analyzed if there is no pattern. Each file is analyzed by AnalyzeLog, so the primary result of each file is flagged,
+// and the gzip compressed files are decompressed, see NewAutoReader.
+// The results are in the order of the file names, and ErrorResult.File is set to the name of the file.
+// A truncated file does not stop the batch, its partial results are kept
+func (a *Analyzer) AnalyzeFiles(ctx context.Context, fsys fs.FS, patterns ...string) (results []*ErrorResult, err error) {
+	var names []string
+	if len(patterns) == 0 {
+		if err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err == nil && d.Type().IsRegular() {
+				names = append(names, name)
+			}
+			return err
+		}); err != nil {
+			return
+		}
+	}
+	for _, pattern := range patterns {
+		var matches []string
+		if matches, err = fs.Glob(fsys, pattern); err != nil {
+			return
+		}
+		names = append(names, matches...)
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+	for _, name := range names {
+		if err = ctx.Err(); err != nil {
+			return nil, context.Cause(ctx)
+		}
+		if info, er := fs.Stat(fsys, name); er == nil && info.IsDir() { // matched by a pattern
+			continue
+		}
+		var analysis *LogAnalysis
+		if analysis, err = a.analyzeFile(ctx, fsys, name); err != nil {
+			return nil, err
+		}
+		for _, res := range analysis.Results {
+			res.File = name
+		}
+		results = append(results, analysis.Results...)
+	}
+	return
+}
+
+func (a *Analyzer) analyzeFile(ctx context.Context, fsys fs.FS, name string) (analysis *LogAnalysis, err error) {
+	fd, err := fsys.Open(name)
+	if err != nil {
+		return
+	}
+	defer fd.Close()
+	if analysis, err = a.AnalyzeLog(ctx, NewAutoReader(fd)); errors.Is(err, ErrCrashReportIncomplete) {
+		err = nil
+	}
+	return
+}
+
+// ErrorGroup is the same error found in several results, e.g. in different files of a batch
+type ErrorGroup struct {
+	// Signature is the class, the masked message and the top frame of the error
+	Signature string         `json:"signature"`
+	Results   []*ErrorResult `json:"results"`
+	// Files are the distinct files where the error is found
+	Files []string `json:"files"`
+}
+
+// GroupResults groups the results whose errors have the same class, the same message after masking
+// the numbers, ids and paths, and the same top frame. The groups are sorted by how many files they are found in,
+// then by how many times they are found, and then by their first result
+func GroupResults(results []*ErrorResult) (groups []*ErrorGroup) {
+	index := make(map[string]*ErrorGroup)
+	for _, res := range results {
+		if res.Error == nil {
+			continue
+		}
+		sig := errorSignature(res.Error)
+		g, ok := index[sig]
+		if !ok {
+			g = &ErrorGroup{Signature: sig}
+			index[sig] = g
+			groups = append(groups, g)
+		}
+		g.Results = append(g.Results, res)
+		if !slices.Contains(g.Files, res.File) {
+			g.Files = append(g.Files, res.File)
+		}
+	}
+	slices.SortStableFunc(groups, func(x, y *ErrorGroup) int {
+		if c := cmp.Compare(len(y.Files), len(x.Files)); c != 0 {
+			return c
+		}
+		return cmp.Compare(len(y.Results), len(x.Results))
+	})
+	return
+}
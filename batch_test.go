@@ -0,0 +1,69 @@
+package mcla_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+func npeCrashReport(entity int) string {
+	return `---- Minecraft Crash Report ----
+Description: Ticking entity
+
+java.lang.NullPointerException: Cannot read field "level" of entity ` + strconv.Itoa(entity) + `
+	at com.example.mod.TickHandler.onTick(TickHandler.java:42)
+	at net.minecraft.world.level.Level.tick(Level.java:100)
+
+`
+}
+
+func TestAnalyzeFiles(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(([]byte)(npeCrashReport(3)))
+	w.Close()
+	fsys := fstest.MapFS{
+		"crashes/crash-1.txt":    {Data: ([]byte)(npeCrashReport(1))},
+		"crashes/crash-2.txt":    {Data: ([]byte)(npeCrashReport(2))},
+		"crashes/crash-3.txt.gz": {Data: gz.Bytes()},
+		"crashes/crash-4.txt":    {Data: ([]byte)("[12:00:00] [main/ERROR]: Failed to save\njava.io.IOException: Disk full\n\tat com.example.mod.Storage.save(Storage.java:10)\n")},
+		"logs/latest.log":        {Data: ([]byte)("[12:00:00] [main/INFO]: Done\n")},
+	}
+	analyzer := NewAnalyzer(&memErrorDB{})
+	results, err := analyzer.AnalyzeFiles(context.Background(), fsys, "crashes/crash-*")
+	if err != nil {
+		t.Fatalf("AnalyzeFiles: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expect 4 results, got %d", len(results))
+	}
+	for i, expect := range []string{"crashes/crash-1.txt", "crashes/crash-2.txt", "crashes/crash-3.txt.gz", "crashes/crash-4.txt"} {
+		if results[i].File != expect {
+			t.Errorf("Expect result %d from %q, got %q", i, expect, results[i].File)
+		}
+	}
+
+	groups := GroupResults(results)
+	if len(groups) != 2 {
+		t.Fatalf("Expect 2 groups, got %d", len(groups))
+	}
+	if g := groups[0]; len(g.Files) != 3 || g.Results[0].Error.Class != "java.lang.NullPointerException" {
+		t.Errorf("Expect the NullPointerException in 3 files first, got %s in %v", g.Results[0].Error.Class, g.Files)
+	}
+	if g := groups[1]; len(g.Files) != 1 || g.Files[0] != "crashes/crash-4.txt" {
+		t.Errorf("Expect the IOException in crash-4.txt, got %v", g.Files)
+	}
+
+	all, err := analyzer.AnalyzeFiles(context.Background(), fsys)
+	if err != nil {
+		t.Fatalf("AnalyzeFiles: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("Expect all files to be analyzed without patterns, got %d results", len(all))
+	}
+}
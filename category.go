@@ -0,0 +1,73 @@
+package mcla
+
+import (
+	"strings"
+)
+
+// Category is a coarse classification of an error
+type Category string
+
+const (
+	CategoryUnknown           Category = "unknown"
+	CategoryMissingDependency Category = "missingDependency"
+	CategoryVersionMismatch   Category = "versionMismatch"
+	CategoryOutOfMemory       Category = "outOfMemory"
+	CategoryMixinConflict     Category = "mixinConflict"
+	CategoryNativeCrash       Category = "nativeCrash"
+	CategoryNetworking        Category = "networking"
+	CategoryWorldCorruption   Category = "worldCorruption"
+)
+
+var classCategories = map[string]Category{
+	"java.lang.OutOfMemoryError":                                               CategoryOutOfMemory,
+	"java.lang.ClassNotFoundException":                                         CategoryMissingDependency,
+	"java.lang.NoClassDefFoundError":                                           CategoryMissingDependency,
+	"java.lang.NoSuchMethodError":                                              CategoryVersionMismatch,
+	"java.lang.NoSuchFieldError":                                               CategoryVersionMismatch,
+	"java.lang.AbstractMethodError":                                            CategoryVersionMismatch,
+	"java.lang.IncompatibleClassChangeError":                                   CategoryVersionMismatch,
+	"java.lang.UnsupportedClassVersionError":                                   CategoryVersionMismatch,
+	"java.lang.UnsatisfiedLinkError":                                           CategoryNativeCrash,
+	"java.net.BindException":                                                   CategoryNetworking,
+	"java.net.ConnectException":                                                CategoryNetworking,
+	"java.net.SocketException":                                                 CategoryNetworking,
+	"java.net.SocketTimeoutException":                                          CategoryNetworking,
+	"java.net.UnknownHostException":                                            CategoryNetworking,
+	"org.spongepowered.asm.mixin.transformer.throwables.MixinTransformerError": CategoryMixinConflict,
+}
+
+// categoryOfClass infers the category from the error class when no matched description has a category
+func categoryOfClass(class string) Category {
+	if c, ok := classCategories[class]; ok {
+		return c
+	}
+	switch {
+	case strings.HasPrefix(class, "org.spongepowered.asm.mixin."):
+		return CategoryMixinConflict
+	case strings.HasPrefix(class, "io.netty."):
+		return CategoryNetworking
+	case class == jvmFatalErrorClass || class == processExitClass:
+		return CategoryNativeCrash
+	}
+	return CategoryUnknown
+}
+
+// categoryMinMatch is the minimum score of a match to decide the category, a weak match may be unrelated
+const categoryMinMatch = 0.5
+
+// categorize uses the category of the best match which has one, or infers it from the error class
+func categorize(jerr *JavaError, matched []SolutionPossibility) Category {
+	var (
+		category Category
+		best     float32
+	)
+	for _, m := range matched {
+		if m.ErrorDesc.Category != "" && m.Match >= categoryMinMatch && m.Match > best {
+			category, best = m.ErrorDesc.Category, m.Match
+		}
+	}
+	if category != "" {
+		return category
+	}
+	return categoryOfClass(jerr.Class)
+}
@@ -0,0 +1,50 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+)
+
+func TestResultCategory(t *testing.T) {
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:    "java.lang.IllegalStateException",
+				Message:  "Failed to load mod list",
+				Category: CategoryMissingDependency,
+			},
+		},
+	}
+	datas := []struct {
+		Name   string
+		Error  string
+		Expect Category
+	}{
+		{"explicit", "java.lang.IllegalStateException: Failed to load mod list", CategoryMissingDependency},
+		{"dependency check", "net.minecraftforge.fml.ModLoadingException: Mod create requires flywheel 0.6.8 or above\nCurrently, flywheel is not installed", CategoryMissingDependency},
+		{"version check", "java.lang.NoSuchMethodError: 'void com.example.lib.Foo.bar(int)'", CategoryVersionMismatch},
+		{"out of memory", "java.lang.OutOfMemoryError: Java heap space", CategoryOutOfMemory},
+		{"mixin", "org.spongepowered.asm.mixin.injection.throwables.InvalidInjectionException: Critical injection failure", CategoryMixinConflict},
+		{"native", "java.lang.UnsatisfiedLinkError: no lwjgl64 in java.library.path", CategoryNativeCrash},
+		{"networking", "java.net.BindException: Address already in use", CategoryNetworking},
+		{"unknown", "java.lang.IllegalArgumentException: Something is wrong", CategoryUnknown},
+	}
+	analyzer := NewAnalyzer(db)
+	for _, d := range datas {
+		log := d.Error + "\n\tat com.example.mod.Foo.bar(Foo.java:42)\n"
+		analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log))
+		if err != nil {
+			t.Fatalf("%s: AnalyzeLog: %v", d.Name, err)
+		}
+		if len(analysis.Results) != 1 {
+			t.Errorf("%s: Expect 1 result, got %d", d.Name, len(analysis.Results))
+			continue
+		}
+		if category := analysis.Results[0].Category; category != d.Expect {
+			t.Errorf("%s: Expect category %q, got %q", d.Name, d.Expect, category)
+		}
+	}
+}
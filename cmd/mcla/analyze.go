@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/GlobeMC/mcla"
+)
+
+// analyzeCmd runs `mcla analyze [-json] [-top N] <file or ->...`
+func analyzeCmd(args []string) {
+	if code := runAnalyze(defaultAnalyzer, args, os.Stdin, os.Stdout); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// runAnalyze analyzes the files by the analyzer and returns the exit code
+func runAnalyze(analyzer *mcla.Analyzer, args []string, stdin io.Reader, stdout io.Writer) int {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	jsonOut := flags.Bool("json", false, "print the analyses as JSON")
+	top := flags.Int("top", 0, "print at most `N` matched solutions of each error, 0 means all")
+	flags.Usage = func() {
+		printf("Usage: mcla analyze [-json] [-top N] <file or - for stdin>...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	files := flags.Args()
+	if len(files) == 0 {
+		flags.Usage()
+		return 2
+	}
+	analyzer.MaxResults = *top
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	failed := false
+	for _, name := range files {
+		analysis, err := analyzeFile(analyzer, name, stdin)
+		if err != nil {
+			printf("Error when analyzing file %q: %v", name, err)
+			failed = true
+			if analysis == nil {
+				continue
+			}
+		}
+		if *jsonOut {
+			if err = encoder.Encode(analysis); err != nil {
+				printf("\nError when encoding the analysis as json: %v", err)
+				return 1
+			}
+			continue
+		}
+		printAnalysis(stdout, analyzer.DB, analysis)
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// analyzeFile analyzes the log file, `-` is the stdin.
+// The truncated log returns the partial analysis with the error
+func analyzeFile(analyzer *mcla.Analyzer, name string, stdin io.Reader) (analysis *mcla.LogAnalysis, err error) {
+	r := stdin
+	if name != "-" {
+		fd, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer fd.Close()
+		r = fd
+	}
+	analysis, err = analyzer.AnalyzeLog(context.Background(), mcla.NewAutoReader(r))
+	if analysis != nil {
+		for _, res := range analysis.Results {
+			res.File = name
+		}
+	}
+	return
+}
+
+// printAnalysis prints the errors and their matched solutions for human
+func printAnalysis(w io.Writer, db mcla.ErrorDB, analysis *mcla.LogAnalysis) {
+	if len(analysis.Results) == 0 {
+		fmt.Fprintln(w, "No any error was found")
+		return
+	}
+	for _, res := range analysis.Results {
+		primary := ""
+		if res.Primary {
+			primary = " (primary)"
+		}
+		fmt.Fprintf(w, "%s:%d: %s%s\n", res.File, res.Error.LineNo, errorLine(res.Error), primary)
+		for c := res.Error.CausedBy; c != nil; c = c.CausedBy {
+			fmt.Fprintf(w, "  Caused by: %s\n", errorLine(c))
+		}
+		if len(res.Matched) == 0 {
+			fmt.Fprintln(w, "  No matched solution")
+		}
+		for _, m := range res.Matched {
+			printMatch(w, db, m)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func printMatch(w io.Writer, db mcla.ErrorDB, m mcla.SolutionPossibility) {
+	title := m.Description
+	if title == "" {
+		title = m.ErrorDesc.Message
+	}
+	if title == "" {
+		title = m.ErrorDesc.Error
+	}
+	fmt.Fprintf(w, "  [%3.0f%%] %s (%s)\n", m.Match*100, firstLine(title), m.Severity)
+	for _, id := range m.ErrorDesc.Solutions {
+		sol, err := db.GetSolution(id)
+		if err != nil {
+			printf("Error when getting solution %d: %v", id, err)
+			continue
+		}
+		if sol == nil {
+			continue
+		}
+		fmt.Fprintf(w, "    - %s\n", firstLine(sol.Description))
+		if sol.LinkTo != "" {
+			fmt.Fprintf(w, "      %s\n", sol.LinkTo)
+		}
+	}
+	for _, l := range m.Links {
+		if l.Label != "" {
+			fmt.Fprintf(w, "    * %s: %s\n", l.Label, l.URL)
+		} else {
+			fmt.Fprintf(w, "    * %s\n", l.URL)
+		}
+	}
+}
+
+func errorLine(e *mcla.JavaError) string {
+	if e.Message == "" {
+		return e.Class
+	}
+	if e.Class == "" {
+		return firstLine(e.Message)
+	}
+	return e.Class + ": " + firstLine(e.Message)
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(s, "\n")
+	return s
+}
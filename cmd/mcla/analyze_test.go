@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/GlobeMC/mcla"
+)
+
+const testLog = `[12:00:00] [main/INFO]: Loading examplemod
+[12:00:01] [main/ERROR]: Something went wrong
+com.example.mod.ExampleException: Config file examplemod.toml is broken
+	at com.example.mod.Foo.bar(Foo.java:42)
+[12:00:02] [main/INFO]: Stopping
+`
+
+func newTestAnalyzer() *mcla.Analyzer {
+	return mcla.NewAnalyzer(mcla.NewFileDB(fstest.MapFS{
+		"errors/1.json":    {Data: []byte(`{"error":"com.example.mod.ExampleException","message":"Config file examplemod.toml is broken","solutions":[1]}`)},
+		"errors/2.json":    {Data: []byte(`{"error":"com.example.mod.ExampleException","solutions":[2]}`)},
+		"solutions/1.json": {Data: []byte(`{"description":"Delete the config file","link_to":"https://example.com/config"}`)},
+		"solutions/2.json": {Data: []byte(`{"description":"Update examplemod"}`)},
+	}))
+}
+
+func writeTestLog(t *testing.T) string {
+	name := filepath.Join(t.TempDir(), "latest.log")
+	if err := os.WriteFile(name, ([]byte)(testLog), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return name
+}
+
+func TestAnalyzeFile(t *testing.T) {
+	name := writeTestLog(t)
+	for _, d := range []struct {
+		Name  string
+		Stdin string
+	}{
+		{name, ""},
+		{"-", testLog},
+	} {
+		analysis, err := analyzeFile(newTestAnalyzer(), d.Name, strings.NewReader(d.Stdin))
+		if err != nil {
+			t.Fatalf("%s: analyzeFile: %v", d.Name, err)
+		}
+		if len(analysis.Results) != 1 {
+			t.Fatalf("%s: Expect 1 result, got %d", d.Name, len(analysis.Results))
+		}
+		res := analysis.Results[0]
+		if res.File != d.Name {
+			t.Errorf("%s: Expect File == %q, got %q", d.Name, d.Name, res.File)
+		}
+		if res.Error.LineNo != 3 {
+			t.Errorf("%s: Expect LineNo == 3, got %d", d.Name, res.Error.LineNo)
+		}
+		if len(res.Matched) != 2 {
+			t.Errorf("%s: Expect 2 matches, got %d", d.Name, len(res.Matched))
+		}
+	}
+	if _, err := analyzeFile(newTestAnalyzer(), filepath.Join(t.TempDir(), "missing.log"), nil); err == nil {
+		t.Errorf("Expect an error for the missing file")
+	}
+}
+
+func TestRunAnalyzeTop(t *testing.T) {
+	name := writeTestLog(t)
+	var out bytes.Buffer
+	if code := runAnalyze(newTestAnalyzer(), []string{"-top", "1", name}, nil, &out); code != 0 {
+		t.Fatalf("Expect exit code 0, got %d", code)
+	}
+	text := out.String()
+	for _, s := range []string{
+		name + ":3: com.example.mod.ExampleException: Config file examplemod.toml is broken",
+		"- Delete the config file",
+		"https://example.com/config",
+	} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Expect the output contains %q, got:\n%s", s, text)
+		}
+	}
+	if strings.Contains(text, "Update examplemod") {
+		t.Errorf("Expect -top 1 prints only the best match, got:\n%s", text)
+	}
+}
+
+func TestRunAnalyzeJSON(t *testing.T) {
+	var out bytes.Buffer
+	if code := runAnalyze(newTestAnalyzer(), []string{"-json", "-"}, strings.NewReader(testLog), &out); code != 0 {
+		t.Fatalf("Expect exit code 0, got %d", code)
+	}
+	var analysis mcla.LogAnalysis
+	if err := json.Unmarshal(out.Bytes(), &analysis); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, out.String())
+	}
+	if len(analysis.Results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+	}
+	if res := analysis.Results[0]; res.File != "-" || len(res.Matched) != 2 {
+		t.Errorf("Expect the result of - with 2 matches, got %q with %d", res.File, len(res.Matched))
+	}
+}
+
+func TestRunAnalyzeMissingFile(t *testing.T) {
+	var out bytes.Buffer
+	if code := runAnalyze(newTestAnalyzer(), []string{filepath.Join(t.TempDir(), "missing.log")}, nil, &out); code != 1 {
+		t.Errorf("Expect exit code 1, got %d", code)
+	}
+}
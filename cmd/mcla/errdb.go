@@ -1,43 +1,38 @@
 package main
 
 import (
-	"fmt"
-	"io"
-	"net/http"
-	"net/url"
+	"os"
+	"path/filepath"
 
 	"github.com/GlobeMC/mcla"
 	"github.com/GlobeMC/mcla/ghdb"
 )
 
-type HTTPStatusErr struct {
-	URL        string
-	StatusCode int
-}
+var ghRepoPrefix = "https://raw.githubusercontent.com/kmcsr/mcla-db-dev/main"
 
-func (e *HTTPStatusErr) Error() string {
-	return fmt.Sprintf("HTTP status code error: %d when getting %q", e.StatusCode, e.URL)
+var defaultErrDB = &ghdb.ErrDB{
+	Cache:     newDefaultCache(),
+	Transport: &ghdb.HTTPTransport{Prefix: ghRepoPrefix},
 }
 
-var ghRepoPrefix = "https://raw.githubusercontent.com/kmcsr/mcla-db-dev/main"
+// newDefaultCache caches the database under the user's cache directory,
+// or in memory if there is no cache directory
+func newDefaultCache() ghdb.Cache {
+	dir, err := defaultCacheDir()
+	if err != nil {
+		return ghdb.NewInMemoryCache()
+	}
+	return ghdb.NewFileCache(dir)
+}
 
-var defaultErrDB = &ghdb.ErrDB{
-	Cache: ghdb.NewInMemoryCache(),
-	Fetch: func(path string) (io.ReadCloser, error) {
-		path, err := url.JoinPath(ghRepoPrefix, path)
-		if err != nil {
-			return nil, err
-		}
-		res, err := http.DefaultClient.Get(path)
-		if err != nil {
-			return nil, err
-		}
-		if res.StatusCode != 200 {
-			res.Body.Close()
-			return nil, &HTTPStatusErr{res.Request.URL.String(), res.StatusCode}
-		}
-		return res.Body, nil
-	},
+// defaultCacheDir returns `mcla` under the user's cache directory, e.g. `~/.cache/mcla`
+func defaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcla"), nil
 }
 
-var defaultAnalyzer = mcla.NewAnalyzer(defaultErrDB)
+// the embedded database is used until the github database is reachable
+var defaultAnalyzer = mcla.NewAnalyzer(mcla.NewFallbackDB(defaultErrDB, mcla.NewEmbeddedErrDB()))
@@ -14,8 +14,12 @@ Usage:
    mcla <subcommand> [<subcmd args>...]
 
 Subcommands:
+   - analyze [-json] [-top N] <filename or - for stdin>...
    - parseCrashReport <filename>
-   - analyzeErrors [<filename>...]
+   - analyzeCrashReports [<filename or directory>...]
+   - analyzeErrors [-fast-json] [<filename>...]
+
+The error database is cached under the user's cache directory, e.g. ~/.cache/mcla
 `
 
 func help() {
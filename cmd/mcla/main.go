@@ -1,10 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/GlobeMC/mcla"
 )
@@ -47,13 +50,48 @@ func main() {
 			printf("\nError when encoding report file as json: %v", err)
 			os.Exit(1)
 		}
+	case "analyze":
+		analyzeCmd(os.Args[2:])
 	case "analyzeErrors":
 		if len(os.Args) <= 2 {
 			return
 		}
 		files := os.Args[2:]
+		fastJSON := false
+		if files[0] == "-fast-json" {
+			fastJSON = true
+			files = files[1:]
+		}
 		for _, name := range files {
-			analysisAndOutput(name)
+			analysisAndOutput(name, fastJSON)
+		}
+	case "analyzeCrashReports":
+		if len(os.Args) <= 2 {
+			printf("[ERROR]: Must give the crashreport files or directories")
+			os.Exit(1)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		for _, name := range crashReportFiles(os.Args[2:]) {
+			fd, err := os.Open(name)
+			if err != nil {
+				printf("Error when opening report file %q: %v", name, err)
+				continue
+			}
+			analysis, err := defaultAnalyzer.AnalyzeCrashReport(context.Background(), fd)
+			fd.Close()
+			if err != nil {
+				printf("Error when analyzing report file %q: %v", name, err)
+				continue
+			}
+			for _, res := range analysis.Results {
+				res.File = name
+			}
+			if err = encoder.Encode(analysis); err != nil {
+				printf("\nError when encoding report file as json: %v", err)
+				os.Exit(1)
+			}
 		}
 	case "help":
 		help()
@@ -64,17 +102,52 @@ func main() {
 	}
 }
 
-func analysisAndOutput(file string) {
+// crashReportFiles expands the directories to the `.txt` files in them, the files are kept as is
+func crashReportFiles(names []string) (files []string) {
+	for _, name := range names {
+		entries, err := os.ReadDir(name)
+		if err != nil {
+			files = append(files, name)
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".txt" {
+				files = append(files, filepath.Join(name, e.Name()))
+			}
+		}
+	}
+	return
+}
+
+// writeFastJSON writes the result same as the json encoder of analysisAndOutput, the buffers are reused between the results
+func writeFastJSON(w io.Writer, res *mcla.ErrorResult, buf, indented *bytes.Buffer) error {
+	b, err := res.AppendJSON(buf.AvailableBuffer())
+	if err != nil {
+		return err
+	}
+	indented.Reset()
+	if err = json.Indent(indented, b, "", "  "); err != nil {
+		return err
+	}
+	indented.WriteByte('\n')
+	_, err = indented.WriteTo(w)
+	return err
+}
+
+// analysisAndOutput prints the results of the file, fastJSON encodes them by ErrorResult.AppendJSON
+// instead of the reflection based encoding/json
+func analysisAndOutput(file string, fastJSON bool) {
 	fd, err := os.Open(file)
 	if err != nil {
 		printf("Error when opening file %q: %v", file, err)
 		os.Exit(1)
 	}
 	defer fd.Close()
-	result, ctx := defaultAnalyzer.DoLogStream(context.Background(), fd)
+	result, ctx := defaultAnalyzer.DoLogStreamAuto(context.Background(), fd)
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetEscapeHTML(false)
 	encoder.SetIndent("", "  ")
+	var buf, indented bytes.Buffer
 	ok := false
 LOOP_RES:
 	for {
@@ -85,6 +158,13 @@ LOOP_RES:
 			}
 			ok = true
 			res.File = file
+			if fastJSON {
+				if err := writeFastJSON(os.Stdout, res, &buf, &indented); err != nil {
+					printf("\nError when encoding report file as json: %v", err)
+					os.Exit(1)
+				}
+				continue
+			}
 			if err = encoder.Encode(res); err != nil {
 				printf("\nError when encoding report file as json: %v", err)
 				os.Exit(1)
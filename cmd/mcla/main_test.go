@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/GlobeMC/mcla"
+)
+
+func TestWriteFastJSON(t *testing.T) {
+	res := &mcla.ErrorResult{
+		Error: &mcla.JavaError{
+			Class:   "java.lang.IllegalArgumentException",
+			Message: `Invalid <tag> & "quote" < \\u003e` + " ",
+			LineNo:  3,
+		},
+		Matched: []mcla.SolutionPossibility{},
+		File:    "a&b.log",
+	}
+	var expect bytes.Buffer
+	encoder := json.NewEncoder(&expect)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(res); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var out, buf, indented bytes.Buffer
+	if err := writeFastJSON(&out, res, &buf, &indented); err != nil {
+		t.Fatalf("writeFastJSON: %v", err)
+	}
+	if !strings.Contains(out.String(), `Invalid <tag> & \"quote\"`) {
+		t.Errorf("Expect the HTML characters are not escaped, got:\n%s", out.String())
+	}
+	if out.String() != expect.String() {
+		t.Errorf("Expect the fast json same as the encoder:\n%s\ngot:\n%s", expect.String(), out.String())
+	}
+}
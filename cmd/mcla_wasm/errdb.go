@@ -1,138 +1,115 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"io"
+	"net/http"
 	"net/url"
-	"strings"
-	"sync"
 	"syscall/js"
 
 	"github.com/GlobeMC/mcla"
 	"github.com/GlobeMC/mcla/ghdb"
 )
 
-type HTTPStatusErr struct {
-	URL        string
-	StatusCode int
-}
-
-func (e *HTTPStatusErr) Error() string {
-	return fmt.Sprintf("HTTP status code error: %d when getting %q", e.StatusCode, e.URL)
-}
-
 var ghRepoPrefix = "https://raw.githubusercontent.com/kmcsr/mcla-db-dev/main"
 
 // TODO: use https://developer.mozilla.org/en-US/docs/Web/API/IDBFactory
 
-type JsStorageCache struct {
+// jsStorage is a ghdb.Storage on the Web Storage API, or a storage which returns promises like localForage
+type jsStorage struct {
 	storage js.Value
-	prefix  string
-
-	workMux sync.RWMutex
-	working map[string]chan struct{}
 }
 
-var _ ghdb.Cache = &JsStorageCache{}
+var _ ghdb.Storage = (*jsStorage)(nil)
 
-func NewJsStorageCache(storage js.Value, prefix string) *JsStorageCache {
-	return &JsStorageCache{
-		storage: storage,
-		prefix:  prefix,
-		working: make(map[string]chan struct{}, 32),
+func NewJsStorageCache(storage js.Value, prefix string) ghdb.Cache {
+	return ghdb.NewStorageCache(&jsStorage{storage: storage}, prefix)
+}
+
+func (s *jsStorage) Get(key string) (value string, ok bool) {
+	item := s.storage.Call("getItem", key)
+	if item.Truthy() {
+		res, _ := awaitPromise(item)
+		if res.Truthy() {
+			return res.String(), true
+		}
 	}
+	return "", false
 }
 
-func (s *JsStorageCache) Clear() {
+func (s *jsStorage) Set(key string, value string) {
+	s.storage.Call("setItem", key, value)
+}
+
+func (s *jsStorage) Remove(key string) {
+	s.storage.Call("removeItem", key)
+}
+
+func (s *jsStorage) Keys() (keys []string) {
 	obj := s.storage.Get("length")
 	if obj.Type() == js.TypeNumber {
 		leng := obj.Int()
+		keys = make([]string, 0, leng)
 		for i := 0; i < leng; i++ {
-			key := s.storage.Call("key", i).String()
-			if strings.HasPrefix(key, s.prefix) {
-				s.storage.Call("removeItem", key)
-			}
+			keys = append(keys, s.storage.Call("key", i).String())
 		}
 	} else if keysFn := s.storage.Get("keys"); keysFn.Type() == js.TypeFunction {
-		keys, _ := awaitPromise(keysFn.Invoke())
-		if keys.InstanceOf(Array) {
-			leng := keys.Length()
+		res, _ := awaitPromise(keysFn.Invoke())
+		if res.InstanceOf(Array) {
+			leng := res.Length()
+			keys = make([]string, 0, leng)
 			for i := 0; i < leng; i++ {
-				key := keys.Index(i).String()
-				if strings.HasPrefix(key, s.prefix) {
-					s.storage.Call("removeItem", key)
-				}
+				keys = append(keys, res.Index(i).String())
 			}
 		}
 	}
+	return
 }
 
-func (s *JsStorageCache) Get(key string) string {
-	s.workMux.RLock()
-	ch := s.working[key]
-	s.workMux.RUnlock()
-	if ch != nil {
-		<-ch
-	}
-	item := s.storage.Call("getItem", s.prefix+key)
-	if item.Truthy() {
-		res, _ := awaitPromise(item)
-		if res.Truthy() {
-			return res.String()
-		}
-	}
-	return ""
-}
+const appStorageKeyPrefix = "com.github.kmcsr.mcla."
 
-func (s *JsStorageCache) Set(key string, value string) {
-	s.storage.Call("setItem", s.prefix+key, value)
-}
+// fetchTransport fetches the database files under ghRepoPrefix
+type fetchTransport struct{}
 
-func (s *JsStorageCache) Remove(key string) {
-	s.storage.Call("removeItem", s.prefix+key)
+var _ ghdb.ConditionalTransport = fetchTransport{}
+
+func (fetchTransport) Open(path string) (io.ReadCloser, error) {
+	body, _, err := fetchTransport{}.OpenConditional(bgCtx, path, "")
+	return body, err
 }
 
-func (s *JsStorageCache) GetOrSet(key string, setter func() string) string {
-	v := s.Get(key)
-	if v == "" {
-		s.workMux.Lock()
-		if ch := s.working[key]; ch != nil {
-			s.workMux.Unlock()
-			return s.Get(key)
+func (fetchTransport) OpenConditional(ctx context.Context, path string, validator string) (io.ReadCloser, string, error) {
+	path, err := url.JoinPath(ghRepoPrefix, path)
+	if err != nil {
+		return nil, "", err
+	}
+	var opts []Map
+	if validator != "" {
+		header := Map{"If-Modified-Since": validator}
+		if ghdb.IsETag(validator) {
+			header = Map{"If-None-Match": validator}
 		}
-		done := make(chan struct{}, 0)
-		s.working[key] = done
-		s.workMux.Unlock()
-
-		v = setter()
-		s.Set(key, v)
-		close(done)
-		s.workMux.Lock()
-		delete(s.working, key)
-		s.workMux.Unlock()
+		opts = append(opts, Map{"headers": header})
 	}
-	return v
+	res, err := fetchContext(ctx, path, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	if res.StatusCode == 304 && validator != "" {
+		res.Body.Close()
+		return nil, "", ghdb.ErrNotModified
+	}
+	if res.StatusCode != 200 {
+		res.Body.Close()
+		return nil, "", &ghdb.HTTPStatusErr{URL: res.Url, StatusCode: res.StatusCode}
+	}
+	return res.Body, ghdb.ResponseValidator((http.Header)(res.Header)), nil
 }
 
-const appStorageKeyPrefix = "com.github.kmcsr.mcla."
-
 var defaultErrDB = &ghdb.ErrDB{
-	Cache: NewJsStorageCache(localStorage, appStorageKeyPrefix),
-	Fetch: func(path string) (io.ReadCloser, error) {
-		path, err := url.JoinPath(ghRepoPrefix, path)
-		if err != nil {
-			return nil, err
-		}
-		res, err := fetch(path)
-		if err != nil {
-			return nil, err
-		}
-		if res.StatusCode != 200 {
-			res.Body.Close()
-			return nil, &HTTPStatusErr{res.Url, res.StatusCode}
-		}
-		return res.Body, nil
-	},
+	Cache:     NewJsStorageCache(localStorage, appStorageKeyPrefix),
+	Transport: fetchTransport{},
 }
 
-var defaultAnalyzer = mcla.NewAnalyzer(defaultErrDB)
+// the embedded database is used until the github database is reachable
+var defaultAnalyzer = mcla.NewAnalyzer(mcla.NewFallbackDB(defaultErrDB, mcla.NewEmbeddedErrDB()))
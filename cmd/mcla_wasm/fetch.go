@@ -23,7 +23,7 @@ func fetchContext(ctx context.Context, url string, opts ...Map) (res *Response,
 	args := make([]any, 1, 2)
 	args[0] = url
 	if len(opts) > 0 {
-		args = append(args, opts)
+		args = append(args, opts[0])
 	}
 	var res0 js.Value
 	if res0, err = awaitPromiseContext(ctx, jsFetch.Invoke(args...)); err != nil {
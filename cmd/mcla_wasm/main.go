@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"sync"
 	"syscall/js"
 
 	. "github.com/GlobeMC/mcla"
+	"github.com/GlobeMC/mcla/ghdb"
 )
 
 var bgCtx context.Context = createBackgroundCtx()
@@ -32,6 +35,18 @@ func getAPI() (m Map) {
 		"analyzeLogErrorsIter": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
 			return analyzeLogErrorsIter(args)
 		}),
+		"analyzeLogStream": js.FuncOf(func(_ js.Value, args []js.Value) (res any) {
+			return analyzeLogStream(args)
+		}),
+		"newIncrementalAnalysis": js.FuncOf(func(_ js.Value, _ []js.Value) (res any) {
+			return newIncrementalAnalysis()
+		}),
+		"warmup": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
+			return nil, warmup(args)
+		}),
+		"updateErrors": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
+			return nil, updateErrors(args)
+		}),
 		"setGhDbPrefix": js.FuncOf(func(_ js.Value, args []js.Value) (res any) {
 			prefix := args[0]
 			prefixStr := prefix.String()
@@ -48,8 +63,7 @@ Under GNU AFFERO GENERAL PUBLIC LICENSE v3
 `
 
 func main() {
-	defaultErrDB.RefreshCache()
-
+	// the database is loaded by updateErrors or the first analysis, so the page can show the progress
 	api := getAPI()
 	api["release"] = js.FuncOf(func(_ js.Value, _ []js.Value) (_ any) {
 		global.Delete("MCLA")
@@ -95,16 +109,142 @@ func analyzeLogErrors(args []js.Value) (result []*ErrorResult, err error) {
 	if err != nil {
 		return
 	}
-	result = make([]*ErrorResult, 0, 5)
-	resCh, ctx := defaultAnalyzer.DoLogStream(bgCtx, r)
-	for {
-		select {
-		case res := <-resCh:
-			result = append(result, res)
-		case <-ctx.Done():
-			return nil, context.Cause(ctx)
+	analysis, err := defaultAnalyzer.AnalyzeLog(bgCtx, r)
+	if err != nil && !errors.Is(err, ErrCrashReportIncomplete) { // a truncated log still has its partial results
+		return
+	}
+	return analysis.Results, nil
+}
+
+// warmup prefetches the database files into the storage cache.
+// Both arguments are optional, the first one is the max concurrent fetches,
+// the second one is a callback called with (done, total) after each file is fetched
+func warmup(args []js.Value) (err error) {
+	concurrency := 0
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		concurrency = args[0].Int()
+	}
+	var progress ghdb.WarmupProgress
+	if len(args) > 1 && args[1].Type() == js.TypeFunction {
+		onProgress := args[1]
+		progress = func(done, total int) {
+			onProgress.Invoke(done, total)
+		}
+	}
+	return defaultErrDB.Warmup(bgCtx, concurrency, progress)
+}
+
+// updateErrors reloads the error descriptions of the analyzer.
+// The optional argument is a callback called with `{done, fetched, cached, remaining, total}` as the descriptions are loaded,
+// fetched counts the files downloaded from the database, and cached counts the ones read from the storage
+func updateErrors(args []js.Value) (err error) {
+	ctx := bgCtx
+	if len(args) > 0 && args[0].Type() == js.TypeFunction {
+		onProgress := args[0]
+		ctx = ghdb.WithLoadProgress(ctx, func(done, fetched, total int) {
+			onProgress.Invoke(Map{
+				"done":      done,
+				"fetched":   fetched,
+				"cached":    done - fetched,
+				"remaining": total - done,
+				"total":     total,
+			})
+		})
+	}
+	return defaultAnalyzer.UpdateErrorsContext(ctx)
+}
+
+// newIncrementalAnalysis returns an object with `append(text)` and `finish()` methods,
+// both of them resolve the results of the errors which are completed
+func newIncrementalAnalysis() Map {
+	var mux sync.Mutex
+	ia := defaultAnalyzer.NewIncrementalAnalysis()
+	return Map{
+		"append": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
+			mux.Lock()
+			defer mux.Unlock()
+			return ia.Append(([]byte)(args[0].String()))
+		}),
+		"finish": asyncFuncOf(func(_ js.Value, _ []js.Value) (res any, err error) {
+			mux.Lock()
+			defer mux.Unlock()
+			return ia.Finish()
+		}),
+	}
+}
+
+var (
+	errStreamCanceled   = errors.New("Log stream canceled")
+	errNoResultCallback = errors.New("onResult must be a function")
+)
+
+// analyzeLogStream drives DoLogStream, it calls `onResult(result)` for each result as soon as it's analyzed,
+// and then `onDone(incomplete)` when the log ends, or `onError(message)` if the stream failed or canceled.
+// The optional fifth argument is an AbortSignal, and the returned function cancels the stream as well.
+// The returned function is released when the stream ends, so it should not be called after onDone or onError.
+// If onResult is not a function, the stream is not started and onError is called
+func analyzeLogStream(args []js.Value) (cancelFn js.Func) {
+	arg := func(i int) js.Value {
+		if i < len(args) {
+			return args[i]
+		}
+		return js.Undefined()
+	}
+	value, onResult, onDone, onError := arg(0), arg(1), arg(2), arg(3)
+	ctx, cancel := context.WithCancelCause(bgCtx)
+	cancelFn = js.FuncOf(func(_ js.Value, _ []js.Value) (res any) {
+		cancel(errStreamCanceled)
+		return
+	})
+	context.AfterFunc(ctx, cancelFn.Release)
+	fail := func(err error) {
+		if onError.Type() == js.TypeFunction {
+			onError.Invoke(err.Error())
+		}
+	}
+	if onResult.Type() != js.TypeFunction {
+		cancel(errNoResultCallback)
+		fail(errNoResultCallback)
+		return
+	}
+	if signal := arg(4); signal.Type() == js.TypeObject {
+		if signal.Get("aborted").Bool() {
+			cancel(errStreamCanceled)
 		}
+		onAbort := js.FuncOf(func(_ js.Value, _ []js.Value) (res any) {
+			cancel(errStreamCanceled)
+			return
+		})
+		signal.Call("addEventListener", "abort", onAbort)
+		context.AfterFunc(ctx, func() {
+			signal.Call("removeEventListener", "abort", onAbort)
+			onAbort.Release()
+		})
 	}
+	go func() {
+		defer cancel(nil)
+		r, err := wrapJsValueAsReader(value)
+		if err != nil {
+			fail(err)
+			return
+		}
+		// a pending read of a ReadableStream will not stop by itself
+		if c, ok := r.(io.Closer); ok {
+			context.AfterFunc(ctx, func() { c.Close() })
+		}
+		result, sctx := defaultAnalyzer.DoLogStream(ctx, r)
+		for res := range result {
+			onResult.Invoke(asJsValue(res))
+		}
+		err = context.Cause(sctx)
+		if err != nil && !errors.Is(err, ErrCrashReportIncomplete) {
+			fail(err)
+			return
+		}
+		if onDone.Type() == js.TypeFunction {
+			onDone.Invoke(err != nil)
+		}
+	}()
 	return
 }
 
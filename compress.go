@@ -0,0 +1,56 @@
+package mcla
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+)
+
+// gzipMagic is the first two bytes of a gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// autoReader decompresses the data if it starts with the gzip magic bytes, see NewAutoReader
+type autoReader struct {
+	br *bufio.Reader
+	r  io.Reader // the reader after the detection, nil before the first read
+}
+
+// NewAutoReader returns a reader which decompresses r if it's gzip compressed, e.g. a rotated `2024-01-01-1.log.gz`,
+// otherwise it reads the same data as r. The magic bytes are peeked by a buffered reader at the first read,
+// so no bytes are lost, and creating the reader does not block
+func NewAutoReader(r io.Reader) io.Reader {
+	return &autoReader{br: bufio.NewReader(r)}
+}
+
+func (r *autoReader) Read(buf []byte) (n int, err error) {
+	if r.r == nil {
+		r.r = r.br
+		magic, err := r.br.Peek(len(gzipMagic))
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if bytes.Equal(magic, gzipMagic) {
+			if r.r, err = gzip.NewReader(r.br); err != nil {
+				r.r = errReader{err}
+				return 0, err
+			}
+		}
+	}
+	return r.r.Read(buf)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// DoLogStreamAuto is same as DoLogStream, but the log can be gzip compressed, see NewAutoReader.
+// A corrupted gzip stream cancels the context with the error like the other read errors
+func (a *Analyzer) DoLogStreamAuto(c context.Context, r io.Reader) (<-chan *ErrorResult, context.Context) {
+	return a.DoLogStream(c, NewAutoReader(r))
+}
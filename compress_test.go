@@ -0,0 +1,71 @@
+package mcla_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+func gzipText(t *testing.T, text string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(w, text); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestAutoReader(t *testing.T) {
+	for _, d := range []struct {
+		Name string
+		Data []byte
+	}{
+		{"plain", ([]byte)(tickSpamLog)},
+		{"gzip", gzipText(t, tickSpamLog)},
+	} {
+		data, err := io.ReadAll(NewAutoReader(bytes.NewReader(d.Data)))
+		if err != nil {
+			t.Fatalf("%s: ReadAll: %v", d.Name, err)
+		}
+		if (string)(data) != tickSpamLog {
+			t.Errorf("%s: Expect the original log, got %q", d.Name, data)
+		}
+	}
+	for _, text := range []string{"", "\x1f"} {
+		data, err := io.ReadAll(NewAutoReader(strings.NewReader(text)))
+		if err != nil || (string)(data) != text {
+			t.Errorf("Expect the short input %q to pass through, got %q, %v", text, data, err)
+		}
+	}
+}
+
+func TestDoLogStreamAuto(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	results, _ := analyzer.DoLogStreamAuto(context.Background(), bytes.NewReader(gzipText(t, tickSpamLog)))
+	count := 0
+	for res := range results {
+		if res.Error.Class == "" {
+			t.Errorf("Expect the errors of the decompressed log, got %#v", res.Error)
+		}
+		count++
+	}
+	if count != 4 {
+		t.Errorf("Expect 4 results from the compressed log, got %d", count)
+	}
+
+	corrupted := gzipText(t, tickSpamLog)[:20]
+	results, ctx := analyzer.DoLogStreamAuto(context.Background(), bytes.NewReader(corrupted))
+	for range results {
+	}
+	if err := context.Cause(ctx); err == nil {
+		t.Errorf("Expect the corrupted gzip stream to cancel the context")
+	}
+}
@@ -0,0 +1,58 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+)
+
+const contextLog = `[12:00:00] [Server thread/INFO]: Preparing spawn area: 0%
+[12:00:01] [Server thread/ERROR]: Encountered an unexpected exception
+java.lang.RuntimeException: Something went wrong
+	at com.example.mod.Foo.bar(Foo.java:42)
+[12:01:00] [Render thread/INFO]: Reloading ResourceManager: Default
+[12:01:01] [Render thread/ERROR]: Encountered an unexpected exception
+java.lang.RuntimeException: Something went wrong
+	at com.example.mod.Foo.bar(Foo.java:42)
+`
+
+func TestDoErrorContext(t *testing.T) {
+	worldLoad := &ErrorDesc{
+		Error:   "java.lang.RuntimeException",
+		Message: "Something went wrong",
+		Context: `Preparing spawn area|Preparing start region`,
+	}
+	rendering := &ErrorDesc{
+		Error:   "java.lang.RuntimeException",
+		Message: "Something went wrong",
+		Context: `Render thread`,
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{worldLoad, rendering}})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(contextLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 2 {
+		t.Fatalf("Expect 2 results, got %d", len(analysis.Results))
+	}
+	for i, expect := range []*ErrorDesc{worldLoad, rendering} {
+		res := analysis.Results[i]
+		if len(res.Error.Context) == 0 || !strings.Contains(res.Error.Context[len(res.Error.Context)-1], "Encountered an unexpected exception") {
+			t.Errorf("Expect the context of error %d to end with the preceding log line, got %q", i, res.Error.Context)
+		}
+		var best SolutionPossibility
+		for _, m := range res.Matched {
+			if m.Match > best.Match {
+				best = m
+			}
+		}
+		if !sameDesc(best.ErrorDesc, expect) {
+			t.Errorf("Expect error %d to match the description with context %q, got %#v", i, expect.Context, best.ErrorDesc)
+		}
+		if best.Match != 1 {
+			t.Errorf("Expect error %d to match with 1, got %v", i, best.Match)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package mcla
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+const defaultCorrelationWindow = 5 * time.Second
+
+// LabeledLog is a log to be analyzed together with the others, the label is usually "client" or "server"
+type LabeledLog struct {
+	Label  string
+	Reader io.Reader
+}
+
+type LabeledResult struct {
+	Label  string       `json:"label"`
+	Result *ErrorResult `json:"result"`
+}
+
+// ErrorLink links two errors from different logs which may have the same cause
+type ErrorLink struct {
+	A LabeledResult `json:"a"`
+	B LabeledResult `json:"b"`
+	// TimeDiff is the absolute difference between the errors' time, it's only valid if ByTime is true
+	TimeDiff        time.Duration `json:"timeDiff"`
+	ByTime          bool          `json:"byTime"`
+	SameFingerprint bool          `json:"sameFingerprint"`
+}
+
+type MultiLogAnalysis struct {
+	Logs  map[string]*LogAnalysis `json:"logs"`
+	Links []ErrorLink             `json:"links"`
+}
+
+// AnalyzeLogs analyzes the logs one by one, then links the top level errors across different logs
+// when they have the same fingerprint or happened within the analyzer's CorrelationWindow.
+// The log timestamps usually don't have a date, so the logs are assumed to be recorded on the same day.
+// If any log is truncated, ErrCrashReportIncomplete is returned together with the result
+func (a *Analyzer) AnalyzeLogs(ctx context.Context, logs []LabeledLog) (res *MultiLogAnalysis, err error) {
+	res = &MultiLogAnalysis{
+		Logs:  make(map[string]*LogAnalysis, len(logs)),
+		Links: make([]ErrorLink, 0),
+	}
+	var incomplete error
+	for _, l := range logs {
+		analysis, err := a.AnalyzeLog(ctx, l.Reader)
+		if err != nil {
+			if !errors.Is(err, ErrCrashReportIncomplete) {
+				return nil, err
+			}
+			incomplete = err
+		}
+		res.Logs[l.Label] = analysis
+	}
+	err = incomplete
+	window := a.CorrelationWindow
+	if window <= 0 {
+		window = defaultCorrelationWindow
+	}
+	for i, l1 := range logs {
+		tops1 := topLevelResults(res.Logs[l1.Label].Results)
+		for _, l2 := range logs[i+1:] {
+			if l1.Label == l2.Label {
+				continue
+			}
+			tops2 := topLevelResults(res.Logs[l2.Label].Results)
+			for _, r1 := range tops1 {
+				for _, r2 := range tops2 {
+					if link, ok := linkErrors(r1, r2, window); ok {
+						link.A = LabeledResult{l1.Label, r1}
+						link.B = LabeledResult{l2.Label, r2}
+						res.Links = append(res.Links, link)
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+func linkErrors(r1, r2 *ErrorResult, window time.Duration) (link ErrorLink, ok bool) {
+	e1, e2 := r1.Error, r2.Error
+	if !e1.Time.IsZero() && !e2.Time.IsZero() {
+		diff := logTimeDiff(e1.Time, e2.Time)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= window {
+			link.TimeDiff = diff
+			link.ByTime = true
+			ok = true
+		}
+	}
+	if e1.Fingerprint() == e2.Fingerprint() {
+		link.SameFingerprint = true
+		ok = true
+	}
+	return
+}
+
+// logTimeDiff returns t1 - t2, only the clock times are compared if any of them does not have a date
+func logTimeDiff(t1, t2 time.Time) time.Duration {
+	if t1.Year() == clockTimeYear || t2.Year() == clockTimeYear {
+		t1, t2 = t1.UTC(), t2.UTC()
+		t1 = time.Date(clockTimeYear, time.January, 1, t1.Hour(), t1.Minute(), t1.Second(), t1.Nanosecond(), time.UTC)
+		t2 = time.Date(clockTimeYear, time.January, 1, t2.Hour(), t2.Minute(), t2.Second(), t2.Nanosecond(), time.UTC)
+	}
+	return t1.Sub(t2)
+}
@@ -0,0 +1,95 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+	"time"
+)
+
+const clientLog = `[12:00:00] [Render thread/INFO]: Setting user: Steve
+[12:00:10] [Render thread/ERROR]: Failed to load texture
+java.io.FileNotFoundException: minecraft:textures/foo.png
+	at net.minecraft.client.renderer.texture.SimpleTexture.load(SimpleTexture.java:40)
+[12:30:15] [Netty Client IO #1/ERROR]: Exception caught in connection
+io.netty.handler.codec.DecoderException: java.io.IOException: Connection reset by peer
+	at io.netty.handler.codec.ByteToMessageDecoder.callDecode(ByteToMessageDecoder.java:489)
+[12:30:15] [Render thread/INFO]: Connection lost
+`
+
+const serverLog = `[12:00:00] [Server thread/INFO]: Starting minecraft server version 1.20.1
+[12:30:14] [Server thread/ERROR]: Encountered an unexpected exception
+java.lang.IllegalStateException: Entity is already tracked!
+	at net.minecraft.server.level.ChunkMap.addEntity(ChunkMap.java:1027)
+	at net.minecraft.server.level.ServerLevel.addEntity(ServerLevel.java:980)
+[12:30:16] [Server thread/INFO]: Stopping server
+`
+
+func TestAnalyzeLogsCorrelation(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	res, err := analyzer.AnalyzeLogs(context.Background(), []LabeledLog{
+		{Label: "client", Reader: strings.NewReader(clientLog)},
+		{Label: "server", Reader: strings.NewReader(serverLog)},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeLogs: %v", err)
+	}
+	if n := len(res.Logs["client"].Results); n != 2 {
+		t.Errorf("Expect 2 client results, got %d", n)
+	}
+	if n := len(res.Logs["server"].Results); n != 1 {
+		t.Errorf("Expect 1 server result, got %d", n)
+	}
+	if len(res.Links) != 1 {
+		t.Fatalf("Expect exactly 1 link, got %d", len(res.Links))
+	}
+	link := res.Links[0]
+	if link.A.Label != "client" || link.B.Label != "server" {
+		t.Errorf("Expect link between client and server, got %q and %q", link.A.Label, link.B.Label)
+	}
+	if expect := "io.netty.handler.codec.DecoderException"; link.A.Result.Error.Class != expect {
+		t.Errorf("Expect client error %q, got %q", expect, link.A.Result.Error.Class)
+	}
+	if expect := "java.lang.IllegalStateException"; link.B.Result.Error.Class != expect {
+		t.Errorf("Expect server error %q, got %q", expect, link.B.Result.Error.Class)
+	}
+	if !link.ByTime || link.TimeDiff != time.Second {
+		t.Errorf("Expect the errors linked by time with 1s difference, got ByTime=%v TimeDiff=%v", link.ByTime, link.TimeDiff)
+	}
+}
+
+func TestAnalyzeLogsCorrelationMixedDates(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	res, err := analyzer.AnalyzeLogs(context.Background(), []LabeledLog{
+		{Label: "client", Reader: strings.NewReader(clientLog)},
+		{Label: "server", Reader: strings.NewReader(strings.ReplaceAll(serverLog, "[12:", "[2024-01-01 12:"))},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeLogs: %v", err)
+	}
+	if len(res.Links) != 1 {
+		t.Fatalf("Expect exactly 1 link, got %d", len(res.Links))
+	}
+	if link := res.Links[0]; !link.ByTime || link.TimeDiff != time.Second {
+		t.Errorf("Expect the errors linked by clock time with 1s difference, got ByTime=%v TimeDiff=%v", link.ByTime, link.TimeDiff)
+	}
+}
+
+func TestAnalyzeLogsCorrelationMidnight(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	res, err := analyzer.AnalyzeLogs(context.Background(), []LabeledLog{
+		{Label: "client", Reader: strings.NewReader(strings.ReplaceAll(clientLog, "[12:30:15]", "[00:00:01]"))},
+		{Label: "server", Reader: strings.NewReader(strings.ReplaceAll(serverLog, "[12:30:14]", "[00:00:00]"))},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeLogs: %v", err)
+	}
+	if len(res.Links) != 1 {
+		t.Fatalf("Expect exactly 1 link, got %d", len(res.Links))
+	}
+	if link := res.Links[0]; !link.ByTime || link.TimeDiff != time.Second {
+		t.Errorf("Expect the error at midnight linked by time with 1s difference, got ByTime=%v TimeDiff=%v", link.ByTime, link.TimeDiff)
+	}
+}
@@ -2,8 +2,10 @@ package mcla
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
+	"slices"
 	"strings"
 )
 
@@ -20,6 +22,8 @@ var (
 	detailsKeyHeader  = strings.ToUpper("Details:")
 	stacktraceHeader  = strings.ToUpper("Stacktrace:")
 	threadKeyHeader   = strings.ToUpper("Thread:")
+	// systemDetailsHeader is the last section of a crash report
+	systemDetailsHeader = strings.ToUpper("-- System Details --")
 )
 
 func hasIndent(line []byte) bool {
@@ -43,6 +47,7 @@ func parseReportDetails(sc *lineScanner) (d ReportDetails, err error) {
 func parseReportDetails0(sc *lineScanner) (d ReportDetails, err error) {
 	d = make(ReportDetails)
 	line := sc.Bytes()
+	var lastKey string
 	for {
 		if len(line) < 2 || !hasIndent(line) {
 			return
@@ -53,12 +58,22 @@ func parseReportDetails0(sc *lineScanner) (d ReportDetails, err error) {
 		line = line[1:]
 		i := bytes.IndexByte(line, ':')
 		if i < 0 {
-			return nil, ErrMissingColon
+			// the legacy Forge lists the mods without keys, e.g. `UCHIJAAAA	mcp{9.05} [Minecraft Coder Pack] (minecraft.jar)`
+			if len(lastKey) == 0 {
+				return nil, ErrMissingColon
+			}
+			d.add(lastKey, (string)(bytes.TrimSpace(line)))
+			if !sc.Scan() {
+				return
+			}
+			line = sc.Bytes()
+			continue
 		}
 		var (
 			key    string = (string)(bytes.TrimSpace(line[:i]))
 			values []string
 		)
+		lastKey = key
 		if line = bytes.TrimSpace(line[i+1:]); len(line) > 0 {
 			values = []string{(string)(line)}
 		}
@@ -84,6 +99,11 @@ func (d ReportDetails) set(key string, values []string) {
 	d[strings.ToUpper(key)] = values
 }
 
+func (d ReportDetails) add(key string, value string) {
+	key = strings.ToUpper(key)
+	d[key] = append(d[key], value)
+}
+
 func (d ReportDetails) Has(key string) (ok bool) {
 	_, ok = d[strings.ToUpper(key)]
 	return
@@ -123,7 +143,7 @@ func parseHeadThread(sc *lineScanner) (res HeadThread, err error) {
 				return
 			}
 		case strings.HasPrefix(line, stacktraceHeader):
-			res.Stacktrace = parseStacktrace(sc)
+			res.Stacktrace, _ = parseStacktrace(sc)
 		default:
 			if !sc.Scan() {
 				return
@@ -157,7 +177,7 @@ func parseAffectedLevel(sc *lineScanner) (res AffectedLevel, err error) {
 				return
 			}
 		case strings.HasPrefix(line, stacktraceHeader):
-			res.Stacktrace = parseStacktrace(sc)
+			res.Stacktrace, _ = parseStacktrace(sc)
 		default:
 			if !sc.Scan() {
 				return
@@ -204,10 +224,62 @@ type CrashReport struct { // ---- Minecraft Crash Report ----
 	HeadThread    HeadThread             `json:"head"`          // -- Head --
 	AffectedLevel AffectedLevel          `json:"affectedLevel"` // -- Affected level --
 	OtherDetails  map[string]DetailsItem `json:"others"`        // -- <KEY> --
+	// Sections are the raw text of the `-- <KEY> --` sections without the headers, the keys are in upper case
+	Sections map[string]string `json:"sections,omitempty"`
+	// Environment is parsed from the `-- System Details --` section, it's nil if the section is missing
+	Environment *Environment `json:"environment,omitempty"`
 }
 
+// ParseCrashReport parses the crash report in the log, the lines before the crash report header are skipped.
+// io.EOF is returned if there is no crash report
 func ParseCrashReport(r io.Reader) (report *CrashReport, err error) {
-	sc := newLineScanner(r)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	if report, err = parseCrashReport(newLineScanner(bytes.NewReader(data))); report != nil {
+		report.Sections = splitReportSections(data)
+		if details, ok := report.Sections[strings.ToUpper("System Details")]; ok {
+			report.Environment = parseEnvironment(details)
+		}
+	}
+	return
+}
+
+// splitReportSections collects the lines of each `-- <KEY> --` section
+func splitReportSections(data []byte) (sections map[string]string) {
+	var (
+		name  string
+		lines []string
+	)
+	flush := func() {
+		if name == "" {
+			return
+		}
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if sections == nil {
+			sections = make(map[string]string)
+		}
+		sections[name] = strings.Join(lines, "\n")
+	}
+	for _, line := range strings.Split((string)(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if h := strings.TrimSpace(line); strings.HasPrefix(h, "-- ") && strings.HasSuffix(h, " --") {
+			flush()
+			name, lines = strings.ToUpper(strings.TrimSpace(h[len("-- "):len(h)-len(" --")])), nil
+			continue
+		}
+		if name != "" {
+			lines = append(lines, line)
+		}
+	}
+	flush()
+	return
+}
+
+func parseCrashReport(sc *lineScanner) (report *CrashReport, err error) {
 	for {
 		if !sc.Scan() {
 			if err = sc.Err(); err == nil {
@@ -273,3 +345,53 @@ func ParseCrashReport(r io.Reader) (report *CrashReport, err error) {
 func (report *CrashReport) GetDetails(key string) (value DetailsItem) {
 	return report.OtherDetails[strings.ToUpper(key)]
 }
+
+// GetSection returns the raw text of the `-- <KEY> --` section, the key is case insensitive
+func (report *CrashReport) GetSection(key string) string {
+	return report.Sections[strings.ToUpper(key)]
+}
+
+// AnalyzeCrashReport parses the crash report with ParseCrashReport, and analyzes its primary exception and the causes.
+// The descriptions are scoped by the metadata of the report, and the crash is located by the `-- Head --` stacktrace.
+// If the report only has a description, it is analyzed as a summary like in DoLogStream
+func (a *Analyzer) AnalyzeCrashReport(ctx context.Context, r io.Reader) (analysis *LogAnalysis, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	// record the report like a stream, so the recent mixin logs are of the report
+	recorder := a.newLogRecorder()
+	recorder.Write(data)
+	recorder.Close()
+
+	report, err := ParseCrashReport(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	analysis = &LogAnalysis{
+		Results:     make([]*ErrorResult, 0, 3),
+		CrashReport: report,
+	}
+	cache := newSimilarityCache(a.SimilarityCacheSize)
+	if report.Error == nil {
+		if report.Description != "" {
+			if analysis.Results, err = a.summaryResults(ctx, []summaryLine{{Text: report.Description}}, cache, recorder.logs); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if analysis.Results, err = a.analyzeChain(ctx, report.Error, report.Metadata(), cache, recorder.logs); err != nil {
+			return nil, err
+		}
+		if len(report.HeadThread.Stacktrace) > 0 && len(analysis.Results) > 0 && analysis.Results[0].Error == report.Error {
+			analysis.Results[0].Location = a.LocateCrash(report.Error, &report.HeadThread)
+		}
+	}
+	if a.ResultFilter != nil {
+		analysis.Results = slices.DeleteFunc(analysis.Results, func(res *ErrorResult) bool {
+			return !a.ResultFilter(res)
+		})
+	}
+	a.selectPrimary(analysis, nil)
+	return
+}
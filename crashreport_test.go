@@ -0,0 +1,188 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"io"
+	"strings"
+)
+
+const moddedCrashReport = `---- Minecraft Crash Report ----
+// Who set us up the TNT?
+
+Time: 2024-01-01 12:34:56
+Description: Rendering overlay
+
+java.lang.NullPointerException: Cannot invoke "net.minecraft.client.renderer.RenderType.m_110405_()" because "renderType" is null
+	at com.example.mod.client.Renderer.render(Renderer.java:42) ~[examplemod-1.0.0.jar%23120!/:1.0.0] {re:classloading}
+	at net.minecraft.client.renderer.GameRenderer.m_109093_(GameRenderer.java:954) ~[client-1.20.1-20230612.114412-srg.jar%23156!/:?] {re:classloading,pl:runtimedistcleaner:A}
+	at net.minecraft.client.Minecraft.m_91383_(Minecraft.java:1146) ~[client-1.20.1-20230612.114412-srg.jar%23156!/:?] {re:classloading,pl:runtimedistcleaner:A}
+
+
+A detailed walkthrough of the error, its code path and all known details is as follows:
+---------------------------------------------------------------------------------------
+
+-- Head --
+Thread: Render thread
+Suspected Mod: 
+	Example Mod (examplemod), Version: 1.0.0
+		at TRANSFORMER/examplemod@1.0.0/com.example.mod.client.Renderer.render(Renderer.java:42)
+Stacktrace:
+	at com.example.mod.client.Renderer.render(Renderer.java:42) ~[examplemod-1.0.0.jar%23120!/:1.0.0] {re:classloading}
+
+-- System Details --
+Details:
+	Minecraft Version: 1.20.1
+	Minecraft Version ID: 1.20.1
+	Operating System: Windows 10 (amd64) version 10.0
+	Java Version: 17.0.8, Eclipse Adoptium
+	Launched Version: forge-47.2.0
+	Backend library: LWJGL version 3.3.1 build 7
+	Is Modded: Definitely; Client brand changed to 'forge'
+	Type: Client (map_client.txt)
+`
+
+const vanillaCrashReport = `---- Minecraft Crash Report ----
+Description: Rendering overlay
+
+java.lang.NullPointerException: Cannot invoke "net.minecraft.client.renderer.RenderType.m_110405_()" because "renderType" is null
+	at net.minecraft.client.renderer.GameRenderer.render(GameRenderer.java:954)
+
+-- System Details --
+Details:
+	Minecraft Version: 1.20.1
+	Launched Version: HMCL 3.5.5
+	Is Modded: Probably not. Client jar signature and brand is untouched
+	Type: Client (map_client.txt)
+`
+
+func TestCrashReportMetadata(t *testing.T) {
+	report, err := ParseCrashReport(strings.NewReader(moddedCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	meta := report.Metadata()
+	if meta.Modded != ModdedYes {
+		t.Errorf("Expect meta.Modded == %q, got %q", ModdedYes, meta.Modded)
+	}
+	if expect := "Client brand changed to 'forge'"; meta.ModdedReason != expect {
+		t.Errorf("Expect meta.ModdedReason == %q, got %q", expect, meta.ModdedReason)
+	}
+	if expect := "forge-47.2.0"; meta.LaunchedVersion != expect {
+		t.Errorf("Expect meta.LaunchedVersion == %q, got %q", expect, meta.LaunchedVersion)
+	}
+	if expect := "Client (map_client.txt)"; meta.Type != expect {
+		t.Errorf("Expect meta.Type == %q, got %q", expect, meta.Type)
+	}
+	if expect := 17; meta.JavaVersion != expect {
+		t.Errorf("Expect meta.JavaVersion == %d, got %d", expect, meta.JavaVersion)
+	}
+
+	report, err = ParseCrashReport(strings.NewReader(vanillaCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	meta = report.Metadata()
+	if meta.Modded != ModdedNo {
+		t.Errorf("Expect meta.Modded == %q, got %q", ModdedNo, meta.Modded)
+	}
+	if expect := "HMCL"; meta.Launcher != expect {
+		t.Errorf("Expect meta.Launcher == %q, got %q", expect, meta.Launcher)
+	}
+}
+
+func TestDoErrorModdedScope(t *testing.T) {
+	desc := &ErrorDesc{
+		Error:   "java.lang.NullPointerException",
+		Message: "Cannot invoke \"net.minecraft.client.renderer.RenderType.m_110405_()\" because \"renderType\" is null",
+		Modded:  ModdedYes,
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+	for _, d := range []struct {
+		Report string
+		Expect bool
+	}{
+		{moddedCrashReport, true},
+		{vanillaCrashReport, false},
+	} {
+		report, err := ParseCrashReport(strings.NewReader(d.Report))
+		if err != nil {
+			t.Fatalf("ParseCrashReport: %v", err)
+		}
+		matched, err := analyzer.DoErrorWithMetadata(report.Error, report.Metadata())
+		if err != nil {
+			t.Fatalf("DoErrorWithMetadata: %v", err)
+		}
+		if _, ok := findMatch(matched, desc); ok != d.Expect {
+			t.Errorf("Expect modded only description matched == %v, got %v", d.Expect, ok)
+		}
+	}
+	// unknown metadata does not exclude anything
+	matched, _ := analyzer.DoError(&JavaError{Class: desc.Error, Message: desc.Message})
+	if _, ok := findMatch(matched, desc); !ok {
+		t.Errorf("Expect the description to match without metadata")
+	}
+}
+
+func TestCrashReportSections(t *testing.T) {
+	report, err := ParseCrashReport(strings.NewReader(moddedCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	if expect := "Rendering overlay"; report.Description != expect {
+		t.Errorf("Expect Description == %q, got %q", expect, report.Description)
+	}
+	if report.Error == nil || report.Error.Class != "java.lang.NullPointerException" || len(report.Error.Stacktrace) != 3 {
+		t.Fatalf("Expect the NullPointerException with 3 frames, got %#v", report.Error)
+	}
+	if expect := "Render thread"; report.HeadThread.Thread != expect {
+		t.Errorf("Expect HeadThread.Thread == %q, got %q", expect, report.HeadThread.Thread)
+	}
+	head := report.GetSection("Head")
+	if !strings.HasPrefix(head, "Thread: Render thread\nSuspected Mod: \n\tExample Mod (examplemod)") || !strings.HasSuffix(head, "{re:classloading}") {
+		t.Errorf("Expect the raw Head section, got %q", head)
+	}
+	if details := report.GetSection("system details"); !strings.HasPrefix(details, "Details:\n\tMinecraft Version: 1.20.1\n") {
+		t.Errorf("Expect the raw System Details section, got %q", details)
+	}
+}
+
+func TestAnalyzeCrashReport(t *testing.T) {
+	desc := &ErrorDesc{
+		Error:   "java.lang.NullPointerException",
+		Message: "Cannot invoke \"net.minecraft.client.renderer.RenderType.m_110405_()\" because \"renderType\" is null",
+		Modded:  ModdedYes,
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+	for _, d := range []struct {
+		Report string
+		Expect bool
+	}{
+		{moddedCrashReport, true},
+		{vanillaCrashReport, false},
+	} {
+		analysis, err := analyzer.AnalyzeCrashReport(context.Background(), strings.NewReader(d.Report))
+		if err != nil {
+			t.Fatalf("AnalyzeCrashReport: %v", err)
+		}
+		if analysis.CrashReport == nil {
+			t.Fatalf("Expect the crash report to be set")
+		}
+		if len(analysis.Results) != 1 || analysis.Primary != analysis.Results[0] {
+			t.Fatalf("Expect 1 primary result, got %d", len(analysis.Results))
+		}
+		if _, ok := findMatch(analysis.Primary.Matched, desc); ok != d.Expect {
+			t.Errorf("Expect modded only description matched == %v, got %v", d.Expect, ok)
+		}
+	}
+	analysis, _ := analyzer.AnalyzeCrashReport(context.Background(), strings.NewReader(moddedCrashReport))
+	if loc := analysis.Primary.Location; loc == nil || loc.Class != "com.example.mod.client.Renderer" || loc.Line != 42 {
+		t.Errorf("Expect the crash to be located in the mod, got %#v", loc)
+	}
+
+	if _, err := analyzer.AnalyzeCrashReport(context.Background(), strings.NewReader("[12:00:00] [main/INFO]: Not a crash report\n")); err != io.EOF {
+		t.Errorf("Expect err == %v, got %v", io.EOF, err)
+	}
+}
@@ -0,0 +1,75 @@
+package mcla
+
+import (
+	"strings"
+	"unicode"
+)
+
+// platformPackages are the packages of the game, the mod loaders and their libraries, they are not suspects of a crash
+var platformPackages = []string{
+	"net.minecraft.", "com.mojang.", "net.minecraftforge.", "net.neoforged.", "cpw.mods.", "net.fabricmc.", "org.quiltmc.",
+	"org.spongepowered.", "com.llamalad7.mixinextras.", "org.objectweb.asm.", "com.google.", "io.netty.", "org.apache.",
+	"org.slf4j.", "org.lwjgl.", "it.unimi.dsi.fastutil.", "oshi.", "MC-BOOTSTRAP",
+}
+
+func isPlatformFrame(s StackInfo) bool {
+	for _, prefix := range platformPackages {
+		if strings.HasPrefix(s.Class, prefix) {
+			return true
+		}
+	}
+	if s.Jar != "" {
+		lower := strings.ToLower(s.Jar)
+		for _, prefix := range platformJarPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// modOfJar guesses the mod id from the jar name, e.g. `DistantHorizons-2.0.1-a-1.18.2.jar` is `distanthorizons`
+func modOfJar(jar string) string {
+	name := strings.TrimSuffix(jar, ".jar")
+	for i := 1; i < len(name); i++ {
+		if c := name[i-1]; (c == '-' || c == '_' || c == '+') && unicode.IsDigit((rune)(name[i])) {
+			name = name[:i-1]
+			break
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// AttributeMod guesses which mod caused the error by the frames of the error, its causes and suppressed errors.
+// The frames of the JDK, the game and the mod loaders are skipped, the others are counted by the mod id of their jar hints,
+// or by their package roots if there is no jar hint.
+// The most frequent one is returned, and the confidence is its share of the counted frames.
+// An empty modid is returned if all frames are skipped
+func (a *Analyzer) AttributeMod(jerr *JavaError) (modid string, confidence float32) {
+	counts := make(map[string]int)
+	total := 0
+	best := 0
+	for _, je := range errorChain(jerr) {
+		for _, s := range je.Stacktrace {
+			if a.skipFrame(s) || isPlatformFrame(s) {
+				continue
+			}
+			var mod string
+			if s.Jar != "" {
+				mod = modOfJar(s.Jar)
+			} else {
+				mod = libraryRoot(s.Class)
+			}
+			total++
+			counts[mod]++
+			if n := counts[mod]; n > best { // the first one wins a tie, since the frames on the top are closer to the crash
+				modid, best = mod, n
+			}
+		}
+	}
+	if total == 0 {
+		return "", 0
+	}
+	return modid, (float32)(best) / (float32)(total)
+}
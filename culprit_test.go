@@ -0,0 +1,59 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"strings"
+)
+
+func TestAttributeMod(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	datas := []struct {
+		Log        string
+		Mod        string
+		Confidence float32
+	}{
+		{
+			`java.lang.NullPointerException: Cannot invoke "net.minecraft.world.level.Level.getBlockState()" because "level" is null
+	at com.example.coolmod.block.CoolBlock.tick(CoolBlock.java:42) ~[coolmod-1.2.0.jar%23190!/:1.2.0]
+	at com.example.coolmod.block.CoolBlock.update(CoolBlock.java:30) ~[coolmod-1.2.0.jar%23190!/:1.2.0]
+	at net.minecraft.server.level.ServerLevel.tick(ServerLevel.java:100) ~[client-1.20.1-20230612.114412-srg.jar%23300!/:?]
+	at othermod.Hooks.onTick(Hooks.java:5) ~[OtherMod-0.1.jar%23191!/:0.1]
+	at net.minecraftforge.eventbus.EventBus.post(EventBus.java:10) ~[eventbus-6.0.5.jar%2385!/:?]
+`,
+			"coolmod", 2.0 / 3,
+		},
+		{
+			`java.lang.IllegalStateException: Not a valid state
+	at me.steve.fancy.render.FancyRenderer.render(FancyRenderer.java:12)
+	at net.minecraft.client.renderer.GameRenderer.render(GameRenderer.java:900)
+Caused by: java.lang.ArithmeticException: / by zero
+	at me.steve.fancy.render.Util.div(Util.java:3)
+	at java.lang.Thread.run(Thread.java:833)
+`,
+			"me.steve.fancy", 1,
+		},
+		{
+			`java.lang.OutOfMemoryError: Java heap space
+	at java.util.Arrays.copyOf(Arrays.java:3512)
+	at net.minecraft.client.Minecraft.run(Minecraft.java:700)
+	at net.fabricmc.loader.impl.game.minecraft.MinecraftGameProvider.launch(MinecraftGameProvider.java:470)
+`,
+			"", 0,
+		},
+	}
+	for i, d := range datas {
+		errs, err := ScanJavaErrors(strings.NewReader(d.Log))
+		if err != nil || len(errs) != 1 {
+			t.Fatalf("Expect log %d has 1 error, got %d (%v)", i, len(errs), err)
+		}
+		mod, confidence := analyzer.AttributeMod(errs[0])
+		if mod != d.Mod {
+			t.Errorf("Expect log %d mod == %q, got %q", i, d.Mod, mod)
+		}
+		if confidence != d.Confidence {
+			t.Errorf("Expect log %d confidence == %v, got %v", i, d.Confidence, confidence)
+		}
+	}
+}
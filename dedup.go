@@ -0,0 +1,113 @@
+package mcla
+
+import (
+	"strings"
+	"sync"
+)
+
+// errorSignature identifies the repeated errors by the class, the masked message and the top frame,
+// so the errors only differ in the numbers, ids or paths of the message are same
+func errorSignature(jerr *JavaError) string {
+	var sb strings.Builder
+	sb.WriteString(jerr.Class)
+	sb.WriteByte('\n')
+	sb.WriteString(maskMessage(jerr.Message))
+	if len(jerr.Stacktrace) > 0 {
+		top := jerr.Stacktrace[0]
+		sb.WriteByte('\n')
+		sb.WriteString(top.Class)
+		sb.WriteByte('.')
+		sb.WriteString(top.Method)
+	}
+	return sb.String()
+}
+
+// chainSignature is the signatures of the error, its suppressed errors and its causes
+func chainSignature(jerr *JavaError) string {
+	chain := errorChain(jerr)
+	sigs := make([]string, len(chain))
+	for i, je := range chain {
+		sigs[i] = errorSignature(je)
+	}
+	return strings.Join(sigs, "\x00")
+}
+
+// dedupEntry is the results of a distinct error chain, members are the indexes of their errors in the chain.
+// The results are already sent, so the repeats are counted here instead of in them
+type dedupEntry struct {
+	results   []*ErrorResult
+	members   []int
+	count     int
+	lastLines []int
+}
+
+// errorDeduper merges the repeated error chains of a stream, see Analyzer.DedupErrors
+type errorDeduper struct {
+	mux     sync.Mutex
+	seen    map[string]*dedupEntry
+	entries []*dedupEntry // in the order of the first sighting
+}
+
+func newErrorDeduper() *errorDeduper {
+	return &errorDeduper{
+		seen: make(map[string]*dedupEntry),
+	}
+}
+
+// repeat counts the error chain if it's seen before, so it does not need to be analyzed again
+func (d *errorDeduper) repeat(jerr *JavaError) bool {
+	sig := chainSignature(jerr)
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	entry, ok := d.seen[sig]
+	if !ok {
+		return false
+	}
+	chain := errorChain(jerr)
+	entry.count++
+	for i, m := range entry.members {
+		if m < len(chain) {
+			entry.lastLines[i] = chain[m].LineNo
+		}
+	}
+	return true
+}
+
+// add records the results of an error chain which is not seen before, it must be called before they are sent
+func (d *errorDeduper) add(jerr *JavaError, results []*ErrorResult) {
+	chain := errorChain(jerr)
+	entry := &dedupEntry{
+		results:   results,
+		members:   make([]int, len(results)),
+		count:     1,
+		lastLines: make([]int, len(results)),
+	}
+	for i, res := range results {
+		res.Count = 1
+		res.LastLine = res.Error.LineNo
+		entry.lastLines[i] = res.LastLine
+		for j, je := range chain {
+			if je == res.Error {
+				entry.members[i] = j
+				break
+			}
+		}
+	}
+	sig := chainSignature(jerr)
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.seen[sig] = entry
+	d.entries = append(d.entries, entry)
+}
+
+// update sets the counts of the sent results to the repeats seen so far, it's called by the consumer of the stream
+func (d *errorDeduper) update() {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	for _, entry := range d.entries {
+		for i, res := range entry.results {
+			res.Count = entry.count
+			res.LastLine = entry.lastLines[i]
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package mcla_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+const tickSpamLog = `[12:00:00] [Server thread/ERROR]: Exception ticking entity 12
+java.lang.NullPointerException: Cannot read field "level" of entity 12
+	at com.example.mod.TickHandler.onTick(TickHandler.java:42)
+[12:00:01] [Server thread/INFO]: Saving chunks
+[12:00:02] [Server thread/ERROR]: Exception ticking entity 345
+java.lang.NullPointerException: Cannot read field "level" of entity 345
+	at com.example.mod.TickHandler.onTick(TickHandler.java:42)
+[12:00:03] [Server thread/ERROR]: Failed to save
+java.io.IOException: Disk full
+	at com.example.mod.Storage.save(Storage.java:10)
+[12:00:04] [Server thread/ERROR]: Exception ticking entity 6789
+java.lang.NullPointerException: Cannot read field "level" of entity 6789
+	at com.example.mod.TickHandler.onTick(TickHandler.java:42)
+`
+
+func TestDedupErrors(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	results, _ := analyzer.DoLogStream(context.Background(), strings.NewReader(tickSpamLog))
+	count := 0
+	for res := range results {
+		count++
+		if res.Count != 0 || res.LastLine != 0 {
+			t.Errorf("Expect no counts without DedupErrors, got %d and %d", res.Count, res.LastLine)
+		}
+	}
+	if count != 4 {
+		t.Errorf("Expect 4 results without DedupErrors, got %d", count)
+	}
+
+	analyzer.DedupErrors = true
+	stream := analyzer.StartLogStream(context.Background(), strings.NewReader(tickSpamLog))
+	var got []*ErrorResult
+	for res := range stream.Results {
+		if res.Count != 1 || res.LastLine != res.StartLine {
+			t.Errorf("Expect a sent result only counts itself, got %d times to line %d", res.Count, res.LastLine)
+		}
+		got = append(got, res)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expect 2 distinct results, got %d", len(got))
+	}
+	stream.UpdateRepeatCounts()
+	for i, d := range []struct {
+		Class     string
+		Count     int
+		StartLine int
+		LastLine  int
+	}{
+		{"java.lang.NullPointerException", 3, 2, 12},
+		{"java.io.IOException", 1, 9, 9},
+	} {
+		res := got[i]
+		if res.Error.Class != d.Class {
+			t.Errorf("Expect result %d == %s, got %s", i, d.Class, res.Error.Class)
+		}
+		if res.Count != d.Count || res.StartLine != d.StartLine || res.LastLine != d.LastLine {
+			t.Errorf("Expect %s seen %d times from line %d to %d, got %d times from line %d to %d",
+				d.Class, d.Count, d.StartLine, d.LastLine, res.Count, res.StartLine, res.LastLine)
+		}
+	}
+}
+
+func TestDedupErrorsStreaming(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{
+		{Error: "java.lang.NullPointerException", Message: `Cannot read field "level" of entity 12`},
+	}})
+	analyzer.DedupErrors = true
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	results, _ := analyzer.DoLogStream(context.Background(), pr)
+	// the log is still open after the repeat of the first error, which should be sent already
+	go io.WriteString(pw, strings.Join(strings.SplitAfter(tickSpamLog, "\n")[:6], ""))
+	select {
+	case res := <-results:
+		if res == nil || res.Error.Class != "java.lang.NullPointerException" {
+			t.Fatalf("Expect the first NullPointerException, got %#v", res)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expect the first occurrence is sent before the log ends")
+	}
+
+	analyzer.StopOnConfidence = 0.9
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(tickSpamLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if !analysis.StoppedEarly || len(analysis.Results) != 1 {
+		t.Fatalf("Expect the stream stops at the first confident result, got %d results, StoppedEarly=%v", len(analysis.Results), analysis.StoppedEarly)
+	}
+	if res := analysis.Results[0]; res.Count != 1 || res.StartLine != 2 {
+		t.Errorf("Expect the first occurrence counted once, got %d times from line %d", res.Count, res.StartLine)
+	}
+}
@@ -0,0 +1,101 @@
+package mcla
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DescIDFunc returns the stable id of an error description
+type DescIDFunc func(e *ErrorDesc) string
+
+// DefaultDescID uses the explicit ID of the description, then the file name of its Source without the extension,
+// then a hash of its content
+func DefaultDescID(e *ErrorDesc) string {
+	if e.ID != "" {
+		return e.ID
+	}
+	if e.Source != "" {
+		name := path.Base(e.Source)
+		return strings.TrimSuffix(name, path.Ext(name))
+	}
+	buf, _ := json.Marshal(e)
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:6])
+}
+
+type DuplicateDescIDErr struct {
+	ID     string
+	First  *ErrorDesc
+	Second *ErrorDesc
+}
+
+func (e *DuplicateDescIDErr) Error() string {
+	return fmt.Sprintf("Duplicate error description id %q (%s and %s)", e.ID, e.First.Source, e.Second.Source)
+}
+
+// assignDescIDs fills the ID of the descriptions with the analyzer's DescID and checks they are unique.
+// The descriptions are owned by the database and may be shared by other analyzers,
+// so a description is copied instead of modified when its ID has to change
+func (a *Analyzer) assignDescIDs(errs []*ErrorDesc) error {
+	idOf := a.DescID
+	if idOf == nil {
+		idOf = DefaultDescID
+	}
+	seen := make(map[string]*ErrorDesc, len(errs))
+	for i, e := range errs {
+		if id := idOf(e); id != e.ID {
+			desc := *e
+			desc.ID = id
+			e = &desc
+			errs[i] = e
+		}
+		if other, ok := seen[e.ID]; ok {
+			return &DuplicateDescIDErr{ID: e.ID, First: other, Second: e}
+		}
+		seen[e.ID] = e
+	}
+	return nil
+}
+
+// ErrorsDelta is the difference of the description ids between two loads of the database
+type ErrorsDelta struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"` // the ids exist in both loads but their contents are different
+}
+
+func diffErrors(old, cur []*ErrorDesc) (delta *ErrorsDelta) {
+	delta = new(ErrorsDelta)
+	olds := make(map[string]*ErrorDesc, len(old))
+	for _, e := range old {
+		olds[e.ID] = e
+	}
+	for _, e := range cur {
+		o, ok := olds[e.ID]
+		if !ok {
+			delta.Added = append(delta.Added, e.ID)
+			continue
+		}
+		delete(olds, e.ID)
+		if o != e && !sameErrorDesc(o, e) {
+			delta.Changed = append(delta.Changed, e.ID)
+		}
+	}
+	for _, e := range old {
+		if _, ok := olds[e.ID]; ok {
+			delta.Removed = append(delta.Removed, e.ID)
+		}
+	}
+	return
+}
+
+func sameErrorDesc(a, b *ErrorDesc) bool {
+	x, err1 := json.Marshal(a)
+	y, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(x, y)
+}
@@ -0,0 +1,140 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"errors"
+	"slices"
+	"testing/fstest"
+)
+
+func TestDescIDs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"errors/1.json": {Data: []byte(`{"error":"java.lang.NullPointerException","message":"","solutions":[]}`)},
+		"errors/2.json": {Data: []byte(`{"id":"oom","error":"java.lang.OutOfMemoryError","message":"Java heap space","solutions":[]}`)},
+		"errors/3.json": {Data: []byte(`{"error":"java.lang.OutOfMemoryError","message":"Metaspace","solutions":[]}`)},
+	}
+	analyzer := NewAnalyzer(NewFileDB(fsys))
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	matched, err := analyzer.DoError(&JavaError{
+		Class:   "java.lang.OutOfMemoryError",
+		Message: "Java heap space",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, m := range matched {
+		if m.ID == "" || m.ID != m.ErrorDesc.ID {
+			t.Errorf("Expect the result id to be the description id, got %q and %q", m.ID, m.ErrorDesc.ID)
+		}
+		if ids[m.ID] {
+			t.Errorf("Expect unique ids, got %q twice", m.ID)
+		}
+		ids[m.ID] = true
+	}
+	for _, id := range []string{"oom", "3"} {
+		if !ids[id] {
+			t.Errorf("Expect id %q in the results, got %v", id, ids)
+		}
+	}
+
+	analyzer = NewAnalyzer(&memErrorDB{
+		errors: []*ErrorDesc{
+			{Error: "java.lang.NullPointerException"},
+			{Error: "java.lang.IllegalStateException"},
+		},
+	})
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	matched, _ = analyzer.DoError(&JavaError{Class: "java.lang.NullPointerException"})
+	if len(matched) != 1 || matched[0].ID == "" {
+		t.Errorf("Expect a description without source to have a content based id, got %#v", matched)
+	}
+
+	fsys["errors/4.json"] = &fstest.MapFile{Data: []byte(`{"id":"oom","error":"java.lang.OutOfMemoryError","message":"GC overhead limit exceeded","solutions":[]}`)}
+	analyzer = NewAnalyzer(NewFileDB(fsys))
+	var dupErr *DuplicateDescIDErr
+	if err := analyzer.UpdateErrors(); !errors.As(err, &dupErr) || dupErr.ID != "oom" {
+		t.Errorf("Expect a duplicate id error for %q, got %v", "oom", err)
+	}
+}
+
+func TestDescIDsSharedDB(t *testing.T) {
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{Error: "java.lang.NullPointerException"},
+			{Error: "java.lang.IllegalStateException"},
+		},
+	}
+	prefixed := func(prefix string) DescIDFunc {
+		return func(e *ErrorDesc) string { return prefix + DefaultDescID(e) }
+	}
+	analyzers := []*Analyzer{NewAnalyzer(db), NewAnalyzer(db)}
+	analyzers[0].DescID = prefixed("a.")
+	analyzers[1].DescID = prefixed("b.")
+	done := make(chan error, len(analyzers))
+	for _, analyzer := range analyzers {
+		go func() {
+			done <- analyzer.UpdateErrors()
+		}()
+	}
+	for range analyzers {
+		if err := <-done; err != nil {
+			t.Fatalf("UpdateErrors: %v", err)
+		}
+	}
+	for i, prefix := range []string{"a.", "b."} {
+		matched, _ := analyzers[i].DoError(&JavaError{Class: "java.lang.NullPointerException"})
+		if len(matched) != 1 || matched[0].ID != prefix+DefaultDescID(db.errors[0]) {
+			t.Errorf("Expect the analyzer %d to use its own id, got %#v", i, matched)
+		}
+	}
+	for _, e := range db.errors {
+		if e.ID != "" {
+			t.Errorf("Expect the descriptions of the database not to be modified, got id %q", e.ID)
+		}
+	}
+}
+
+func TestErrorsRefreshHook(t *testing.T) {
+	fsys := fstest.MapFS{
+		"errors/npe.json": {Data: []byte(`{"error":"java.lang.NullPointerException","message":"","solutions":[]}`)},
+		"errors/oom.json": {Data: []byte(`{"error":"java.lang.OutOfMemoryError","message":"Java heap space","solutions":[]}`)},
+	}
+	var deltas []*ErrorsDelta
+	analyzer := NewAnalyzer(NewFileDB(fsys))
+	analyzer.OnErrorsRefresh = func(delta *ErrorsDelta) {
+		deltas = append(deltas, delta)
+	}
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	if len(deltas) != 0 {
+		t.Errorf("Expect no callback for the first load, got %#v", deltas)
+	}
+
+	fsys["errors/cme.json"] = &fstest.MapFile{Data: []byte(`{"error":"java.util.ConcurrentModificationException","message":"","solutions":[]}`)}
+	fsys["errors/oom.json"] = &fstest.MapFile{Data: []byte(`{"error":"java.lang.OutOfMemoryError","message":"Metaspace","solutions":[]}`)}
+	delete(fsys, "errors/npe.json")
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	if len(deltas) != 1 {
+		t.Fatalf("Expect the callback to be called once, got %d", len(deltas))
+	}
+	delta := deltas[0]
+	if !slices.Equal(delta.Added, []string{"cme"}) {
+		t.Errorf("Expect added == %v, got %v", []string{"cme"}, delta.Added)
+	}
+	if !slices.Equal(delta.Removed, []string{"npe"}) {
+		t.Errorf("Expect removed == %v, got %v", []string{"npe"}, delta.Removed)
+	}
+	if !slices.Equal(delta.Changed, []string{"oom"}) {
+		t.Errorf("Expect changed == %v, got %v", []string{"oom"}, delta.Changed)
+	}
+}
@@ -0,0 +1,106 @@
+package mcla
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Encoding string
+
+const (
+	EncodingPlain  Encoding = ""
+	EncodingBase64 Encoding = "base64"
+	EncodingURL    Encoding = "url" // percent-encoding, e.g. from a query string or a form
+)
+
+// EmbeddedLog describes where a log is inside a wrapper payload from a bug report tool
+type EmbeddedLog struct {
+	// Path is the dot separated keys to the log field in the JSON payload, e.g. "report.attachments.0.data".
+	// An empty path means the whole payload is the encoded log
+	Path     string
+	Encoding Encoding
+}
+
+// ExtractEmbeddedLog extracts the log from the payload and decodes it
+func ExtractEmbeddedLog(r io.Reader, opts EmbeddedLog) (io.Reader, error) {
+	if opts.Path == "" {
+		return decodeEmbeddedLog(r, opts.Encoding)
+	}
+	var payload any
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+	value := payload
+	for _, key := range strings.Split(opts.Path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = v[key]; !ok {
+				return nil, fmt.Errorf("Embedded log path %q: key %q not found", opts.Path, key)
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("Embedded log path %q: invalid index %q", opts.Path, key)
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("Embedded log path %q: cannot get %q from %T", opts.Path, key, value)
+		}
+	}
+	data, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("Embedded log path %q: expect a string, got %T", opts.Path, value)
+	}
+	return decodeEmbeddedLog(strings.NewReader(data), opts.Encoding)
+}
+
+func decodeEmbeddedLog(r io.Reader, encoding Encoding) (io.Reader, error) {
+	switch encoding {
+	case EncodingPlain:
+		return r, nil
+	case EncodingBase64:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimSpace(data)
+		// the padding and the alphabet differ between the tools, so try all of them
+		for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+			buf := make([]byte, enc.DecodedLen(len(data)))
+			if n, err := enc.Decode(buf, data); err == nil {
+				return bytes.NewReader(buf[:n]), nil
+			}
+		}
+		return nil, fmt.Errorf("Embedded log is not valid base64")
+	case EncodingURL:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		text, err := url.QueryUnescape((string)(bytes.TrimSpace(data)))
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(text), nil
+	}
+	return nil, fmt.Errorf("Unknown embedded log encoding %q", encoding)
+}
+
+// AnalyzeEmbeddedLog extracts the log from the payload and analyzes it with AnalyzeLog
+func (a *Analyzer) AnalyzeEmbeddedLog(ctx context.Context, r io.Reader, opts EmbeddedLog) (*LogAnalysis, error) {
+	log, err := ExtractEmbeddedLog(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return a.AnalyzeLog(ctx, log)
+}
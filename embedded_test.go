@@ -0,0 +1,61 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+)
+
+func TestAnalyzeEmbeddedLog(t *testing.T) {
+	payload, _ := json.Marshal(map[string]any{
+		"app":     "example-reporter",
+		"version": 3,
+		"report": map[string]any{
+			"attachments": []any{
+				map[string]any{"name": "crash.txt", "data": base64.StdEncoding.EncodeToString(([]byte)(moddedCrashReport))},
+			},
+		},
+	})
+	opts := EmbeddedLog{
+		Path:     "report.attachments.0.data",
+		Encoding: EncodingBase64,
+	}
+
+	r, err := ExtractEmbeddedLog(strings.NewReader((string)(payload)), opts)
+	if err != nil {
+		t.Fatalf("ExtractEmbeddedLog: %v", err)
+	}
+	log, _ := io.ReadAll(r)
+	if (string)(log) != moddedCrashReport {
+		t.Errorf("Expect the extracted log is same as the original crash report")
+	}
+
+	analysis, err := NewAnalyzer(&memErrorDB{}).AnalyzeEmbeddedLog(context.Background(), strings.NewReader((string)(payload)), opts)
+	if err != nil {
+		t.Fatalf("AnalyzeEmbeddedLog: %v", err)
+	}
+	if len(analysis.Results) == 0 {
+		t.Fatalf("Expect results from the embedded crash report, got none")
+	}
+	if expect := "java.lang.NullPointerException"; analysis.Results[0].Error.Class != expect {
+		t.Errorf("Expect the error %q, got %q", expect, analysis.Results[0].Error.Class)
+	}
+
+	if _, err := ExtractEmbeddedLog(strings.NewReader((string)(payload)), EmbeddedLog{Path: "report.missing", Encoding: EncodingBase64}); err == nil {
+		t.Errorf("Expect an error for a missing path")
+	}
+
+	r, err = ExtractEmbeddedLog(strings.NewReader(url.QueryEscape(vanillaCrashReport)), EmbeddedLog{Encoding: EncodingURL})
+	if err != nil {
+		t.Fatalf("ExtractEmbeddedLog: %v", err)
+	}
+	if log, _ = io.ReadAll(r); (string)(log) != vanillaCrashReport {
+		t.Errorf("Expect the url encoded log to be decoded")
+	}
+}
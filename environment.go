@@ -0,0 +1,195 @@
+package mcla
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Environment is the game environment described by the `-- System Details --` section of a crash report
+type Environment struct {
+	MinecraftVersion string    `json:"minecraftVersion,omitempty"`
+	Loader           string    `json:"loader,omitempty"`        // "forge", "neoforge", "fabric" or "quilt", empty if unknown
+	LoaderVersion    string    `json:"loaderVersion,omitempty"` // e.g. "47.2.0"
+	JavaVersion      string    `json:"javaVersion,omitempty"`   // e.g. "17.0.8, Eclipse Adoptium"
+	Mods             []ModInfo `json:"mods,omitempty"`
+}
+
+// ModInfo is a loaded mod listed in the crash report
+type ModInfo struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	File    string `json:"file,omitempty"`
+}
+
+// Mod returns the loaded mod with the id, the id is case insensitive
+func (env *Environment) Mod(id string) *ModInfo {
+	for i, m := range env.Mods {
+		if strings.EqualFold(m.ID, id) {
+			return &env.Mods[i]
+		}
+	}
+	return nil
+}
+
+var (
+	// e.g. `net.minecraftforge:47.2.0`, `net.neoforged:20.4.237`
+	loaderCoordRe = regexp.MustCompile(`^[\w.-]+:([\w.+-]+)$`)
+	// e.g. `MCP 9.42 Powered by Forge 14.23.5.2859 58 mods loaded` or `MCP v9.05 FML v7.10.99.99 Minecraft Forge 10.13.4.1614`
+	legacyForgeRe = regexp.MustCompile(`\bForge\s+(\d[\w.-]*)`)
+	// e.g. `UCHIJAAAA	mcp{9.05} [Minecraft Coder Pack] (minecraft.jar)`
+	legacyModRe = regexp.MustCompile(`^[A-Z]+\s+([^{\s]+)\{([^}]*)\}\s*\[([^\]]*)\]\s*\(([^)]*)\)`)
+	// e.g. `Client brand changed to 'forge'`
+	clientBrandRe = regexp.MustCompile(`brand changed to '([^']+)'`)
+)
+
+// parseEnvironment parses the raw text of the `-- System Details --` section.
+// The modern Forge `Mod List`, the Fabric `Fabric Mods`, the markdown tables of legacy Forge and Quilt,
+// and the mod states list of Forge before 1.8 are supported
+func parseEnvironment(details string) (env *Environment) {
+	env = new(Environment)
+	var (
+		list   string   // the key of the mod list the lines are in
+		header []string // the header of the markdown table
+		brand  string
+	)
+	for _, line := range strings.Split(details, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "|") {
+			cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+			for i, c := range cells {
+				cells[i] = strings.TrimSpace(c)
+			}
+			switch {
+			case header == nil:
+				header = cells
+			case strings.HasPrefix(cells[0], ":") || strings.HasPrefix(cells[0], "-"): // the separator row
+			default:
+				if m := modFromTableRow(header, cells); m.ID != "" {
+					env.Mods = append(env.Mods, m)
+				}
+			}
+			continue
+		}
+		header = nil
+		if m := legacyModRe.FindStringSubmatch(trimmed); m != nil {
+			env.Mods = append(env.Mods, ModInfo{ID: m[1], Version: m[2], Name: m[3], File: m[4]})
+			continue
+		}
+		if hasDbIndent(([]byte)(line)) {
+			if m, ok := modFromListLine(list, trimmed); ok {
+				env.Mods = append(env.Mods, m)
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		list = strings.ToUpper(strings.TrimSpace(key))
+		switch list {
+		case "MINECRAFT VERSION":
+			env.MinecraftVersion = value
+		case "JAVA VERSION":
+			env.JavaVersion = value
+		case "FORGE", "NEOFORGE":
+			env.Loader = strings.ToLower(list)
+			if m := loaderCoordRe.FindStringSubmatch(value); m != nil {
+				env.LoaderVersion = m[1]
+			} else {
+				env.LoaderVersion = value
+			}
+		case "FML":
+			if m := legacyForgeRe.FindStringSubmatch(value); m != nil && env.Loader == "" {
+				env.Loader, env.LoaderVersion = "forge", m[1]
+			}
+		case "FABRIC MODS":
+			if env.Loader == "" {
+				env.Loader = "fabric"
+			}
+		case "QUILT MODS":
+			env.Loader = "quilt"
+		case "IS MODDED":
+			if m := clientBrandRe.FindStringSubmatch(value); m != nil {
+				brand = strings.ToLower(m[1])
+			}
+		}
+	}
+	if env.Loader == "" {
+		switch brand {
+		case "forge", "neoforge", "fabric", "quilt":
+			env.Loader = brand
+		}
+	}
+	if env.LoaderVersion == "" {
+		switch env.Loader {
+		case "fabric":
+			if m := env.Mod("fabricloader"); m != nil {
+				env.LoaderVersion = m.Version
+			}
+		case "quilt":
+			if m := env.Mod("quilt_loader"); m != nil {
+				env.LoaderVersion = m.Version
+			}
+		}
+	}
+	return
+}
+
+// modFromListLine parses a line of the `Mod List` or the `Fabric Mods`, e.g.
+// `jei-1.20.1-forge-15.2.0.27.jar |Just Enough Items |jei |15.2.0.27 |DONE |Manifest: NOSIGNATURE`
+// or `fabric-api: Fabric API 0.92.0+1.20.1`
+func modFromListLine(list string, line string) (m ModInfo, ok bool) {
+	switch list {
+	case "MOD LIST":
+		cells := strings.Split(line, "|")
+		if len(cells) < 4 {
+			return
+		}
+		m = ModInfo{
+			File:    strings.TrimSpace(cells[0]),
+			Name:    strings.TrimSpace(cells[1]),
+			ID:      strings.TrimSpace(cells[2]),
+			Version: strings.TrimSpace(cells[3]),
+		}
+	case "FABRIC MODS", "QUILT MODS":
+		id, rest, found := strings.Cut(line, ":")
+		if !found {
+			return
+		}
+		m.ID = strings.TrimSpace(id)
+		rest = strings.TrimSpace(rest)
+		if i := strings.LastIndexByte(rest, ' '); i >= 0 {
+			m.Name, m.Version = rest[:i], rest[i+1:]
+		} else {
+			m.Version = rest
+		}
+	default:
+		return
+	}
+	return m, m.ID != ""
+}
+
+// modFromTableRow reads the mod from a row of the markdown table by its header
+func modFromTableRow(header []string, cells []string) (m ModInfo) {
+	for i, h := range header {
+		if i >= len(cells) {
+			break
+		}
+		switch strings.ToUpper(h) {
+		case "ID":
+			m.ID = cells[i]
+		case "MOD", "NAME":
+			m.Name = cells[i]
+		case "VERSION":
+			m.Version = cells[i]
+		case "SOURCE", "FILE", "FILE(S)":
+			m.File = cells[i]
+		}
+	}
+	return
+}
@@ -0,0 +1,142 @@
+package mcla_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+const forgeSystemDetails = `---- Minecraft Crash Report ----
+Description: Unexpected error
+
+java.lang.IllegalStateException: boom
+	at net.minecraft.client.Minecraft.run(Minecraft.java:1)
+
+
+-- System Details --
+Details:
+	Minecraft Version: 1.20.1
+	Java Version: 17.0.8, Eclipse Adoptium
+	Is Modded: Definitely; Client brand changed to 'forge'
+	Mod List: 
+		forge-1.20.1-47.2.0-universal.jar                 |Forge                         |forge                         |47.2.0              |DONE      |Manifest: NOSIGNATURE
+		jei-1.20.1-forge-15.2.0.27.jar                    |Just Enough Items             |jei                           |15.2.0.27           |DONE      |Manifest: NOSIGNATURE
+	Crash Report UUID: 2b0f3c1e-0000-0000-0000-000000000000
+	FML: 47.2
+	Forge: net.minecraftforge:47.2.0
+`
+
+const fabricSystemDetails = `---- Minecraft Crash Report ----
+Description: Unexpected error
+
+java.lang.IllegalStateException: boom
+	at net.minecraft.client.MinecraftClient.run(MinecraftClient.java:1)
+
+
+-- System Details --
+Details:
+	Minecraft Version: 1.20.1
+	Java Version: 17.0.8, Eclipse Adoptium
+	Fabric Mods: 
+		fabric-api: Fabric API 0.92.0+1.20.1
+			fabric-api-base: Fabric API Base 0.4.31+ef105b4977
+		fabricloader: Fabric Loader 0.15.3
+		sodium: Sodium 0.5.3+mc1.20.1
+	Is Modded: Definitely; Client brand changed to 'fabric'
+`
+
+const legacyForgeSystemDetails = `---- Minecraft Crash Report ----
+Description: Initializing game
+
+java.lang.IllegalStateException: boom
+	at net.minecraft.client.Minecraft.init(Minecraft.java:1)
+
+
+-- System Details --
+Details:
+	Minecraft Version: 1.12.2
+	Java Version: 1.8.0_381, Oracle Corporation
+	FML: MCP 9.42 Powered by Forge 14.23.5.2859 2 mods loaded, 2 mods active
+	States: 'U' = Unloaded 'L' = Loaded 'C' = Constructed
+
+	| State  | ID        | Version      | Source                        | Signature |
+	|:------ |:--------- |:------------ |:----------------------------- |:--------- |
+	| LCHIJ  | minecraft | 1.12.2       | minecraft.jar                 | None      |
+	| LCHIJ  | jei       | 4.16.1.301   | jei_1.12.2-4.16.1.301.jar     | None      |
+
+	Loaded coremods (and transformers): 
+`
+
+const oldForgeSystemDetails = `---- Minecraft Crash Report ----
+Description: Initializing game
+
+java.lang.IllegalStateException: boom
+	at net.minecraft.client.Minecraft.init(Minecraft.java:1)
+
+
+-- System Details --
+Details:
+	Minecraft Version: 1.7.10
+	Java Version: 1.8.0_381, Oracle Corporation
+	FML: MCP v9.05 FML v7.10.99.99 Minecraft Forge 10.13.4.1614 2 mods loaded, 2 mods active
+	States: 'U' = Unloaded 'L' = Loaded 'C' = Constructed
+	UCHIJAAAA	mcp{9.05} [Minecraft Coder Pack] (minecraft.jar) 
+	UCHIJAAAA	NotEnoughItems{1.0.5.120} [Not Enough Items] (NotEnoughItems-1.7.10-1.0.5.120-universal.jar) 
+	GL info: ' Vendor: 'NVIDIA Corporation'
+`
+
+func TestCrashReportEnvironment(t *testing.T) {
+	for _, d := range []struct {
+		Name          string
+		Report        string
+		Minecraft     string
+		Loader        string
+		LoaderVersion string
+		Mods          int
+		Mod           ModInfo
+	}{
+		{"forge", forgeSystemDetails, "1.20.1", "forge", "47.2.0", 2,
+			ModInfo{ID: "jei", Name: "Just Enough Items", Version: "15.2.0.27", File: "jei-1.20.1-forge-15.2.0.27.jar"}},
+		{"fabric", fabricSystemDetails, "1.20.1", "fabric", "0.15.3", 4,
+			ModInfo{ID: "sodium", Name: "Sodium", Version: "0.5.3+mc1.20.1"}},
+		{"legacy forge", legacyForgeSystemDetails, "1.12.2", "forge", "14.23.5.2859", 2,
+			ModInfo{ID: "jei", Version: "4.16.1.301", File: "jei_1.12.2-4.16.1.301.jar"}},
+		{"old forge", oldForgeSystemDetails, "1.7.10", "forge", "10.13.4.1614", 2,
+			ModInfo{ID: "NotEnoughItems", Name: "Not Enough Items", Version: "1.0.5.120", File: "NotEnoughItems-1.7.10-1.0.5.120-universal.jar"}},
+	} {
+		report, err := ParseCrashReport(strings.NewReader(d.Report))
+		if err != nil {
+			t.Fatalf("%s: ParseCrashReport: %v", d.Name, err)
+		}
+		env := report.Environment
+		if env == nil {
+			t.Fatalf("%s: Expect the environment to be parsed", d.Name)
+		}
+		if env.MinecraftVersion != d.Minecraft {
+			t.Errorf("%s: Expect MinecraftVersion == %q, got %q", d.Name, d.Minecraft, env.MinecraftVersion)
+		}
+		if env.Loader != d.Loader || env.LoaderVersion != d.LoaderVersion {
+			t.Errorf("%s: Expect loader %s %s, got %s %s", d.Name, d.Loader, d.LoaderVersion, env.Loader, env.LoaderVersion)
+		}
+		if env.JavaVersion == "" {
+			t.Errorf("%s: Expect JavaVersion to be set", d.Name)
+		}
+		if len(env.Mods) != d.Mods {
+			t.Errorf("%s: Expect %d mods, got %#v", d.Name, d.Mods, env.Mods)
+		}
+		if m := env.Mod(strings.ToUpper(d.Mod.ID)); m == nil || *m != d.Mod {
+			t.Errorf("%s: Expect mod %#v, got %#v", d.Name, d.Mod, m)
+		}
+	}
+}
+
+func TestCrashReportWithoutEnvironment(t *testing.T) {
+	report, err := ParseCrashReport(strings.NewReader("---- Minecraft Crash Report ----\nDescription: Unexpected error\n"))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	if report.Environment != nil {
+		t.Errorf("Expect no environment without the System Details section, got %#v", report.Environment)
+	}
+}
@@ -1,10 +1,59 @@
 package mcla
 
+import (
+	"context"
+)
+
 type ErrorDesc struct {
-	Error     string         `json:"error"`
-	Message   string         `json:"message"`
+	// ID is the stable id of the description, it's filled by the Analyzer with its DescID when loading the database
+	ID string `json:"id,omitempty"`
+	// Error is the class of the error. `*.Name` matches the class name in any package, and scores same as the exact class.
+	// `pkg.*` matches the classes in the package and its subpackages, and scores as the class name only,
+	// so an exact class ranks above it. A bare `*` ignores the error type, and the message provides 100% score weight
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	// Messages are the alternatives of Message, e.g. the wordings of the same error in the different Minecraft versions.
+	// The message of the error is scored against each of them, and the best one is taken.
+	// The database files can also give the message as an array, see UnmarshalJSON
+	Messages  []string       `json:"messages,omitempty"`
 	Solutions []int          `json:"solutions"`
 	Data      map[string]any `json:"data,omitempty"`
+
+	// MessageIsRegex makes Message a regular expression which must match the whole first line of the error message,
+	// a match scores 100% of the message weight. Its groups are captured into SolutionPossibility.Captures.
+	// An invalid expression is reported by Analyzer.OnInvalidDesc and the description is skipped
+	MessageIsRegex bool `json:"messageIsRegex,omitempty"`
+
+	// MustNotMatch is a regular expression tested against the error message and every stacktrace line.
+	// It is evaluated before the error type and message, and a hit forces the match to zero,
+	// which lets two descriptions of the same error exclude each other.
+	// An invalid expression is reported by Analyzer.OnInvalidDesc and the description is skipped
+	MustNotMatch string `json:"mustNotMatch,omitempty"`
+	// Description is an optional human readable guidance, it's mostly used by the hard coded checks
+	Description string `json:"description,omitempty"`
+	// Descriptions are the translations of Description by the locales, e.g. "zh-CN", see LocalizedDescription
+	Descriptions map[string]string `json:"descriptions,omitempty"`
+	// Source is where the description was loaded from, e.g. the path of the database file.
+	// It's filled by the ErrorDB
+	Source string `json:"source,omitempty"`
+	// Modded limits the description to modded or vanilla games, and Launchers limits it to the listed launchers.
+	// They are only checked when the analyzed log's metadata is known
+	Modded    ModdedState `json:"modded,omitempty"`
+	Launchers []string    `json:"launchers,omitempty"`
+	// Context is a regular expression tested against the log lines before the error, see JavaError.Context.
+	// When it's set, it provides 20% of the score, so it can tell apart the same error thrown in different situations.
+	// An invalid expression is reported by Analyzer.OnInvalidDesc and the description is skipped
+	Context string `json:"context,omitempty"`
+	// Signals are combined with the score of the error type and message by noisy-OR, see Signal.
+	// A signal with an invalid pattern is reported by Analyzer.OnInvalidDesc and the description is skipped
+	Signals []Signal `json:"signals,omitempty"`
+	// Category is the category of the matched errors, it's inferred from the error class when empty
+	Category Category `json:"category,omitempty"`
+	// Severity is how serious the matched errors are, e.g. a warning logged at the ERROR level.
+	// It's SeverityUnknown in the results when empty
+	Severity Severity `json:"severity,omitempty"`
+	// Links are the references to read more, the malformed ones are reported by Analyzer.OnInvalidDesc and skipped
+	Links []Link `json:"links,omitempty"`
 }
 
 type SolutionDesc struct {
@@ -17,3 +66,36 @@ type ErrorDB interface {
 	ForEachErrors(callback func(*ErrorDesc) error) (err error)
 	GetSolution(id int) (sol *SolutionDesc, err error)
 }
+
+// ContextErrorDB is an ErrorDB which can stop iterating when the context is done, e.g. a database behind the network.
+// The analyzer uses ForEachErrorsContext instead of ForEachErrors if the DB implements it
+type ContextErrorDB interface {
+	ErrorDB
+	ForEachErrorsContext(ctx context.Context, callback func(*ErrorDesc) error) (err error)
+}
+
+// forEachErrors iterates the descriptions in the database until the context is done.
+// If the database does not implement ContextErrorDB, the context is checked before each description
+func forEachErrors(ctx context.Context, db ErrorDB, callback func(*ErrorDesc) error) error {
+	if cdb, ok := db.(ContextErrorDB); ok {
+		return cdb.ForEachErrorsContext(ctx, callback)
+	}
+	if err := ctx.Err(); err != nil {
+		return context.Cause(ctx)
+	}
+	return db.ForEachErrors(func(e *ErrorDesc) error {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		return callback(e)
+	})
+}
+
+// ExportErrors collects all error descriptions in the database, in the order they are iterated
+func ExportErrors(db ErrorDB) (errs []*ErrorDesc, err error) {
+	err = db.ForEachErrors(func(e *ErrorDesc) error {
+		errs = append(errs, e)
+		return nil
+	})
+	return
+}
@@ -0,0 +1,91 @@
+package mcla
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+)
+
+// embeddedErrDB is a small set of the common errors, written and maintained in this repository in the layout of the github database.
+// It's not exported from the github database, so its ids are its own, except the solution ModConflictSolutionID,
+// which is the mod conflict solution referred by the hard coded checks
+//
+//go:embed errdb
+var embeddedErrDB embed.FS
+
+// NewEmbeddedErrDB returns the ErrorDB bundled in the binary, so the analyzer has the common errors
+// before the first download completes, or when the network database is unreachable.
+// Its ids are not the github database's, see NewFallbackDB for using it together with one
+func NewEmbeddedErrDB() ErrorDB {
+	fsys, err := fs.Sub(embeddedErrDB, "errdb")
+	if err != nil {
+		panic(err)
+	}
+	return NewFileDB(fsys)
+}
+
+// fallbackDB is the ErrorDB returned by NewFallbackDB
+type fallbackDB struct {
+	primary  ErrorDB
+	fallback ErrorDB
+}
+
+var _ ContextErrorDB = (*fallbackDB)(nil)
+
+// NewFallbackDB returns an ErrorDB which prefers the primary database, e.g. a ghdb.ErrDB,
+// and iterates the fallback database instead if the primary one fails or is empty before yielding any description.
+// An error after some descriptions are yielded, an error of the callback, or a done context is returned as is.
+//
+// The databases may number their solutions differently, so the descriptions of the fallback database are yielded
+// as copies whose solution n becomes 1<<20+n, and GetSolution maps it back like NewMultiErrorDB
+func NewFallbackDB(primary, fallback ErrorDB) ErrorDB {
+	return &fallbackDB{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (db *fallbackDB) ForEachErrors(callback func(*ErrorDesc) error) (err error) {
+	return db.ForEachErrorsContext(context.Background(), callback)
+}
+
+func (db *fallbackDB) ForEachErrorsContext(ctx context.Context, callback func(*ErrorDesc) error) (err error) {
+	var (
+		yielded bool
+		cbErr   error
+	)
+	err = forEachErrors(ctx, db.primary, func(e *ErrorDesc) error {
+		yielded = true
+		cbErr = callback(e)
+		return cbErr
+	})
+	if yielded || cbErr != nil || ctx.Err() != nil {
+		return
+	}
+	return forEachErrors(ctx, db.fallback, func(e *ErrorDesc) error {
+		desc := *e
+		if desc.Solutions != nil {
+			desc.Solutions = make([]int, len(e.Solutions))
+			for i, id := range e.Solutions {
+				desc.Solutions[i] = multiSolutionIDRange + id
+			}
+		}
+		return callback(&desc)
+	})
+}
+
+// GetSolution maps the remapped ids back to the solutions of the fallback database.
+// The other ids, e.g. the ids used by the hard coded checks, are tried on the primary database first,
+// and the error of the primary database is returned if both fail
+func (db *fallbackDB) GetSolution(id int) (sol *SolutionDesc, err error) {
+	if id >= multiSolutionIDRange {
+		return db.fallback.GetSolution(id - multiSolutionIDRange)
+	}
+	if sol, err = db.primary.GetSolution(id); err == nil {
+		return
+	}
+	if fsol, ferr := db.fallback.GetSolution(id); ferr == nil {
+		return fsol, nil
+	}
+	return
+}
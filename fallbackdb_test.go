@@ -0,0 +1,111 @@
+package mcla_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+var errOffline = errors.New("offline")
+
+type offlineDB struct{}
+
+func (offlineDB) ForEachErrors(func(*ErrorDesc) error) error {
+	return errOffline
+}
+
+func (offlineDB) GetSolution(int) (*SolutionDesc, error) {
+	return nil, errOffline
+}
+
+func TestEmbeddedErrDB(t *testing.T) {
+	db := NewEmbeddedErrDB()
+	errs, err := ExportErrors(db)
+	if err != nil {
+		t.Fatalf("ExportErrors: %v", err)
+	}
+	if len(errs) == 0 {
+		t.Fatalf("Expect the embedded database is not empty")
+	}
+	for _, e := range errs {
+		for _, id := range e.Solutions {
+			if _, err := db.GetSolution(id); err != nil {
+				t.Errorf("Solution %d of %s: %v", id, e.Source, err)
+			}
+		}
+	}
+	if sol, err := db.GetSolution(ModConflictSolutionID); err != nil || sol == nil {
+		t.Errorf("Expect the mod conflict solution of the hard coded checks, got %v, %v", sol, err)
+	}
+
+	matched, err := NewAnalyzer(db).DoError(&JavaError{
+		Class:   "java.lang.OutOfMemoryError",
+		Message: "Java heap space",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) == 0 || matched[0].Match < 0.99 {
+		t.Errorf("Expect the embedded database matches the OutOfMemoryError, got %v", matched)
+	}
+}
+
+func TestFallbackDB(t *testing.T) {
+	primary := &memErrorDB{
+		errors:    []*ErrorDesc{{Error: "java.lang.IllegalStateException", Message: "primary"}},
+		solutions: []*SolutionDesc{{Description: "primary solution"}},
+	}
+	fallback := &memErrorDB{
+		errors:    []*ErrorDesc{{Error: "java.lang.IllegalStateException", Message: "fallback"}},
+		solutions: []*SolutionDesc{{Description: "fallback solution"}, {Description: "only in fallback"}},
+	}
+	for _, tc := range []struct {
+		name    string
+		primary ErrorDB
+		expect  string
+	}{
+		{"online", primary, "primary"},
+		{"empty", &memErrorDB{}, "fallback"},
+		{"offline", offlineDB{}, "fallback"},
+	} {
+		errs, err := ExportErrors(NewFallbackDB(tc.primary, fallback))
+		if err != nil {
+			t.Errorf("%s: ExportErrors: %v", tc.name, err)
+			continue
+		}
+		if len(errs) != 1 || errs[0].Message != tc.expect {
+			t.Errorf("%s: Expect only the %s description, got %v", tc.name, tc.expect, errs)
+		}
+	}
+
+	// the solutions of the fallback descriptions do not resolve to the primary database
+	db := NewFallbackDB(&memErrorDB{solutions: primary.solutions}, &memErrorDB{
+		errors:    []*ErrorDesc{{Error: "java.lang.IllegalStateException", Message: "fallback", Solutions: []int{1}}},
+		solutions: fallback.solutions,
+	})
+	errs, err := ExportErrors(db)
+	if err != nil {
+		t.Fatalf("ExportErrors: %v", err)
+	}
+	if len(errs) != 1 || len(errs[0].Solutions) != 1 {
+		t.Fatalf("Expect the fallback description with 1 solution, got %v", errs)
+	}
+	if sol, err := db.GetSolution(errs[0].Solutions[0]); err != nil || sol == nil || sol.Description != "fallback solution" {
+		t.Errorf("Expect the solution of the fallback database, got %v, %v", sol, err)
+	}
+	if sol, err := db.GetSolution(1); err != nil || sol == nil || sol.Description != "primary solution" {
+		t.Errorf("Expect the solution 1 of the primary database, got %v, %v", sol, err)
+	}
+
+	sol, err := NewFallbackDB(offlineDB{}, fallback).GetSolution(2)
+	if err != nil {
+		t.Fatalf("GetSolution: %v", err)
+	}
+	if expect := "only in fallback"; sol.Description != expect {
+		t.Errorf("Expect sol.Description == %q, got %q", expect, sol.Description)
+	}
+	if _, err := NewFallbackDB(offlineDB{}, offlineDB{}).GetSolution(1); !errors.Is(err, errOffline) {
+		t.Errorf("Expect the primary error, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package mcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// FileDB is an ErrorDB that loads the json files from a filesystem.
+// It uses the same layout as the github database: errors/<id>.json and solutions/<id>.json
+type FileDB struct {
+	FS fs.FS
+}
+
+var _ ErrorDB = (*FileDB)(nil)
+
+func NewFileDB(fsys fs.FS) *FileDB {
+	return &FileDB{
+		FS: fsys,
+	}
+}
+
+func (db *FileDB) ForEachErrors(callback func(*ErrorDesc) error) (err error) {
+	files, err := fs.Glob(db.FS, "errors/*.json")
+	if err != nil {
+		return
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		var buf []byte
+		if buf, err = fs.ReadFile(db.FS, name); err != nil {
+			return
+		}
+		desc := new(ErrorDesc)
+		if err = json.Unmarshal(buf, desc); err != nil {
+			return fmt.Errorf("Cannot parse %q: %w", name, err)
+		}
+		desc.Source = name
+		if err = callback(desc); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (db *FileDB) GetSolution(id int) (sol *SolutionDesc, err error) {
+	name := fmt.Sprintf("solutions/%d.json", id)
+	buf, err := fs.ReadFile(db.FS, name)
+	if err != nil {
+		return
+	}
+	sol = new(SolutionDesc)
+	if err = json.Unmarshal(buf, sol); err != nil {
+		return nil, fmt.Errorf("Cannot parse %q: %w", name, err)
+	}
+	return
+}
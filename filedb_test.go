@@ -0,0 +1,52 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"testing/fstest"
+)
+
+func TestFileDBSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"errors/1.json": &fstest.MapFile{
+			Data: ([]byte)(`{"error":"java.lang.OutOfMemoryError","message":"Java heap space","solutions":[1]}`),
+		},
+		"errors/2.json": &fstest.MapFile{
+			Data: ([]byte)(`{"error":"java.lang.ClassNotFoundException","message":"net.minecraft.client.Minecraft","solutions":[2]}`),
+		},
+		"solutions/1.json": &fstest.MapFile{
+			Data: ([]byte)(`{"tags":[],"description":"Increase the memory","link_to":""}`),
+		},
+	}
+	analyzer := NewAnalyzer(NewFileDB(fsys))
+	matched, err := analyzer.DoError(&JavaError{
+		Class:   "java.lang.ClassNotFoundException",
+		Message: "net.minecraft.client.Minecraft",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	var best *SolutionPossibility
+	for i, m := range matched {
+		if best == nil || m.Match > best.Match {
+			best = &matched[i]
+		}
+	}
+	if best == nil {
+		t.Fatalf("Expect a match, got none")
+	}
+	if expect := "errors/2.json"; best.Source != expect {
+		t.Errorf("Expect best.Source == %q, got %q", expect, best.Source)
+	}
+	if expect := "errors/2.json"; best.ErrorDesc.Source != expect {
+		t.Errorf("Expect ErrorDesc.Source == %q, got %q", expect, best.ErrorDesc.Source)
+	}
+	sol, err := analyzer.DB.GetSolution(1)
+	if err != nil {
+		t.Fatalf("GetSolution: %v", err)
+	}
+	if expect := "Increase the memory"; sol.Description != expect {
+		t.Errorf("Expect sol.Description == %q, got %q", expect, sol.Description)
+	}
+}
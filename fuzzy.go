@@ -0,0 +1,71 @@
+package mcla
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// matches `C:\Users\Steve\mods\foo.jar`, `/home/steve/mods/foo.jar` and `file:/home/steve/mods/foo.jar`
+	pathMaskRe = regexp.MustCompile(`(?i)(^|[\s"'(\[=])(?:file:)?(?:[a-z]:[\\/]|/)[^\s"'<>|)\]]*`)
+	// matches the hash codes and the ids, e.g. `@1b2c3d4e`, `0x00007ffd` and `5f3e2a1b`
+	hexMaskRe = regexp.MustCompile(`(?i)@[0-9a-f]+\b|\b0x[0-9a-f]+\b|\b[0-9a-f]{6,}\b`)
+	// matches the numbers and the versions, but not the digits in an identifier, e.g. `m_110405_`
+	numberMaskRe = regexp.MustCompile(`\b\d+(?:\.\d+)*\b`)
+)
+
+// maskedMatchWeight is multiplied to the similarity of the masked messages,
+// so a message which only matches after masking ranks below an exact match
+const maskedMatchWeight = 0.95
+
+// maskMessage replaces the volatile parts of a message, i.e. the paths, UUIDs, hex ids and numbers, with placeholders
+func maskMessage(text string) string {
+	text = uuidRe.ReplaceAllString(text, "<uuid>")
+	text = pathMaskRe.ReplaceAllString(text, "${1}<path>")
+	text = hexMaskRe.ReplaceAllStringFunc(text, func(s string) string {
+		if !strings.ContainsAny(s, "0123456789") || strings.Trim(s, "0123456789") == "" { // a word like `decade` or a number
+			return s
+		}
+		return "<hex>"
+	})
+	text = numberMaskRe.ReplaceAllString(text, "<n>")
+	return text
+}
+
+// fuzzyMatchPercent compares the messages by tokenizedMatchPercent, and by tokenMatchPercent after they are masked,
+// the better one is returned
+func fuzzyMatchPercent(text, match string, tokenizer Tokenizer) float32 {
+	score := tokenizedMatchPercent(text, match, tokenizer)
+	if score == 1 {
+		return score
+	}
+	return max(score, tokenMatchPercent(maskMessage(text), maskMessage(match))*maskedMatchWeight)
+}
+
+// tokenMatchPercent is one minus the edit distance of the words normalized by the word count.
+// Replacing a word costs how different the two words are, so `net.foo.Bar` is close to `net.foo.Baz`
+func tokenMatchPercent(text, match string) float32 {
+	a, b := strings.Fields(text), strings.Fields(match)
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(b) == 0 {
+		return 0
+	}
+	prev, cur := make([]float32, len(b)+1), make([]float32, len(b)+1)
+	for j := range prev {
+		prev[j] = (float32)(j)
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = (float32)(i)
+		for j := 1; j <= len(b); j++ {
+			replace := prev[j-1]
+			if a[i-1] != b[j-1] {
+				replace += 1 - lcsPercent(([]rune)(a[i-1]), ([]rune)(b[j-1]))
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, replace)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - prev[len(b)]/(float32)(len(a))
+}
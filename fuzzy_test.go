@@ -0,0 +1,58 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func TestFuzzyMessageMatch(t *testing.T) {
+	datas := []struct {
+		Message string
+		Desc    string
+	}{
+		{"Failed to allocate 134217728 bytes for chunk 12, 34", "Failed to allocate 1024 bytes for chunk 0, 0"},
+		{`Failed to read /home/steve/.minecraft/config/examplemod-client.toml`, `Failed to read C:\Users\alex\AppData\Roaming\.minecraft\config\examplemod-client.toml`},
+		{"Entity 1f3a9c2e-7b4d-4e21-9a8f-0c6d5e4b3a21 is already tracked by net.minecraft.world.level.Level@5e2de80c", "Entity 00000000-0000-0000-0000-000000000000 is already tracked by net.minecraft.world.level.Level@1b2c3d4e"},
+		{"Could not load class net.foo.Bar", "Could not load class net.foo.Baz"},
+	}
+	for i, d := range datas {
+		desc := &ErrorDesc{Error: "java.lang.IllegalStateException", Message: d.Desc}
+		exact := &ErrorDesc{Error: "java.lang.IllegalStateException", Message: d.Message}
+		jerr := &JavaError{Class: "java.lang.IllegalStateException", Message: d.Message}
+		analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc, exact}})
+
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		lineMatch, _ := findMatch(matched, desc)
+
+		analyzer.FuzzyMessageMatch = true
+		if matched, err = analyzer.DoError(jerr); err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		fuzzyMatch, _ := findMatch(matched, desc)
+		exactMatch, _ := findMatch(matched, exact)
+		if fuzzyMatch < 0.9 {
+			t.Errorf("Expect message %d to match fuzzily, got %v", i, fuzzyMatch)
+		}
+		if fuzzyMatch < lineMatch {
+			t.Errorf("Expect the fuzzy match of message %d >= the line match, got %v < %v", i, fuzzyMatch, lineMatch)
+		}
+		if exactMatch != 1 || fuzzyMatch >= exactMatch {
+			t.Errorf("Expect the exact match of message %d == 1 and ranks first, got %v and %v", i, exactMatch, fuzzyMatch)
+		}
+	}
+
+	// the masked parts do not make unrelated messages match
+	desc := &ErrorDesc{Error: "java.lang.IllegalStateException", Message: "Failed to allocate 1024 bytes for chunk 0, 0"}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+	analyzer.FuzzyMessageMatch = true
+	matched, err := analyzer.DoError(&JavaError{Class: "java.lang.IllegalStateException", Message: "Ticking entity 12 at 34, 56"})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if m, _ := findMatch(matched, desc); m > 0.6 {
+		t.Errorf("Expect an unrelated message not to match, got %v", m)
+	}
+}
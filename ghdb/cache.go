@@ -1,6 +1,7 @@
 package ghdb
 
 import (
+	"strings"
 	"sync"
 )
 
@@ -13,53 +14,134 @@ type Cache interface {
 	GetOrSet(key string, setter func() string) string
 }
 
-type memoryCache struct {
-	l sync.RWMutex
-	m map[string]string
+// Storage is the raw key value storage behind a cache, e.g. the memory, the localStorage or a directory.
+// It must be thread safe, and it does not need to coordinate the concurrent setters, see NewStorageCache
+type Storage interface {
+	Get(key string) (value string, ok bool)
+	Set(key string, value string)
+	Remove(key string)
+	// Keys returns all keys in the storage, including the keys which are not set by the cache
+	Keys() []string
+}
+
+// baseCache implements Cache on a Storage.
+// Only one setter runs for a key at the same time, the others wait for its result.
+// Different keys can be set concurrently
+type baseCache struct {
+	storage Storage
+	prefix  string
+
+	mux sync.Mutex
+	// working holds the keys which setters are running
+	working map[string]chan struct{}
+}
+
+var _ Cache = (*baseCache)(nil)
+
+// NewStorageCache creates a Cache which stores the values in the storage with the prefix prepended to the keys,
+// so Clear only removes the keys with the prefix
+func NewStorageCache(storage Storage, prefix string) Cache {
+	return &baseCache{
+		storage: storage,
+		prefix:  prefix,
+		working: make(map[string]chan struct{}),
+	}
 }
 
 func NewInMemoryCache() Cache {
-	return &memoryCache{
+	return NewStorageCache(&memoryStorage{
 		m: make(map[string]string),
+	}, "")
+}
+
+func (c *baseCache) Clear() {
+	for _, key := range c.storage.Keys() {
+		if strings.HasPrefix(key, c.prefix) {
+			c.storage.Remove(key)
+		}
 	}
 }
 
-func (m *memoryCache) Clear() {
-	m.l.Lock()
-	defer m.l.Unlock()
-	clear(m.m)
+// Get waits for the running setter of the key, so it does not miss a value which is being fetched
+func (c *baseCache) Get(key string) string {
+	c.mux.Lock()
+	ch := c.working[key]
+	c.mux.Unlock()
+	if ch != nil {
+		<-ch
+	}
+	v, _ := c.storage.Get(c.prefix + key)
+	return v
 }
 
-func (m *memoryCache) Get(key string) string {
+func (c *baseCache) Set(key string, value string) {
+	c.storage.Set(c.prefix+key, value)
+}
+
+func (c *baseCache) Remove(key string) {
+	c.storage.Remove(c.prefix + key)
+}
+
+func (c *baseCache) GetOrSet(key string, setter func() string) string {
+	for {
+		if v, ok := c.storage.Get(c.prefix + key); ok {
+			return v
+		}
+		c.mux.Lock()
+		if ch := c.working[key]; ch != nil {
+			c.mux.Unlock()
+			<-ch
+			continue
+		}
+		// the previous setter may have finished after the first check
+		if v, ok := c.storage.Get(c.prefix + key); ok {
+			c.mux.Unlock()
+			return v
+		}
+		done := make(chan struct{})
+		c.working[key] = done
+		c.mux.Unlock()
+
+		v := setter()
+		c.storage.Set(c.prefix+key, v)
+		c.mux.Lock()
+		delete(c.working, key)
+		c.mux.Unlock()
+		close(done)
+		return v
+	}
+}
+
+type memoryStorage struct {
+	l sync.RWMutex
+	m map[string]string
+}
+
+func (m *memoryStorage) Get(key string) (value string, ok bool) {
 	m.l.RLock()
 	defer m.l.RUnlock()
-	return m.m[key]
+	value, ok = m.m[key]
+	return
 }
 
-func (m *memoryCache) Set(key string, value string) {
+func (m *memoryStorage) Set(key string, value string) {
 	m.l.Lock()
 	defer m.l.Unlock()
 	m.m[key] = value
 }
 
-func (m *memoryCache) Remove(key string) {
+func (m *memoryStorage) Remove(key string) {
 	m.l.Lock()
 	defer m.l.Unlock()
 	delete(m.m, key)
 }
 
-func (m *memoryCache) GetOrSet(key string, setter func() string) string {
+func (m *memoryStorage) Keys() []string {
 	m.l.RLock()
-	v, ok := m.m[key]
-	m.l.RUnlock()
-	if !ok {
-		m.l.Lock()
-		defer m.l.Unlock()
-		v, ok = m.m[key]
-		if !ok {
-			v = setter()
-			m.m[key] = v
-		}
+	defer m.l.RUnlock()
+	keys := make([]string, 0, len(m.m))
+	for k := range m.m {
+		keys = append(keys, k)
 	}
-	return v
+	return keys
 }
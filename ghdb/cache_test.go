@@ -0,0 +1,247 @@
+package ghdb_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GlobeMC/mcla"
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+type mockStorage struct {
+	mux  sync.Mutex
+	m    map[string]string
+	sets int
+}
+
+var _ Storage = (*mockStorage)(nil)
+
+func newMockStorage() *mockStorage {
+	return &mockStorage{m: make(map[string]string)}
+}
+
+func (s *mockStorage) Get(key string) (value string, ok bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	value, ok = s.m[key]
+	return
+}
+
+func (s *mockStorage) Set(key string, value string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.m[key] = value
+	s.sets++
+}
+
+func (s *mockStorage) Remove(key string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.m, key)
+}
+
+func (s *mockStorage) Keys() (keys []string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for k := range s.m {
+		keys = append(keys, k)
+	}
+	return
+}
+
+func TestStorageCacheSingleFlight(t *testing.T) {
+	storage := newMockStorage()
+	cache := NewStorageCache(storage, "test.")
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	setter := func() string {
+		calls.Add(1)
+		<-release
+		return "value"
+	}
+	const n = 8
+	var wg sync.WaitGroup
+	results := make([]string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = cache.GetOrSet("key", setter)
+		}(i)
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	getDone := make(chan string)
+	go func() {
+		getDone <- cache.Get("key")
+	}()
+
+	// a different key must not wait for the running setter
+	otherDone := make(chan string)
+	go func() {
+		otherDone <- cache.GetOrSet("other", func() string { return "other value" })
+	}()
+	select {
+	case v := <-otherDone:
+		if v != "other value" {
+			t.Errorf("Expect %q, got %q", "other value", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expect a different key to be set while another setter is running")
+	}
+	select {
+	case v := <-getDone:
+		t.Fatalf("Expect Get to wait for the running setter, got %q", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	wg.Wait()
+	if v := <-getDone; v != "value" {
+		t.Errorf("Expect Get to return the value of the setter, got %q", v)
+	}
+	if c := calls.Load(); c != 1 {
+		t.Errorf("Expect the setter to be called once, got %d", c)
+	}
+	for i, v := range results {
+		if v != "value" {
+			t.Errorf("Expect result %d == %q, got %q", i, "value", v)
+		}
+	}
+	if storage.sets != 2 {
+		t.Errorf("Expect 2 writes to the storage, got %d", storage.sets)
+	}
+	if v, ok := storage.Get("test.key"); !ok || v != "value" {
+		t.Errorf("Expect the value stored with the prefix, got %q", v)
+	}
+	if v := cache.GetOrSet("key", func() string { t.Errorf("Expect the setter not to be called for a cached key"); return "" }); v != "value" {
+		t.Errorf("Expect the cached value %q, got %q", "value", v)
+	}
+}
+
+func TestStorageCacheClear(t *testing.T) {
+	storage := newMockStorage()
+	storage.Set("unrelated", "keep")
+	cache := NewStorageCache(storage, "test.")
+	cache.Set("a", "1")
+	cache.Set("b", "2")
+	cache.Remove("b")
+	if v := cache.Get("b"); v != "" {
+		t.Errorf("Expect a removed key to be empty, got %q", v)
+	}
+	cache.Clear()
+	keys := storage.Keys()
+	if !slices.Equal(keys, []string{"unrelated"}) {
+		t.Errorf("Expect only the keys with the prefix to be cleared, got %v", keys)
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mcla")
+	cache := NewFileCache(dir)
+	if v := cache.Get("errors/1.json"); v != "" {
+		t.Errorf("Expect a missing key to be empty, got %q", v)
+	}
+	cache.Set("errors/1.json", "one")
+	cache.Set("version", "1")
+	if v := cache.Get("errors/1.json"); v != "one" {
+		t.Errorf("Expect errors/1.json == %q, got %q", "one", v)
+	}
+
+	// the values are kept for the next run
+	cache = NewFileCache(dir)
+	if v := cache.Get("errors/1.json"); v != "one" {
+		t.Errorf("Expect the stored errors/1.json == %q, got %q", "one", v)
+	}
+
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v := cache.GetOrSet("errors/2.json", func() string {
+				calls.Add(1)
+				time.Sleep(20 * time.Millisecond)
+				return "two"
+			}); v != "two" {
+				t.Errorf("Expect errors/2.json == %q, got %q", "two", v)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expect the setter to be called once, got %d", n)
+	}
+
+	// another process sharing the directory
+	other := NewFileCache(dir)
+	for i := range 16 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cache.Set("shared", strings.Repeat("a", 4096))
+		}()
+		go func() {
+			defer wg.Done()
+			other.Set("shared", strings.Repeat("b", 4096))
+			if v := cache.Get("shared"); len(v) != 4096 {
+				t.Errorf("Expect a complete value at %d, got %d bytes", i, len(v))
+			}
+		}()
+	}
+	wg.Wait()
+
+	cache.Remove("version")
+	if v := other.Get("version"); v != "" {
+		t.Errorf("Expect a removed key to be empty, got %q", v)
+	}
+	cache.Clear()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expect no files after Clear, got %d", len(entries))
+	}
+}
+
+func TestErrDBFileCacheNextRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mcla")
+	count := func(transport *countingTransport) (n int) {
+		db := &ErrDB{
+			Transport: transport,
+			Cache:     NewFileCache(dir),
+		}
+		if err := db.ForEachErrors(func(*mcla.ErrorDesc) error {
+			n++
+			return nil
+		}); err != nil {
+			t.Fatalf("ForEachErrors: %v", err)
+		}
+		return
+	}
+	first := &countingTransport{Transport: newTestTransport()}
+	if n := count(first); n != 2 {
+		t.Errorf("Expect 2 descriptions, got %d", n)
+	}
+	if n := first.fileFetches(); n == 0 {
+		t.Errorf("Expect the first run to fetch the files")
+	}
+	// the next run with the same cache directory only checks the version
+	next := &countingTransport{Transport: newTestTransport()}
+	if n := count(next); n != 2 {
+		t.Errorf("Expect 2 descriptions, got %d", n)
+	}
+	if n := next.fileFetches(); n != 0 {
+		t.Errorf("Expect the next run not to fetch the cached files, got %d fetches", n)
+	}
+}
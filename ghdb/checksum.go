@@ -0,0 +1,109 @@
+package ghdb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+const (
+	indexSource = "version.json"
+	// indexChecksumSource is the optional checksum of the index, in the format of sha256sum, e.g. `<hex digest>  version.json`
+	indexChecksumSource = indexSource + ".sha256"
+
+	indexCacheKey         = "index"
+	indexChecksumCacheKey = "index.sha256"
+)
+
+// ChecksumErr is returned when a file does not match the SHA-256 digest published for it.
+// It's retryable, since the file may be partially downloaded
+type ChecksumErr struct {
+	Path   string
+	Expect string
+	Got    string
+}
+
+func (e *ChecksumErr) Error() string {
+	return fmt.Sprintf("checksum mismatch of %q: expect sha256 %s, got %s", e.Path, e.Expect, e.Got)
+}
+
+// indexData is the content of the index, Hashes are the SHA-256 digests of the files in hex keyed by their paths,
+// e.g. "errors/1.json". The files which are not listed are not verified
+type indexData struct {
+	versionData
+	Hashes map[string]string `json:"hashes,omitempty"`
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256(([]byte)(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyChecksum checks the file against the digest, an empty digest skips the check
+func verifyChecksum(path string, data string, expect string) error {
+	if expect == "" {
+		return nil
+	}
+	if got := sha256Hex(data); !strings.EqualFold(got, expect) {
+		return &ChecksumErr{Path: path, Expect: expect, Got: got}
+	}
+	return nil
+}
+
+// parseChecksumFile returns the digest of a sha256sum output, which is the first field of the file
+func parseChecksumFile(data string) string {
+	fields := strings.Fields(data)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// isNotFound reports whether the file does not exist in the transport
+func isNotFound(err error) bool {
+	var statusErr *HTTPStatusErr
+	return errors.Is(err, fs.ErrNotExist) || errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// fileHash returns the digest of the file listed in the latest index, or empty if it's not listed
+func (db *ErrDB) fileHash(source string) string {
+	if hashes := db.hashes.Load(); hashes != nil {
+		return (*hashes)[source]
+	}
+	return ""
+}
+
+func (db *ErrDB) setHashes(hashes map[string]string) {
+	db.hashes.Store(&hashes)
+}
+
+// loadCachedIndex reads the digests of the cached index, so the cached files can be verified before the index is fetched,
+// e.g. when the transport is offline. The index is dropped if it does not match its cached checksum or cannot be decoded
+func (db *ErrDB) loadCachedIndex() {
+	raw := db.Cache.Get(indexCacheKey)
+	if raw == "" {
+		return
+	}
+	var idx indexData
+	if verifyChecksum(indexSource, raw, db.Cache.Get(indexChecksumCacheKey)) != nil || json.Unmarshal(([]byte)(raw), &idx) != nil {
+		db.Cache.Remove(indexCacheKey)
+		db.Cache.Remove(indexChecksumCacheKey)
+		return
+	}
+	db.setHashes(idx.Hashes)
+}
+
+// storeIndex caches the index after its files are fetched, see loadCachedIndex
+func (db *ErrDB) storeIndex(raw string, sum string) {
+	db.Cache.Set(indexCacheKey, raw)
+	if sum == "" {
+		db.Cache.Remove(indexChecksumCacheKey)
+	} else {
+		db.Cache.Set(indexChecksumCacheKey, sum)
+	}
+}
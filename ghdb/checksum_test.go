@@ -0,0 +1,131 @@
+package ghdb_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/GlobeMC/mcla"
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256(([]byte)(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// newHashedTransport lists the digests of the database files in the index
+func newHashedTransport() memTransport {
+	t := newTestTransport()
+	t["version.json"] = `{"major":0,"minor":1,"patch":0,"errorIncId":2,"solutionIncId":1,"hashes":{` +
+		`"errors/1.json":"` + sha256Hex(t["errors/1.json"]) + `",` +
+		`"errors/2.json":"` + sha256Hex(t["errors/2.json"]) + `",` +
+		`"solutions/1.json":"` + sha256Hex(t["solutions/1.json"]) + `"}}`
+	return t
+}
+
+// corruptTransport truncates the file the first n times it's opened
+type corruptTransport struct {
+	memTransport
+	path string
+	n    int
+}
+
+func (t *corruptTransport) Open(path string) (io.ReadCloser, error) {
+	if path == t.path && t.n > 0 {
+		t.n--
+		data := t.memTransport[path]
+		return io.NopCloser(strings.NewReader(data[:len(data)/2])), nil
+	}
+	return t.memTransport.Open(path)
+}
+
+func TestErrDBChecksumCorruptCache(t *testing.T) {
+	db := &ErrDB{
+		Transport: newHashedTransport(),
+		Cache:     NewInMemoryCache(),
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	// e.g. the localStorage is truncated
+	db.Cache.Set("error.2", `{"error":"java.lang.OutOfMemoryError","message":"Java he`)
+	desc, err := db.GetErrorDesc(2)
+	if err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if expect := "Java heap space"; desc.Message != expect {
+		t.Errorf("Expect desc.Message == %q, got %q", expect, desc.Message)
+	}
+	if v := db.Cache.Get("error.2"); !strings.Contains(v, "Java heap space") {
+		t.Errorf("Expect the corrupted entry to be replaced, got %q", v)
+	}
+
+	// a new session without the network verifies the cached files with the cached index
+	offline := &ErrDB{
+		Transport: memTransport{},
+		Cache:     db.Cache,
+		Retry:     &RetryPolicy{},
+	}
+	offline.Cache.Set("solution.1", `{"tags":["test"],"description":"a test`)
+	offline.ForEachErrors(func(*mcla.ErrorDesc) error { return nil })
+	if _, err := offline.GetSolution(1); err == nil {
+		t.Errorf("Expect an error for the corrupted solution, got nil")
+	}
+	if v := offline.Cache.Get("solution.1"); v != "" {
+		t.Errorf("Expect the corrupted entry to be evicted, got %q", v)
+	}
+}
+
+func TestErrDBChecksumRefetch(t *testing.T) {
+	transport := &corruptTransport{memTransport: newHashedTransport(), path: "errors/2.json", n: 1}
+	db := &ErrDB{
+		Transport: transport,
+		Cache:     NewInMemoryCache(),
+		Retry:     &RetryPolicy{MaxAttempts: 2},
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if v := db.Cache.Get("error.2"); !strings.Contains(v, "Java heap space") {
+		t.Errorf("Expect the partial download to be fetched again, got %q", v)
+	}
+
+	transport.n = 2
+	db.BypassCache = true
+	_, err := db.GetErrorDesc(2)
+	var checksumErr *ChecksumErr
+	if !errors.As(err, &checksumErr) || checksumErr.Path != "errors/2.json" {
+		t.Fatalf("Expect a *ChecksumErr of errors/2.json, got %v", err)
+	}
+	if v := db.Cache.Get("error.2"); !strings.Contains(v, "Java heap space") {
+		t.Errorf("Expect the corrupted download not to be cached, got %q", v)
+	}
+}
+
+func TestErrDBIndexChecksum(t *testing.T) {
+	transport := newHashedTransport()
+	transport["version.json.sha256"] = sha256Hex(transport["version.json"]) + "  version.json\n"
+	db := &ErrDB{
+		Transport: transport,
+		Cache:     NewInMemoryCache(),
+		Retry:     &RetryPolicy{},
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+
+	transport["version.json.sha256"] = sha256Hex("another index")
+	db = &ErrDB{
+		Transport: transport,
+		Cache:     NewInMemoryCache(),
+		Retry:     &RetryPolicy{},
+	}
+	var checksumErr *ChecksumErr
+	if err := db.RefreshCache(); !errors.As(err, &checksumErr) || checksumErr.Path != "version.json" {
+		t.Errorf("Expect a *ChecksumErr of version.json, got %v", err)
+	}
+}
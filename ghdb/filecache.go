@@ -0,0 +1,81 @@
+package ghdb
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileStorage is a Storage which stores each key in a file of the directory.
+// The keys are escaped as the file names, so the keys like `errors/1.json` do not create subdirectories
+type fileStorage struct {
+	dir string
+}
+
+var _ Storage = (*fileStorage)(nil)
+
+// NewFileCache creates a Cache which stores the values as files in the directory, e.g. for ErrDB in a native program,
+// so the database is not downloaded again by the next run. The directory is created when the first value is set.
+// GetOrSet runs one setter for a key in the process like the other caches, the processes which share the directory
+// may fetch the same key at the same time, but a reader never sees a partial value
+func NewFileCache(dir string) Cache {
+	return NewStorageCache(&fileStorage{dir: dir}, "")
+}
+
+func (s *fileStorage) path(key string) string {
+	return filepath.Join(s.dir, url.PathEscape(key))
+}
+
+func (s *fileStorage) Get(key string) (value string, ok bool) {
+	buf, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return "", false
+	}
+	return string(buf), true
+}
+
+// Set writes the value to a temporary file and renames it, so a concurrent reader never sees a partial value.
+// The cache is best effort, the errors are ignored
+func (s *fileStorage) Set(key string, value string) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return
+	}
+	fd, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = fd.WriteString(value)
+	if err2 := fd.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(fd.Name(), s.path(key))
+	}
+	if err != nil {
+		os.Remove(fd.Name())
+	}
+}
+
+func (s *fileStorage) Remove(key string) {
+	os.Remove(s.path(key))
+}
+
+func (s *fileStorage) Keys() (keys []string) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	keys = make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		key, err := url.PathUnescape(e.Name())
+		if err != nil || strings.HasPrefix(key, ".tmp-") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return
+}
@@ -4,6 +4,7 @@ package ghdb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"path"
@@ -33,84 +34,172 @@ type versionData struct {
 }
 
 type ErrDB struct {
-	Fetch func(path string) (io.ReadCloser, error)
-	Cache Cache
+	Transport Transport
+	Cache     Cache
+	// BypassCache always fetches the files from Transport instead of reading Cache,
+	// but the fetched files are still written to Cache.
+	// If Transport is a ConditionalTransport, the cached files are revalidated instead
+	BypassCache bool
+	// Retry is the policy to fetch a file again after a transient failure, nil means DefaultRetryPolicy
+	Retry *RetryPolicy
+	// MaxConcurrentFetches is how many files can be fetched from Transport at the same time,
+	// so a burst of fetches is not rate limited by the server. After a fetch is rejected with 429 Too Many Requests,
+	// no new fetch is started for the base delay of Retry.
+	// Default is DefaultMaxConcurrentFetches, a negative value means no limit. It should not be changed after the first fetch
+	MaxConcurrentFetches int
 
 	checking      atomic.Bool
+	mux           sync.Mutex // guards the refreshes, cachedVersion and lastCheck
 	cachedVersion versionData
 	lastCheck     time.Time
+	hashes        atomic.Pointer[map[string]string] // the digests of the files listed in the latest index
+	limiter       fetchLimiter
 }
 
-var _ mcla.ErrorDB = (*ErrDB)(nil)
+var _ mcla.ContextErrorDB = (*ErrDB)(nil)
 
-func (db *ErrDB) fetch(subpaths ...string) (io.ReadCloser, error) {
-	return db.Fetch(path.Join(subpaths...))
+func (db *ErrDB) fetch(ctx context.Context, subpaths ...string) (io.ReadCloser, error) {
+	if t, ok := db.Transport.(ContextTransport); ok {
+		return t.OpenContext(ctx, path.Join(subpaths...))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, context.Cause(ctx)
+	}
+	return db.Transport.Open(path.Join(subpaths...))
 }
 
-func (db *ErrDB) fetchGhDBVersion() (v versionData, err error) {
-	var res io.ReadCloser
-	if res, err = db.fetch("version.json"); err != nil {
+func (db *ErrDB) retryPolicy() *RetryPolicy {
+	if db.Retry == nil {
+		return DefaultRetryPolicy
+	}
+	return db.Retry
+}
+
+// fetchIndex fetches the index and verifies it with the published checksum if there is one.
+// The digests of the files listed in the index are used to verify the files fetched after it
+func (db *ErrDB) fetchIndex(ctx context.Context) (v versionData, raw string, sum string, err error) {
+	sumFile, _, err := db.fetchFile(ctx, indexChecksumSource, "", "")
+	if err != nil && !isNotFound(err) {
 		return
 	}
-	defer res.Close()
-	if err = json.NewDecoder(res).Decode(&v); err != nil {
+	sum = parseChecksumFile(sumFile)
+	if raw, _, err = db.fetchFile(ctx, indexSource, "", sum); err != nil {
+		return
+	}
+	var idx indexData
+	if err = json.Unmarshal(([]byte)(raw), &idx); err != nil {
 		return
 	}
-	if v.Major != syntaxVersion {
+	if v = idx.versionData; v.Major != syntaxVersion {
 		err = &UnsupportSyntaxErr{v.Major}
 		return
 	}
+	db.setHashes(idx.Hashes)
 	return
 }
 
-func (db *ErrDB) checkUpdate() error {
+func (db *ErrDB) checkUpdate(ctx context.Context) error {
 	if !db.checking.CompareAndSwap(false, true) {
 		return nil
 	}
 	defer db.checking.Store(false)
 
+	db.mux.Lock()
+	defer db.mux.Unlock()
 	if !db.lastCheck.IsZero() && time.Since(db.lastCheck) <= time.Minute {
 		return nil
 	}
 
-	return db.RefreshCache()
+	return db.refreshCache(ctx)
 }
 
 func (db *ErrDB) RefreshCache() (err error) {
-	if db.cachedVersion == (versionData{}) {
-		version := db.Cache.Get("version")
+	db.mux.Lock()
+	defer db.mux.Unlock()
+	return db.refreshCache(context.Background())
+}
+
+// PurgeCache drops all cached files and fetches the database index and files again,
+// so the upstream changes are picked up immediately instead of after the next version check.
+// The refreshes are serialized, but the descriptions being read concurrently may still come from the old files.
+// Call mcla.Analyzer.InvalidateCache after it to reload the descriptions of the analyzer
+func (db *ErrDB) PurgeCache() (err error) {
+	return db.PurgeCacheContext(context.Background())
+}
+
+// PurgeCacheContext is same as PurgeCache, but stops fetching the files when the context is done
+func (db *ErrDB) PurgeCacheContext(ctx context.Context) (err error) {
+	db.mux.Lock()
+	defer db.mux.Unlock()
+	db.Cache.Clear()
+	db.cachedVersion = versionData{}
+	db.lastCheck = time.Time{}
+	return db.refreshCache(ctx)
+}
+
+// refreshCache checks the database version and fetches the changed files, the caller must hold mux
+func (db *ErrDB) refreshCache(ctx context.Context) (err error) {
+	if db.cachedVersion == (versionData{}) && !db.BypassCache {
+		version := db.Cache.Get(versionCacheKey)
 		json.Unmarshal(([]byte)(version), &db.cachedVersion)
+		if db.hashes.Load() == nil {
+			db.loadCachedIndex()
+		}
 	}
-	newVersion, err := db.fetchGhDBVersion()
+	tracker := loadTrackerOf(ctx)
+	newVersion, rawIndex, indexSum, err := db.fetchIndex(ctx)
 	if err != nil {
 		return
 	}
 	if newVersion.Major != db.cachedVersion.Major || newVersion.Minor != db.cachedVersion.Minor {
 		db.cachedVersion = newVersion
-		db.Cache.Clear()
+		// the cached files can be revalidated instead of being downloaded again
+		_, conditional := db.Transport.(ConditionalTransport)
+		if !conditional {
+			db.Cache.Clear()
+		}
+		tracker.setTotal(newVersion.ErrorIncId)
 		var wg sync.WaitGroup
 		wg.Add(newVersion.ErrorIncId)
 		for i := 1; i <= newVersion.ErrorIncId; i++ {
 			go func(i int) {
 				defer wg.Done()
-				db.GetErrorDesc(i) // refresh cache
+				var (
+					fetched bool
+					err     error
+				)
+				if conditional {
+					_, fetched, err = db.revalidateFile(ctx, errorCacheKey(i), errorSource(i))
+				} else {
+					_, fetched, err = db.loadErrorDesc(ctx, i) // refresh cache
+				}
+				if err == nil {
+					tracker.add(i, fetched)
+				}
 			}(i)
 		}
 		wg.Add(newVersion.SolutionIncId)
 		for i := 1; i <= newVersion.SolutionIncId; i++ {
 			go func(i int) {
 				defer wg.Done()
-				db.GetSolution(i) // refresh cache
+				if conditional {
+					db.revalidateFile(ctx, solutionCacheKey(i), solutionSource(i))
+				} else {
+					db.getSolution(ctx, i) // refresh cache
+				}
 			}(i)
 		}
 		wg.Wait()
 	} else if newVersion.Patch != db.cachedVersion.Patch {
+		tracker.setTotal(newVersion.ErrorIncId)
 		var wg sync.WaitGroup
 		wg.Add(newVersion.ErrorIncId - db.cachedVersion.ErrorIncId)
 		for i := db.cachedVersion.ErrorIncId + 1; i <= newVersion.ErrorIncId; i++ {
 			go func(i int) {
 				defer wg.Done()
-				db.GetErrorDesc(i) // refresh cache
+				if _, fetched, err := db.loadErrorDesc(ctx, i); err == nil { // refresh cache
+					tracker.add(i, fetched)
+				}
 			}(i)
 		}
 		wg.Wait()
@@ -119,33 +208,177 @@ func (db *ErrDB) RefreshCache() (err error) {
 		for i := db.cachedVersion.SolutionIncId + 1; i <= newVersion.SolutionIncId; i++ {
 			go func(i int) {
 				defer wg.Done()
-				db.GetSolution(i) // refresh cache
+				db.getSolution(ctx, i) // refresh cache
 			}(i)
 		}
 		wg.Wait()
 		db.cachedVersion.SolutionIncId = newVersion.SolutionIncId
 		db.cachedVersion.Patch = newVersion.Patch
 	}
+	db.recordVersion(newVersion, rawIndex, indexSum)
+	return
+}
+
+// versionCacheKey stores the version of the cached files, so a persistent Cache is not fetched again in the next run
+const versionCacheKey = "version"
 
+// recordVersion records the version and the index whose files are cached, and the time of the check.
+// The caller must hold mux
+func (db *ErrDB) recordVersion(version versionData, rawIndex string, indexSum string) {
+	db.cachedVersion = version
+	buf, _ := json.Marshal(version)
+	db.Cache.Set(versionCacheKey, (string)(buf))
+	db.storeIndex(rawIndex, indexSum)
 	db.lastCheck = time.Now()
+}
+
+// validatorKeySuffix is appended to the cache key to store the ETag or the Last-Modified time of the cached file
+const validatorKeySuffix = ".validator"
+
+// fetchFile fetches the file with the validator if the transport is a ConditionalTransport,
+// ErrNotModified is returned if the file is not changed.
+// The file is verified with the SHA-256 digest in hex unless it's empty, a *ChecksumErr is returned on mismatch.
+// The transient failures and the mismatches are retried with the retry policy
+func (db *ErrDB) fetchFile(ctx context.Context, source string, validator string, hash string) (buf string, newValidator string, err error) {
+	err = db.retryPolicy().do(ctx, func() (err error) {
+		buf, newValidator, err = db.fetchFile0(ctx, source, validator, hash)
+		return
+	})
 	return
 }
 
-func (db *ErrDB) GetErrorDesc(id int) (desc *mcla.ErrorDesc, err error) {
-	cacheKey := fmt.Sprintf("error.%d", id)
-	buf := db.Cache.GetOrSet(cacheKey, func() string {
-		var res io.ReadCloser
-		if res, err = db.fetch("errors", fmt.Sprintf("%d.json", id)); err != nil {
-			return ""
+func (db *ErrDB) fetchFile0(ctx context.Context, source string, validator string, hash string) (buf string, newValidator string, err error) {
+	release, err := db.acquireFetch(ctx)
+	if err != nil {
+		return
+	}
+	defer release()
+	var res io.ReadCloser
+	if t, ok := db.Transport.(ConditionalTransport); ok {
+		res, newValidator, err = t.OpenConditional(ctx, source, validator)
+	} else {
+		res, err = db.fetch(ctx, source)
+	}
+	if err != nil {
+		db.throttled(err)
+		return
+	}
+	defer res.Close()
+	data, err := io.ReadAll(res)
+	if err != nil {
+		return
+	}
+	if err = verifyChecksum(source, (string)(data), hash); err != nil {
+		return
+	}
+	return (string)(data), newValidator, nil
+}
+
+func (db *ErrDB) setValidator(cacheKey string, validator string) {
+	if validator == "" {
+		db.Cache.Remove(cacheKey + validatorKeySuffix)
+	} else {
+		db.Cache.Set(cacheKey+validatorKeySuffix, validator)
+	}
+}
+
+// revalidateFile fetches the file again, the cached copy is kept if the transport reports it's not modified.
+// The cache is not read if the transport is not a ConditionalTransport.
+// A cached copy which does not match the digest in the index is evicted and downloaded again.
+// fetched is false if the cached copy is kept
+func (db *ErrDB) revalidateFile(ctx context.Context, cacheKey string, source string) (buf string, fetched bool, err error) {
+	hash := db.fileHash(source)
+	var validator string
+	if _, ok := db.Transport.(ConditionalTransport); ok && db.Cache.Get(cacheKey) != "" {
+		validator = db.Cache.Get(cacheKey + validatorKeySuffix)
+	}
+	fetched = true
+	buf, validator, err = db.fetchFile(ctx, source, validator, hash)
+	if errors.Is(err, ErrNotModified) {
+		if buf = db.Cache.Get(cacheKey); verifyChecksum(source, buf, hash) == nil {
+			return buf, false, nil
 		}
-		var buf []byte
-		buf, err = io.ReadAll(res)
-		res.Close()
-		if err != nil {
-			return ""
+		db.evictFile(cacheKey)
+		buf, validator, err = db.fetchFile(ctx, source, "", hash)
+	}
+	if err != nil {
+		return
+	}
+	db.Cache.Set(cacheKey, buf)
+	db.setValidator(cacheKey, validator)
+	return
+}
+
+// getFile reads the file from the cache, or fetches and caches it, fetched reports whether the transport is used.
+// A cached copy which does not match the digest in the index is evicted and fetched again
+func (db *ErrDB) getFile(ctx context.Context, cacheKey string, source string) (buf string, fetched bool, err error) {
+	if db.BypassCache {
+		return db.revalidateFile(ctx, cacheKey, source)
+	}
+	hash := db.fileHash(source)
+	buf = db.Cache.GetOrSet(cacheKey, func() string {
+		var v, validator string
+		v, validator, err = db.fetchFile(ctx, source, "", hash)
+		if err == nil {
+			db.setValidator(cacheKey, validator)
 		}
-		return (string)(buf)
+		fetched = true
+		return v
 	})
+	if err != nil {
+		db.Cache.Remove(cacheKey)
+		return
+	}
+	if fetched || verifyChecksum(source, buf, hash) == nil {
+		return
+	}
+	db.evictFile(cacheKey)
+	fetched = true
+	var validator string
+	if buf, validator, err = db.fetchFile(ctx, source, "", hash); err != nil {
+		return
+	}
+	db.Cache.Set(cacheKey, buf)
+	db.setValidator(cacheKey, validator)
+	return
+}
+
+// evictFile removes the corrupted file and its validator from the cache
+func (db *ErrDB) evictFile(cacheKey string) {
+	db.Cache.Remove(cacheKey)
+	db.Cache.Remove(cacheKey + validatorKeySuffix)
+}
+
+func errorCacheKey(id int) string {
+	return fmt.Sprintf("error.%d", id)
+}
+
+func errorSource(id int) string {
+	return path.Join("errors", fmt.Sprintf("%d.json", id))
+}
+
+func solutionCacheKey(id int) string {
+	return fmt.Sprintf("solution.%d", id)
+}
+
+func solutionSource(id int) string {
+	return path.Join("solutions", fmt.Sprintf("%d.json", id))
+}
+
+func (db *ErrDB) GetErrorDesc(id int) (desc *mcla.ErrorDesc, err error) {
+	return db.getErrorDesc(context.Background(), id)
+}
+
+func (db *ErrDB) getErrorDesc(ctx context.Context, id int) (desc *mcla.ErrorDesc, err error) {
+	desc, _, err = db.loadErrorDesc(ctx, id)
+	return
+}
+
+// loadErrorDesc is same as getErrorDesc, and reports whether the file is fetched instead of read from the cache
+func (db *ErrDB) loadErrorDesc(ctx context.Context, id int) (desc *mcla.ErrorDesc, fetched bool, err error) {
+	cacheKey := errorCacheKey(id)
+	source := errorSource(id)
+	buf, fetched, err := db.getFile(ctx, cacheKey, source)
 	if err != nil {
 		return
 	}
@@ -155,30 +388,51 @@ func (db *ErrDB) GetErrorDesc(id int) (desc *mcla.ErrorDesc, err error) {
 		desc = nil
 		return
 	}
+	desc.Source = source
 	return
 }
 
 func (db *ErrDB) ForEachErrors(callback func(*mcla.ErrorDesc) error) (err error) {
-	db.checkUpdate()
+	return db.ForEachErrorsContext(context.Background(), callback)
+}
 
-	ctx, cancel := context.WithCancelCause(context.Background())
-	resCh := make(chan *mcla.ErrorDesc, 2)
+// ForEachErrorsContext is same as ForEachErrors, but stops fetching the files when the context is done.
+// The progress is reported to the LoadProgress of the context, see WithLoadProgress
+func (db *ErrDB) ForEachErrorsContext(ctx context.Context, callback func(*mcla.ErrorDesc) error) (err error) {
+	ctx, tracker := withLoadTracker(ctx)
+	db.checkUpdate(ctx)
+	db.mux.Lock()
+	count := db.cachedVersion.ErrorIncId
+	db.mux.Unlock()
+	tracker.setTotal(count)
 
-	for i := 1; i <= db.cachedVersion.ErrorIncId; i++ {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	type loadedDesc struct {
+		id      int
+		desc    *mcla.ErrorDesc
+		fetched bool
+	}
+	resCh := make(chan loadedDesc, 2)
+
+	for i := 1; i <= count; i++ {
 		go func(i int) {
-			println("getting error", i)
-			desc, err := db.GetErrorDesc(i)
+			desc, fetched, err := db.loadErrorDesc(ctx, i)
 			if err != nil {
 				cancel(err)
 				return
 			}
-			resCh <- desc
+			select {
+			case resCh <- loadedDesc{i, desc, fetched}:
+			case <-ctx.Done():
+			}
 		}(i)
 	}
-	for i := 1; i <= db.cachedVersion.ErrorIncId; i++ {
+	for i := 1; i <= count; i++ {
 		select {
-		case desc := <-resCh:
-			if err = callback(desc); err != nil {
+		case res := <-resCh:
+			tracker.add(res.id, res.fetched)
+			if err = callback(res.desc); err != nil {
 				return
 			}
 		case <-ctx.Done():
@@ -189,20 +443,12 @@ func (db *ErrDB) ForEachErrors(callback func(*mcla.ErrorDesc) error) (err error)
 }
 
 func (db *ErrDB) GetSolution(id int) (sol *mcla.SolutionDesc, err error) {
-	cacheKey := fmt.Sprintf("solution.%d", id)
-	buf := db.Cache.GetOrSet(cacheKey, func() string {
-		var res io.ReadCloser
-		if res, err = db.fetch("solutions", fmt.Sprintf("%d.json", id)); err != nil {
-			return ""
-		}
-		var buf []byte
-		buf, err = io.ReadAll(res)
-		res.Close()
-		if err != nil {
-			return ""
-		}
-		return (string)(buf)
-	})
+	return db.getSolution(context.Background(), id)
+}
+
+func (db *ErrDB) getSolution(ctx context.Context, id int) (sol *mcla.SolutionDesc, err error) {
+	cacheKey := solutionCacheKey(id)
+	buf, _, err := db.getFile(ctx, cacheKey, solutionSource(id))
 	if err != nil {
 		return
 	}
@@ -0,0 +1,170 @@
+package ghdb_test
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/GlobeMC/mcla"
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+type memTransport map[string]string
+
+func (t memTransport) Open(path string) (io.ReadCloser, error) {
+	data, ok := t[path]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return io.NopCloser(strings.NewReader(data)), nil
+}
+
+func newTestTransport() memTransport {
+	return memTransport{
+		"version.json":     `{"major":0,"minor":1,"patch":0,"errorIncId":2,"solutionIncId":1}`,
+		"errors/1.json":    `{"error":"java.lang.NullPointerException","message":"","solutions":[1]}`,
+		"errors/2.json":    `{"error":"java.lang.OutOfMemoryError","message":"Java heap space","solutions":[1]}`,
+		"solutions/1.json": `{"tags":["test"],"description":"a test solution","link_to":""}`,
+	}
+}
+
+func TestErrDBTransport(t *testing.T) {
+	db := &ErrDB{
+		Transport: newTestTransport(),
+		Cache:     NewInMemoryCache(),
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	found := make(map[string]bool)
+	if err := db.ForEachErrors(func(e *mcla.ErrorDesc) error {
+		found[e.Error] = true
+		if _, ok := db.Transport.(memTransport)[e.Source]; !ok {
+			t.Errorf("Expect e.Source to be the database file, got %q", e.Source)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachErrors: %v", err)
+	}
+	for _, name := range []string{"java.lang.NullPointerException", "java.lang.OutOfMemoryError"} {
+		if !found[name] {
+			t.Errorf("Expect error %q to be loaded, got %v", name, found)
+		}
+	}
+	sol, err := db.GetSolution(1)
+	if err != nil {
+		t.Fatalf("GetSolution: %v", err)
+	}
+	if expect := "a test solution"; sol.Description != expect {
+		t.Errorf("Expect sol.Description == %q, got %q", expect, sol.Description)
+	}
+	if _, err := db.GetSolution(2); err == nil {
+		t.Errorf("Expect an error when the transport does not have the file")
+	}
+}
+
+func TestErrDBLocalizedDescriptions(t *testing.T) {
+	transport := newTestTransport()
+	transport["errors/2.json"] = `{"error":"java.lang.OutOfMemoryError","message":"Java heap space","solutions":[1],` +
+		`"description":"Increase the memory","descriptions":{"zh-CN":"增加内存"}}`
+	db := &ErrDB{
+		Transport: transport,
+		Cache:     NewInMemoryCache(),
+	}
+	descs := make(map[string]*mcla.ErrorDesc)
+	if err := db.ForEachErrors(func(e *mcla.ErrorDesc) error {
+		descs[e.Error] = e
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachErrors: %v", err)
+	}
+	oom, npe := descs["java.lang.OutOfMemoryError"], descs["java.lang.NullPointerException"]
+	if oom == nil || npe == nil {
+		t.Fatalf("Expect both errors to be loaded, got %v", descs)
+	}
+	if expect := "增加内存"; oom.LocalizedDescription("zh-CN") != expect {
+		t.Errorf("Expect the zh-CN description == %q, got %q", expect, oom.LocalizedDescription("zh-CN"))
+	}
+	if expect := "Increase the memory"; oom.LocalizedDescription("fr") != expect {
+		t.Errorf("Expect the default description == %q, got %q", expect, oom.LocalizedDescription("fr"))
+	}
+	if npe.Descriptions != nil || npe.LocalizedDescription("zh-CN") != "" {
+		t.Errorf("Expect an entry without translations to have no description, got %v", npe.Descriptions)
+	}
+}
+
+type countingCache struct {
+	Cache
+	reads int
+}
+
+func (c *countingCache) Get(key string) string {
+	c.reads++
+	return c.Cache.Get(key)
+}
+
+func (c *countingCache) GetOrSet(key string, setter func() string) string {
+	c.reads++
+	return c.Cache.GetOrSet(key, setter)
+}
+
+func TestErrDBBypassCache(t *testing.T) {
+	cache := &countingCache{Cache: NewInMemoryCache()}
+	cache.Set("error.2", `{"error":"java.lang.OutOfMemoryError","message":"stale","solutions":[]}`)
+	db := &ErrDB{
+		Transport:   newTestTransport(),
+		Cache:       cache,
+		BypassCache: true,
+	}
+	desc, err := db.GetErrorDesc(2)
+	if err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if expect := "Java heap space"; desc.Message != expect {
+		t.Errorf("Expect the fresh message %q, got %q", expect, desc.Message)
+	}
+	if _, err = db.GetSolution(1); err != nil {
+		t.Fatalf("GetSolution: %v", err)
+	}
+	if cache.reads != 0 {
+		t.Errorf("Expect the cache to not be read in bypass mode, got %d reads", cache.reads)
+	}
+	if v := cache.Get("error.2"); !strings.Contains(v, "Java heap space") {
+		t.Errorf("Expect the fetched error to be written to the cache, got %q", v)
+	}
+	if v := cache.Get("solution.1"); !strings.Contains(v, "a test solution") {
+		t.Errorf("Expect the fetched solution to be written to the cache, got %q", v)
+	}
+
+	db.BypassCache = false
+	db.Transport = memTransport{}
+	if desc, err = db.GetErrorDesc(2); err != nil {
+		t.Fatalf("Expect the cached error to be used without bypass, got %v", err)
+	}
+	if expect := "Java heap space"; desc.Message != expect {
+		t.Errorf("Expect the cached message %q, got %q", expect, desc.Message)
+	}
+}
+
+func TestErrDBPurgeCache(t *testing.T) {
+	transport := newTestTransport()
+	db := &ErrDB{
+		Transport: transport,
+		Cache:     NewInMemoryCache(),
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	db.Cache.Set("unrelated", "stale")
+	transport["errors/2.json"] = `{"error":"java.lang.OutOfMemoryError","message":"Metaspace","solutions":[1]}`
+	if err := db.PurgeCache(); err != nil {
+		t.Fatalf("PurgeCache: %v", err)
+	}
+	if v := db.Cache.Get("unrelated"); v != "" {
+		t.Errorf("Expect the cache to be cleared, got %q", v)
+	}
+	if v := db.Cache.Get("error.2"); !strings.Contains(v, "Metaspace") {
+		t.Errorf("Expect the changed file to be fetched again, got %q", v)
+	}
+}
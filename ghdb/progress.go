@@ -0,0 +1,82 @@
+package ghdb
+
+import (
+	"context"
+	"sync"
+)
+
+// LoadProgress reports the progress of ErrDB.ForEachErrorsContext, see WithLoadProgress.
+// done is how many error descriptions are ready, fetched is how many of them are downloaded by the transport
+// instead of read from the cache, including the ones downloaded while checking the database version,
+// and total is how many there are. It's called again when total is changed
+type LoadProgress func(done, fetched, total int)
+
+type loadProgressKey struct{}
+
+// WithLoadProgress returns a context which reports the progress of ErrDB.ForEachErrorsContext to progress,
+// e.g. for Analyzer.UpdateErrorsContext. The progress is called from the goroutines which fetch the files,
+// but not concurrently
+func WithLoadProgress(ctx context.Context, progress LoadProgress) context.Context {
+	return context.WithValue(ctx, loadProgressKey{}, progress)
+}
+
+// loadTracker counts each error description once, whether it's fetched by the version check or by the iteration
+type loadTracker struct {
+	progress LoadProgress
+
+	mux     sync.Mutex
+	total   int
+	ready   map[int]bool
+	fetched int
+}
+
+type loadTrackerKey struct{}
+
+// withLoadTracker returns a context with a loadTracker if the context has a LoadProgress
+func withLoadTracker(ctx context.Context) (context.Context, *loadTracker) {
+	progress, _ := ctx.Value(loadProgressKey{}).(LoadProgress)
+	if progress == nil {
+		return ctx, nil
+	}
+	t := &loadTracker{
+		progress: progress,
+		total:    -1,
+		ready:    make(map[int]bool),
+	}
+	return context.WithValue(ctx, loadTrackerKey{}, t), t
+}
+
+func loadTrackerOf(ctx context.Context) *loadTracker {
+	t, _ := ctx.Value(loadTrackerKey{}).(*loadTracker)
+	return t
+}
+
+func (t *loadTracker) setTotal(total int) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.total == total {
+		return
+	}
+	t.total = total
+	t.progress(len(t.ready), t.fetched, t.total)
+}
+
+// add marks the error description ready
+func (t *loadTracker) add(id int, fetched bool) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.ready[id] {
+		return
+	}
+	t.ready[id] = true
+	if fetched {
+		t.fetched++
+	}
+	t.progress(len(t.ready), t.fetched, t.total)
+}
@@ -0,0 +1,45 @@
+package ghdb_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/GlobeMC/mcla"
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+type loadReport struct {
+	done, fetched, total int
+}
+
+func recordLoadProgress(reports *[]loadReport) context.Context {
+	return WithLoadProgress(context.Background(), func(done, fetched, total int) {
+		*reports = append(*reports, loadReport{done, fetched, total})
+	})
+}
+
+func TestErrDBLoadProgress(t *testing.T) {
+	db := &ErrDB{
+		Transport: newTestTransport(),
+		Cache:     NewInMemoryCache(),
+	}
+	var reports []loadReport
+	if err := mcla.NewAnalyzer(db).UpdateErrorsContext(recordLoadProgress(&reports)); err != nil {
+		t.Fatalf("UpdateErrorsContext: %v", err)
+	}
+	expect := []loadReport{{0, 0, 2}, {1, 1, 2}, {2, 2, 2}}
+	if !slices.Equal(reports, expect) {
+		t.Errorf("Expect the first load to report %v, got %v", expect, reports)
+	}
+
+	// the files are in the cache now
+	reports = nil
+	if err := db.ForEachErrorsContext(recordLoadProgress(&reports), func(*mcla.ErrorDesc) error { return nil }); err != nil {
+		t.Fatalf("ForEachErrorsContext: %v", err)
+	}
+	expect = []loadReport{{0, 0, 2}, {1, 0, 2}, {2, 0, 2}}
+	if !slices.Equal(reports, expect) {
+		t.Errorf("Expect the cached load to report %v, got %v", expect, reports)
+	}
+}
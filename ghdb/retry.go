@@ -0,0 +1,93 @@
+package ghdb
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides whether and when a failed fetch is tried again
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt, not positive means no retry
+	MaxAttempts int
+	// BaseDelay is the delay before the second attempt, it's doubled after each attempt
+	BaseDelay time.Duration
+	// MaxDelay limits the delay, zero means no limit
+	MaxDelay time.Duration
+	// Jitter randomizes the delay by the fraction, e.g. 0.2 picks a delay in [0.8, 1.2] times of it
+	Jitter float64
+	// Retryable reports whether the error is transient, nil means IsRetryable
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy is used by ErrDB when its Retry is nil
+var DefaultRetryPolicy = &RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.2,
+}
+
+// IsRetryable reports whether the fetch error may be transient.
+// The canceled context, the missing file, ErrNotModified and the HTTP status codes
+// other than 5xx and 429 Too Many Requests are not retryable, the others like network errors are
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrNotModified) {
+		return false
+	}
+	var statusErr *HTTPStatusErr
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	var syntaxErr *UnsupportSyntaxErr
+	return !errors.As(err, &syntaxErr)
+}
+
+// delay returns the delay before the attempt, which counts from 1.
+// The delay stops doubling before it overflows, so it's still limited without MaxDelay
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 2; i < attempt && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 && d > 0 {
+		if f := (float64)(d) * (1 + p.Jitter*(2*rand.Float64()-1)); f < math.MaxInt64 {
+			d = (time.Duration)(f)
+		} else {
+			d = math.MaxInt64
+		}
+	}
+	return d
+}
+
+// do calls fn until it succeeds, the error is not retryable, the attempts are used up, or the context is done
+func (p *RetryPolicy) do(ctx context.Context, fn func() error) (err error) {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return
+		}
+		if d := p.delay(attempt + 1); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+	}
+}
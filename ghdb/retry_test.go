@@ -0,0 +1,86 @@
+package ghdb_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+// flakyTransport fails the first attempts of each file with the error
+type flakyTransport struct {
+	memTransport
+	failures int
+	err      error
+
+	mux      sync.Mutex
+	attempts map[string]int
+}
+
+func (t *flakyTransport) Open(path string) (io.ReadCloser, error) {
+	t.mux.Lock()
+	t.attempts[path]++
+	n := t.attempts[path]
+	t.mux.Unlock()
+	if n <= t.failures {
+		return nil, t.err
+	}
+	return t.memTransport.Open(path)
+}
+
+func TestErrDBRetry(t *testing.T) {
+	for _, d := range []struct {
+		Name     string
+		Err      error
+		Failures int
+		Ok       bool
+		Attempts int
+	}{
+		{"network error", errors.New("connection reset by peer"), 2, true, 3},
+		{"server error", &HTTPStatusErr{StatusCode: http.StatusBadGateway}, 1, true, 2},
+		{"too many failures", errors.New("connection reset by peer"), 3, false, 3},
+		{"not found", &HTTPStatusErr{StatusCode: http.StatusNotFound}, 1, false, 1},
+	} {
+		transport := &flakyTransport{
+			memTransport: newTestTransport(),
+			failures:     d.Failures,
+			err:          d.Err,
+			attempts:     make(map[string]int),
+		}
+		db := &ErrDB{
+			Transport: transport,
+			Cache:     NewInMemoryCache(),
+			Retry:     &RetryPolicy{MaxAttempts: 3},
+		}
+		desc, err := db.GetErrorDesc(2)
+		if ok := err == nil; ok != d.Ok {
+			t.Errorf("%s: Expect success == %v, got error %v", d.Name, d.Ok, err)
+		} else if ok && desc.Message != "Java heap space" {
+			t.Errorf("%s: Expect the fetched message, got %q", d.Name, desc.Message)
+		}
+		if n := transport.attempts["errors/2.json"]; n != d.Attempts {
+			t.Errorf("%s: Expect %d attempts, got %d", d.Name, d.Attempts, n)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, d := range []struct {
+		Err    error
+		Expect bool
+	}{
+		{errors.New("connection refused"), true},
+		{&HTTPStatusErr{StatusCode: http.StatusServiceUnavailable}, true},
+		{&HTTPStatusErr{StatusCode: http.StatusTooManyRequests}, true},
+		{&HTTPStatusErr{StatusCode: http.StatusNotFound}, false},
+		{ErrNotModified, false},
+		{&UnsupportSyntaxErr{Version: 1}, false},
+	} {
+		if got := IsRetryable(d.Err); got != d.Expect {
+			t.Errorf("Expect IsRetryable(%v) == %v, got %v", d.Err, d.Expect, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ghdb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultMaxConcurrentFetches is used by ErrDB when its MaxConcurrentFetches is zero
+const DefaultMaxConcurrentFetches = 6
+
+// fetchLimiter limits the concurrent fetches of an ErrDB,
+// and holds the new fetches for a while after the server responds 429 Too Many Requests
+type fetchLimiter struct {
+	once        sync.Once
+	sem         chan struct{} // nil means no limit
+	mux         sync.Mutex
+	pausedUntil time.Time
+}
+
+// acquireFetch waits until a fetch can be started, release must be called after the response is read
+func (db *ErrDB) acquireFetch(ctx context.Context) (release func(), err error) {
+	l := &db.limiter
+	l.once.Do(func() {
+		n := db.MaxConcurrentFetches
+		if n == 0 {
+			n = DefaultMaxConcurrentFetches
+		}
+		if n > 0 {
+			l.sem = make(chan struct{}, n)
+		}
+	})
+	release = func() {}
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+			release = func() { <-l.sem }
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+	// the pause is checked after the slot is taken, since it may start while waiting for the slot
+	for {
+		l.mux.Lock()
+		d := time.Until(l.pausedUntil)
+		l.mux.Unlock()
+		if d <= 0 {
+			return
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, context.Cause(ctx)
+		}
+	}
+}
+
+// throttled holds the new fetches for the base delay of the retry policy if the server asks to slow down,
+// so the other fetches back off together instead of being rejected too
+func (db *ErrDB) throttled(err error) {
+	var statusErr *HTTPStatusErr
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	d := db.retryPolicy().delay(2)
+	if d <= 0 {
+		return
+	}
+	l := &db.limiter
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
@@ -0,0 +1,95 @@
+package ghdb_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GlobeMC/mcla"
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+func manyErrorsTransport(count int) memTransport {
+	files := memTransport{
+		"version.json": fmt.Sprintf(`{"major":0,"minor":1,"patch":0,"errorIncId":%d,"solutionIncId":0}`, count),
+	}
+	for i := 1; i <= count; i++ {
+		files[fmt.Sprintf("errors/%d.json", i)] = fmt.Sprintf(`{"error":"java.lang.Error%d","message":""}`, i)
+	}
+	return files
+}
+
+func TestErrDBMaxConcurrentFetches(t *testing.T) {
+	for _, limit := range []int{0, 2} {
+		transport := &slowTransport{Transport: manyErrorsTransport(30)}
+		db := &ErrDB{
+			Transport:            transport,
+			Cache:                NewInMemoryCache(),
+			MaxConcurrentFetches: limit,
+		}
+		count := 0
+		if err := db.ForEachErrors(func(*mcla.ErrorDesc) error {
+			count++
+			return nil
+		}); err != nil {
+			t.Fatalf("ForEachErrors: %v", err)
+		}
+		if count != 30 {
+			t.Errorf("Expect 30 descriptions, got %d", count)
+		}
+		expect := limit
+		if expect == 0 {
+			expect = DefaultMaxConcurrentFetches
+		}
+		if max := transport.maxRun.Load(); max > (int32)(expect) {
+			t.Errorf("Expect at most %d concurrent fetches, got %d", expect, max)
+		}
+	}
+}
+
+// throttledTransport rejects the first fetch of errors/1.json with 429 Too Many Requests,
+// and counts the fetches started within the backoff after it
+type throttledTransport struct {
+	Transport
+	backoff time.Duration
+
+	mux      sync.Mutex
+	rejected time.Time
+	tooSoon  int
+}
+
+func (t *throttledTransport) Open(path string) (io.ReadCloser, error) {
+	t.mux.Lock()
+	if !t.rejected.IsZero() && time.Since(t.rejected) < t.backoff {
+		t.tooSoon++
+	}
+	if path == "errors/1.json" && t.rejected.IsZero() {
+		t.rejected = time.Now()
+		t.mux.Unlock()
+		return nil, &HTTPStatusErr{URL: path, StatusCode: http.StatusTooManyRequests}
+	}
+	t.mux.Unlock()
+	return t.Transport.Open(path)
+}
+
+func TestErrDBTooManyRequests(t *testing.T) {
+	const backoff = 50 * time.Millisecond
+	transport := &throttledTransport{Transport: &slowTransport{Transport: manyErrorsTransport(20)}, backoff: backoff}
+	db := &ErrDB{
+		Transport: transport,
+		Cache:     NewInMemoryCache(),
+		Retry:     &RetryPolicy{MaxAttempts: 2, BaseDelay: backoff},
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if v := db.Cache.Get("error.1"); v == "" {
+		t.Errorf("Expect the rejected file to be fetched again")
+	}
+	if transport.tooSoon != 0 {
+		t.Errorf("Expect no fetch to start while backing off, got %d", transport.tooSoon)
+	}
+}
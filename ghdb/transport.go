@@ -0,0 +1,129 @@
+package ghdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Transport opens the database files by their path relative to the database root
+type Transport interface {
+	Open(path string) (io.ReadCloser, error)
+}
+
+// ContextTransport is a Transport which can stop opening a file when the context is done
+type ContextTransport interface {
+	Transport
+	OpenContext(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// ErrNotModified is returned by ConditionalTransport when the file is not changed since the validator
+var ErrNotModified = errors.New("ghdb: file not modified")
+
+// ConditionalTransport is a Transport which can revalidate a cached file instead of downloading it again
+type ConditionalTransport interface {
+	Transport
+	// OpenConditional opens the file with the validator of the cached copy, an empty validator means there is no cached copy.
+	// The validator of the opened file is returned, which is the ETag or the Last-Modified time.
+	// ErrNotModified is returned if the cached copy is still fresh
+	OpenConditional(ctx context.Context, path string, validator string) (body io.ReadCloser, newValidator string, err error)
+}
+
+// TransportFunc adapts an ordinary function as a Transport
+type TransportFunc func(path string) (io.ReadCloser, error)
+
+var _ Transport = (TransportFunc)(nil)
+
+func (f TransportFunc) Open(path string) (io.ReadCloser, error) {
+	return f(path)
+}
+
+type HTTPStatusErr struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusErr) Error() string {
+	return fmt.Sprintf("HTTP status code error: %d when getting %q", e.StatusCode, e.URL)
+}
+
+// DefaultHTTPClient is used by HTTPTransport when its Client is nil
+var DefaultHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// HTTPTransport fetches the database files with HTTP GET requests under Prefix.
+// Set ErrDB.Transport to an HTTPTransport with a custom Client to configure the proxy, TLS or timeout
+type HTTPTransport struct {
+	Prefix string
+	Client *http.Client
+}
+
+var (
+	_ ContextTransport     = (*HTTPTransport)(nil)
+	_ ConditionalTransport = (*HTTPTransport)(nil)
+)
+
+func (t *HTTPTransport) Open(path string) (io.ReadCloser, error) {
+	return t.OpenContext(context.Background(), path)
+}
+
+func (t *HTTPTransport) OpenContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	body, _, err := t.OpenConditional(ctx, path, "")
+	return body, err
+}
+
+// OpenConditional sends `If-None-Match` for an ETag validator, or `If-Modified-Since` for a Last-Modified validator,
+// and treats `304 Not Modified` as ErrNotModified
+func (t *HTTPTransport) OpenConditional(ctx context.Context, path string, validator string) (io.ReadCloser, string, error) {
+	path, err := url.JoinPath(t.Prefix, path)
+	if err != nil {
+		return nil, "", err
+	}
+	client := t.Client
+	if client == nil {
+		client = DefaultHTTPClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if validator != "" {
+		if IsETag(validator) {
+			req.Header.Set("If-None-Match", validator)
+		} else {
+			req.Header.Set("If-Modified-Since", validator)
+		}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if res.StatusCode == http.StatusNotModified && validator != "" {
+		res.Body.Close()
+		return nil, "", ErrNotModified
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, "", &HTTPStatusErr{res.Request.URL.String(), res.StatusCode}
+	}
+	return res.Body, ResponseValidator(res.Header), nil
+}
+
+// IsETag reports whether the validator is an ETag like `"abc"` or `W/"abc"` rather than a Last-Modified time
+func IsETag(validator string) bool {
+	return strings.HasPrefix(validator, `"`) || strings.HasPrefix(validator, `W/"`)
+}
+
+// ResponseValidator returns the ETag of the response, or the Last-Modified time if there is no ETag
+func ResponseValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
@@ -0,0 +1,188 @@
+package ghdb_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GlobeMC/mcla"
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+type countingRoundTripper struct {
+	rt    http.RoundTripper
+	count atomic.Int32
+}
+
+func (c *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.count.Add(1)
+	return c.rt.RoundTrip(req)
+}
+
+func TestHTTPTransportClient(t *testing.T) {
+	files := newTestTransport()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		data, ok := files[strings.TrimPrefix(req.URL.Path, "/db/")]
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+		rw.Write(([]byte)(data))
+	}))
+	defer server.Close()
+
+	rt := &countingRoundTripper{rt: server.Client().Transport}
+	db := &ErrDB{
+		Transport: &HTTPTransport{
+			Prefix: server.URL + "/db",
+			Client: &http.Client{Transport: rt},
+		},
+		Cache: NewInMemoryCache(),
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	count := 0
+	if err := db.ForEachErrors(func(*mcla.ErrorDesc) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachErrors: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expect 2 errors, got %d", count)
+	}
+	if rt.count.Load() == 0 {
+		t.Errorf("Expect the injected client to be used")
+	}
+
+	_, err := db.GetSolution(404)
+	var statusErr *HTTPStatusErr
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expect a 404 HTTPStatusErr, got %v", err)
+	}
+}
+
+func TestErrDBForEachErrorsContext(t *testing.T) {
+	files := newTestTransport()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		name := strings.TrimPrefix(req.URL.Path, "/db/")
+		if strings.HasPrefix(name, "errors/") {
+			<-req.Context().Done() // a stuck fetch
+			return
+		}
+		rw.Write(([]byte)(files[name]))
+	}))
+	defer server.Close()
+
+	db := &ErrDB{
+		Transport: &HTTPTransport{Prefix: server.URL + "/db", Client: server.Client()},
+		Cache:     NewInMemoryCache(),
+	}
+	analyzer := mcla.NewAnalyzer(db)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := analyzer.UpdateErrorsContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expect err == %v, got %v", context.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Expect the stuck fetch to be aborted, took %v", d)
+	}
+}
+
+func TestHTTPTransportConditional(t *testing.T) {
+	files := newTestTransport()
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		name := strings.TrimPrefix(req.URL.Path, "/db/")
+		data, ok := files[name]
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+		if name == "solutions/1.json" { // validated by the modified time
+			if req.Header.Get("If-Modified-Since") == "Mon, 02 Jan 2006 15:04:05 GMT" {
+				notModified.Add(1)
+				rw.WriteHeader(http.StatusNotModified)
+				return
+			}
+			rw.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		} else {
+			etag := `"` + name + `"`
+			if req.Header.Get("If-None-Match") == etag {
+				notModified.Add(1)
+				rw.WriteHeader(http.StatusNotModified)
+				return
+			}
+			rw.Header().Set("ETag", etag)
+		}
+		full.Add(1)
+		rw.Write(([]byte)(data))
+	}))
+	defer server.Close()
+
+	db := &ErrDB{
+		Transport: &HTTPTransport{Prefix: server.URL + "/db", Client: server.Client()},
+		Cache:     NewInMemoryCache(),
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	// the version.json and the 3 database files
+	if n := full.Load(); n != 4 {
+		t.Errorf("Expect 4 full downloads, got %d", n)
+	}
+
+	// a new session on the same cache only checks the version, the cached version is not changed
+	db = &ErrDB{
+		Transport: db.Transport,
+		Cache:     db.Cache,
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if n := full.Load(); n != 5 {
+		t.Errorf("Expect only version.json to be downloaded again, got %d full downloads", n)
+	}
+	if n := notModified.Load(); n != 0 {
+		t.Errorf("Expect the cached files not to be revalidated, got %d not modified responses", n)
+	}
+
+	// a minor version change revalidates the cached files instead of downloading them again
+	files["version.json"] = `{"major":0,"minor":2,"patch":0,"errorIncId":2,"solutionIncId":1}`
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if n := full.Load(); n != 6 {
+		t.Errorf("Expect only version.json to be downloaded again, got %d full downloads", n)
+	}
+	if n := notModified.Load(); n != 3 {
+		t.Errorf("Expect 3 not modified responses, got %d", n)
+	}
+
+	db.BypassCache = true
+	desc, err := db.GetErrorDesc(2)
+	if err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if expect := "Java heap space"; desc.Message != expect {
+		t.Errorf("Expect desc.Message == %q, got %q", expect, desc.Message)
+	}
+	sol, err := db.GetSolution(1)
+	if err != nil {
+		t.Fatalf("GetSolution: %v", err)
+	}
+	if expect := "a test solution"; sol.Description != expect {
+		t.Errorf("Expect sol.Description == %q, got %q", expect, sol.Description)
+	}
+	if n := notModified.Load(); n != 5 {
+		t.Errorf("Expect the bypassed cache to be revalidated, got %d not modified responses", n)
+	}
+}
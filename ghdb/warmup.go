@@ -0,0 +1,70 @@
+package ghdb
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultWarmupConcurrency is used by ErrDB.Warmup when the concurrency is not positive
+const DefaultWarmupConcurrency = 8
+
+// WarmupProgress is called after each database file is prefetched
+type WarmupProgress func(done, total int)
+
+// Warmup prefetches all error and solution files of the latest database version into the cache,
+// with at most concurrency files being fetched at the same time.
+// It does not stop when a file fails to fetch, the first error is returned after all files are tried.
+// If all files are fetched, the version is recorded as cached, so the next update check does not fetch them again
+func (db *ErrDB) Warmup(ctx context.Context, concurrency int, progress WarmupProgress) (err error) {
+	if concurrency <= 0 {
+		concurrency = DefaultWarmupConcurrency
+	}
+	version, rawIndex, indexSum, err := db.fetchIndex(ctx)
+	if err != nil {
+		return
+	}
+	total := version.ErrorIncId + version.SolutionIncId
+
+	var (
+		wg   sync.WaitGroup
+		mux  sync.Mutex
+		done int
+	)
+	sem := make(chan struct{}, concurrency)
+	for i := 1; i <= total; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return context.Cause(ctx)
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var er error
+			if i <= version.ErrorIncId {
+				_, er = db.getErrorDesc(ctx, i)
+			} else {
+				_, er = db.getSolution(ctx, i-version.ErrorIncId)
+			}
+			<-sem
+
+			mux.Lock()
+			defer mux.Unlock()
+			if er != nil && err == nil {
+				err = er
+			}
+			done++
+			if progress != nil {
+				progress(done, total)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err == nil {
+		db.mux.Lock()
+		db.recordVersion(version, rawIndex, indexSum)
+		db.mux.Unlock()
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package ghdb_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GlobeMC/mcla"
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+type slowTransport struct {
+	Transport
+	running atomic.Int32
+	maxRun  atomic.Int32
+}
+
+func (t *slowTransport) Open(path string) (io.ReadCloser, error) {
+	n := t.running.Add(1)
+	defer t.running.Add(-1)
+	for {
+		m := t.maxRun.Load()
+		if n <= m || t.maxRun.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return t.Transport.Open(path)
+}
+
+func TestErrDBWarmupConcurrency(t *testing.T) {
+	const errorCount, solutionCount = 20, 10
+	files := memTransport{
+		"version.json": fmt.Sprintf(`{"major":0,"minor":1,"patch":0,"errorIncId":%d,"solutionIncId":%d}`, errorCount, solutionCount),
+	}
+	for i := 1; i <= errorCount; i++ {
+		files[fmt.Sprintf("errors/%d.json", i)] = fmt.Sprintf(`{"error":"java.lang.Error%d","message":"","solutions":[1]}`, i)
+	}
+	for i := 1; i <= solutionCount; i++ {
+		files[fmt.Sprintf("solutions/%d.json", i)] = `{"tags":[],"description":"","link_to":""}`
+	}
+	transport := &slowTransport{Transport: files}
+	cache := NewInMemoryCache()
+	db := &ErrDB{
+		Transport: transport,
+		Cache:     cache,
+	}
+
+	const limit = 3
+	var lastDone, lastTotal int
+	if err := db.Warmup(context.Background(), limit, func(done, total int) {
+		if done != lastDone+1 {
+			t.Errorf("Expect progress to increase by 1, got %d after %d", done, lastDone)
+		}
+		lastDone, lastTotal = done, total
+	}); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	if max := transport.maxRun.Load(); max > limit {
+		t.Errorf("Expect at most %d concurrent fetches, got %d", limit, max)
+	} else if max < 2 {
+		t.Errorf("Expect the fetches to run concurrently, got at most %d", max)
+	}
+	if lastDone != errorCount+solutionCount || lastTotal != errorCount+solutionCount {
+		t.Errorf("Expect progress to end at %d/%d, got %d/%d", errorCount+solutionCount, errorCount+solutionCount, lastDone, lastTotal)
+	}
+	for i := 1; i <= errorCount; i++ {
+		if cache.Get(fmt.Sprintf("error.%d", i)) == "" {
+			t.Errorf("Expect error %d to be cached", i)
+		}
+	}
+	for i := 1; i <= solutionCount; i++ {
+		if cache.Get(fmt.Sprintf("solution.%d", i)) == "" {
+			t.Errorf("Expect solution %d to be cached", i)
+		}
+	}
+}
+
+// countingTransport counts the fetches of each path
+type countingTransport struct {
+	Transport
+	mux    sync.Mutex
+	counts map[string]int
+}
+
+func (t *countingTransport) Open(path string) (io.ReadCloser, error) {
+	t.mux.Lock()
+	if t.counts == nil {
+		t.counts = make(map[string]int)
+	}
+	t.counts[path]++
+	t.mux.Unlock()
+	return t.Transport.Open(path)
+}
+
+func (t *countingTransport) fileFetches() (n int) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	for path, count := range t.counts {
+		if strings.HasPrefix(path, "errors/") || strings.HasPrefix(path, "solutions/") {
+			n += count
+		}
+	}
+	return
+}
+
+func TestErrDBWarmupRecordsVersion(t *testing.T) {
+	transport := &countingTransport{Transport: newTestTransport()}
+	db := &ErrDB{
+		Transport: transport,
+		Cache:     NewInMemoryCache(),
+	}
+	if err := db.Warmup(context.Background(), 0, nil); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	if n := transport.fileFetches(); n != 3 {
+		t.Fatalf("Expect the warmup to fetch 3 files, got %d", n)
+	}
+	count := 0
+	if err := db.ForEachErrors(func(*mcla.ErrorDesc) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachErrors: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expect 2 descriptions, got %d", count)
+	}
+	if n := transport.fileFetches(); n != 3 {
+		t.Errorf("Expect the warmed files not to be fetched again, got %d fetches", n)
+	}
+}
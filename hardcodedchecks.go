@@ -1,7 +1,10 @@
 package mcla
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -9,15 +12,137 @@ const ModConflictSolutionID = 12
 
 const (
 	spongepoweredInjectionErrorClass = "org.spongepowered.asm.mixin.injection.throwables.InjectionError"
+	unsatisfiedLinkErrorClass        = "java.lang.UnsatisfiedLinkError"
+	noSuchMethodErrorClass           = "java.lang.NoSuchMethodError"
+	noSuchFieldErrorClass            = "java.lang.NoSuchFieldError"
+	linkageErrorClass                = "java.lang.LinkageError"
+	bindExceptionClass               = "java.net.BindException"
+	unsupportedClassVersionClass     = "java.lang.UnsupportedClassVersionError"
+	outOfMemoryErrorClass            = "java.lang.OutOfMemoryError"
+	mixinApplyErrorClass             = "org.spongepowered.asm.mixin.throwables.MixinApplyError"
+	invalidMixinExceptionClass       = "org.spongepowered.asm.mixin.transformer.throwables.InvalidMixinException"
+	mixinTargetAlreadyLoadedClass    = "org.spongepowered.asm.mixin.transformer.throwables.MixinTargetAlreadyLoadedException"
 )
 
+// HardCodedRule is an always-match rule, it returns the description and true if it recognizes the error.
+// The description is reported with a 100% match, and the database is not searched
+type HardCodedRule func(jerr *JavaError) (desc *ErrorDesc, ok bool)
+
+// builtinRule is a built-in hard coded check, the metadata and the recent logs before the error can be nil
+type builtinRule func(a *Analyzer, jerr *JavaError, meta *LogMetadata, logs logSnapshot) (desc *ErrorDesc, err error)
+
+func withoutMetadata(check func(a *Analyzer, jerr *JavaError) (*ErrorDesc, error)) builtinRule {
+	return func(a *Analyzer, jerr *JavaError, _ *LogMetadata, _ logSnapshot) (*ErrorDesc, error) {
+		return check(a, jerr)
+	}
+}
+
+func withLogs(check func(a *Analyzer, jerr *JavaError, logs logSnapshot) (*ErrorDesc, error)) builtinRule {
+	return func(a *Analyzer, jerr *JavaError, _ *LogMetadata, logs logSnapshot) (*ErrorDesc, error) {
+		return check(a, jerr, logs)
+	}
+}
+
+func withMetadata(check func(a *Analyzer, jerr *JavaError, meta *LogMetadata) (*ErrorDesc, error)) builtinRule {
+	return func(a *Analyzer, jerr *JavaError, meta *LogMetadata, _ logSnapshot) (*ErrorDesc, error) {
+		return check(a, jerr, meta)
+	}
+}
+
+// builtinHardCodedRules are checked in order before builtinLeadingRules and the rules added by AddHardCodedRule
+var builtinHardCodedRules = []builtinRule{
+	withLogs((*Analyzer).hardCodedRedirectConflictCheck),
+	withLogs((*Analyzer).hardCodedMixinConflictCheck),
+	withoutMetadata((*Analyzer).hardCodedNativeLibraryCheck),
+	withoutMetadata((*Analyzer).hardCodedNativeCrashCheck),
+	withoutMetadata((*Analyzer).hardCodedDependencyConstraintCheck),
+	withoutMetadata((*Analyzer).hardCodedLibraryConflictCheck),
+	withoutMetadata((*Analyzer).hardCodedBindCheck),
+	withoutMetadata((*Analyzer).hardCodedWorldCorruptionCheck),
+	withMetadata((*Analyzer).hardCodedJavaVersionCheck),
+}
+
+// builtinLeadingRules are checked before the rules added by AddHardCodedRule. The recognized description is the best match,
+// but the matches from the database are still listed after it
+var builtinLeadingRules = []builtinRule{
+	withoutMetadata((*Analyzer).hardCodedOutOfMemoryCheck),
+}
+
+// AddHardCodedRule registers an always-match rule, e.g. a known fix of a modpack.
+// The built-in rules are checked first, then the added rules in the order they are added,
+// and the first recognized description is the only result of the error. It's safe to call during an analysis.
+// The built-in OutOfMemoryError variants lead the matches from the database instead
+func (a *Analyzer) AddHardCodedRule(rule HardCodedRule) {
+	a.rulesMux.Lock()
+	defer a.rulesMux.Unlock()
+	a.hardCodedRules = append(a.hardCodedRules, rule)
+}
+
+// HardCodedChecks returns the description of the first hard coded rule which recognizes the error, or nil.
+// The rules which need the recent logs of a stream, e.g. the mixin conflicts, do not recognize the error
 func (a *Analyzer) HardCodedChecks(jerr *JavaError) (desc *ErrorDesc, err error) {
-	if jerr.Class == spongepoweredInjectionErrorClass {
-		if desc, err = a.hardCodedRedirectConflictCheck(jerr); desc != nil || err != nil {
+	desc, _, err = a.hardCodedChecks(jerr, nil, nil)
+	return
+}
+
+// hardCodedChecks is same as HardCodedChecks, the metadata and the recent logs before the error can be nil.
+// leading reports the description is from builtinLeadingRules
+func (a *Analyzer) hardCodedChecks(jerr *JavaError, meta *LogMetadata, logs logSnapshot) (desc *ErrorDesc, leading bool, err error) {
+	for _, rule := range builtinHardCodedRules {
+		if desc, err = rule(a, jerr, meta, logs); desc != nil || err != nil {
 			return
 		}
 	}
-	return nil, nil
+	for _, rule := range builtinLeadingRules {
+		if desc, err = rule(a, jerr, meta, logs); desc != nil || err != nil {
+			return desc, true, err
+		}
+	}
+	a.rulesMux.RLock()
+	rules := a.hardCodedRules
+	a.rulesMux.RUnlock()
+	for _, rule := range rules {
+		if desc, ok := rule(jerr); ok && desc != nil {
+			return desc, false, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// hardCodedCaptureKeys are the keys of the Data of the built-in descriptions which are also reported as the captures
+// of their matches, by the description ids. The values are the snippets extracted from the error
+var hardCodedCaptureKeys = map[string][]string{
+	"hardcoded.libraryConflict":    {"class", "method", "signature"},
+	"hardcoded.bindFailure":        {"address", "port"},
+	"hardcoded.serverStartFailure": {"address", "port"},
+	"hardcoded.javaVersion":        {"class", "classVersion", "runningClassVersion", "requiredJavaVersion", "runningJavaVersion"},
+}
+
+// hardCodedCaptures returns the captures of a built-in description, see hardCodedCaptureKeys. The empty values are skipped
+func hardCodedCaptures(e *ErrorDesc) (captures map[string]string) {
+	for _, key := range hardCodedCaptureKeys[e.ID] {
+		v, ok := e.Data[key]
+		if !ok {
+			continue
+		}
+		value := fmt.Sprint(v)
+		if value == "" || value == "0" {
+			continue
+		}
+		if captures == nil {
+			captures = make(map[string]string)
+		}
+		captures[key] = value
+	}
+	return
+}
+
+// fallbackMatch is the score of the fallback checks, so any good match from the database ranks higher
+const fallbackMatch = 0.5
+
+// hardCodedFallbackChecks are general advices which are added beside the matches from the database
+func (a *Analyzer) hardCodedFallbackChecks(jerr *JavaError) (desc *ErrorDesc, err error) {
+	return a.hardCodedServerStartCheck(jerr)
 }
 
 var (
@@ -33,7 +158,10 @@ var (
 // ...
 // Caused by: org.spongepowered.asm.mixin.injection.throwables.InjectionError: Critical injection failure: Redirector shouldFreezeWithClimate(Lnet/minecraft/world/level/biome/Biome;Lnet/minecraft/core/BlockPos;Lnet/minecraft/world/level/LevelReader;)Z in tfc.mixins.json:BiomeMixin failed injection check, (0/1) succeeded. Scanned 1 target(s). Using refmap tfc.refmap.json
 // ```
-func (a *Analyzer) hardCodedRedirectConflictCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+func (a *Analyzer) hardCodedRedirectConflictCheck(jerr *JavaError, logs logSnapshot) (desc *ErrorDesc, err error) {
+	if jerr.Class != spongepoweredInjectionErrorClass {
+		return
+	}
 	const redirectorMessage = "Critical injection failure: Redirector "
 	targetName, ok := strings.CutPrefix(jerr.Message, redirectorMessage)
 	if !ok {
@@ -44,7 +172,7 @@ func (a *Analyzer) hardCodedRedirectConflictCheck(jerr *JavaError) (desc *ErrorD
 		return
 	}
 	var mod1, mod2, method string
-	for line := range a.recentMixinLogs.IterReversed() {
+	for _, line := range slices.Backward(logs[LogCategoryMixin]) {
 		matches := mixinRedirectConflictRe.FindStringSubmatch(line)
 		if matches != nil {
 			mod1, method, mod2 = matches[1], matches[2], matches[3]
@@ -53,6 +181,8 @@ func (a *Analyzer) hardCodedRedirectConflictCheck(jerr *JavaError) (desc *ErrorD
 	}
 	if mod1 != "" {
 		return &ErrorDesc{
+			ID:        "hardcoded.redirectConflict",
+			Category:  CategoryMixinConflict,
 			Error:     spongepoweredInjectionErrorClass,
 			Message:   redirectorMessage,
 			Solutions: []int{ModConflictSolutionID},
@@ -65,3 +195,963 @@ func (a *Analyzer) hardCodedRedirectConflictCheck(jerr *JavaError) (desc *ErrorD
 	}
 	return
 }
+
+var (
+	mixinApplyFailedRe   = regexp.MustCompile(`Mixin apply (?:for mod [\w.-]+ )?failed ([\w\-]+(?:\.[\w\-]+)*\.json):([\w$]+(?:\.[\w$]+)*)(?: from mod [\w.-]+)? -> ([\w$]+(?:[./][\w$]+)+)`)
+	mixinApplyErrorRe    = regexp.MustCompile(`^Mixin \[([^\]]+)\] from phase \[\w+\] in config \[([^\]]+)\] FAILED during \w+`)
+	mixinLoadedEarlyRe   = regexp.MustCompile(`([\w\-]+(?:\.[\w\-]+)*\.json):([\w$]+(?:\.[\w$]+)*) target ([\w$]+(?:[./][\w$]+)+) was loaded too early`)
+	mixinOverwrittenByRe = regexp.MustCompile(`previously written by ([\w\-]+(?:\.[\w\-]+)*\.json):([\w$]+(?:\.[\w$]+)*)`)
+	// mixingIntoRe matches the verbose log of applying a mixin, e.g. `Mixing client.MixinMinecraft from sodium.mixins.json into net.minecraft.client.Minecraft`
+	mixingIntoRe = regexp.MustCompile(`Mixing ([\w$]+(?:\.[\w$]+)*) from ([\w\-]+(?:\.[\w\-]+)*\.json) into ([\w$]+(?:[./][\w$]+)+)`)
+)
+
+// simpleMixinName returns the simple class name of the mixin, e.g. `MixinWindow` of `sodium.mixins.json:core.MixinWindow`
+func simpleMixinName(mixin string) string {
+	if i := strings.LastIndexByte(mixin, ':'); i >= 0 {
+		mixin = mixin[i+1:]
+	}
+	if i := strings.LastIndexByte(mixin, '.'); i >= 0 {
+		mixin = mixin[i+1:]
+	}
+	return mixin
+}
+
+// Examples:
+// ```
+// [12:00:02] [main/ERROR] [mixin/]: Mixin apply for mod examplemod failed examplemod.mixins.json:MixinLevelRenderer from mod examplemod -> net.minecraft.client.renderer.LevelRenderer: org.spongepowered.asm.mixin.injection.throwables.InvalidInjectionException ...
+// [12:00:02] [main/WARN] [mixin/]: Method overwrite conflict for renderLevel in examplemod.mixins.json:MixinLevelRenderer, previously written by othermod.mixins.json:LevelRendererMixin. Skipping method.
+// ...
+// Caused by: org.spongepowered.asm.mixin.throwables.MixinApplyError: Mixin [examplemod.mixins.json:MixinLevelRenderer] from phase [DEFAULT] in config [examplemod.mixins.json] FAILED during APPLY
+// org.spongepowered.asm.mixin.transformer.throwables.MixinTargetAlreadyLoadedException: Critical problem: examplemod.mixins.json:MixinLevelRenderer target net.minecraft.client.renderer.LevelRenderer was loaded too early
+// java.lang.LinkageError: loader 'app' attempted duplicate class definition for net/minecraft/client/renderer/LevelRenderer.
+// ```
+// The error is only recognized if the logs or the error show a conflict: another mixin which overwrites or changes the target,
+// the target which is loaded too early, or the duplicate class which a recent mixin failed to apply to.
+// The other duplicate classes are left to hardCodedLibraryConflictCheck, and the plain injection failures to the database
+func (a *Analyzer) hardCodedMixinConflictCheck(jerr *JavaError, logs logSnapshot) (desc *ErrorDesc, err error) {
+	message, _ := split(jerr.Message, '\n')
+	message = strings.TrimSpace(message)
+	var config, mixin, target string
+	loadedEarly := false
+	switch jerr.Class {
+	case mixinApplyErrorClass, invalidMixinExceptionClass, mixinTargetAlreadyLoadedClass:
+		if matches := mixinApplyErrorRe.FindStringSubmatch(message); matches != nil {
+			mixin = matches[1]
+			if strings.HasSuffix(matches[2], ".json") {
+				config = matches[2]
+			}
+		} else if matches := mixinLoadedEarlyRe.FindStringSubmatch(message); matches != nil {
+			config, mixin, target = matches[1], matches[2], matches[3]
+			loadedEarly = true
+		} else if c, m, ok := findMixinConfig(message); ok {
+			config, mixin = c, m
+		}
+	case linkageErrorClass:
+		matches := duplicateClassRe.FindStringSubmatch(message)
+		if matches == nil {
+			return
+		}
+		target = strings.TrimSuffix(matches[1], ".")
+	default:
+		return
+	}
+	mixin = simpleMixinName(mixin)
+	target = strings.ReplaceAll(target, "/", ".")
+
+	lines := logs[LogCategoryMixin]
+	applied := false
+	for _, line := range slices.Backward(lines) {
+		matches := mixinApplyFailedRe.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		lconfig, lmixin, ltarget := matches[1], simpleMixinName(matches[2]), strings.ReplaceAll(matches[3], "/", ".")
+		if (mixin == "" || lmixin == mixin) && (target == "" || ltarget == target) && (config == "" || lconfig == config) {
+			config, mixin, target = lconfig, lmixin, ltarget
+			applied = true
+			break
+		}
+	}
+	if mixin == "" || (jerr.Class == linkageErrorClass && !applied) {
+		return
+	}
+
+	var conflictConfig, conflictMixin string
+	if matches := mixinOverwrittenByRe.FindStringSubmatch(jerr.Message); matches != nil && matches[1] != config {
+		conflictConfig, conflictMixin = matches[1], simpleMixinName(matches[2])
+	}
+	for _, line := range slices.Backward(lines) {
+		if conflictConfig != "" {
+			break
+		}
+		if matches := mixinLoadedEarlyRe.FindStringSubmatch(line); matches != nil {
+			if simpleMixinName(matches[2]) == mixin && (config == "" || matches[1] == config) {
+				loadedEarly = true
+			}
+			continue
+		}
+		if matches := mixinOverwrittenByRe.FindStringSubmatch(line); matches != nil {
+			if matches[1] != config && strings.Contains(line, mixin) {
+				conflictConfig, conflictMixin = matches[1], simpleMixinName(matches[2])
+			}
+			continue
+		}
+		if target == "" || mixinApplyFailedRe.MatchString(line) {
+			continue
+		}
+		if matches := mixingIntoRe.FindStringSubmatch(line); matches != nil {
+			if matches[2] != config && strings.ReplaceAll(matches[3], "/", ".") == target {
+				conflictConfig, conflictMixin = matches[2], simpleMixinName(matches[1])
+			}
+			continue
+		}
+		if matches := mixinTargetRe.FindStringSubmatch(line); matches != nil && strings.ReplaceAll(matches[1], "/", ".") == target {
+			if c, m, ok := findMixinConfig(line); ok && c != config {
+				conflictConfig, conflictMixin = c, m
+			}
+		}
+	}
+
+	// a plain injection failure is left to the database, only a conflict shown by the logs is recognized
+	var reason string
+	switch {
+	case conflictConfig != "":
+		reason = "conflict"
+	case jerr.Class == linkageErrorClass:
+		reason = "duplicateClass"
+	case loadedEarly:
+		reason = "loadedEarly"
+	default:
+		return
+	}
+
+	mod, conflictMod := modOfMixinConfig(config), modOfMixinConfig(conflictConfig)
+	targetName := target
+	if targetName == "" {
+		targetName = "its target class"
+	}
+	var b strings.Builder
+	switch {
+	case jerr.Class == linkageErrorClass:
+		fmt.Fprintf(&b, "The class %s is defined more than once, and the %s failed to apply to it. "+
+			"Two mods probably ship the same class.", target, describeMixin(mixin, mod))
+	case loadedEarly:
+		fmt.Fprintf(&b, "The %s could not apply to %s, since the class was loaded before the mixins were applied. "+
+			"Another mod probably loads the class too early.", describeMixin(mixin, mod), targetName)
+	default:
+		fmt.Fprintf(&b, "The %s failed to apply to %s.", describeMixin(mixin, mod), targetName)
+	}
+	if conflictConfig != "" {
+		conflict := "The " + describeMixin(conflictMixin, conflictMod)
+		if conflictMixin == "" {
+			conflict = "A mixin of " + conflictConfig
+			if conflictMod != "" {
+				conflict = "A mixin of the mod " + conflictMod
+			}
+		}
+		fmt.Fprintf(&b, " %s also changes the class, so the two mods are probably incompatible. "+
+			"Update both mods, or remove one of them.", conflict)
+	} else {
+		b.WriteString(" Update the mods, or remove one of the mods which change the same class.")
+	}
+	return &ErrorDesc{
+		ID:          "hardcoded.mixinConflict",
+		Category:    CategoryMixinConflict,
+		Error:       jerr.Class,
+		Message:     message,
+		Description: b.String(),
+		Solutions:   []int{ModConflictSolutionID},
+		Data: map[string]any{
+			"mod":            mod,
+			"config":         config,
+			"mixin":          mixin,
+			"target":         target,
+			"conflictMod":    conflictMod,
+			"conflictConfig": conflictConfig,
+			"conflictMixin":  conflictMixin,
+			"reason":         reason,
+		},
+	}, nil
+}
+
+// describeMixin names the mixin and its mod for the descriptions, e.g. `mixin MixinWindow of the mod sodium`
+func describeMixin(mixin string, mod string) string {
+	if mod == "" {
+		return "mixin " + mixin
+	}
+	return "mixin " + mixin + " of the mod " + mod
+}
+
+var (
+	nativeLibNotInPathRe   = regexp.MustCompile(`no (\S+) in java\.library\.path`)
+	nativeLibLocateRe      = regexp.MustCompile(`Failed to locate library: (\S+)`)
+	nativeLibArchRe        = regexp.MustCompile(`^(.+?): Can't load (?:IA |AMD |ARM )?\d+-bit \.(?:dll|so|dylib) on a .+ platform`)
+	nativeLibDependencyRe  = regexp.MustCompile(`^.+?: (\S+): cannot open shared object file`)
+	nativeLibCannotLoadRe  = regexp.MustCompile(`^Can't load library: (.+)$`)
+	nativeLibLoadFailureRe = regexp.MustCompile(`^(\S+\.(?:dll|so|dylib)): `)
+)
+
+// Examples:
+// ```
+// java.lang.UnsatisfiedLinkError: no lwjgl64 in java.library.path
+// java.lang.UnsatisfiedLinkError: Failed to locate library: lwjgl.dll
+// java.lang.UnsatisfiedLinkError: C:\Users\Steve\AppData\Local\Temp\lwjgl\lwjgl.dll: Can't load IA 32-bit .dll on a AMD 64-bit platform
+// java.lang.UnsatisfiedLinkError: /tmp/lwjglsteve/3.3.1/liblwjgl.so: libGL.so.1: cannot open shared object file: No such file or directory
+// ```
+func (a *Analyzer) hardCodedNativeLibraryCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+	if jerr.Class != unsatisfiedLinkErrorClass && !strings.Contains(jerr.Message, "java.library.path") {
+		return
+	}
+	message, _ := split(jerr.Message, '\n')
+	message = strings.TrimSpace(message)
+	var library, reason string
+	if matches := nativeLibArchRe.FindStringSubmatch(message); matches != nil {
+		library, reason = matches[1], "architecture"
+	} else if matches := nativeLibDependencyRe.FindStringSubmatch(message); matches != nil {
+		library, reason = matches[1], "dependency"
+	} else if matches := nativeLibNotInPathRe.FindStringSubmatch(message); matches != nil {
+		library, reason = matches[1], "missing"
+	} else if matches := nativeLibLocateRe.FindStringSubmatch(message); matches != nil {
+		library, reason = matches[1], "missing"
+	} else if matches := nativeLibCannotLoadRe.FindStringSubmatch(message); matches != nil {
+		library, reason = matches[1], "missing"
+	} else if matches := nativeLibLoadFailureRe.FindStringSubmatch(message); matches != nil {
+		library, reason = matches[1], "missing"
+	} else {
+		return
+	}
+	if i := strings.LastIndexAny(library, `/\`); i >= 0 {
+		library = library[i+1:]
+	}
+	var description string
+	switch reason {
+	case "architecture":
+		description = fmt.Sprintf("The native library %s does not match the architecture of the running Java. "+
+			"Install a Java build that matches your system (e.g. 64-bit Java on a 64-bit system) and select it in the launcher.", library)
+	case "dependency":
+		description = fmt.Sprintf("The system library %s required by the game could not be found. "+
+			"Install or update your GPU drivers (OpenGL), and make sure the Java architecture matches your system.", library)
+	default:
+		description = fmt.Sprintf("The native library %s could not be loaded. "+
+			"Reinstall the game version so the launcher extracts its natives again, update your GPU drivers, "+
+			"and make sure the Java architecture matches your system.", library)
+	}
+	return &ErrorDesc{
+		ID:          "hardcoded.nativeLibrary",
+		Category:    CategoryNativeCrash,
+		Error:       jerr.Class,
+		Message:     message,
+		Description: description,
+		Data: map[string]any{
+			"library": library,
+			"reason":  reason,
+		},
+	}, nil
+}
+
+var intelDriverRe = regexp.MustCompile(`^ig\w*icd(?:32|64)\.dll$`)
+
+// gpuVendorOf returns the vendor if the native library is an OpenGL driver, e.g. `atio6axx.dll` of AMD
+func gpuVendorOf(library string) string {
+	library = strings.ToLower(library)
+	switch {
+	case strings.HasPrefix(library, "atio") || strings.HasPrefix(library, "atig") || strings.HasPrefix(library, "radeonsi_dri"):
+		return "AMD"
+	case strings.HasPrefix(library, "nvoglv") || strings.HasPrefix(library, "libnvidia-"):
+		return "NVIDIA"
+	case intelDriverRe.MatchString(library) || strings.HasPrefix(library, "iris_dri"):
+		return "Intel"
+	}
+	return ""
+}
+
+// Examples, see scanNativeCrash:
+// ```
+// native/JVMFatalError: EXCEPTION_ACCESS_VIOLATION (0xc0000005) at pc=0x00007ffb8f4c1a2b, pid=1234, tid=5678
+// Problematic frame: C  [atio6axx.dll+0x1a2b]
+// native/ProcessExit: Process crashed with exit code -1073741819 (0xC0000005)
+// ```
+func (a *Analyzer) hardCodedNativeCrashCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+	switch jerr.Class {
+	case jvmFatalErrorClass:
+		signal, rest := split(jerr.Message, '\n')
+		var frame, library string
+		for _, line := range strings.Split(rest, "\n") {
+			if f, ok := strings.CutPrefix(line, "Problematic frame: "); ok {
+				frame = f
+			}
+		}
+		if matches := nativeFrameLibraryRe.FindStringSubmatch(frame); matches != nil {
+			library = matches[1]
+		}
+		vendor := gpuVendorOf(library)
+		var description string
+		switch {
+		case vendor != "":
+			description = fmt.Sprintf("The Java Virtual Machine crashed in the %s graphics driver (%s). "+
+				"Update the GPU driver from the vendor's website, and remove the shader mods or overlays if it still crashes.", vendor, library)
+		case library != "":
+			description = fmt.Sprintf("The Java Virtual Machine crashed in the native library %s. "+
+				"Remove or update the mod that ships it, update the GPU drivers and Java, "+
+				"and check the hs_err_pid*.log file for more details.", library)
+		default:
+			description = "The Java Virtual Machine crashed without a Java exception. " +
+				"Update Java and the GPU drivers, remove the mods with native code, " +
+				"and check the hs_err_pid*.log file for the problematic frame."
+		}
+		return &ErrorDesc{
+			ID:          "hardcoded.jvmFatalError",
+			Category:    CategoryNativeCrash,
+			Error:       jerr.Class,
+			Message:     signal,
+			Description: description,
+			Data: map[string]any{
+				"frame":   frame,
+				"library": library,
+				"vendor":  vendor,
+			},
+		}, nil
+	case processExitClass:
+		matches := processExitRe.FindStringSubmatch(jerr.Message)
+		if matches == nil {
+			return
+		}
+		code, _ := strconv.ParseInt(matches[2], 10, 32)
+		var description string
+		switch (uint32)(code) {
+		case 0xC0000005:
+			description = "The game crashed with an access violation (0xC0000005), usually in the graphics driver or a mod with native code. " +
+				"Update the GPU drivers, and remove the recently added mods that use native code or shaders."
+		case 0xCFFFFFFF:
+			description = "The game stopped responding and was closed by Windows. " +
+				"Wait longer while the game loads, or lower the render distance and remove the heavy mods."
+		case 137:
+			description = "The game was killed by the system, probably because the system ran out of memory. " +
+				"Lower the memory allocated to the game, or close the other programs."
+		default:
+			description = fmt.Sprintf("The game exited with code %d without a Java exception. "+
+				"Check the launcher log and the hs_err_pid*.log files in the game directory for a crash of the Java Virtual Machine, "+
+				"and update Java and the GPU drivers.", code)
+		}
+		return &ErrorDesc{
+			ID:          "hardcoded.processExit",
+			Category:    CategoryNativeCrash,
+			Error:       jerr.Class,
+			Message:     jerr.Message,
+			Description: description,
+			Data: map[string]any{
+				"code": (int)(code),
+			},
+		}, nil
+	}
+	return
+}
+
+var (
+	// 'void com.example.lib.Foo.bar(int)'
+	quotedSignatureRe = regexp.MustCompile(`'(?:\S+ )?([\w$/]+(?:\.[\w$/]+)+)\.([\w$<>]+)(\([^)]*\))?'`)
+	// com.example.lib.Foo.bar(I)V
+	bytecodeSignatureRe = regexp.MustCompile(`^([\w$/]+(?:\.[\w$/]+)+)\.([\w$<>]+)(\(\S*\)\S+)$`)
+	duplicateClassRe    = regexp.MustCompile(`duplicate class definition for (?:name: )?"?([\w$/.]+)`)
+	jarHintRe           = regexp.MustCompile(`\[([^\]\s:]+?\.jar)[^\]:]*(?::([^\]]*))?\]`)
+)
+
+// platformJarPrefixes are the jars of the game and mod loaders, they are not suspects of a library conflict
+var platformJarPrefixes = []string{
+	"minecraft", "client-", "server-", "forge-", "fmlcore", "fmlloader", "javafmllanguage", "lowcodelanguage", "mclanguage",
+	"neoforge-", "modlauncher", "securejarhandler", "bootstraplauncher", "eventbus", "fabric-loader", "quilt-loader",
+	"mixin", "sponge-mixin", "asm", "guava", "datafixerupper", "brigadier", "authlib", "netty",
+}
+
+// suspectJars collects the third party jars which appear in the stacktrace
+func suspectJars(st Stacktrace) (jars []string) {
+	seen := make(map[string]struct{})
+	for _, s := range st {
+		matches := jarHintRe.FindStringSubmatch(s.Raw)
+		if matches == nil {
+			continue
+		}
+		jar := matches[1]
+		if _, ok := seen[jar]; ok {
+			continue
+		}
+		seen[jar] = struct{}{}
+		lower := strings.ToLower(jar)
+		platform := false
+		for _, prefix := range platformJarPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				platform = true
+				break
+			}
+		}
+		if !platform {
+			jars = append(jars, jar)
+		}
+	}
+	return
+}
+
+// libraryRoot guesses the library of a class by its first three package segments
+func libraryRoot(class string) string {
+	pkg, _ := rsplit(class, '.')
+	if pkg == "" {
+		return class
+	}
+	parts := strings.SplitN(pkg, ".", 4)
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return strings.Join(parts, ".")
+}
+
+// Examples:
+// ```
+// java.lang.NoSuchMethodError: 'com.google.common.collect.ImmutableList com.google.common.collect.ImmutableList.of(java.lang.Object)'
+// java.lang.NoSuchMethodError: com.example.lib.Config.get(Ljava/lang/String;)Ljava/lang/Object;
+// java.lang.LinkageError: loader 'app' attempted duplicate class definition for com/example/lib/Config. (com.example.lib.Config is in unnamed module of loader 'app')
+// ```
+func (a *Analyzer) hardCodedLibraryConflictCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+	switch jerr.Class {
+	case noSuchMethodErrorClass, noSuchFieldErrorClass, linkageErrorClass:
+	default:
+		return
+	}
+	message, _ := split(jerr.Message, '\n')
+	message = strings.TrimSpace(message)
+	var class, member, signature string
+	if matches := quotedSignatureRe.FindStringSubmatch(message); matches != nil {
+		class, member, signature = matches[1], matches[2], matches[0]
+	} else if matches := bytecodeSignatureRe.FindStringSubmatch(message); matches != nil {
+		class, member, signature = matches[1], matches[2], matches[0]
+	} else if matches := duplicateClassRe.FindStringSubmatch(message); matches != nil {
+		class, signature = strings.TrimSuffix(matches[1], "."), matches[1]
+	} else {
+		return
+	}
+	signature = strings.Trim(signature, "'")
+	class = strings.ReplaceAll(class, "/", ".")
+	library := libraryRoot(class)
+	suspects := suspectJars(jerr.Stacktrace)
+
+	var b strings.Builder
+	if member != "" {
+		fmt.Fprintf(&b, "The member %s of %s from the library %s is not what the caller expects. ", member, class, library)
+	} else {
+		fmt.Fprintf(&b, "The class %s from the library %s is defined more than once. ", class, library)
+	}
+	b.WriteString("Two mods probably ship (shade) incompatible versions of the same library. " +
+		"Update the mods so they are built against the same library version, or remove one of them.")
+	if len(suspects) > 0 {
+		fmt.Fprintf(&b, " Suspects: %s", strings.Join(suspects, ", "))
+	}
+	return &ErrorDesc{
+		ID:          "hardcoded.libraryConflict",
+		Category:    CategoryVersionMismatch,
+		Error:       jerr.Class,
+		Message:     message,
+		Description: b.String(),
+		Data: map[string]any{
+			"class":     class,
+			"method":    member,
+			"signature": signature,
+			"library":   library,
+			"suspects":  suspects,
+		},
+	}, nil
+}
+
+// DependencyConstraint is an unmet dependency reported by the mod loader
+type DependencyConstraint struct {
+	Mod        string `json:"mod"`            // the mod which declares the dependency
+	Dependency string `json:"dependency"`     // the required mod
+	Requires   string `json:"requires"`       // the required version range
+	Have       string `json:"have,omitempty"` // the installed version, empty when it's missing
+}
+
+var (
+	fabricDependencyRe   = regexp.MustCompile(`Mod '[^']*' \(([\w.-]+)\) \S+ requires (?:version )?(.+?) of (?:mod )?(?:'[^']*' \(([\w.-]+)\)|([\w.-]+)), (?:but only the wrong version is present: ([^!\s]+)|which is missing)`)
+	forgeDependencyRe    = regexp.MustCompile(`Mod ID: '([^']+)', Requested by: '([^']+)', Expected range: '([^']+)', Actual version: '([^']+)'`)
+	forgeDependencyMsgRe = regexp.MustCompile(`Mod (\S+) requires (\S+) (.+?)\s+Currently, \S+ is (not installed|\S+)`)
+)
+
+func parseDependencyConstraints(message string) (constraints []DependencyConstraint) {
+	for _, m := range fabricDependencyRe.FindAllStringSubmatch(message, -1) {
+		dep := m[3]
+		if dep == "" {
+			dep = m[4]
+		}
+		constraints = append(constraints, DependencyConstraint{
+			Mod:        m[1],
+			Dependency: dep,
+			Requires:   m[2],
+			Have:       m[5],
+		})
+	}
+	for _, m := range forgeDependencyRe.FindAllStringSubmatch(message, -1) {
+		have := m[4]
+		if have == "[MISSING]" {
+			have = ""
+		}
+		constraints = append(constraints, DependencyConstraint{
+			Mod:        m[2],
+			Dependency: m[1],
+			Requires:   m[3],
+			Have:       have,
+		})
+	}
+	for _, m := range forgeDependencyMsgRe.FindAllStringSubmatch(message, -1) {
+		have := m[4]
+		if have == "not installed" {
+			have = ""
+		}
+		constraints = append(constraints, DependencyConstraint{
+			Mod:        m[1],
+			Dependency: m[2],
+			Requires:   m[3],
+			Have:       have,
+		})
+	}
+	return
+}
+
+// Examples:
+// ```
+// net.fabricmc.loader.impl.FormattedException: Mod resolution encountered an incompatible mod set!
+// Unmet dependency listing:
+// Mod 'Iris' (iris) 1.6.4 requires version 0.5.0 or later of mod 'Sodium' (sodium), but only the wrong version is present: 0.4.10!
+// Mod 'Mod Menu' (modmenu) 7.2.1 requires any version of fabric-api, which is missing!
+// ```
+// ```
+// Missing or unsupported mandatory dependencies:
+// Mod ID: 'flywheel', Requested by: 'create', Expected range: '[0.6.9,0.6.10)', Actual version: '0.6.8'
+// ```
+// ```
+// Mod create requires flywheel 1.18-0.6.8 or above
+// Currently, flywheel is not installed
+// ```
+func (a *Analyzer) hardCodedDependencyConstraintCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+	if !strings.Contains(jerr.Message, "requires") && !strings.Contains(jerr.Message, "Expected range:") {
+		return
+	}
+	constraints := parseDependencyConstraints(jerr.Message)
+	if len(constraints) == 0 {
+		return
+	}
+	category := CategoryMissingDependency
+	lines := make([]string, len(constraints))
+	for i, c := range constraints {
+		if c.Have == "" {
+			lines[i] = fmt.Sprintf("Install %s %s, it is required by %s.", c.Dependency, c.Requires, c.Mod)
+		} else {
+			category = CategoryVersionMismatch
+			lines[i] = fmt.Sprintf("%s requires %s %s, but %s is installed. Replace %s with a matching version, or remove %s.",
+				c.Mod, c.Dependency, c.Requires, c.Have, c.Dependency, c.Mod)
+		}
+	}
+	message, _ := split(jerr.Message, '\n')
+	return &ErrorDesc{
+		ID:          "hardcoded.dependencyConstraint",
+		Category:    category,
+		Error:       jerr.Class,
+		Message:     strings.TrimSpace(message),
+		Description: strings.Join(lines, "\n"),
+		Data: map[string]any{
+			"constraints": constraints,
+		},
+	}, nil
+}
+
+var (
+	// Starting Minecraft server on *:25565
+	// Failed to bind to /0.0.0.0:25565
+	listenAddressRe = regexp.MustCompile(`(?:\bon|\bto|\bat|address)\s+/?(\*|\[[0-9A-Fa-f:.]+\]|[\w.-]+)?:(\d{1,5})\b`)
+)
+
+// findListenAddress searches the address and port the server listens on in the message and the context
+func findListenAddress(jerr *JavaError) (address, port string) {
+	if matches := listenAddressRe.FindStringSubmatch(jerr.Message); matches != nil {
+		return matches[1], matches[2]
+	}
+	for i := len(jerr.Context) - 1; i >= 0; i-- {
+		if matches := listenAddressRe.FindStringSubmatch(jerr.Context[i]); matches != nil {
+			return matches[1], matches[2]
+		}
+	}
+	return
+}
+
+func bindFailureReason(je *JavaError) string {
+	message := strings.ToLower(je.Message)
+	isBind := je.Class == bindExceptionClass || strings.Contains(message, "bind(..) failed") || strings.Contains(message, "failed to bind")
+	if !isBind {
+		return ""
+	}
+	switch {
+	case strings.Contains(message, "permission denied") || strings.Contains(message, "access is denied") ||
+		strings.Contains(message, "access permissions"):
+		return "permission"
+	case strings.Contains(message, "cannot assign requested address") || strings.Contains(message, "requested address is not valid"):
+		return "unavailable"
+	default:
+		return "in_use"
+	}
+}
+
+// Examples:
+// ```
+// java.net.BindException: Address already in use
+// java.net.BindException: Address already in use: bind
+// io.netty.channel.unix.Errors$NativeIoException: bind(..) failed: Address already in use
+// java.net.BindException: Permission denied
+// java.net.BindException: Cannot assign requested address
+// ```
+// The error may be the cause of the reported error, so the whole cause chain is checked
+func (a *Analyzer) hardCodedBindCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+	var reason string
+	je := jerr
+	for ; je != nil; je = je.CausedBy {
+		if reason = bindFailureReason(je); reason != "" {
+			break
+		}
+	}
+	if je == nil {
+		return
+	}
+	address, port := findListenAddress(je)
+	if port == "" {
+		address, port = findListenAddress(jerr)
+	}
+	target := "the server port"
+	if port != "" {
+		target = "port " + port
+		if address != "" && address != "*" {
+			target = address + ":" + port
+		}
+	}
+	var description string
+	switch reason {
+	case "permission":
+		description = fmt.Sprintf("The server is not allowed to listen on %s. "+
+			"Ports below 1024 need administrator privileges on most systems, "+
+			"change server-port in server.properties to a higher port (e.g. 25565).", target)
+	case "unavailable":
+		description = fmt.Sprintf("The server cannot listen on %s because the address does not belong to this machine. "+
+			"Clear server-ip in server.properties, or set it to an address of this machine.", target)
+	default:
+		description = fmt.Sprintf("Another process is already listening on %s. "+
+			"Stop the other server (or the previous instance of this server which is still running), "+
+			"or change server-port in server.properties.", target)
+	}
+	message, _ := split(je.Message, '\n')
+	return &ErrorDesc{
+		ID:          "hardcoded.bindFailure",
+		Category:    CategoryNetworking,
+		Error:       je.Class,
+		Message:     strings.TrimSpace(message),
+		Description: description,
+		Data: map[string]any{
+			"address": address,
+			"port":    port,
+			"reason":  reason,
+		},
+	}, nil
+}
+
+var (
+	// matches `Couldn't load chunk [12, -5]`, `Failed to save chunk 12,-5` and `ChunkPos{x=12, z=-5}`
+	chunkPosRe   = regexp.MustCompile(`(?i)chunk(?:Pos)?\s*(?:\[|\{x=)?\s*(-?\d+)\s*,\s*(?:z=)?(-?\d+)`)
+	regionFileRe = regexp.MustCompile(`\br\.(-?\d+)\.(-?\d+)\.mca\b`)
+)
+
+// worldStorageFramePrefixes are the classes which read and write the world save
+var worldStorageFramePrefixes = []string{
+	"net.minecraft.nbt.NbtIo.",
+	"net.minecraft.nbt.CompressedStreamTools.",
+	"net.minecraft.world.level.chunk.storage.",
+	"net.minecraft.world.chunk.storage.",
+	"net.minecraft.world.level.storage.",
+}
+
+// worldCorruptionClasses are thrown when the data of a save file is broken
+var worldCorruptionClasses = []string{
+	"java.io.EOFException",
+	"java.io.UTFDataFormatException",
+	"java.util.zip.ZipException",
+	"java.util.zip.DataFormatException",
+	"net.minecraft.nbt.ReportedNbtException",
+	"net.minecraft.nbt.NbtFormatException",
+}
+
+// isWorldCorruption checks if the error is thrown while reading or writing a broken save file
+func isWorldCorruption(je *JavaError) bool {
+	if strings.Contains(je.Message, "NBT tag") && (strings.Contains(je.Message, "too big") || strings.Contains(je.Message, "too high complexity")) {
+		return true
+	}
+	if !slices.Contains(worldCorruptionClasses, je.Class) {
+		return false
+	}
+	for _, s := range je.Stacktrace {
+		for _, prefix := range worldStorageFramePrefixes {
+			if strings.HasPrefix(s.Class+".", prefix) {
+				return true
+			}
+		}
+	}
+	// the chunk storage may run on a worker thread, so the stacktrace may not have the storage frames,
+	// then the log line right before the error is checked
+	if len(je.Context) > 0 {
+		line := je.Context[len(je.Context)-1]
+		return chunkPosRe.MatchString(line) || regionFileRe.MatchString(line)
+	}
+	return false
+}
+
+// findCorruptedChunk searches the chunk and region coordinates in the messages of the errors and the log line before them
+func findCorruptedChunk(jerr *JavaError) (chunk []string, region string) {
+	var lines []string
+	if len(jerr.Context) > 0 {
+		lines = append(lines, jerr.Context[len(jerr.Context)-1])
+	}
+	for je := jerr; je != nil; je = je.CausedBy {
+		lines = append(lines, je.Message)
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		if chunk == nil {
+			if matches := chunkPosRe.FindStringSubmatch(line); matches != nil {
+				chunk = matches[1:3]
+			}
+		}
+		if region == "" {
+			region = regionFileRe.FindString(line)
+		}
+	}
+	if region == "" && chunk != nil {
+		// a region file contains 32x32 chunks
+		x, _ := strconv.Atoi(chunk[0])
+		z, _ := strconv.Atoi(chunk[1])
+		region = fmt.Sprintf("r.%d.%d.mca", x>>5, z>>5)
+	}
+	return
+}
+
+// Examples:
+// ```
+// [12:00:00] [Server thread/ERROR]: Couldn't load chunk [12, -5]
+// java.util.zip.ZipException: invalid distance too far back
+// at net.minecraft.nbt.NbtIo.read(NbtIo.java:98)
+// ```
+// ```
+// net.minecraft.ReportedException: Exception ticking world
+// ...
+// Caused by: java.io.EOFException
+// at net.minecraft.world.level.chunk.storage.RegionFile.getChunkDataInputStream(RegionFile.java:120)
+// ```
+// The corruption is usually the cause of the reported error, so the whole cause chain is checked
+func (a *Analyzer) hardCodedWorldCorruptionCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+	je := jerr
+	for ; je != nil; je = je.CausedBy {
+		if isWorldCorruption(je) {
+			break
+		}
+	}
+	if je == nil {
+		return
+	}
+	chunk, region := findCorruptedChunk(jerr)
+	target := "a chunk of the world"
+	if chunk != nil {
+		target = fmt.Sprintf("the chunk [%s, %s]", chunk[0], chunk[1])
+	}
+	if region != "" {
+		target += " in the region file " + region
+	}
+	data := map[string]any{
+		"region": region,
+	}
+	if chunk != nil {
+		x, _ := strconv.Atoi(chunk[0])
+		z, _ := strconv.Atoi(chunk[1])
+		data["chunkX"], data["chunkZ"] = x, z
+	}
+	message, _ := split(je.Message, '\n')
+	return &ErrorDesc{
+		ID:       "hardcoded.worldCorruption",
+		Category: CategoryWorldCorruption,
+		Error:    je.Class,
+		Message:  strings.TrimSpace(message),
+		Description: fmt.Sprintf("The world save is corrupted at %s, which is not fixed by changing mods. "+
+			"Stop the server and make a backup of the world first, "+
+			"then restore the region file from a backup, or remove the broken chunks with a tool like Region-Fixer or MCA Selector. "+
+			"Corruption is often caused by the game being killed or the disk being full while saving.", target),
+		Data: data,
+	}, nil
+}
+
+var (
+	classVersionRe = regexp.MustCompile(`^(\S+) has been compiled by a more recent version of the Java Runtime \(class file version (\d+)(?:\.\d+)?\), this version of the Java Runtime only recognizes class file versions up to (\d+)(?:\.\d+)?`)
+	// the message before Java 11
+	classVersionOldRe = regexp.MustCompile(`^(\S+) : Unsupported major\.minor version (\d+)(?:\.\d+)?`)
+)
+
+// javaVersionOfClassVersion maps the class file version to the Java major version, e.g. 52 to Java 8
+func javaVersionOfClassVersion(classVersion int) int {
+	return classVersion - 44
+}
+
+// Examples:
+// ```
+// java.lang.UnsupportedClassVersionError: com/example/mod/ExampleMod has been compiled by a more recent version of the Java Runtime (class file version 65.0), this version of the Java Runtime only recognizes class file versions up to 61.0
+// java.lang.UnsupportedClassVersionError: com/example/mod/ExampleMod : Unsupported major.minor version 52.0
+// ```
+// The running Java version is taken from the message, or the metadata if the message does not have it.
+// The error may be the cause of the reported error, so the whole cause chain is checked
+func (a *Analyzer) hardCodedJavaVersionCheck(jerr *JavaError, meta *LogMetadata) (desc *ErrorDesc, err error) {
+	var (
+		class                      string
+		classVersion, runningClass int
+	)
+	je := jerr
+	for ; je != nil; je = je.CausedBy {
+		if je.Class != unsupportedClassVersionClass {
+			continue
+		}
+		message, _ := split(je.Message, '\n')
+		if matches := classVersionRe.FindStringSubmatch(message); matches != nil {
+			class = matches[1]
+			classVersion, _ = strconv.Atoi(matches[2])
+			runningClass, _ = strconv.Atoi(matches[3])
+			break
+		}
+		if matches := classVersionOldRe.FindStringSubmatch(message); matches != nil {
+			class = matches[1]
+			classVersion, _ = strconv.Atoi(matches[2])
+			break
+		}
+	}
+	if je == nil {
+		return
+	}
+	class = strings.ReplaceAll(class, "/", ".")
+	required := javaVersionOfClassVersion(classVersion)
+	running := 0
+	if runningClass > 0 {
+		running = javaVersionOfClassVersion(runningClass)
+	} else if meta != nil {
+		running = meta.JavaVersion
+	}
+	runningDesc := "an older Java"
+	if running > 0 {
+		runningDesc = fmt.Sprintf("Java %d", running)
+	}
+	message, _ := split(je.Message, '\n')
+	return &ErrorDesc{
+		ID:       "hardcoded.javaVersion",
+		Category: CategoryVersionMismatch,
+		Error:    je.Class,
+		Message:  strings.TrimSpace(message),
+		Description: fmt.Sprintf("%s needs Java %d or newer, but the game is running on %s. "+
+			"Install Java %d and select it in the launcher, or use the version of the mod which supports %s.",
+			class, required, runningDesc, required, runningDesc),
+		Data: map[string]any{
+			"class":               class,
+			"classVersion":        classVersion,
+			"runningClassVersion": runningClass,
+			"requiredJavaVersion": required,
+			"runningJavaVersion":  running,
+		},
+	}, nil
+}
+
+// outOfMemoryVariant is a kind of OutOfMemoryError, the message tells which memory pool is exhausted
+type outOfMemoryVariant struct {
+	message     string
+	variant     string
+	description string
+}
+
+var outOfMemoryVariants = []outOfMemoryVariant{
+	{
+		message: "Java heap space",
+		variant: "heap",
+		description: "The game ran out of the memory allocated to Java. " +
+			"Increase the max memory in the launcher (the -Xmx argument, e.g. -Xmx4G for a modpack, but no more than about half of your RAM), " +
+			"lower the render distance, or remove some large mods and resource packs.",
+	},
+	{
+		message: "GC overhead limit exceeded",
+		variant: "gcOverhead",
+		description: "Java spent almost all of its time collecting garbage because the memory allocated to it is nearly full. " +
+			"Increase the max memory in the launcher (the -Xmx argument, e.g. -Xmx4G), and lower the render distance.",
+	},
+	{
+		message: "Metaspace",
+		variant: "metaspace",
+		description: "Java ran out of the memory for the loaded classes, which is separate from the heap set by -Xmx. " +
+			"Remove the -XX:MaxMetaspaceSize argument from the JVM arguments or raise it (e.g. -XX:MaxMetaspaceSize=1G), " +
+			"since a large modpack loads a lot of classes.",
+	},
+}
+
+// Examples:
+// ```
+// java.lang.OutOfMemoryError: Java heap space
+// java.lang.OutOfMemoryError: GC overhead limit exceeded
+// java.lang.OutOfMemoryError: Metaspace
+// ```
+// Each variant has its own description, since the advice depends on which memory is exhausted.
+// The error may be the cause of the reported error, so the whole cause chain is checked
+func (a *Analyzer) hardCodedOutOfMemoryCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+	for je := jerr; je != nil; je = je.CausedBy {
+		if je.Class != outOfMemoryErrorClass {
+			continue
+		}
+		message, _ := split(je.Message, '\n')
+		message = strings.TrimSpace(message)
+		for _, v := range outOfMemoryVariants {
+			if strings.HasPrefix(message, v.message) {
+				return &ErrorDesc{
+					ID:          "hardcoded.outOfMemory." + v.variant,
+					Category:    CategoryOutOfMemory,
+					Error:       je.Class,
+					Message:     v.message,
+					Description: v.description,
+					Data: map[string]any{
+						"variant": v.variant,
+					},
+				}, nil
+			}
+		}
+	}
+	return
+}
+
+const serverStartFailureMessage = "Failed to start the minecraft server"
+
+// Example:
+// ```
+// [12:00:00] [Server thread/ERROR]: Failed to start the minecraft server
+// java.lang.IllegalStateException: Failed to initialize server
+// ```
+func (a *Analyzer) hardCodedServerStartCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+	found := strings.Contains(jerr.Message, serverStartFailureMessage)
+	if !found && len(jerr.Context) > 0 {
+		found = strings.Contains(jerr.Context[len(jerr.Context)-1], serverStartFailureMessage)
+	}
+	if !found {
+		return
+	}
+	address, port := findListenAddress(jerr)
+	return &ErrorDesc{
+		ID:      "hardcoded.serverStartFailure",
+		Error:   jerr.Class,
+		Message: serverStartFailureMessage,
+		Description: "The dedicated server failed to start, which is not always caused by a mod. " +
+			"Check the error below it, and make sure eula.txt is accepted, server.properties is valid, " +
+			"the server has permission to write its folder, and no other server is using the same world or port.",
+		Data: map[string]any{
+			"address": address,
+			"port":    port,
+		},
+	}, nil
+}
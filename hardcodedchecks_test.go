@@ -0,0 +1,648 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"maps"
+	"strings"
+)
+
+func TestNativeLibraryCheck(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	datas := []struct {
+		Message  string
+		Library  string
+		Reason   string
+		Contains string
+	}{
+		{
+			Message:  "no lwjgl64 in java.library.path",
+			Library:  "lwjgl64",
+			Reason:   "missing",
+			Contains: "GPU drivers",
+		},
+		{
+			Message:  "Failed to locate library: lwjgl.dll",
+			Library:  "lwjgl.dll",
+			Reason:   "missing",
+			Contains: "Reinstall",
+		},
+		{
+			Message:  `C:\Users\Steve\AppData\Local\Temp\lwjgl\lwjgl.dll: Can't load IA 32-bit .dll on a AMD 64-bit platform`,
+			Library:  "lwjgl.dll",
+			Reason:   "architecture",
+			Contains: "64-bit Java",
+		},
+		{
+			Message:  "/tmp/lwjglsteve/3.3.1/liblwjgl.so: libGL.so.1: cannot open shared object file: No such file or directory",
+			Library:  "libGL.so.1",
+			Reason:   "dependency",
+			Contains: "GPU drivers",
+		},
+	}
+	for _, d := range datas {
+		jerr := &JavaError{
+			Class:   "java.lang.UnsatisfiedLinkError",
+			Message: d.Message,
+		}
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		if len(matched) != 1 || matched[0].Match != 1 {
+			t.Errorf("Expect exactly one hard coded match for %q, got %#v", d.Message, matched)
+			continue
+		}
+		desc := matched[0].ErrorDesc
+		if lib := desc.Data["library"]; lib != d.Library {
+			t.Errorf("Expect library == %q, got %v", d.Library, lib)
+		}
+		if reason := desc.Data["reason"]; reason != d.Reason {
+			t.Errorf("Expect reason == %q, got %v", d.Reason, reason)
+		}
+		if !strings.Contains(desc.Description, d.Contains) || !strings.Contains(desc.Description, d.Library) {
+			t.Errorf("Expect description to mention %q and %q, got %q", d.Contains, d.Library, desc.Description)
+		}
+	}
+}
+
+func TestLibraryConflictCheck(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	jerr := &JavaError{
+		Class:   "java.lang.NoSuchMethodError",
+		Message: "'com.electronwill.nightconfig.core.Config com.electronwill.nightconfig.core.Config.of(java.util.function.Supplier)'",
+		Stacktrace: Stacktrace{
+			{Raw: "at com.example.moda.ConfigLoader.load(ConfigLoader.java:31) ~[ModA-1.2.0.jar%23120!/:?]"},
+			{Raw: "at com.example.modb.Setup.init(Setup.java:12) ~[ModB-3.0.1.jar%23121!/:?]"},
+			{Raw: "at net.minecraftforge.fml.javafmlmod.FMLModContainer.constructMod(FMLModContainer.java:67) ~[javafmllanguage-1.18.2-40.2.17.jar%23103!/:?]"},
+		},
+	}
+	matched, err := analyzer.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 1 || matched[0].Match != 1 {
+		t.Fatalf("Expect exactly one hard coded match, got %#v", matched)
+	}
+	desc := matched[0].ErrorDesc
+	if expect := "com.electronwill.nightconfig.core.Config"; desc.Data["class"] != expect {
+		t.Errorf("Expect class == %q, got %v", expect, desc.Data["class"])
+	}
+	if expect := "of"; desc.Data["method"] != expect {
+		t.Errorf("Expect method == %q, got %v", expect, desc.Data["method"])
+	}
+	if expect := "com.electronwill.nightconfig.core.Config com.electronwill.nightconfig.core.Config.of(java.util.function.Supplier)"; desc.Data["signature"] != expect {
+		t.Errorf("Expect signature == %q, got %v", expect, desc.Data["signature"])
+	}
+	if expect := "com.electronwill.nightconfig"; desc.Data["library"] != expect {
+		t.Errorf("Expect library == %q, got %v", expect, desc.Data["library"])
+	}
+	suspects, _ := desc.Data["suspects"].([]string)
+	if len(suspects) != 2 || suspects[0] != "ModA-1.2.0.jar" || suspects[1] != "ModB-3.0.1.jar" {
+		t.Errorf("Expect suspects to be the two mod jars, got %v", suspects)
+	}
+	if !strings.Contains(desc.Description, "incompatible versions of the same library") || !strings.Contains(desc.Description, "ModA-1.2.0.jar") {
+		t.Errorf("Expect the library conflict guidance, got %q", desc.Description)
+	}
+	if captures := matched[0].Captures; captures["class"] != "com.electronwill.nightconfig.core.Config" || captures["method"] != "of" ||
+		captures["signature"] != desc.Data["signature"] {
+		t.Errorf("Expect the class, the method and the signature to be captured, got %v", captures)
+	}
+
+	jerr = &JavaError{
+		Class:   "java.lang.NoSuchMethodError",
+		Message: "com.example.lib.Config.get(Ljava/lang/String;)Ljava/lang/Object;",
+	}
+	if desc, _ = analyzer.HardCodedChecks(jerr); desc == nil {
+		t.Fatalf("Expect the bytecode signature to be recognized")
+	}
+	if desc.Data["class"] != "com.example.lib.Config" || desc.Data["method"] != "get" {
+		t.Errorf("Expect class com.example.lib.Config and method get, got %v and %v", desc.Data["class"], desc.Data["method"])
+	}
+}
+
+const mixinConflictLog = `[12:00:00] [main/INFO] [mixin/]: Compatibility level set to JAVA_17
+[12:00:01] [main/WARN] [mixin/]: Method overwrite conflict for renderLevel in examplemod.mixins.json:MixinLevelRenderer, previously written by othermod.mixins.json:LevelRendererMixin. Skipping method.
+[12:00:02] [main/ERROR] [mixin/]: Mixin apply for mod examplemod failed examplemod.mixins.json:MixinLevelRenderer from mod examplemod -> net.minecraft.client.renderer.LevelRenderer: org.spongepowered.asm.mixin.injection.throwables.InvalidInjectionException Critical injection failure
+[12:00:03] [main/FATAL]: Unreported exception thrown!
+org.spongepowered.asm.mixin.transformer.throwables.MixinTransformerError: An unexpected critical error was encountered
+	at org.spongepowered.asm.mixin.transformer.MixinProcessor.applyMixins(MixinProcessor.java:392)
+Caused by: org.spongepowered.asm.mixin.throwables.MixinApplyError: Mixin [MixinLevelRenderer] from phase [DEFAULT] in config [unknown] FAILED during APPLY
+	at org.spongepowered.asm.mixin.transformer.MixinProcessor.handleMixinError(MixinProcessor.java:636)
+	... 1 more
+[12:00:04] [main/INFO] [mixin/]: Mixing client.MixinMinecraft from sodium.mixins.json into net.minecraft.client.Minecraft
+[12:00:05] [main/ERROR] [mixin/]: Mixin apply failed lithium.mixins.json:MinecraftMixin -> net.minecraft.client.Minecraft: org.spongepowered.asm.mixin.throwables.MixinApplyError
+[12:00:06] [main/FATAL]: Unreported exception thrown!
+java.lang.LinkageError: loader 'app' attempted duplicate class definition for net/minecraft/client/Minecraft.
+	at java.lang.ClassLoader.defineClass1(Native Method)
+[12:00:07] [main/FATAL]: Unreported exception thrown!
+java.lang.LinkageError: loader 'app' attempted duplicate class definition for com/example/lib/Config.
+	at java.lang.ClassLoader.defineClass1(Native Method)
+`
+
+func TestMixinConflictCheck(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(mixinConflictLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 4 {
+		t.Fatalf("Expect 4 results, got %d", len(analysis.Results))
+	}
+	datas := []struct {
+		Index          int
+		Mod            string
+		Mixin          string
+		Target         string
+		ConflictMod    string
+		ConflictMixin  string
+		ConflictConfig string
+	}{
+		{1, "examplemod", "MixinLevelRenderer", "net.minecraft.client.renderer.LevelRenderer", "othermod", "LevelRendererMixin", "othermod.mixins.json"},
+		{2, "lithium", "MinecraftMixin", "net.minecraft.client.Minecraft", "sodium", "MixinMinecraft", "sodium.mixins.json"},
+	}
+	for _, d := range datas {
+		res := analysis.Results[d.Index]
+		if len(res.Matched) != 1 || res.Matched[0].ID != "hardcoded.mixinConflict" {
+			t.Errorf("Expect result %d to be the mixin conflict, got %#v", d.Index, res.Matched)
+			continue
+		}
+		if res.Category != CategoryMixinConflict {
+			t.Errorf("Expect result %d category == %q, got %q", d.Index, CategoryMixinConflict, res.Category)
+		}
+		data := res.Matched[0].ErrorDesc.Data
+		for key, expect := range map[string]string{
+			"mod":            d.Mod,
+			"mixin":          d.Mixin,
+			"target":         d.Target,
+			"conflictMod":    d.ConflictMod,
+			"conflictMixin":  d.ConflictMixin,
+			"conflictConfig": d.ConflictConfig,
+		} {
+			if data[key] != expect {
+				t.Errorf("Expect result %d %s == %q, got %v", d.Index, key, expect, data[key])
+			}
+		}
+		if desc := res.Matched[0].Description; !strings.Contains(desc, d.Mixin) || !strings.Contains(desc, d.ConflictMod) {
+			t.Errorf("Expect result %d description to name both mixins, got %q", d.Index, desc)
+		}
+	}
+	if res := analysis.Results[0]; len(res.Matched) != 0 {
+		t.Errorf("Expect the transformer error to be left to the database, got %#v", res.Matched)
+	}
+	if res := analysis.Results[3]; len(res.Matched) != 1 || res.Matched[0].ID != "hardcoded.libraryConflict" {
+		t.Errorf("Expect the duplicate class without a mixin failure to be the library conflict, got %#v", res.Matched)
+	}
+
+	// a plain injection failure without a conflict in the logs is left to the database
+	const injectionLog = `[12:00:00] [main/ERROR] [mixin/]: Mixin apply failed examplemod.mixins.json:MixinLevelRenderer -> net.minecraft.client.renderer.LevelRenderer: org.spongepowered.asm.mixin.injection.throwables.InvalidInjectionException Critical injection failure
+[12:00:01] [main/FATAL]: Unreported exception thrown!
+org.spongepowered.asm.mixin.throwables.MixinApplyError: Mixin [examplemod.mixins.json:MixinLevelRenderer] from phase [DEFAULT] in config [examplemod.mixins.json] FAILED during APPLY
+	at org.spongepowered.asm.mixin.transformer.MixinProcessor.handleMixinError(MixinProcessor.java:636)
+`
+	analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(injectionLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 || len(analysis.Results[0].Matched) != 0 {
+		t.Errorf("Expect the plain injection failure not to be a mixin conflict, got %#v", analysis.Results)
+	}
+
+	// Fabric logs the failures without the mixin logger name
+	const fabricLog = `[12:00:00] [main/WARN]: Method overwrite conflict for renderLevel in examplemod.mixins.json:MixinLevelRenderer, previously written by othermod.mixins.json:LevelRendererMixin. Skipping method.
+[12:00:01] [main/ERROR]: Mixin apply for mod examplemod failed examplemod.mixins.json:MixinLevelRenderer from mod examplemod -> net.minecraft.class_761: org.spongepowered.asm.mixin.injection.throwables.InvalidInjectionException Critical injection failure
+[12:00:02] [main/FATAL]: Unreported exception thrown!
+org.spongepowered.asm.mixin.throwables.MixinApplyError: Mixin [examplemod.mixins.json:MixinLevelRenderer] from phase [DEFAULT] in config [examplemod.mixins.json] FAILED during APPLY
+	at org.spongepowered.asm.mixin.transformer.MixinProcessor.handleMixinError(MixinProcessor.java:636)
+`
+	analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(fabricLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 || len(analysis.Results[0].Matched) != 1 {
+		t.Fatalf("Expect the Fabric mixin conflict to be recognized, got %#v", analysis.Results)
+	}
+	if data := analysis.Results[0].Matched[0].ErrorDesc.Data; data["conflictMod"] != "othermod" || data["target"] != "net.minecraft.class_761" {
+		t.Errorf("Expect the conflict with othermod on net.minecraft.class_761, got %v", data)
+	}
+
+	desc, _ := analyzer.HardCodedChecks(&JavaError{
+		Class:   "org.spongepowered.asm.mixin.transformer.throwables.MixinTargetAlreadyLoadedException",
+		Message: "Critical problem: examplemod.mixins.json:MixinLevelRenderer target net.minecraft.client.renderer.LevelRenderer was loaded too early",
+	})
+	if desc == nil {
+		t.Fatalf("Expect the target loaded too early to be recognized")
+	}
+	if desc.Data["mod"] != "examplemod" || desc.Data["target"] != "net.minecraft.client.renderer.LevelRenderer" || desc.Data["reason"] != "loadedEarly" {
+		t.Errorf("Expect mod examplemod, target net.minecraft.client.renderer.LevelRenderer and reason loadedEarly, got %v", desc.Data)
+	}
+}
+
+func TestDependencyConstraintCheck(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	datas := []struct {
+		Name    string
+		Error   *JavaError
+		Expect  []DependencyConstraint
+		Contain string
+	}{
+		{
+			Name: "fabric",
+			Error: &JavaError{
+				Class: "net.fabricmc.loader.impl.FormattedException",
+				Message: "Mod resolution encountered an incompatible mod set!\n" +
+					"A potential solution has been determined:\n" +
+					"\t - Replace 'Sodium' (sodium) 0.4.10 with version 0.5.0 or later.\n" +
+					"\t - Install fabric-api, any version.\n" +
+					"Unmet dependency listing:\n" +
+					"\t - Mod 'Iris' (iris) 1.6.4 requires version 0.5.0 or later of mod 'Sodium' (sodium), but only the wrong version is present: 0.4.10!\n" +
+					"\t - Mod 'Mod Menu' (modmenu) 7.2.1 requires any version of fabric-api, which is missing!",
+			},
+			Expect: []DependencyConstraint{
+				{Mod: "iris", Dependency: "sodium", Requires: "0.5.0 or later", Have: "0.4.10"},
+				{Mod: "modmenu", Dependency: "fabric-api", Requires: "any version"},
+			},
+			Contain: "Install fabric-api any version, it is required by modmenu.",
+		},
+		{
+			Name: "forge",
+			Error: &JavaError{
+				Class: "net.minecraftforge.fml.ModLoadingException",
+				Message: "Missing or unsupported mandatory dependencies:\n" +
+					"\tMod ID: 'flywheel', Requested by: 'create', Expected range: '[0.6.9,0.6.10)', Actual version: '0.6.8'\n" +
+					"\tMod ID: 'jei', Requested by: 'jeresources', Expected range: '[15.2,)', Actual version: '[MISSING]'",
+			},
+			Expect: []DependencyConstraint{
+				{Mod: "create", Dependency: "flywheel", Requires: "[0.6.9,0.6.10)", Have: "0.6.8"},
+				{Mod: "jeresources", Dependency: "jei", Requires: "[15.2,)"},
+			},
+			Contain: "create requires flywheel [0.6.9,0.6.10), but 0.6.8 is installed.",
+		},
+		{
+			Name: "forge message",
+			Error: &JavaError{
+				Class:   "net.minecraftforge.fml.ModLoadingException",
+				Message: "Mod create requires flywheel 1.18-0.6.8 or above\nCurrently, flywheel is not installed",
+			},
+			Expect: []DependencyConstraint{
+				{Mod: "create", Dependency: "flywheel", Requires: "1.18-0.6.8 or above"},
+			},
+			Contain: "Install flywheel 1.18-0.6.8 or above, it is required by create.",
+		},
+	}
+	for _, d := range datas {
+		matched, err := analyzer.DoError(d.Error)
+		if err != nil {
+			t.Fatalf("%s: DoError: %v", d.Name, err)
+		}
+		if len(matched) != 1 || matched[0].Match != 1 {
+			t.Errorf("%s: Expect exactly one hard coded match, got %#v", d.Name, matched)
+			continue
+		}
+		desc := matched[0].ErrorDesc
+		constraints, _ := desc.Data["constraints"].([]DependencyConstraint)
+		if len(constraints) != len(d.Expect) {
+			t.Errorf("%s: Expect %d constraints, got %#v", d.Name, len(d.Expect), constraints)
+			continue
+		}
+		for i, c := range constraints {
+			if c != d.Expect[i] {
+				t.Errorf("%s: Expect constraint %d == %#v, got %#v", d.Name, i, d.Expect[i], c)
+			}
+		}
+		if !strings.Contains(desc.Description, d.Contain) {
+			t.Errorf("%s: Expect description to contain %q, got %q", d.Name, d.Contain, desc.Description)
+		}
+	}
+}
+
+const bindFailureLog = `[12:00:00] [Server thread/INFO]: Starting Minecraft server on 0.0.0.0:25565
+[12:00:00] [Server thread/INFO]: Using epoll channel type
+[12:00:01] [Server thread/ERROR]: Encountered an unexpected exception
+java.lang.IllegalStateException: Failed to bind the server
+	at net.minecraft.server.network.ServerConnectionListener.startTcpServerListener(ServerConnectionListener.java:80)
+Caused by: java.net.BindException: Address already in use
+	at sun.nio.ch.Net.bind0(Native Method)
+	... 1 more
+`
+
+const serverStartFailureLog = `[12:00:00] [Server thread/INFO]: Starting Minecraft server on *:25565
+[12:00:05] [Server thread/ERROR]: Failed to start the minecraft server
+java.lang.IllegalStateException: Failed to load world data
+	at net.minecraft.server.Main.loadWorldData(Main.java:318)
+`
+
+func TestServerStartChecks(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(bindFailureLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 2 {
+		t.Fatalf("Expect 2 results, got %d", len(analysis.Results))
+	}
+	for _, res := range analysis.Results {
+		if len(res.Matched) != 1 || res.Matched[0].Match != 1 || res.Matched[0].ID != "hardcoded.bindFailure" {
+			t.Errorf("Expect %s to have exactly the bind failure match, got %#v", res.Error.Class, res.Matched)
+			continue
+		}
+		desc := res.Matched[0].ErrorDesc
+		if desc.Data["port"] != "25565" || desc.Data["address"] != "0.0.0.0" || desc.Data["reason"] != "in_use" {
+			t.Errorf("Expect port 25565 on 0.0.0.0 in use, got %v", desc.Data)
+		}
+		if !strings.Contains(desc.Description, "already listening on 0.0.0.0:25565") {
+			t.Errorf("Expect the port in use advice, got %q", desc.Description)
+		}
+		if expect := map[string]string{"address": "0.0.0.0", "port": "25565"}; !maps.Equal(res.Matched[0].Captures, expect) {
+			t.Errorf("Expect the captures %v, got %v", expect, res.Matched[0].Captures)
+		}
+	}
+
+	matched, err := analyzer.DoError(&JavaError{
+		Class:   "java.net.BindException",
+		Message: "Permission denied",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 1 || matched[0].ErrorDesc.Data["reason"] != "permission" || !strings.Contains(matched[0].ErrorDesc.Description, "administrator") {
+		t.Errorf("Expect the permission advice, got %#v", matched)
+	}
+
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:   "java.lang.IllegalStateException",
+				Message: "Failed to load world data",
+			},
+		},
+	}
+	analyzer = NewAnalyzer(db)
+	if analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(serverStartFailureLog)); err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+	}
+	matched = analysis.Results[0].Matched
+	if len(matched) != 2 {
+		t.Fatalf("Expect the database match and the server start advice, got %#v", matched)
+	}
+	if !sameDesc(matched[0].ErrorDesc, db.errors[0]) || matched[0].Match != 1 {
+		t.Errorf("Expect the database match to be kept, got %#v", matched[0])
+	}
+	if matched[1].ID != "hardcoded.serverStartFailure" || matched[1].Match >= matched[0].Match {
+		t.Errorf("Expect the server start advice as a lower fallback match, got %#v", matched[1])
+	}
+	if desc := matched[1].ErrorDesc; desc.Data["port"] != "25565" || !strings.Contains(desc.Description, "eula.txt") {
+		t.Errorf("Expect the server start advice with the port, got %#v", desc)
+	}
+	if captures := matched[1].Captures; captures["port"] != "25565" {
+		t.Errorf("Expect the port to be captured, got %v", captures)
+	}
+}
+
+const worldCorruptionLog = `[12:00:00] [Server thread/INFO]: Preparing spawn area: 0%
+[12:00:05] [Server thread/ERROR]: Encountered an unexpected exception
+net.minecraft.ReportedException: Exception ticking world
+	at net.minecraft.server.MinecraftServer.tickChildren(MinecraftServer.java:1058)
+	at net.minecraft.server.MinecraftServer.tickServer(MinecraftServer.java:942)
+Caused by: java.lang.RuntimeException: Couldn't load chunk [-37, 70]
+	at net.minecraft.server.level.ChunkMap.lambda$scheduleChunkLoad$14(ChunkMap.java:612)
+Caused by: java.io.EOFException
+	at java.io.DataInputStream.readFully(DataInputStream.java:203)
+	at net.minecraft.nbt.NbtIo.read(NbtIo.java:143)
+	at net.minecraft.world.level.chunk.storage.RegionFileStorage.read(RegionFileStorage.java:58)
+	... 2 more
+[12:00:10] [Server thread/ERROR]: Couldn't load chunk [3, 4]
+java.util.zip.ZipException: invalid distance too far back
+	at java.util.zip.InflaterInputStream.read(InflaterInputStream.java:165)
+	at java.io.DataInputStream.readByte(DataInputStream.java:271)
+[12:00:12] [Server thread/ERROR]: Failed to save level
+java.io.EOFException
+	at java.io.DataInputStream.readFully(DataInputStream.java:203)
+	at com.example.mod.Config.load(Config.java:10)
+`
+
+func TestWorldCorruptionCheck(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(worldCorruptionLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 5 {
+		t.Fatalf("Expect 5 results, got %d", len(analysis.Results))
+	}
+	datas := []struct {
+		Index  int
+		ChunkX int
+		ChunkZ int
+		Region string
+	}{
+		{0, -37, 70, "r.-2.2.mca"},
+		{1, -37, 70, "r.-2.2.mca"},
+		{3, 3, 4, "r.0.0.mca"},
+	}
+	for _, d := range datas {
+		res := analysis.Results[d.Index]
+		if len(res.Matched) != 1 || res.Matched[0].ID != "hardcoded.worldCorruption" {
+			t.Errorf("Expect %s to match the world corruption check, got %#v", res.Error.Class, res.Matched)
+			continue
+		}
+		if res.Category != CategoryWorldCorruption {
+			t.Errorf("Expect category %q, got %q", CategoryWorldCorruption, res.Category)
+		}
+		desc := res.Matched[0].ErrorDesc
+		if desc.Data["chunkX"] != d.ChunkX || desc.Data["chunkZ"] != d.ChunkZ || desc.Data["region"] != d.Region {
+			t.Errorf("Expect chunk [%d, %d] in %s, got %v", d.ChunkX, d.ChunkZ, d.Region, desc.Data)
+		}
+		if !strings.Contains(desc.Description, "backup") || !strings.Contains(desc.Description, d.Region) {
+			t.Errorf("Expect the recovery guidance for %s, got %q", d.Region, desc.Description)
+		}
+	}
+	if res := analysis.Results[2]; len(res.Matched) != 1 || res.Matched[0].ID != "hardcoded.worldCorruption" {
+		t.Errorf("Expect the root cause to match the world corruption check without the coordinates, got %#v", res.Matched)
+	}
+	if res := analysis.Results[4]; len(res.Matched) != 0 {
+		t.Errorf("Expect an EOFException outside of the world storage not to match, got %#v", res.Matched)
+	}
+}
+
+const javaVersionLog = `[12:00:00] [main/ERROR]: Failed to load mod class
+java.lang.RuntimeException: Failed to load class com.example.mod.ExampleMod
+	at net.fabricmc.loader.impl.entrypoint.EntrypointUtils.invoke0(EntrypointUtils.java:47)
+Caused by: java.lang.UnsupportedClassVersionError: com/example/mod/ExampleMod has been compiled by a more recent version of the Java Runtime (class file version 65.0), this version of the Java Runtime only recognizes class file versions up to 52.0
+	at java.lang.ClassLoader.defineClass1(Native Method)
+	... 1 more
+`
+
+func TestJavaVersionCheck(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(javaVersionLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 2 {
+		t.Fatalf("Expect 2 results, got %d", len(analysis.Results))
+	}
+	for _, res := range analysis.Results {
+		if len(res.Matched) != 1 || res.Matched[0].ID != "hardcoded.javaVersion" {
+			t.Errorf("Expect %s to match the Java version check, got %#v", res.Error.Class, res.Matched)
+			continue
+		}
+		if res.Category != CategoryVersionMismatch {
+			t.Errorf("Expect category %q, got %q", CategoryVersionMismatch, res.Category)
+		}
+		desc := res.Matched[0].ErrorDesc
+		if desc.Data["classVersion"] != 65 || desc.Data["requiredJavaVersion"] != 21 || desc.Data["runningJavaVersion"] != 8 ||
+			desc.Data["class"] != "com.example.mod.ExampleMod" {
+			t.Errorf("Expect class version 65 to require Java 21 while running Java 8, got %v", desc.Data)
+		}
+		if expect := "com.example.mod.ExampleMod needs Java 21 or newer, but the game is running on Java 8."; !strings.HasPrefix(desc.Description, expect) {
+			t.Errorf("Expect the guidance %q, got %q", expect, desc.Description)
+		}
+		if expect := map[string]string{
+			"class":               "com.example.mod.ExampleMod",
+			"classVersion":        "65",
+			"runningClassVersion": "52",
+			"requiredJavaVersion": "21",
+			"runningJavaVersion":  "8",
+		}; !maps.Equal(res.Matched[0].Captures, expect) {
+			t.Errorf("Expect the captures %v, got %v", expect, res.Matched[0].Captures)
+		}
+	}
+
+	jerr := &JavaError{
+		Class:   "java.lang.UnsupportedClassVersionError",
+		Message: "com/example/mod/ExampleMod : Unsupported major.minor version 61.0",
+	}
+	for _, d := range []struct {
+		Meta    *LogMetadata
+		Running any
+		Expect  string
+	}{
+		{nil, 0, "needs Java 17 or newer, but the game is running on an older Java."},
+		{&LogMetadata{JavaVersion: 8}, 8, "needs Java 17 or newer, but the game is running on Java 8."},
+	} {
+		matched, err := analyzer.DoErrorWithMetadata(jerr, d.Meta)
+		if err != nil {
+			t.Fatalf("DoErrorWithMetadata: %v", err)
+		}
+		if len(matched) != 1 || matched[0].ID != "hardcoded.javaVersion" {
+			t.Fatalf("Expect the Java version check, got %#v", matched)
+		}
+		desc := matched[0].ErrorDesc
+		if desc.Data["requiredJavaVersion"] != 17 || desc.Data["runningJavaVersion"] != d.Running {
+			t.Errorf("Expect Java 17 required while running %v, got %v", d.Running, desc.Data)
+		}
+		if captures := matched[0].Captures; captures["classVersion"] != "61" || captures["requiredJavaVersion"] != "17" {
+			t.Errorf("Expect the class version 61 and Java 17 to be captured, got %v", captures)
+		}
+		if !strings.Contains(desc.Description, d.Expect) {
+			t.Errorf("Expect the guidance %q, got %q", d.Expect, desc.Description)
+		}
+	}
+}
+
+func TestOutOfMemoryCheck(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	for _, d := range []struct {
+		Message string
+		ID      string
+		Contain string
+	}{
+		{"Java heap space", "hardcoded.outOfMemory.heap", "-Xmx"},
+		{"GC overhead limit exceeded", "hardcoded.outOfMemory.gcOverhead", "-Xmx"},
+		{"Metaspace", "hardcoded.outOfMemory.metaspace", "MaxMetaspaceSize"},
+	} {
+		matched, err := analyzer.DoError(&JavaError{
+			Class:   "java.lang.OutOfMemoryError",
+			Message: d.Message,
+		})
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		if len(matched) != 1 || matched[0].ID != d.ID || matched[0].Match != 1 {
+			t.Fatalf("Expect %s to match %q with 1, got %#v", d.Message, d.ID, matched)
+		}
+		desc := matched[0].ErrorDesc
+		if desc.Category != CategoryOutOfMemory {
+			t.Errorf("Expect category %q, got %q", CategoryOutOfMemory, desc.Category)
+		}
+		if !strings.Contains(desc.Description, d.Contain) {
+			t.Errorf("Expect the guidance of %s contains %q, got %q", d.Message, d.Contain, desc.Description)
+		}
+	}
+
+	jerr := &JavaError{
+		Class:   "net.minecraft.ReportedException",
+		Message: "Ticking entity",
+		CausedBy: &JavaError{
+			Class:   "java.lang.OutOfMemoryError",
+			Message: "Java heap space",
+		},
+	}
+	if desc, _ := analyzer.HardCodedChecks(jerr); desc == nil || desc.ID != "hardcoded.outOfMemory.heap" {
+		t.Errorf("Expect the OutOfMemoryError cause to be recognized, got %#v", desc)
+	}
+	if desc, _ := analyzer.HardCodedChecks(&JavaError{Class: "java.lang.OutOfMemoryError", Message: "Direct buffer memory"}); desc != nil {
+		t.Errorf("Expect an unknown variant is left to the database, got %#v", desc)
+	}
+
+	dbDesc := &ErrorDesc{Error: "java.lang.OutOfMemoryError", Message: "Java heap space", Solutions: []int{1}}
+	analyzer = NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{dbDesc}})
+	matched, err := analyzer.DoError(&JavaError{Class: "java.lang.OutOfMemoryError", Message: "Java heap space"})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 2 || matched[0].ID != "hardcoded.outOfMemory.heap" || matched[1].ErrorDesc.Message != dbDesc.Message {
+		t.Errorf("Expect the hard coded description leads the database match, got %#v", matched)
+	}
+	analyzer.MaxResults = 1
+	if matched, _ = analyzer.DoError(&JavaError{Class: "java.lang.OutOfMemoryError", Message: "Java heap space"}); len(matched) != 1 || matched[0].ID != "hardcoded.outOfMemory.heap" {
+		t.Errorf("Expect MaxResults keeps the hard coded description, got %#v", matched)
+	}
+}
+
+func TestAddHardCodedRule(t *testing.T) {
+	const message = "Shaders are not compatible with the chunk culling of ExamplePack"
+	fix := &ErrorDesc{ID: "pack.shaders", Description: "Disable the chunk culling in the pack settings"}
+	analyzer := NewAnalyzer(&memErrorDB{
+		errors: []*ErrorDesc{{Error: "java.lang.IllegalStateException", Message: message}},
+	})
+	var calls []string
+	analyzer.AddHardCodedRule(func(jerr *JavaError) (*ErrorDesc, bool) {
+		calls = append(calls, "first")
+		return fix, strings.Contains(jerr.Message, "ExamplePack")
+	})
+	analyzer.AddHardCodedRule(func(jerr *JavaError) (*ErrorDesc, bool) {
+		calls = append(calls, "second")
+		return &ErrorDesc{ID: "pack.any"}, true
+	})
+
+	matched, err := analyzer.DoError(&JavaError{Class: "java.lang.IllegalStateException", Message: message})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 1 || !sameDesc(matched[0].ErrorDesc, fix) || matched[0].Match != 1 {
+		t.Fatalf("Expect only the first rule to match with 1, got %#v", matched)
+	}
+	if len(calls) != 1 {
+		t.Errorf("Expect the rules after the first match are not called, got %v", calls)
+	}
+
+	// the built-in rules are checked first
+	calls = nil
+	if matched, err = analyzer.DoError(&JavaError{Class: "java.lang.OutOfMemoryError", Message: "Metaspace"}); err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) == 0 || matched[0].ID != "hardcoded.outOfMemory.metaspace" || len(calls) != 0 {
+		t.Errorf("Expect the built-in rule to match before the added rules, got %#v and %v", matched, calls)
+	}
+
+	if desc, _ := NewAnalyzer(&memErrorDB{}).HardCodedChecks(&JavaError{Class: "java.lang.IllegalStateException", Message: message}); desc != nil {
+		t.Errorf("Expect the rules are not shared between the analyzers, got %#v", desc)
+	}
+}
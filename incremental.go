@@ -0,0 +1,123 @@
+package mcla
+
+import (
+	"bytes"
+	"context"
+	"errors"
+)
+
+// IncrementalAnalysis analyzes a log which is appended piece by piece, e.g. when the user is pasting it.
+// Only the lines of the last incomplete error are scanned again on each Append,
+// and an error is not reported until a line after it shows it's complete.
+// It's not safe for concurrent use
+type IncrementalAnalysis struct {
+	a        *Analyzer
+	recorder *logRecorder
+	buf      []byte    // the lines of the incomplete error, and the trailing partial line
+	base     int       // how many lines are before buf
+	baseOff  int64     // how many bytes are before buf
+	recorded int       // how many bytes of buf are written to the recorder
+	state    scanState // the scanner state at the start of buf
+	results  []*ErrorResult
+	finished bool
+	cache    *similarityCache
+}
+
+// NewIncrementalAnalysis creates an IncrementalAnalysis
+func (a *Analyzer) NewIncrementalAnalysis() *IncrementalAnalysis {
+	return &IncrementalAnalysis{
+		a:        a,
+		recorder: a.newLogRecorder(),
+		cache:    newSimilarityCache(a.SimilarityCacheSize),
+	}
+}
+
+// Results returns all results reported so far
+func (ia *IncrementalAnalysis) Results() []*ErrorResult {
+	return ia.results
+}
+
+// Append adds the text to the end of the log, and returns the results of the errors which are completed by it
+func (ia *IncrementalAnalysis) Append(text []byte) (results []*ErrorResult, err error) {
+	if ia.finished {
+		return nil, errors.New("mcla: append to a finished incremental analysis")
+	}
+	ia.buf = append(ia.buf, text...)
+	end := bytes.LastIndexByte(ia.buf, '\n') + 1
+	if end == 0 {
+		return
+	}
+	ia.recorder.Write(ia.buf[ia.recorded:end])
+	ia.recorded = end
+	return ia.scan(ia.buf[:end], true)
+}
+
+// Finish marks the end of the log, and returns the results of the remaining errors
+func (ia *IncrementalAnalysis) Finish() (results []*ErrorResult, err error) {
+	if ia.finished {
+		return
+	}
+	ia.finished = true
+	defer ia.recorder.Close()
+	ia.recorder.Write(ia.buf[ia.recorded:])
+	ia.recorded = len(ia.buf)
+	return ia.scan(ia.buf, false)
+}
+
+func (ia *IncrementalAnalysis) scan(data []byte, hold bool) (results []*ErrorResult, err error) {
+	opts := &scanOptions{
+		collapseRepeated: ia.a.CollapseRepeatedLines,
+		holdAtEOF:        hold,
+		keepANSI:         ia.a.KeepANSI,
+		state:            &ia.state,
+	}
+	err = scanJavaErrors(bytes.NewReader(data), opts, func(jerr *JavaError) error {
+		for _, je := range errorChain(jerr) {
+			je.LineNo += ia.base
+			je.EndLineNo += ia.base
+			je.Offset += ia.baseOff
+			je.EndOffset += ia.baseOff
+		}
+		chain, err := ia.a.analyzeChain(context.Background(), jerr, nil, ia.cache, ia.recorder.logs)
+		if err != nil {
+			return err
+		}
+		for _, res := range chain {
+			if ia.a.ResultFilter != nil && !ia.a.ResultFilter(res) {
+				continue
+			}
+			results = append(results, res)
+		}
+		return nil
+	})
+	ia.results = append(ia.results, results...)
+	resume := countLines(data) + 1
+	if ie, ok := err.(*incompleteErr); ok {
+		err, resume = nil, ie.LineNo
+	}
+	if err != nil || !hold {
+		return
+	}
+	ia.drop(resume - 1)
+	return
+}
+
+// drop removes the first n lines from buf
+func (ia *IncrementalAnalysis) drop(n int) {
+	off := 0
+	for i := 0; i < n; i++ {
+		j := bytes.IndexByte(ia.buf[off:], '\n')
+		if j < 0 {
+			break
+		}
+		off += j + 1
+	}
+	ia.buf = ia.buf[off:]
+	ia.base += n
+	ia.baseOff += (int64)(off)
+	ia.recorded -= off
+}
+
+func countLines(data []byte) int {
+	return bytes.Count(data, []byte{'\n'})
+}
@@ -0,0 +1,88 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+)
+
+func TestIncrementalAnalysis(t *testing.T) {
+	log := multiErrorLog + recoveredWarningsLog
+	expect, err := NewAnalyzer(&memErrorDB{}).AnalyzeLog(context.Background(), strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+
+	for _, size := range []int{1, 7, 64, len(log)} {
+		ia := NewAnalyzer(&memErrorDB{}).NewIncrementalAnalysis()
+		var (
+			got           []*ErrorResult
+			firstReported = -1
+		)
+		for i := 0; i < len(log); i += size {
+			results, err := ia.Append(([]byte)(log[i:min(i+size, len(log))]))
+			if err != nil {
+				t.Fatalf("size %d: Append: %v", size, err)
+			}
+			if len(results) > 0 && firstReported == -1 {
+				firstReported = i
+			}
+			got = append(got, results...)
+		}
+		results, err := ia.Finish()
+		if err != nil {
+			t.Fatalf("size %d: Finish: %v", size, err)
+		}
+		got = append(got, results...)
+
+		if size < len(log) && (firstReported == -1 || firstReported > len(multiErrorLog)) {
+			t.Errorf("size %d: Expect the first error to be reported before the log is fully appended, got at %d", size, firstReported)
+		}
+		if len(got) != len(expect.Results) || len(ia.Results()) != len(got) {
+			t.Errorf("size %d: Expect %d results, got %d", size, len(expect.Results), len(got))
+			continue
+		}
+		for i, res := range got {
+			e := expect.Results[i].Error
+			if res.Error.Class != e.Class || res.Error.LineNo != e.LineNo || res.Error.Message != e.Message {
+				t.Errorf("size %d: Expect result %d to be %s at line %d, got %s at line %d", size, i, e.Class, e.LineNo, res.Error.Class, res.Error.LineNo)
+			}
+			if res.Error.Level != e.Level || strings.Join(res.Error.Context, "\n") != strings.Join(e.Context, "\n") {
+				t.Errorf("size %d: Expect result %d to have the same level and context, got %q %q, expect %q %q", size, i, res.Error.Level, res.Error.Context, e.Level, e.Context)
+			}
+		}
+	}
+}
+
+func TestIncrementalAnalysisCompletesPartialError(t *testing.T) {
+	ia := NewAnalyzer(&memErrorDB{}).NewIncrementalAnalysis()
+	results, err := ia.Append([]byte("[12:00:00] [main/ERROR]: Failed\njava.lang.IllegalStateException: Failed to create model\n\tat net.minecraft.client.Minecraft.<init>(Minecraft.java:100)\n"))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("Expect the error to be held until it's complete, got %d results", len(results))
+	}
+	results, err = ia.Append([]byte("Caused by: java.lang.NullPointerException: null\n\tat com.example.mod.Foo.bar(Foo.java:42)\n"))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("Expect the error to be held until it's complete, got %d results", len(results))
+	}
+	results, err = ia.Append([]byte("[12:00:01] [main/INFO]: Stopping!\n"))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expect the completed error and its cause, got %d results", len(results))
+	}
+	if results[0].Error.CausedBy != results[1].Error || results[1].Error.Class != "java.lang.NullPointerException" {
+		t.Errorf("Expect the cause appended later to be a part of the error, got %#v", results[0].Error)
+	}
+	if results, err = ia.Finish(); err != nil || len(results) != 0 {
+		t.Errorf("Expect no more results, got %d (%v)", len(results), err)
+	}
+}
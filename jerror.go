@@ -1,9 +1,14 @@
 package mcla
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -11,27 +16,80 @@ var (
 	stackInfoMatcher = regexp.MustCompile(`^\s*at\s+(?:.+/)?([\w\d$_]+(?:\.[\w\d$_]+)+)\.([\w\d$_<>]+)(?:\s*\((.+)\))?`)
 )
 
+const (
+	// maxMessageLines limits how many lines can be taken as the message of an error before its stacktrace,
+	// so a plain log line that looks like a class name cannot swallow the rest of the log
+	maxMessageLines = 64
+	// maxStackFrames limits how many frames are kept in a stacktrace, the frames after it are skipped
+	maxStackFrames = 1024
+	// maxContextLines is how many non-empty log lines before an error are kept as its context
+	maxContextLines = 3
+)
+
 type (
 	JavaError struct {
 		Class      string     `json:"class"`
 		Message    string     `json:"message"`
 		Stacktrace Stacktrace `json:"stacktrace"`
-		CausedBy   *JavaError `json:"causedBy"`
+		// Elided is the count of the frames omitted by the `... n more` line, they are same as the enclosing error's
+		Elided   int        `json:"elided,omitempty"`
+		CausedBy *JavaError `json:"causedBy"`
+		// Suppressed are the exceptions printed in the `Suppressed: ` blocks, e.g. the failures of closing resources
+		Suppressed []*JavaError `json:"suppressed,omitempty"`
 
 		// extra infos
 		LineNo int `json:"lineNo"` // which line did the error start
+		// EndLineNo is the last line of the error, including its causes and suppressed errors
+		EndLineNo int `json:"endLineNo,omitempty"`
+		// Offset and EndOffset are the byte offsets of the start of LineNo and the end of EndLineNo in the log
+		Offset    int64     `json:"offset,omitempty"`
+		EndOffset int64     `json:"endOffset,omitempty"`
+		Time      time.Time `json:"time"` // the time of the last log line before the error, a zero time is omitted, see MarshalJSON
+		// Context is the last few log lines before the error, the causes share the context of the top level error
+		Context []string `json:"context,omitempty"`
+		// Level is the log level of the last log line before the error, e.g. WARN or ERROR
+		Level string `json:"level,omitempty"`
+		// Truncated reports the error reaches the end of the log, so its stacktrace may be cut off
+		Truncated bool `json:"truncated,omitempty"`
 	}
 
 	StackInfo struct {
 		Raw    string `json:"raw"`
 		Class  string `json:"class"`
 		Method string `json:"method"`
+		File   string `json:"file,omitempty"`
+		Line   int    `json:"line,omitempty"`
+		// Jar and JarVersion are from the hint after the frame, e.g. `~[examplemod-1.0.jar%23190!/:1.0]`
+		Jar        string `json:"jar,omitempty"`
+		JarVersion string `json:"jarVersion,omitempty"`
 	}
 
 	// Stacktrace:
 	Stacktrace []StackInfo
 )
 
+// MarshalJSON encodes the error by the hand written encoder, which omits the zero Time
+func (je *JavaError) MarshalJSON() ([]byte, error) {
+	return je.appendJSON(nil)
+}
+
+// Fingerprint identifies an error by its class, the first line of its message and its top stack frame
+func (je *JavaError) Fingerprint() string {
+	var b strings.Builder
+	msg, _ := split(je.Message, '\n')
+	b.WriteString(je.Class)
+	b.WriteString(": ")
+	b.WriteString(strings.TrimSpace(msg))
+	if len(je.Stacktrace) > 0 {
+		top := je.Stacktrace[0]
+		b.WriteString(" @ ")
+		b.WriteString(top.Class)
+		b.WriteByte('.')
+		b.WriteString(top.Method)
+	}
+	return b.String()
+}
+
 func parseStackInfoFrom(line string) (s StackInfo, ok bool) {
 	res := stackInfoMatcher.FindStringSubmatch(line)
 	if res == nil {
@@ -40,18 +98,28 @@ func parseStackInfoFrom(line string) (s StackInfo, ok bool) {
 	s.Raw = line
 	s.Class = res[1]
 	s.Method = res[2]
+	if matches := frameSourceRe.FindStringSubmatch(line); matches != nil {
+		s.File = matches[1]
+		s.Line, _ = strconv.Atoi(matches[2])
+	}
+	if matches := jarHintRe.FindStringSubmatch(line); matches != nil {
+		s.Jar = matches[1]
+		if matches[2] != "?" {
+			s.JarVersion = matches[2]
+		}
+	}
 	ok = true
 	return
 }
 
-func parseStacktrace(sc *lineScanner) (st Stacktrace) {
+func parseStacktrace(sc *lineScanner) (st Stacktrace, elided int) {
 	if !sc.Scan() {
 		return
 	}
 	return parseStacktrace0(sc)
 }
 
-func parseStacktrace0(sc *lineScanner) (st Stacktrace) {
+func parseStacktrace0(sc *lineScanner) (st Stacktrace, elided int) {
 	var (
 		info StackInfo
 		ok   bool
@@ -59,14 +127,22 @@ func parseStacktrace0(sc *lineScanner) (st Stacktrace) {
 	for {
 		line := sc.Text()
 		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "... ") && strings.HasSuffix(line, " more") {
-			sc.Scan() // move to the next line
-			return
+		if n, ok := strings.CutPrefix(line, "... "); ok {
+			if n, ok := strings.CutSuffix(n, " more"); ok {
+				elided, _ = strconv.Atoi(n)
+				sc.Scan() // move to the next line
+				return
+			}
 		}
 		if info, ok = parseStackInfoFrom(line); !ok {
+			if len(st) > 0 && sc.skipInterleaved(isContinuation) {
+				continue
+			}
 			return
 		}
-		st = append(st, info)
+		if len(st) < maxStackFrames {
+			st = append(st, info)
+		}
 		if !sc.Scan() {
 			return
 		}
@@ -77,10 +153,13 @@ func parseJavaError(sc *lineScanner) (je *JavaError) {
 	if !sc.Scan() {
 		return
 	}
-	return parseJavaError0(sc.Text(), sc)
+	line := sc.Text()
+	return parseJavaError0(strings.TrimSpace(line), indentOf(line), -1, sc)
 }
 
-func parseJavaError0(line string, sc *lineScanner) (je *JavaError) {
+// parseJavaError0 parses the error starts at the current line, indent is the indentation of the line.
+// outer is the indentation of the error which suppressed it, or -1 if the error is not suppressed
+func parseJavaError0(line string, indent int, outer int, sc *lineScanner) (je *JavaError) {
 	je = new(JavaError)
 	i := strings.IndexByte(line, ':')
 	if i == -1 {
@@ -88,79 +167,252 @@ func parseJavaError0(line string, sc *lineScanner) (je *JavaError) {
 	} else {
 		je.Class, je.Message = line[:i], strings.TrimSpace(line[i+1:])
 	}
-	je.LineNo = sc.Count()
-	je.Stacktrace = parseStacktrace(sc)
-	if line, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "Caused by: "); ok {
-		je.CausedBy = parseJavaError0(line, sc)
-	}
+	je.LineNo, je.Offset = sc.Count(), sc.Offset()
+	je.Stacktrace, je.Elided = parseStacktrace(sc)
+	parseErrorTail(je, indent, outer, sc)
+	je.EndLineNo, je.EndOffset = sc.spanEnd()
 	return
 }
 
-func scanJavaErrors(r io.Reader, cb func(*JavaError)) (err error) {
-	sc := newLineScanner(r)
+// parseErrorTail parses the suppressed errors and the cause after the stacktrace of je.
+// Java prints the suppressed errors one tab deeper than je, and the cause at the same indentation as je,
+// so a `Caused by: ` line which is not deeper than the outer error belongs to the outer error instead.
+// If the indentation is stripped, the lines are attached to the innermost error
+func parseErrorTail(je *JavaError, indent int, outer int, sc *lineScanner) {
+	for {
+		sc.skipInterleaved(isNestedError)
+		text := sc.Text()
+		in := indentOf(text)
+		text = strings.TrimSpace(text)
+		if line, ok := strings.CutPrefix(text, "Suppressed: "); ok && (in > indent || in == 0) {
+			je.Suppressed = append(je.Suppressed, parseJavaError0(line, in, indent, sc))
+			continue
+		}
+		if line, ok := strings.CutPrefix(text, "Caused by: "); ok && in > outer {
+			je.CausedBy = parseJavaError0(line, in, outer, sc)
+		}
+		return
+	}
+}
+
+// indentOf returns the length of the leading spaces and tabs of the line
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+func scanJavaErrors(r io.Reader, opts *scanOptions, cb func(*JavaError) error) (err error) {
+	sc := newLineScannerWithOptions(r, opts)
 	if !sc.Scan() {
 		return sc.Err()
 	}
 	var (
-		line   string
-		lineNo int
+		line     string
+		lineNo   int
+		lastTime time.Time
+		level    string
+		ctxLines = make([]string, 0, maxContextLines)
+		// the time and level before the current line
+		prevTime  time.Time
+		prevLevel string
+		// if the last crash report header is not followed by the System Details section yet
+		inCrashReport bool
+		// if any error is found, the exit code of the process is only reported when there is no other error
+		found bool
 	)
+	if state := opts.getState(); state != nil {
+		lastTime, level, found = state.time, state.level, state.found
+		ctxLines = append(ctxLines, state.context...)
+	}
 	for {
 		line = sc.Text()
 		lineNo = sc.Count()
+		offset := sc.Offset()
+		prevTime, prevLevel = lastTime, level
+		if t, ok := parseLogTime(line); ok {
+			lastTime = t
+		}
+		if l, ok := parseLogLevel(line); ok {
+			level = l
+		}
 		emsg := javaErrorMatcher.FindStringSubmatch(line)
+		if emsg == nil {
+			je := scanNativeCrash(sc, line)
+			if je != nil && je.Class == processExitClass && found {
+				je = nil // the game is stopped by the error found before
+			}
+			if je != nil {
+				if je.Truncated && opts != nil && opts.holdAtEOF {
+					opts.saveState(ctxLines, prevTime, prevLevel, found)
+					return &incompleteErr{lineNo}
+				}
+				je.LineNo, je.Offset, je.Time, je.Level = lineNo, offset, lastTime, level
+				if len(ctxLines) > 0 {
+					je.Context = slices.Clone(ctxLines)
+				}
+				found = true
+				if err = cb(je); err != nil {
+					return
+				}
+			} else if l := strings.TrimSpace(line); l != "" {
+				if len(ctxLines) == maxContextLines {
+					ctxLines = append(ctxLines[:0], ctxLines[1:]...)
+				}
+				ctxLines = append(ctxLines, l)
+				if ul := strings.ToUpper(l); strings.HasSuffix(ul, crashReportHeader) {
+					inCrashReport = true
+				} else if ul == systemDetailsHeader {
+					inCrashReport = false
+				}
+			}
+			opts.onSummary(sc, line)
+		}
 		if !sc.Scan() {
-			return sc.Err()
+			if emsg != nil && opts != nil && opts.holdAtEOF {
+				opts.saveState(ctxLines, prevTime, prevLevel, found)
+				return &incompleteErr{lineNo}
+			}
+			if emsg != nil && inCrashReport { // the crash report is cut off right after the error line
+				je := &JavaError{
+					Class:     emsg[1],
+					Message:   emsg[2],
+					LineNo:    lineNo,
+					Offset:    offset,
+					Time:      lastTime,
+					Level:     level,
+					Truncated: true,
+				}
+				if len(ctxLines) > 0 {
+					je.Context = slices.Clone(ctxLines)
+				}
+				je.EndLineNo, je.EndOffset = sc.spanEnd()
+				found = true
+				if err = cb(je); err != nil {
+					return
+				}
+			}
+			opts.saveState(ctxLines, lastTime, level, found)
+			if err = sc.Err(); err == nil && inCrashReport && opts != nil && opts.checkCrashReport {
+				err = ErrCrashReportIncomplete
+			}
+			return
 		}
 		if emsg == nil {
 			continue
 		}
+		msgLines := 0
 		for {
 			l2 := sc.Text()
 			if stackInfoMatcher.MatchString(l2) {
 				break
 			}
+			if sc.skipInterleaved(isContinuation) {
+				continue
+			}
 			if em := javaErrorMatcher.FindStringSubmatch(l2); em != nil {
 				line = l2
 				emsg = em
+				msgLines = 0
 			} else {
+				if msgLines++; msgLines > maxMessageLines {
+					break
+				}
 				emsg[2] += "\n" + l2
 			}
 			if !sc.Scan() {
 				break
 			}
 		}
-		st := parseStacktrace0(sc)
-		if st != nil { // if stacktrace exists
+		st, elided := parseStacktrace0(sc)
+		if opts != nil && opts.holdAtEOF && sc.eof {
+			opts.saveState(ctxLines, prevTime, prevLevel, found)
+			return &incompleteErr{lineNo}
+		}
+		// an error without stacktrace is skipped, unless a truncated crash report ends in it
+		if st != nil || sc.eof && inCrashReport {
 			je := &JavaError{
 				Class:      emsg[1],
 				Message:    emsg[2],
 				Stacktrace: st,
+				Elided:     elided,
 				LineNo:     lineNo,
+				Offset:     offset,
+				Time:       lastTime,
+				Level:      level,
+			}
+			if len(ctxLines) > 0 {
+				je.Context = slices.Clone(ctxLines)
 			}
-			if line, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "Caused by: "); ok {
-				je.CausedBy = parseJavaError0(line, sc)
+			parseErrorTail(je, indentOf(line), -1, sc)
+			je.EndLineNo, je.EndOffset = sc.spanEnd()
+			for _, c := range errorChain(je)[1:] {
+				c.Context, c.Level = je.Context, je.Level
+			}
+			if sc.eof {
+				for _, c := range errorChain(je) {
+					c.Truncated = c.EndOffset == je.EndOffset
+				}
+			}
+			if opts != nil && opts.holdAtEOF && sc.eof {
+				opts.saveState(ctxLines, prevTime, prevLevel, found)
+				return &incompleteErr{lineNo}
+			}
+			found = true
+			if err = cb(je); err != nil {
+				return
 			}
-			cb(je)
 		}
 	}
 }
 
+// incompleteErr is returned by scanJavaErrors when the error starts at LineNo reaches the end of the input
+type incompleteErr struct {
+	LineNo int
+}
+
+func (e *incompleteErr) Error() string {
+	return fmt.Sprintf("incomplete error at line %d", e.LineNo)
+}
+
+// ScanJavaErrors parses all java errors in the log, see StreamJavaErrors for what the errors contain
 func ScanJavaErrors(r io.Reader) (res []*JavaError, err error) {
 	res = make([]*JavaError, 0, 3)
-	err = scanJavaErrors(r, func(je *JavaError) {
+	err = scanJavaErrors(r, nil, func(je *JavaError) error {
 		res = append(res, je)
+		return nil
 	})
 	return
 }
 
+// ScanJavaErrorsIntoChan is StreamJavaErrors with the background context
 func ScanJavaErrorsIntoChan(r io.Reader) (<-chan *JavaError, <-chan error) {
+	return StreamJavaErrors(context.Background(), r)
+}
+
+// StreamJavaErrors parses the java errors in the log without matching them, so no Analyzer or ErrorDB is needed.
+// Each error is sent as soon as its stacktrace ends, with its causes in CausedBy, its suppressed errors in Suppressed,
+// its stacktrace, and where it is in the log: LineNo, Time, Level and Context.
+// The causes and the suppressed errors have their own LineNo, and share the Context and Level of the top level error.
+//
+// The error channel receives at most one error, which is sent before the result channel is closed.
+// Scanning stops when the context is done, and the cause of the context is sent as the error
+func StreamJavaErrors(ctx context.Context, r io.Reader) (<-chan *JavaError, <-chan error) {
+	return scanJavaErrorsIntoChan(ctx, r, nil)
+}
+
+// scanJavaErrorsIntoChan stops scanning when the context is done, so the goroutine will not leak
+// when the receiver gives up. The error is always sent before the result channel is closed
+func scanJavaErrorsIntoChan(ctx context.Context, r io.Reader, opts *scanOptions) (<-chan *JavaError, <-chan error) {
 	resCh := make(chan *JavaError, 3)
-	errCh := make(chan error, 0)
+	errCh := make(chan error, 1)
 	go func() {
 		defer close(resCh)
-		err := scanJavaErrors(r, func(je *JavaError) {
-			resCh <- je
+		err := scanJavaErrors(r, opts, func(je *JavaError) error {
+			select {
+			case resCh <- je:
+				return nil
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
 		})
 		if err != nil {
 			errCh <- err
@@ -4,7 +4,9 @@ import (
 	. "github.com/GlobeMC/mcla"
 	"testing"
 
+	"context"
 	"strings"
+	"time"
 )
 
 func TestScanJavaErrors(t *testing.T) {
@@ -98,3 +100,263 @@ Caused by: java.lang.RuntimeException:
 		return
 	}
 }
+
+func TestStreamJavaErrors(t *testing.T) {
+	const log = `[2024-01-02 12:00:00] [Server thread/INFO]: Preparing spawn area: 0%
+[2024-01-02 12:00:01] [Server thread/ERROR]: Encountered an unexpected exception
+java.lang.RuntimeException: Something went wrong
+	at com.example.mod.Foo.bar(Foo.java:42)
+	at com.example.mod.Foo.baz(Foo.java:50)
+Caused by: java.lang.IllegalStateException: Bad state
+	at com.example.mod.Bar.check(Bar.java:10)
+	... 1 more
+[2024-01-02 12:00:05] [Server thread/WARN]: Something is slow
+java.io.IOException: Broken pipe
+	at com.example.net.Conn.write(Conn.java:7)
+`
+	resCh, errCh := StreamJavaErrors(context.Background(), strings.NewReader(log))
+	var errs []*JavaError
+	for je := range resCh {
+		errs = append(errs, je)
+	}
+	select {
+	case err := <-errCh:
+		t.Fatalf("StreamJavaErrors: %v", err)
+	default:
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expect 2 errors, got %d", len(errs))
+	}
+
+	je := errs[0]
+	if expect := "java.lang.RuntimeException"; je.Class != expect {
+		t.Errorf("Expect je.Class == %q, got %q", expect, je.Class)
+	}
+	if expect := "Something went wrong"; je.Message != expect {
+		t.Errorf("Expect je.Message == %q, got %q", expect, je.Message)
+	}
+	if len(je.Stacktrace) != 2 || je.Stacktrace[1].Class != "com.example.mod.Foo" || je.Stacktrace[1].Method != "baz" {
+		t.Errorf("Expect 2 stack frames ending with com.example.mod.Foo.baz, got %#v", je.Stacktrace)
+	}
+	if je.LineNo != 3 {
+		t.Errorf("Expect je.LineNo == 3, got %d", je.LineNo)
+	}
+	if expect := time.Date(2024, 1, 2, 12, 0, 1, 0, time.UTC); !je.Time.Equal(expect) {
+		t.Errorf("Expect je.Time == %v, got %v", expect, je.Time)
+	}
+	if je.Level != "ERROR" {
+		t.Errorf("Expect je.Level == %q, got %q", "ERROR", je.Level)
+	}
+	if len(je.Context) != 2 || !strings.Contains(je.Context[1], "Encountered an unexpected exception") {
+		t.Errorf("Expect the context to end with the preceding log line, got %q", je.Context)
+	}
+	cause := je.CausedBy
+	if cause == nil {
+		t.Fatalf("Expect je.CausedBy != nil, got nil")
+	}
+	if cause.Class != "java.lang.IllegalStateException" || cause.Message != "Bad state" {
+		t.Errorf("Expect the cause to be IllegalStateException: Bad state, got %s: %s", cause.Class, cause.Message)
+	}
+	if len(cause.Stacktrace) != 1 || cause.LineNo != 6 {
+		t.Errorf("Expect the cause at line 6 with 1 stack frame, got line %d with %d frames", cause.LineNo, len(cause.Stacktrace))
+	}
+	if cause.Level != je.Level || len(cause.Context) != len(je.Context) {
+		t.Errorf("Expect the cause to share the level and context of its parent")
+	}
+
+	je = errs[1]
+	if je.Class != "java.io.IOException" || je.Level != "WARN" || je.CausedBy != nil {
+		t.Errorf("Expect the second error to be a WARN IOException without cause, got %s (%s)", je.Class, je.Level)
+	}
+}
+
+func TestStreamJavaErrorsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := strings.NewReader(strings.Repeat("java.lang.RuntimeException: boom\n\tat a.B.c(B.java:1)\n", 100))
+	resCh, errCh := StreamJavaErrors(ctx, r)
+	for range resCh {
+	}
+	if err := <-errCh; err != context.Canceled {
+		t.Errorf("Expect err == %v, got %v", context.Canceled, err)
+	}
+}
+
+const suppressedLog = `[12:00:00] [main/ERROR]: Failed to load mods
+java.lang.RuntimeException: Failed to load mods
+	at a.b.Loader.load(Loader.java:10)
+	Suppressed: java.io.IOException: Failed to close resource
+		at a.b.Resource.close(Resource.java:20)
+		at a.b.Loader.load(Loader.java:12)
+	Caused by: java.nio.file.AccessDeniedException: /mods/foo.jar
+		at a.b.Files.delete(Files.java:30)
+		... 2 more
+	Suppressed: java.lang.IllegalStateException: Another failure
+		at a.b.Other.run(Other.java:40)
+		... 1 more
+		Suppressed: java.lang.NullPointerException: deep
+			at a.b.Deep.run(Deep.java:50)
+			... 1 more
+Caused by: java.lang.ClassNotFoundException: a.b.Missing
+	at a.b.Loader.find(Loader.java:60)
+	... 1 more
+	Suppressed: java.lang.Error: suppressed by cause
+		at a.b.Loader.find2(Loader.java:61)
+		... 1 more
+[12:00:01] [main/INFO]: Stopping
+`
+
+func TestScanSuppressedErrors(t *testing.T) {
+	res, err := ScanJavaErrors(strings.NewReader(suppressedLog))
+	if err != nil {
+		t.Fatalf("ScanJavaErrors: %v", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("Found %d java errors, but expect only 1", len(res))
+	}
+	je := res[0]
+	if len(je.Suppressed) != 2 {
+		t.Fatalf("Expect 2 suppressed errors, got %d", len(je.Suppressed))
+	}
+	s0, s1 := je.Suppressed[0], je.Suppressed[1]
+	if s0.Class != "java.io.IOException" || len(s0.Stacktrace) != 2 {
+		t.Errorf("Expect suppressed[0] == java.io.IOException with 2 frames, got %s with %d frames", s0.Class, len(s0.Stacktrace))
+	}
+	if s0.CausedBy == nil || s0.CausedBy.Class != "java.nio.file.AccessDeniedException" {
+		t.Errorf("Expect suppressed[0] to be caused by java.nio.file.AccessDeniedException, got %#v", s0.CausedBy)
+	}
+	if s1.Class != "java.lang.IllegalStateException" || len(s1.Suppressed) != 1 || s1.Suppressed[0].Class != "java.lang.NullPointerException" {
+		t.Errorf("Expect suppressed[1] == java.lang.IllegalStateException which suppressed java.lang.NullPointerException, got %#v", s1)
+	}
+	if s1.CausedBy != nil {
+		t.Errorf("Expect suppressed[1] has no cause, got %s", s1.CausedBy.Class)
+	}
+	c := je.CausedBy
+	if c == nil || c.Class != "java.lang.ClassNotFoundException" {
+		t.Fatalf("Expect caused by java.lang.ClassNotFoundException, got %#v", c)
+	}
+	if len(c.Suppressed) != 1 || c.Suppressed[0].Class != "java.lang.Error" {
+		t.Errorf("Expect the cause suppressed java.lang.Error, got %#v", c.Suppressed)
+	}
+	if s0.CausedBy.LineNo != 7 || s0.CausedBy.Level != "ERROR" {
+		t.Errorf("Expect the cause of suppressed[0] at line 7 with level ERROR, got line %d with level %q", s0.CausedBy.LineNo, s0.CausedBy.Level)
+	}
+}
+
+func TestDoLogStreamSuppressed(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	resCh, _ := analyzer.DoLogStream(context.Background(), strings.NewReader(suppressedLog))
+	var classes []string
+	for res := range resCh {
+		classes = append(classes, res.Error.Class)
+	}
+	expect := []string{
+		"java.lang.RuntimeException",
+		"java.io.IOException",
+		"java.nio.file.AccessDeniedException",
+		"java.lang.IllegalStateException",
+		"java.lang.NullPointerException",
+		"java.lang.ClassNotFoundException",
+		"java.lang.Error",
+	}
+	if strings.Join(classes, ",") != strings.Join(expect, ",") {
+		t.Errorf("Expect results == %q, got %q", expect, classes)
+	}
+}
+
+func TestStackFrames(t *testing.T) {
+	const log = `java.lang.RuntimeException: Failed to construct mod
+	at com.example.mod.ExampleMod.<init>(ExampleMod.java:42) ~[examplemod-1.0.jar%23190!/:1.0]
+	at cpw.mods.cl.ModuleClassLoader.loadClass(ModuleClassLoader.java:135) ~[securejarhandler-1.0.8.jar:?]
+	at java.base/java.lang.Thread.run(Thread.java:833) [?:?]
+	at net.minecraft.Util.run(Native Method)
+Caused by: java.lang.NullPointerException: null
+	at com.example.mod.Config.load(Config.java:7)
+	... 12 more
+`
+	res, err := ScanJavaErrors(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ScanJavaErrors: %v", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("Found %d java errors, but expect only 1", len(res))
+	}
+	datas := []StackInfo{
+		{Class: "com.example.mod.ExampleMod", Method: "<init>", File: "ExampleMod.java", Line: 42, Jar: "examplemod-1.0.jar", JarVersion: "1.0"},
+		{Class: "cpw.mods.cl.ModuleClassLoader", Method: "loadClass", File: "ModuleClassLoader.java", Line: 135, Jar: "securejarhandler-1.0.8.jar"},
+		{Class: "java.lang.Thread", Method: "run", File: "Thread.java", Line: 833},
+		{Class: "net.minecraft.Util", Method: "run", File: "Native Method"},
+	}
+	st := res[0].Stacktrace
+	if len(st) != len(datas) {
+		t.Fatalf("Expect %d frames, got %d", len(datas), len(st))
+	}
+	for i, d := range datas {
+		s := st[i]
+		s.Raw = ""
+		if s != d {
+			t.Errorf("Expect frame %d == %#v, got %#v", i, d, s)
+		}
+	}
+	if res[0].Elided != 0 {
+		t.Errorf("Expect Elided == 0, got %d", res[0].Elided)
+	}
+	if c := res[0].CausedBy; c == nil || c.Elided != 12 || len(c.Stacktrace) != 1 {
+		t.Errorf("Expect the cause has 1 frame and 12 elided, got %#v", c)
+	}
+}
+
+func TestScanInterleavedStacktrace(t *testing.T) {
+	const log = `[12:00:00] [Render thread/ERROR]: Failed to load the model
+java.lang.RuntimeException: Could not load the model
+[12:00:00] [Worker-Main-3/INFO]: Loaded 7 recipes
+	at com.example.mod.Models.load(Models.java:10)
+[12:00:00] [Worker-Main-4/INFO]: Loaded 3 advancements
+	at com.example.mod.Models.init(Models.java:20)
+	at net.minecraft.client.Minecraft.run(Minecraft.java:30)
+[12:00:01] [Server thread/WARN]: Can't keep up!
+Caused by: java.io.FileNotFoundException: model.json
+	at com.example.mod.Models.open(Models.java:40)
+[12:00:01] [Worker-Main-5/INFO]: Reloading ResourceManager
+	... 3 more
+[12:00:02] [Render thread/INFO]: Stopping!
+	at com.example.mod.Unrelated.run(Unrelated.java:1)
+[12:00:03] [main/INFO]: Done
+java.lang.IllegalStateException: Not building!
+	at com.example.mod.Builder.build(Builder.java:5)
+[12:00:04] [main/INFO]: Another event
+[12:00:05] [main/INFO]: Yet another event
+	at com.example.mod.Builder.run(Builder.java:6)
+`
+	res, err := ScanJavaErrors(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ScanJavaErrors: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("Found %d java errors, but expect 2", len(res))
+	}
+	je := res[0]
+	if expect := "Could not load the model"; je.Message != expect {
+		t.Errorf("Expect je.Message == %q, got %q", expect, je.Message)
+	}
+	if len(je.Stacktrace) != 3 {
+		t.Errorf("Expect 3 frames, got %d", len(je.Stacktrace))
+	}
+	c := je.CausedBy
+	if c == nil || c.Class != "java.io.FileNotFoundException" {
+		t.Fatalf("Expect caused by java.io.FileNotFoundException, got %#v", c)
+	}
+	if len(c.Stacktrace) != 1 || c.Elided != 3 {
+		t.Errorf("Expect the cause has 1 frame and 3 elided, got %d frames and %d elided", len(c.Stacktrace), c.Elided)
+	}
+	if je.EndLineNo != 12 {
+		t.Errorf("Expect je.EndLineNo == 12, got %d", je.EndLineNo)
+	}
+	if end := (int64)(strings.Index(log, "[12:00:02]")); je.EndOffset != end {
+		t.Errorf("Expect je.EndOffset == %d, got %d", end, je.EndOffset)
+	}
+	// only one interleaved line is skipped, so the frame after two log events is not stitched
+	if je := res[1]; je.Class != "java.lang.IllegalStateException" || len(je.Stacktrace) != 1 {
+		t.Errorf("Expect java.lang.IllegalStateException with 1 frame, got %s with %d frames", je.Class, len(je.Stacktrace))
+	}
+}
@@ -0,0 +1,617 @@
+package mcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// The hand written JSON encoder produces the same output as a json.Encoder with SetEscapeHTML(false) would by the struct tags,
+// but does not use reflection except for the unknown value types in ErrorDesc.Data.
+// It's also the MarshalJSON of ErrorResult, which adds the schemaVersion field, and of JavaError, which omits the zero time
+// without the omitzero tag option, and json.Marshal still escapes the HTML characters of them like the other values
+
+var errUnsupportedFloat = errors.New("mcla: unsupported float value in JSON")
+
+// jsonAppender is implemented by the values in ErrorDesc.Data which can be encoded without reflection
+type jsonAppender interface {
+	appendJSON(b []byte) ([]byte, error)
+}
+
+// AppendJSON appends the JSON encoding of the result to b, the output is same as json.Marshal but the HTML characters
+// are not escaped, like a json.Encoder with SetEscapeHTML(false). It includes the schemaVersion field, see ResultSchemaVersion
+func (r *ErrorResult) AppendJSON(b []byte) ([]byte, error) {
+	return r.appendJSON(b)
+}
+
+// AppendJSON appends the JSON encoding of the solution possibility to b, the output is same as json.Marshal
+// but the HTML characters are not escaped, see ErrorResult.AppendJSON
+func (p *SolutionPossibility) AppendJSON(b []byte) ([]byte, error) {
+	return p.appendJSON(b)
+}
+
+func (r *ErrorResult) appendJSON(b []byte) (_ []byte, err error) {
+	if r == nil {
+		return append(b, "null"...), nil
+	}
+	b = append(b, `{"schemaVersion":"`+ResultSchemaVersion+`","error":`...)
+	if b, err = r.Error.appendJSON(b); err != nil {
+		return
+	}
+	b = append(b, `,"matched":`...)
+	if r.Matched == nil {
+		b = append(b, "null"...)
+	} else {
+		b = append(b, '[')
+		for i := range r.Matched {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			if b, err = r.Matched[i].appendJSON(b); err != nil {
+				return
+			}
+		}
+		b = append(b, ']')
+	}
+	if r.File != "" {
+		b = append(b, `,"file":`...)
+		b = appendJSONString(b, r.File)
+	}
+	if r.Primary {
+		b = append(b, `,"primary":true`...)
+	}
+	if r.Location != nil {
+		b = append(b, `,"location":`...)
+		b = r.Location.appendJSON(b)
+	}
+	if r.Recovered {
+		b = append(b, `,"recovered":true`...)
+	}
+	b = append(b, `,"category":`...)
+	b = appendJSONString(b, (string)(r.Category))
+	if r.SummaryOnly {
+		b = append(b, `,"summaryOnly":true`...)
+	}
+	if r.Mixin != nil {
+		b = append(b, `,"mixin":`...)
+		b = r.Mixin.appendJSON(b)
+	}
+	if len(r.MixinLogs) > 0 {
+		b = append(b, `,"mixinLogs":`...)
+		b = appendJSONStrings(b, r.MixinLogs)
+	}
+	if len(r.Logs) > 0 {
+		b = append(b, `,"logs":`...)
+		b = appendJSONStringsMap(b, r.Logs)
+	}
+	if r.StartLine != 0 {
+		b = append(b, `,"startLine":`...)
+		b = strconv.AppendInt(b, (int64)(r.StartLine), 10)
+	}
+	if r.EndLine != 0 {
+		b = append(b, `,"endLine":`...)
+		b = strconv.AppendInt(b, (int64)(r.EndLine), 10)
+	}
+	if r.StartOffset != 0 {
+		b = append(b, `,"startOffset":`...)
+		b = strconv.AppendInt(b, r.StartOffset, 10)
+	}
+	if r.EndOffset != 0 {
+		b = append(b, `,"endOffset":`...)
+		b = strconv.AppendInt(b, r.EndOffset, 10)
+	}
+	if r.Incomplete {
+		b = append(b, `,"incomplete":true`...)
+	}
+	if r.Count != 0 {
+		b = append(b, `,"count":`...)
+		b = strconv.AppendInt(b, (int64)(r.Count), 10)
+	}
+	if r.LastLine != 0 {
+		b = append(b, `,"lastLine":`...)
+		b = strconv.AppendInt(b, (int64)(r.LastLine), 10)
+	}
+	return append(b, '}'), nil
+}
+
+func (p *SolutionPossibility) appendJSON(b []byte) (_ []byte, err error) {
+	b = append(b, `{"errorDesc":`...)
+	if b, err = p.ErrorDesc.appendJSON(b); err != nil {
+		return
+	}
+	b = append(b, `,"match":`...)
+	if b, err = appendJSONFloat(b, (float64)(p.Match), 32); err != nil {
+		return
+	}
+	if p.Source != "" {
+		b = append(b, `,"source":`...)
+		b = appendJSONString(b, p.Source)
+	}
+	if p.ID != "" {
+		b = append(b, `,"id":`...)
+		b = appendJSONString(b, p.ID)
+	}
+	if len(p.Captures) > 0 {
+		b = append(b, `,"captures":`...)
+		b = appendJSONStringMap(b, p.Captures)
+	}
+	b = appendJSONStringField(b, "description", p.Description)
+	b = append(b, `,"severity":`...)
+	b = appendJSONString(b, (string)(p.Severity))
+	if len(p.Links) > 0 {
+		b = append(b, `,"links":`...)
+		b = appendJSONLinks(b, p.Links)
+	}
+	if d := p.Detail; d != nil {
+		b = append(b, `,"detail":`...)
+		if b, err = d.appendJSON(b); err != nil {
+			return
+		}
+	}
+	return append(b, '}'), nil
+}
+
+func (d *MatchDetail) appendJSON(b []byte) (_ []byte, err error) {
+	b = append(b, `{"class":`...)
+	if b, err = appendJSONFloat(b, (float64)(d.Class), 32); err != nil {
+		return
+	}
+	b = append(b, `,"messageSimilarity":`...)
+	if b, err = appendJSONFloat(b, (float64)(d.MessageSimilarity), 32); err != nil {
+		return
+	}
+	b = append(b, `,"message":`...)
+	if b, err = appendJSONFloat(b, (float64)(d.Message), 32); err != nil {
+		return
+	}
+	if d.Wildcard {
+		b = append(b, `,"wildcard":true`...)
+	}
+	b = append(b, `,"specificity":`...)
+	if b, err = appendJSONFloat(b, (float64)(d.Specificity), 32); err != nil {
+		return
+	}
+	return append(b, '}'), nil
+}
+
+func (e *ErrorDesc) appendJSON(b []byte) (_ []byte, err error) {
+	if e == nil {
+		return append(b, "null"...), nil
+	}
+	b = append(b, '{')
+	if e.ID != "" {
+		b = append(b, `"id":`...)
+		b = appendJSONString(b, e.ID)
+		b = append(b, ',')
+	}
+	b = append(b, `"error":`...)
+	b = appendJSONString(b, e.Error)
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, e.Message)
+	if len(e.Messages) > 0 {
+		b = append(b, `,"messages":`...)
+		b = appendJSONStrings(b, e.Messages)
+	}
+	b = append(b, `,"solutions":`...)
+	if e.Solutions == nil {
+		b = append(b, "null"...)
+	} else {
+		b = append(b, '[')
+		for i, id := range e.Solutions {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = strconv.AppendInt(b, (int64)(id), 10)
+		}
+		b = append(b, ']')
+	}
+	if len(e.Data) > 0 {
+		b = append(b, `,"data":`...)
+		if b, err = appendJSONValue(b, e.Data); err != nil {
+			return
+		}
+	}
+	if e.MessageIsRegex {
+		b = append(b, `,"messageIsRegex":true`...)
+	}
+	b = appendJSONStringField(b, "mustNotMatch", e.MustNotMatch)
+	b = appendJSONStringField(b, "description", e.Description)
+	if len(e.Descriptions) > 0 {
+		b = append(b, `,"descriptions":`...)
+		b = appendJSONStringMap(b, e.Descriptions)
+	}
+	b = appendJSONStringField(b, "source", e.Source)
+	b = appendJSONStringField(b, "modded", (string)(e.Modded))
+	if len(e.Launchers) > 0 {
+		b = append(b, `,"launchers":`...)
+		b = appendJSONStrings(b, e.Launchers)
+	}
+	b = appendJSONStringField(b, "context", e.Context)
+	if len(e.Signals) > 0 {
+		b = append(b, `,"signals":[`...)
+		for i, s := range e.Signals {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, `{"pattern":`...)
+			b = appendJSONString(b, s.Pattern)
+			b = append(b, `,"weight":`...)
+			if b, err = appendJSONFloat(b, (float64)(s.Weight), 32); err != nil {
+				return
+			}
+			b = append(b, '}')
+		}
+		b = append(b, ']')
+	}
+	b = appendJSONStringField(b, "category", (string)(e.Category))
+	b = appendJSONStringField(b, "severity", (string)(e.Severity))
+	if len(e.Links) > 0 {
+		b = append(b, `,"links":`...)
+		b = appendJSONLinks(b, e.Links)
+	}
+	return append(b, '}'), nil
+}
+
+func (je *JavaError) appendJSON(b []byte) (_ []byte, err error) {
+	if je == nil {
+		return append(b, "null"...), nil
+	}
+	b = append(b, `{"class":`...)
+	b = appendJSONString(b, je.Class)
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, je.Message)
+	b = append(b, `,"stacktrace":`...)
+	if je.Stacktrace == nil {
+		b = append(b, "null"...)
+	} else {
+		b = append(b, '[')
+		for i, s := range je.Stacktrace {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, `{"raw":`...)
+			b = appendJSONString(b, s.Raw)
+			b = append(b, `,"class":`...)
+			b = appendJSONString(b, s.Class)
+			b = append(b, `,"method":`...)
+			b = appendJSONString(b, s.Method)
+			b = appendJSONStringField(b, "file", s.File)
+			if s.Line != 0 {
+				b = append(b, `,"line":`...)
+				b = strconv.AppendInt(b, (int64)(s.Line), 10)
+			}
+			b = appendJSONStringField(b, "jar", s.Jar)
+			b = appendJSONStringField(b, "jarVersion", s.JarVersion)
+			b = append(b, '}')
+		}
+		b = append(b, ']')
+	}
+	if je.Elided != 0 {
+		b = append(b, `,"elided":`...)
+		b = strconv.AppendInt(b, (int64)(je.Elided), 10)
+	}
+	b = append(b, `,"causedBy":`...)
+	if b, err = je.CausedBy.appendJSON(b); err != nil {
+		return
+	}
+	if len(je.Suppressed) > 0 {
+		b = append(b, `,"suppressed":[`...)
+		for i, s := range je.Suppressed {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			if b, err = s.appendJSON(b); err != nil {
+				return
+			}
+		}
+		b = append(b, ']')
+	}
+	b = append(b, `,"lineNo":`...)
+	b = strconv.AppendInt(b, (int64)(je.LineNo), 10)
+	if je.EndLineNo != 0 {
+		b = append(b, `,"endLineNo":`...)
+		b = strconv.AppendInt(b, (int64)(je.EndLineNo), 10)
+	}
+	if je.Offset != 0 {
+		b = append(b, `,"offset":`...)
+		b = strconv.AppendInt(b, je.Offset, 10)
+	}
+	if je.EndOffset != 0 {
+		b = append(b, `,"endOffset":`...)
+		b = strconv.AppendInt(b, je.EndOffset, 10)
+	}
+	if !je.Time.IsZero() {
+		b = append(b, `,"time":`...)
+		if b, err = appendJSONTime(b, je.Time); err != nil {
+			return
+		}
+	}
+	if len(je.Context) > 0 {
+		b = append(b, `,"context":`...)
+		b = appendJSONStrings(b, je.Context)
+	}
+	b = appendJSONStringField(b, "level", je.Level)
+	if je.Truncated {
+		b = append(b, `,"truncated":true`...)
+	}
+	return append(b, '}'), nil
+}
+
+func (l *CrashLocation) appendJSON(b []byte) []byte {
+	b = append(b, `{"class":`...)
+	b = appendJSONString(b, l.Class)
+	b = append(b, `,"method":`...)
+	b = appendJSONString(b, l.Method)
+	b = appendJSONStringField(b, "file", l.File)
+	if l.Line != 0 {
+		b = append(b, `,"line":`...)
+		b = strconv.AppendInt(b, (int64)(l.Line), 10)
+	}
+	return append(b, '}')
+}
+
+func (m *MixinSource) appendJSON(b []byte) []byte {
+	b = append(b, `{"mod":`...)
+	b = appendJSONString(b, m.Mod)
+	b = append(b, `,"config":`...)
+	b = appendJSONString(b, m.Config)
+	b = appendJSONStringField(b, "mixin", m.Mixin)
+	return append(b, '}')
+}
+
+func (c DependencyConstraint) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, `{"mod":`...)
+	b = appendJSONString(b, c.Mod)
+	b = append(b, `,"dependency":`...)
+	b = appendJSONString(b, c.Dependency)
+	b = append(b, `,"requires":`...)
+	b = appendJSONString(b, c.Requires)
+	b = appendJSONStringField(b, "have", c.Have)
+	return append(b, '}'), nil
+}
+
+// appendJSONValue encodes the common value types by hand, and falls back to a json.Encoder for the others
+func appendJSONValue(b []byte, v any) (_ []byte, err error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...), nil
+	case string:
+		return appendJSONString(b, v), nil
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case int:
+		return strconv.AppendInt(b, (int64)(v), 10), nil
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case float32:
+		return appendJSONFloat(b, (float64)(v), 32)
+	case float64:
+		return appendJSONFloat(b, v, 64)
+	case []string:
+		if v == nil {
+			return append(b, "null"...), nil
+		}
+		return appendJSONStrings(b, v), nil
+	case []any:
+		if v == nil {
+			return append(b, "null"...), nil
+		}
+		b = append(b, '[')
+		for i, e := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			if b, err = appendJSONValue(b, e); err != nil {
+				return
+			}
+		}
+		return append(b, ']'), nil
+	case map[string]any:
+		if v == nil {
+			return append(b, "null"...), nil
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		b = append(b, '{')
+		for i, k := range keys {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, k)
+			b = append(b, ':')
+			if b, err = appendJSONValue(b, v[k]); err != nil {
+				return
+			}
+		}
+		return append(b, '}'), nil
+	case []DependencyConstraint:
+		if v == nil {
+			return append(b, "null"...), nil
+		}
+		b = append(b, '[')
+		for i, c := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			if b, err = c.appendJSON(b); err != nil {
+				return
+			}
+		}
+		return append(b, ']'), nil
+	case jsonAppender:
+		return v.appendJSON(b)
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(v); err != nil {
+		return
+	}
+	return append(b, bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})...), nil
+}
+
+func appendJSONStringField(b []byte, name string, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = append(b, ',', '"')
+	b = append(b, name...)
+	b = append(b, '"', ':')
+	return appendJSONString(b, value)
+}
+
+// appendJSONStringMap encodes the map with the sorted keys like encoding/json
+func appendJSONStringMap(b []byte, m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, k)
+		b = append(b, ':')
+		b = appendJSONString(b, m[k])
+	}
+	return append(b, '}')
+}
+
+// appendJSONStringsMap encodes the map with the sorted keys like encoding/json
+func appendJSONStringsMap(b []byte, m map[string][]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, k)
+		b = append(b, ':')
+		b = appendJSONStrings(b, m[k])
+	}
+	return append(b, '}')
+}
+
+func appendJSONLinks(b []byte, links []Link) []byte {
+	b = append(b, '[')
+	for i, l := range links {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, `{"url":`...)
+		b = appendJSONString(b, l.URL)
+		b = appendJSONStringField(b, "label", l.Label)
+		b = append(b, '}')
+	}
+	return append(b, ']')
+}
+
+func appendJSONStrings(b []byte, values []string) []byte {
+	b = append(b, '[')
+	for i, s := range values {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, s)
+	}
+	return append(b, ']')
+}
+
+func appendJSONTime(b []byte, t time.Time) ([]byte, error) {
+	if y := t.Year(); y < 0 || y >= 10000 {
+		return b, errors.New("mcla: year outside of range [0,9999] in JSON")
+	}
+	b = append(b, '"')
+	b = t.AppendFormat(b, time.RFC3339Nano)
+	return append(b, '"'), nil
+}
+
+// appendJSONFloat formats the float like encoding/json
+func appendJSONFloat(b []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return b, errUnsupportedFloat
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && ((float32)(abs) < 1e-6 || (float32)(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString quotes the string like encoding/json without HTML escaping
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\uFFFD"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
@@ -0,0 +1,191 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// plainResult is encoded by reflection, since it does not have the MarshalJSON of ErrorResult
+type plainResult ErrorResult
+
+// plainJavaError is encoded by reflection, since it does not have the MarshalJSON of JavaError
+type plainJavaError JavaError
+
+func TestErrorResultAppendJSON(t *testing.T) {
+	desc := &ErrorDesc{
+		ID:           "oom",
+		Error:        "java.lang.OutOfMemoryError",
+		Message:      "Java heap space <&> \"quoted\" \\ \t\n\x01   \xff 中文",
+		Messages:     []string{"GC overhead limit exceeded", ""},
+		Solutions:    []int{1, 2, 3},
+		MustNotMatch: "Metaspace",
+		Description:  "Increase the memory",
+		Descriptions: map[string]string{"zh-CN": "增加内存", "ja": "メモリを増やす"},
+		Source:       "errors/oom.json",
+		Modded:       ModdedYes,
+		Launchers:    []string{"HMCL", "PCL"},
+		Context:      `Loading \w+`,
+		Signals:      []Signal{{Pattern: "GC overhead", Weight: 0.4}, {Pattern: "tiny", Weight: 1e-7}},
+		Category:     CategoryOutOfMemory,
+		Data: map[string]any{
+			"string": "value",
+			"bool":   true,
+			"int":    42,
+			"float":  0.1,
+			"big":    1e21,
+			"nil":    nil,
+			"list":   []any{"a", 1.5, false},
+			"map":    map[string]any{"b": "2", "a": "1"},
+			"constraints": []DependencyConstraint{
+				{Mod: "examplemod", Dependency: "fabric-api", Requires: ">=0.80.0", Have: "0.70.0"},
+				{Mod: "examplemod", Dependency: "cloth-config", Requires: "*"},
+			},
+			"struct": struct {
+				Name string `json:"name"`
+			}{"fallback"},
+		},
+	}
+	results := []*ErrorResult{
+		{
+			Error: &JavaError{
+				Class:   "java.lang.IllegalStateException",
+				Message: "Failed to create model",
+				Stacktrace: Stacktrace{
+					{Raw: "at net.minecraft.client.Minecraft.<init>(Minecraft.java:100)", Class: "net.minecraft.client.Minecraft", Method: "<init>", File: "Minecraft.java", Line: 100},
+					{Raw: "at com.example.Foo.bar(Foo.java) ~[example-1.0.jar%23190!/:1.0]", Class: "com.example.Foo", Method: "bar", File: "Foo.java", Jar: "example-1.0.jar", JarVersion: "1.0"},
+				},
+				Elided: 3,
+				CausedBy: &JavaError{
+					Class:   "java.lang.NullPointerException",
+					Message: "",
+					LineNo:  4,
+				},
+				Suppressed: []*JavaError{
+					{Class: "java.io.IOException", Message: "Stream closed", LineNo: 3},
+				},
+				LineNo:  2,
+				Time:    time.Date(2024, time.March, 5, 12, 34, 56, 789e6, time.FixedZone("", 8*60*60)),
+				Context: []string{"[12:00:00] [main/INFO]: Loading <config>"},
+				Level:   "FATAL",
+			},
+			Matched: []SolutionPossibility{
+				{ErrorDesc: desc, Match: 0.87654321, Source: desc.Source, ID: desc.ID, Description: "增加内存", Severity: SeverityFatal,
+					Detail: &MatchDetail{Class: 0.1, MessageSimilarity: 0.8628258, Message: 0.7765432, Specificity: 1}},
+				{ErrorDesc: &ErrorDesc{Error: "java.lang.IllegalStateException", Severity: SeverityWarning, Links: []Link{{URL: "https://example.com/wiki"}}},
+					Match: 0.5, Links: []Link{{URL: "https://example.com/wiki"}, {URL: "https://example.com/issues/1", Label: "Issue #1"}},
+					Detail: &MatchDetail{Class: 0.5, Wildcard: true, Specificity: 0.5}},
+				{
+					ErrorDesc: &ErrorDesc{Error: "java.lang.ClassNotFoundException", Message: `(?P<class>[\w.$]+)`, MessageIsRegex: true},
+					Match:     1,
+					Captures:  map[string]string{"class": "com.example.Foo", "1": "x"},
+				},
+			},
+			File:      "logs/latest.log",
+			Primary:   true,
+			Location:  &CrashLocation{Class: "com.example.Foo", Method: "bar", File: "Foo.java", Line: 42},
+			Recovered: true,
+			Category:  CategoryMixinConflict,
+			Mixin:     &MixinSource{Mod: "mymod", Config: "mymod.mixins.json", Mixin: "MixinFoo"},
+			MixinLogs: []string{"Mixing MixinFoo from mymod.mixins.json into com.example.Foo"},
+			Logs:      map[string][]string{"warn": {"Reference map 'mymod.refmap.json' could not be read"}, "error": {}},
+			Count:     3,
+			LastLine:  9,
+		},
+		{
+			Error:       &JavaError{Message: "Ticking entity", LineNo: 5, Time: time.Date(1, time.January, 1, 12, 0, 0, 0, time.UTC)},
+			Matched:     []SolutionPossibility{},
+			Category:    CategoryUnknown,
+			SummaryOnly: true,
+		},
+		{},
+	}
+
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(multiErrorLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	results = append(results, analysis.Results...)
+
+	for i, res := range results {
+		// the reflection encoding of the fields, with the schemaVersion which is added by MarshalJSON
+		plain, err := json.Marshal((*plainResult)(res))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if marshaled, err := json.Marshal(res); err != nil || (string)(marshaled) != `{"schemaVersion":"`+ResultSchemaVersion+`",`+(string)(plain[1:]) {
+			t.Errorf("Expect json.Marshal to use the hand written encoder, got %s, %v", marshaled, err)
+		}
+		// AppendJSON does not escape the HTML characters, like the encoder of the command line
+		expect := `{"schemaVersion":"` + ResultSchemaVersion + `",` + encodeUnescaped(t, (*plainResult)(res))[1:]
+		if encoded := encodeUnescaped(t, res); encoded != expect {
+			t.Errorf("Expect the encoder without HTML escaping to use the hand written encoder, got %s", encoded)
+		}
+		got, err := res.AppendJSON(nil)
+		if err != nil {
+			t.Fatalf("AppendJSON: %v", err)
+		}
+		if string(got) != string(expect) {
+			t.Errorf("Expect result %d encoded as\n%s\ngot\n%s", i, expect, got)
+		}
+	}
+	for i := range results[0].Matched {
+		p := &results[0].Matched[i]
+		expect := encodeUnescaped(t, p)
+		got, err := p.AppendJSON([]byte("prefix"))
+		if err != nil {
+			t.Fatalf("AppendJSON: %v", err)
+		}
+		if string(got) != "prefix"+expect {
+			t.Errorf("Expect solution %d encoded as\n%s\ngot\n%s", i, expect, got)
+		}
+	}
+}
+
+func TestJavaErrorMarshalJSON(t *testing.T) {
+	jerr := &JavaError{
+		Class:      "java.lang.IllegalStateException",
+		Message:    "Failed to create <model>",
+		Stacktrace: Stacktrace{{Raw: "at com.example.Foo.bar(Foo.java:1)", Class: "com.example.Foo", Method: "bar", File: "Foo.java", Line: 1}},
+		LineNo:     2,
+		Time:       time.Date(2024, time.March, 5, 12, 34, 56, 0, time.UTC),
+		Level:      "ERROR",
+	}
+	expect, err := json.Marshal((*plainJavaError)(jerr))
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if got, err := json.Marshal(jerr); err != nil || string(got) != string(expect) {
+		t.Errorf("Expect the error encoded as\n%s\ngot\n%s, %v", expect, got, err)
+	}
+	// the zero time is omitted by the JSON encoders of all go versions, like AppendJSON
+	jerr.Time = time.Time{}
+	got, err := json.Marshal(jerr)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if strings.Contains(string(got), `"time"`) {
+		t.Errorf("Expect the zero time to be omitted, got %s", got)
+	}
+	unescaped := encodeUnescaped(t, jerr)
+	if res, err := (&ErrorResult{Error: jerr}).AppendJSON(nil); err != nil || !strings.Contains(string(res), unescaped) {
+		t.Errorf("Expect AppendJSON to encode the error as %s, got %s, %v", unescaped, res, err)
+	}
+}
+
+// encodeUnescaped encodes the value by a json.Encoder which does not escape the HTML characters
+func encodeUnescaped(t *testing.T, v any) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,147 @@
+package mcla
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LauncherInfo is what the launcher which wrapped the game reported besides the game log
+type LauncherInfo struct {
+	Launcher string `json:"launcher"` // the launcher name, same as LogMetadata.Launcher
+	Version  string `json:"version,omitempty"`
+	// ExitCode is the exit code of the game process, it's nil if the launcher did not report the exit
+	ExitCode *int `json:"exitCode,omitempty"`
+	// ExitReason is the launcher's line which reports the exit, e.g. `Process crashed with exitcode -1.`
+	ExitReason string `json:"exitReason,omitempty"`
+	// Crashed reports the launcher considers the exit as a crash
+	Crashed bool `json:"crashed,omitempty"`
+}
+
+var (
+	launcherBannerRes = []struct {
+		launcher string
+		re       *regexp.Regexp
+	}{
+		{"PrismLauncher", regexp.MustCompile(`^(?:Prism ?Launcher|PolyMC) version: (.+)$`)},
+		{"MultiMC", regexp.MustCompile(`^MultiMC version: (.+)$`)},
+		{"CurseForge", regexp.MustCompile(`^\[CurseForge\] (?:App )?[Vv]ersion: (.+)$`)},
+	}
+	// launcherExitRe matches the exit line of MultiMC, Prism Launcher and CurseForge
+	launcherExitRe = regexp.MustCompile(`^(?:\[CurseForge\]\s*)?(?:Process|Game|Minecraft) (crashed|exited) with (?:exit ?code|code) (-?\d+)\.?$`)
+	// launcherNoiseRe matches the other lines printed by the launchers between the game logs
+	launcherNoiseRe = regexp.MustCompile(`^(?:\[CurseForge\]|Minecraft process ID: \d+$|Clipboard copy at: |Killing process|Log upload triggered at: |Uploading paste to)`)
+	// gameLogLineRe matches the first line of the game log, e.g. `[12:00:00] [main/INFO]: ...`
+	gameLogLineRe = regexp.MustCompile(`^\[[^\]]*\d\d:\d\d:\d\d[^\]]*\]`)
+)
+
+// UnwrapLauncherLog detects the launcher which wrapped the log, and strips the launcher's own lines,
+// so only the game log is left. The info is nil if no launcher is detected, in that case the log is unchanged.
+//
+// Example of MultiMC and Prism Launcher:
+// ```
+// Prism Launcher version: 8.0 (official)
+//
+// Minecraft folder is:
+// /home/steve/.local/share/PrismLauncher/instances/1.20.1/.minecraft
+// ...
+// Minecraft process ID: 12345
+//
+// [12:00:00] [main/INFO]: ...
+// ...
+// Process crashed with exitcode -1.
+// Clipboard copy at: 02 Jan 2024 12:01:00 +0000
+// ```
+//
+// Example of CurseForge:
+// ```
+// [CurseForge] App version: 1.250.0
+// [CurseForge] Launching instance "All the Mods 9"
+// [12:00:00] [main/INFO]: ...
+// ...
+// [CurseForge] Game crashed with exit code 1
+// ```
+func UnwrapLauncherLog(r io.Reader) (io.Reader, *LauncherInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := strings.SplitAfter((string)(data), "\n")
+	info, start := findLauncherBanner(lines)
+	if info == nil {
+		return bytes.NewReader(data), nil, nil
+	}
+	if info.Launcher != "CurseForge" {
+		start = skipLauncherHeader(lines, start)
+	}
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, line := range lines[start:] {
+		l := strings.TrimSpace(line)
+		if matches := launcherExitRe.FindStringSubmatch(l); matches != nil {
+			code, _ := strconv.Atoi(matches[2])
+			info.ExitCode = &code
+			info.ExitReason = l
+			info.Crashed = matches[1] == "crashed" || code != 0
+			continue
+		}
+		if launcherNoiseRe.MatchString(l) {
+			continue
+		}
+		b.WriteString(line)
+	}
+	return strings.NewReader(b.String()), info, nil
+}
+
+// findLauncherBanner finds the banner of a launcher in the first lines,
+// and returns the index of the line after the banner
+func findLauncherBanner(lines []string) (info *LauncherInfo, next int) {
+	const maxBannerLine = 16
+	for i, line := range lines[:min(len(lines), maxBannerLine)] {
+		line = strings.TrimSpace(line)
+		for _, b := range launcherBannerRes {
+			if matches := b.re.FindStringSubmatch(line); matches != nil {
+				return &LauncherInfo{
+					Launcher: b.launcher,
+					Version:  strings.TrimSpace(matches[1]),
+				}, i + 1
+			}
+		}
+	}
+	return nil, 0
+}
+
+// skipLauncherHeader skips the instance infos printed by MultiMC and Prism Launcher before the game starts,
+// the header ends with the process ID line and the empty lines after it, or before the first game log line
+func skipLauncherHeader(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "Minecraft process ID: ") {
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) == ""; i++ {
+			}
+			return i
+		}
+		if gameLogLineRe.MatchString(line) {
+			return i
+		}
+	}
+	// the game did not start, keep the header since it may contain the reason
+	return start
+}
+
+// AnalyzeLauncherLog strips the framing of the launcher with UnwrapLauncherLog and analyzes the game log with AnalyzeLog.
+// The analysis reports what the launcher reported in LogAnalysis.Launcher
+func (a *Analyzer) AnalyzeLauncherLog(ctx context.Context, r io.Reader) (*LogAnalysis, error) {
+	log, info, err := UnwrapLauncherLog(r)
+	if err != nil {
+		return nil, err
+	}
+	analysis, err := a.AnalyzeLog(ctx, log)
+	if analysis != nil {
+		analysis.Launcher = info
+	}
+	return analysis, err
+}
@@ -0,0 +1,120 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"io"
+	"strings"
+)
+
+const prismWrappedLog = `Prism Launcher version: 8.0 (official)
+
+Launched instance in online mode
+login.microsoftonline.com resolves to:
+    [20.190.160.20]
+
+Minecraft folder is:
+/home/steve/.local/share/PrismLauncher/instances/1.20.1/.minecraft
+
+
+Java path is:
+/usr/lib/jvm/java-17/bin/java
+
+
+Java is version 17.0.9, using 64 (amd64) architecture, from Eclipse Adoptium.
+
+Main Class:
+  cpw.mods.bootstraplauncher.BootstrapLauncher
+
+Mods:
+  [✔]    examplemod-1.0.jar
+
+Params:
+  --username Steve --version 1.20.1 --gameDir /home/steve/.local/share/PrismLauncher/instances/1.20.1/.minecraft
+
+Window size: 854 x 480
+
+Minecraft process ID: 12345
+
+[12:00:00] [main/INFO]: Loading config
+[12:00:01] [Render thread/FATAL]: Unreported exception thrown!
+java.lang.NullPointerException: Cannot invoke "net.minecraft.world.entity.Entity.getId()" because "entity" is null
+	at com.example.mod.Foo.bar(Foo.java:42)
+	at net.minecraft.client.Minecraft.run(Minecraft.java:100)
+Process crashed with exitcode -1.
+Clipboard copy at: 02 Jan 2024 12:01:00 +0000
+`
+
+func TestUnwrapLauncherLog(t *testing.T) {
+	r, info, err := UnwrapLauncherLog(strings.NewReader(prismWrappedLog))
+	if err != nil {
+		t.Fatalf("UnwrapLauncherLog: %v", err)
+	}
+	if info == nil {
+		t.Fatalf("Expect the launcher to be detected")
+	}
+	if info.Launcher != "PrismLauncher" || info.Version != "8.0 (official)" {
+		t.Errorf("Expect PrismLauncher 8.0 (official), got %q %q", info.Launcher, info.Version)
+	}
+	if info.ExitCode == nil || *info.ExitCode != -1 || !info.Crashed {
+		t.Errorf("Expect the crash with exit code -1, got %#v", info)
+	}
+	if expect := "Process crashed with exitcode -1."; info.ExitReason != expect {
+		t.Errorf("Expect info.ExitReason == %q, got %q", expect, info.ExitReason)
+	}
+	log, _ := io.ReadAll(r)
+	if expect := "[12:00:00] [main/INFO]: Loading config\n"; !strings.HasPrefix((string)(log), expect) {
+		t.Errorf("Expect the game log to start with %q, got %q", expect, log)
+	}
+	for _, framing := range []string{"Prism Launcher", "Minecraft process ID", "Process crashed", "Clipboard copy", "BootstrapLauncher"} {
+		if strings.Contains((string)(log), framing) {
+			t.Errorf("Expect the launcher line %q to be stripped", framing)
+		}
+	}
+
+	analysis, err := NewAnalyzer(&memErrorDB{}).AnalyzeLauncherLog(context.Background(), strings.NewReader(prismWrappedLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLauncherLog: %v", err)
+	}
+	if len(analysis.Results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+	}
+	if je := analysis.Results[0].Error; je.Class != "java.lang.NullPointerException" || len(je.Stacktrace) != 2 || je.LineNo != 3 {
+		t.Errorf("Expect the inner crash at line 3 with 2 frames, got %s at line %d with %d frames", je.Class, je.LineNo, len(je.Stacktrace))
+	}
+	if analysis.Launcher == nil || analysis.Launcher.Launcher != "PrismLauncher" {
+		t.Errorf("Expect the analysis to report the launcher, got %#v", analysis.Launcher)
+	}
+}
+
+func TestUnwrapLauncherLogCurseForge(t *testing.T) {
+	const log = `[CurseForge] App version: 1.250.0
+[CurseForge] Launching instance "All the Mods 9"
+[12:00:00] [main/INFO]: Loading config
+[CurseForge] Game crashed with exit code 1
+`
+	r, info, err := UnwrapLauncherLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("UnwrapLauncherLog: %v", err)
+	}
+	if info == nil || info.Launcher != "CurseForge" || info.Version != "1.250.0" {
+		t.Fatalf("Expect CurseForge 1.250.0, got %#v", info)
+	}
+	if info.ExitCode == nil || *info.ExitCode != 1 || !info.Crashed {
+		t.Errorf("Expect the crash with exit code 1, got %#v", info)
+	}
+	if data, _ := io.ReadAll(r); (string)(data) != "[12:00:00] [main/INFO]: Loading config\n" {
+		t.Errorf("Expect only the game log is left, got %q", data)
+	}
+
+	// a log without a launcher is unchanged
+	r, info, err = UnwrapLauncherLog(strings.NewReader(contextLog))
+	if err != nil || info != nil {
+		t.Fatalf("Expect no launcher, got %#v, %v", info, err)
+	}
+	if data, _ := io.ReadAll(r); (string)(data) != contextLog {
+		t.Errorf("Expect the log to be unchanged")
+	}
+}
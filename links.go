@@ -0,0 +1,66 @@
+package mcla
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Link is a reference of an error description, e.g. a wiki page or an issue
+type Link struct {
+	URL   string `json:"url"`
+	Label string `json:"label,omitempty"`
+}
+
+// InvalidLinkErr is passed to Analyzer.OnInvalidDesc for a malformed link, the link is skipped but the description is kept
+type InvalidLinkErr struct {
+	Link Link
+	Err  error
+}
+
+func (e *InvalidLinkErr) Error() string {
+	return fmt.Sprintf("Invalid link %q: %v", e.Link.URL, e.Err)
+}
+
+func (e *InvalidLinkErr) Unwrap() error {
+	return e.Err
+}
+
+var errNotHTTPURL = errors.New("not an absolute http or https URL")
+
+// checkLink reports whether the link is an absolute http or https URL
+func checkLink(l Link) error {
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errNotHTTPURL
+	}
+	return nil
+}
+
+// checkLinks returns the description without the malformed links, it's copied if any link is skipped
+func (a *Analyzer) checkLinks(e *ErrorDesc) *ErrorDesc {
+	var links []Link
+	for i, l := range e.Links {
+		err := checkLink(l)
+		if err == nil {
+			if links != nil {
+				links = append(links, l)
+			}
+			continue
+		}
+		a.invalidDesc(e, &InvalidLinkErr{Link: l, Err: err})
+		if links == nil {
+			links = make([]Link, i, len(e.Links))
+			copy(links, e.Links)
+		}
+	}
+	if links == nil {
+		return e
+	}
+	c := *e
+	c.Links = links
+	return &c
+}
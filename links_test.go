@@ -0,0 +1,67 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"errors"
+	"slices"
+	"strings"
+)
+
+func TestErrorDescLinks(t *testing.T) {
+	desc := &ErrorDesc{
+		Error:   "java.lang.UnsupportedClassVersionError",
+		Message: "",
+		Links: []Link{
+			{URL: "https://minecraft.wiki/w/Tutorials/Update_Java", Label: "Update Java"},
+			{URL: "javascript:alert(1)"},
+			{URL: "https://example.com/a page_(test)"},
+			{URL: "://broken"},
+		},
+	}
+	db := &memErrorDB{errors: []*ErrorDesc{desc}}
+	analyzer := NewAnalyzer(db)
+	var invalid []string
+	analyzer.OnInvalidDesc = func(e *ErrorDesc, err error) {
+		var linkErr *InvalidLinkErr
+		if !errors.As(err, &linkErr) {
+			t.Errorf("Expect an *InvalidLinkErr, got %v", err)
+			return
+		}
+		invalid = append(invalid, linkErr.Link.URL)
+	}
+	matched, err := analyzer.DoError(&JavaError{Class: "java.lang.UnsupportedClassVersionError", Message: "class file version 65.0"})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 1 {
+		t.Fatalf("Expect the description to be kept, got %d matches", len(matched))
+	}
+	if expect := []string{"javascript:alert(1)", "://broken"}; !slices.Equal(invalid, expect) {
+		t.Errorf("Expect the invalid links == %q, got %q", expect, invalid)
+	}
+	expect := []Link{
+		{URL: "https://minecraft.wiki/w/Tutorials/Update_Java", Label: "Update Java"},
+		{URL: "https://example.com/a page_(test)"},
+	}
+	if links := matched[0].Links; !slices.Equal(links, expect) {
+		t.Errorf("Expect Links == %v, got %v", expect, links)
+	}
+	if len(desc.Links) != 4 {
+		t.Errorf("Expect the description in the database not to be modified, got %v", desc.Links)
+	}
+
+	report := FormatMarkdown([]*ErrorResult{{
+		Error:   &JavaError{Class: "java.lang.UnsupportedClassVersionError", Message: "class file version 65.0"},
+		Matched: matched,
+	}})
+	for _, line := range []string{
+		"  - [Update Java](https://minecraft.wiki/w/Tutorials/Update_Java)\n",
+		"  - [https://example\\.com/a page\\_\\(test\\)](https://example.com/a%20page_%28test%29)\n",
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("Expect the report to contain %q, got\n%s", line, report)
+		}
+	}
+}
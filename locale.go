@@ -0,0 +1,47 @@
+package mcla
+
+import (
+	"strings"
+)
+
+// LocalizedDescription returns the description in the locale, the locales are compared case insensitively and `_` is same as `-`.
+// If there is no translation for the locale, the translation of its language is used, e.g. "zh" for "zh-TW",
+// then a translation of another region of the language, e.g. "zh-CN" for "zh". Description is the fallback
+func (e *ErrorDesc) LocalizedDescription(locale string) string {
+	if locale == "" || len(e.Descriptions) == 0 {
+		return e.Description
+	}
+	locale = normalizeLocale(locale)
+	lang, _, _ := strings.Cut(locale, "-")
+	var (
+		best     string
+		bestKey  string
+		bestRank = 0 // 1 for another region of the language, 2 for the language
+	)
+	for key, desc := range e.Descriptions {
+		key = normalizeLocale(key)
+		rank := 0
+		switch {
+		case key == locale:
+			return desc
+		case key == lang:
+			rank = 2
+		case strings.HasPrefix(key, lang+"-"):
+			rank = 1
+		default:
+			continue
+		}
+		// the smaller key wins a tie, so the choice does not depend on the map order
+		if rank > bestRank || (rank == bestRank && key < bestKey) {
+			best, bestKey, bestRank = desc, key, rank
+		}
+	}
+	if bestRank > 0 {
+		return best
+	}
+	return e.Description
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
@@ -0,0 +1,65 @@
+package mcla_test
+
+import (
+	"testing"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+func TestLocalizedDescription(t *testing.T) {
+	desc := &ErrorDesc{
+		Description: "Increase the memory",
+		Descriptions: map[string]string{
+			"zh_CN": "增加内存",
+			"zh-TW": "增加記憶體",
+			"ja":    "メモリを増やす",
+		},
+	}
+	for _, d := range []struct {
+		Locale string
+		Expect string
+	}{
+		{"", "Increase the memory"},
+		{"en-US", "Increase the memory"},
+		{"zh-CN", "增加内存"},
+		{"zh-cn", "增加内存"},
+		{"zh_TW", "增加記憶體"},
+		{"zh-HK", "增加内存"}, // another region of the language, the smaller key wins
+		{"zh", "增加内存"},
+		{"ja-JP", "メモリを増やす"},
+	} {
+		if got := desc.LocalizedDescription(d.Locale); got != d.Expect {
+			t.Errorf("Expect description in %q == %q, got %q", d.Locale, d.Expect, got)
+		}
+	}
+	if got := (&ErrorDesc{Description: "only"}).LocalizedDescription("zh-CN"); got != "only" {
+		t.Errorf("Expect the description without translations, got %q", got)
+	}
+}
+
+func TestAnalyzerLocale(t *testing.T) {
+	desc := &ErrorDesc{
+		Error:        "java.lang.IllegalStateException",
+		Message:      "Not building!",
+		Description:  "A mod renders outside of the render thread",
+		Descriptions: map[string]string{"zh-CN": "有模组在渲染线程之外渲染"},
+	}
+	jerr := &JavaError{Class: "java.lang.IllegalStateException", Message: "Not building!"}
+	for _, d := range []struct {
+		Locale string
+		Expect string
+	}{
+		{"", desc.Description},
+		{"zh-CN", "有模组在渲染线程之外渲染"},
+	} {
+		analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+		analyzer.Locale = d.Locale
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		if len(matched) != 1 || matched[0].Description != d.Expect {
+			t.Errorf("Expect the description in %q == %q, got %#v", d.Locale, d.Expect, matched)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package mcla
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultSkipFramePrefixes are the JDK and internal packages which are skipped when locating a crash
+var DefaultSkipFramePrefixes = []string{
+	"java.", "javax.", "jdk.", "sun.", "com.sun.",
+}
+
+// CrashLocation is the most relevant stack frame of an error
+type CrashLocation struct {
+	Class  string `json:"class"`
+	Method string `json:"method"`
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+}
+
+// String returns the location like `ClassName.method (File.java:42)`
+func (l *CrashLocation) String() string {
+	var b strings.Builder
+	b.WriteString(l.Class)
+	b.WriteByte('.')
+	b.WriteString(l.Method)
+	if l.File != "" {
+		b.WriteString(" (")
+		b.WriteString(l.File)
+		if l.Line > 0 {
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(l.Line))
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+var frameSourceRe = regexp.MustCompile(`\(([^():]+)(?::(\d+))?\)`)
+
+func locationOfFrame(s StackInfo) *CrashLocation {
+	loc := &CrashLocation{
+		Class:  s.Class,
+		Method: s.Method,
+	}
+	if matches := frameSourceRe.FindStringSubmatch(s.Raw); matches != nil {
+		loc.File = matches[1]
+		loc.Line, _ = strconv.Atoi(matches[2])
+	}
+	return loc
+}
+
+func (a *Analyzer) skipFrame(s StackInfo) bool {
+	prefixes := a.SkipFramePrefixes
+	if prefixes == nil {
+		prefixes = DefaultSkipFramePrefixes
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s.Class, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LocateCrash picks the top stack frame which is not skipped by the analyzer's SkipFramePrefixes.
+// The stacktrace of the `-- Head --` section is preferred when head is not nil.
+// If all frames are skipped, the top frame is used
+func (a *Analyzer) LocateCrash(jerr *JavaError, head *HeadThread) *CrashLocation {
+	st := jerr.Stacktrace
+	if head != nil && len(head.Stacktrace) > 0 {
+		st = head.Stacktrace
+	}
+	if len(st) == 0 {
+		return nil
+	}
+	for _, s := range st {
+		if !a.skipFrame(s) {
+			return locationOfFrame(s)
+		}
+	}
+	return locationOfFrame(st[0])
+}
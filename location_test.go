@@ -0,0 +1,50 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+)
+
+func TestCrashLocation(t *testing.T) {
+	const log = `[12:00:00] [Render thread/ERROR]: Unreported exception thrown!
+java.lang.NullPointerException: Cannot invoke "Object.toString()" because "value" is null
+	at java.base/java.util.Objects.requireNonNull(Objects.java:233)
+	at jdk.internal.reflect.DirectMethodHandleAccessor.invoke(DirectMethodHandleAccessor.java:103)
+	at com.example.mod.client.Renderer.render(Renderer.java:42) ~[examplemod-1.0.0.jar%23120!/:1.0.0] {re:classloading}
+	at net.minecraft.client.renderer.GameRenderer.render(GameRenderer.java:954) ~[client-1.20.1-srg.jar%23156!/:?]
+`
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+	}
+	loc := analysis.Results[0].Location
+	if loc == nil {
+		t.Fatalf("Expect a crash location, got nil")
+	}
+	if expect := "com.example.mod.client.Renderer.render (Renderer.java:42)"; loc.String() != expect {
+		t.Errorf("Expect location %q, got %q", expect, loc.String())
+	}
+
+	analyzer.SkipFramePrefixes = []string{"java.", "jdk.", "com.example."}
+	loc = analyzer.LocateCrash(analysis.Results[0].Error, nil)
+	if expect := "net.minecraft.client.renderer.GameRenderer.render (GameRenderer.java:954)"; loc.String() != expect {
+		t.Errorf("Expect location %q with custom prefixes, got %q", expect, loc.String())
+	}
+
+	report, err := ParseCrashReport(strings.NewReader(moddedCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	analyzer.SkipFramePrefixes = nil
+	loc = analyzer.LocateCrash(report.Error, &report.HeadThread)
+	if expect := "com.example.mod.client.Renderer.render (Renderer.java:42)"; loc.String() != expect {
+		t.Errorf("Expect location %q from the head section, got %q", expect, loc.String())
+	}
+}
@@ -0,0 +1,44 @@
+package mcla
+
+import (
+	"regexp"
+)
+
+var (
+	// matches `[12:34:56] [Render thread/WARN]`, `[12:34:56] [WARN]` and `[12:34:56 WARN]`
+	logLevelRe = regexp.MustCompile(`^\[(?:[^\]]*\]\s*\[(?:[^\]]*/)?|\d{1,2}:\d{2}:\d{2}(?:[.,]\d+)?\s+)(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|SEVERE|FATAL)\]`)
+)
+
+// DefaultRecoveredLevels are the log levels of the errors which are logged and recovered by the game
+var DefaultRecoveredLevels = []string{"TRACE", "DEBUG", "INFO", "WARN"}
+
+// parseLogLevel parses the level in the header of a log line, WARNING and SEVERE are normalized to WARN and ERROR
+func parseLogLevel(line string) (level string, ok bool) {
+	matches := logLevelRe.FindStringSubmatch(line)
+	if matches == nil {
+		return
+	}
+	switch level = matches[1]; level {
+	case "WARNING":
+		level = "WARN"
+	case "SEVERE":
+		level = "ERROR"
+	}
+	return level, true
+}
+
+func (a *Analyzer) isRecovered(jerr *JavaError) bool {
+	if jerr.Level == "" {
+		return false
+	}
+	levels := a.RecoveredLevels
+	if levels == nil {
+		levels = DefaultRecoveredLevels
+	}
+	for _, l := range levels {
+		if l == jerr.Level {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package mcla
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// markdownSpecials are the characters which may start a Markdown syntax, they are escaped by a backslash
+const markdownSpecials = "\\`*_{}[]<>()#+-.!|~"
+
+// markdownURLEscaper escapes the characters which may end the link destination of Markdown
+var markdownURLEscaper = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20", "<", "%3C", ">", "%3E")
+
+// escapeMarkdown escapes the log text so it's rendered as is, e.g. `__init__` is not rendered in bold
+func escapeMarkdown(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if strings.ContainsRune(markdownSpecials, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FormatMarkdown renders the results as a Markdown report for the issues or the chats.
+// Each top level error is rendered with its cause chain, the location, the suspected mod,
+// and the matched descriptions of the error and its causes as bullet points sorted by the match.
+// The log text is escaped, so it cannot break the report
+func FormatMarkdown(results []*ErrorResult) string {
+	var b strings.Builder
+	writeMarkdownResults(&b, results)
+	return b.String()
+}
+
+// FormatAnalysisMarkdown is same as FormatMarkdown, but also renders the environment if the log is a crash report
+func FormatAnalysisMarkdown(analysis *LogAnalysis) string {
+	var b strings.Builder
+	if analysis.CrashReport != nil && analysis.CrashReport.Environment != nil {
+		writeMarkdownEnvironment(&b, analysis.CrashReport.Environment)
+	}
+	writeMarkdownResults(&b, analysis.Results)
+	return b.String()
+}
+
+func writeMarkdownEnvironment(b *strings.Builder, env *Environment) {
+	b.WriteString("### Environment\n\n")
+	if env.MinecraftVersion != "" {
+		fmt.Fprintf(b, "- **Minecraft:** %s\n", escapeMarkdown(env.MinecraftVersion))
+	}
+	if env.Loader != "" {
+		fmt.Fprintf(b, "- **Loader:** %s %s\n", escapeMarkdown(env.Loader), escapeMarkdown(env.LoaderVersion))
+	}
+	if env.JavaVersion != "" {
+		fmt.Fprintf(b, "- **Java:** %s\n", escapeMarkdown(env.JavaVersion))
+	}
+	if len(env.Mods) > 0 {
+		fmt.Fprintf(b, "- **Mods:** %d loaded\n", len(env.Mods))
+	}
+	b.WriteByte('\n')
+}
+
+// writeMarkdownQuote writes the multiline text as a block quote
+func writeMarkdownQuote(b *strings.Builder, text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString("> ")
+		b.WriteString(escapeMarkdown(strings.TrimRight(line, "\r")))
+		b.WriteByte('\n')
+	}
+}
+
+func writeMarkdownResults(b *strings.Builder, results []*ErrorResult) {
+	tops := topLevelResults(results)
+	if len(tops) == 0 {
+		b.WriteString("No error is found.\n")
+		return
+	}
+	byError := make(map[*JavaError]*ErrorResult, len(results))
+	for _, res := range results {
+		byError[res.Error] = res
+	}
+	// the default analyzer only decides the frames to skip in the mod attribution
+	var attributor Analyzer
+	for i, res := range tops {
+		if i > 0 {
+			b.WriteString("\n---\n\n")
+		}
+		jerr := res.Error
+		title := jerr.Class
+		if title == "" {
+			title = "Crash"
+		}
+		fmt.Fprintf(b, "### %d. %s", i+1, escapeMarkdown(title))
+		if res.Primary {
+			b.WriteString(" (primary)")
+		}
+		b.WriteString("\n\n")
+		if jerr.Message != "" {
+			writeMarkdownQuote(b, jerr.Message)
+			b.WriteByte('\n')
+		}
+		if res.File != "" || jerr.LineNo > 0 {
+			b.WriteString("- **Found at:** ")
+			if res.File != "" {
+				b.WriteString(escapeMarkdown(res.File))
+				b.WriteByte(' ')
+			}
+			if jerr.LineNo > 0 {
+				fmt.Fprintf(b, "line %d", jerr.LineNo)
+			}
+			b.WriteByte('\n')
+		}
+		if res.Location != nil {
+			fmt.Fprintf(b, "- **Location:** %s\n", escapeMarkdown(res.Location.String()))
+		}
+		if modid, confidence := attributor.AttributeMod(jerr); modid != "" {
+			fmt.Fprintf(b, "- **Suspected mod:** %s (%.0f%%)\n", escapeMarkdown(modid), confidence*100)
+		}
+		if res.Mixin != nil {
+			fmt.Fprintf(b, "- **Mixin:** %s (%s)\n", escapeMarkdown(res.Mixin.Mod), escapeMarkdown(res.Mixin.Config))
+		}
+		if res.Count > 1 {
+			fmt.Fprintf(b, "- **Repeated:** %d times, last at line %d\n", res.Count, res.LastLine)
+		}
+		chain := errorChain(jerr)
+		if len(chain) > 1 {
+			b.WriteString("\n**Caused by:**\n\n")
+			for j, cause := range chain[1:] {
+				fmt.Fprintf(b, "%d. %s", j+1, escapeMarkdown(cause.Class))
+				if msg, _ := split(cause.Message, '\n'); msg != "" {
+					b.WriteString(": ")
+					b.WriteString(escapeMarkdown(msg))
+				}
+				b.WriteByte('\n')
+			}
+		}
+		b.WriteString("\n**Solutions:**\n\n")
+		matched := markdownMatches(chain, byError)
+		if len(matched) == 0 {
+			b.WriteString("- No known solution\n")
+		}
+		for _, m := range matched {
+			fmt.Fprintf(b, "- **%.0f%%** %s", m.Match*100, escapeMarkdown(describeMatch(m)))
+			if ids := m.ErrorDesc.Solutions; len(ids) > 0 {
+				b.WriteString(" (solutions")
+				for j, id := range ids {
+					if j > 0 {
+						b.WriteByte(',')
+					}
+					fmt.Fprintf(b, " #%d", id)
+				}
+				b.WriteByte(')')
+			}
+			b.WriteByte('\n')
+			for _, l := range m.Links {
+				label := l.Label
+				if label == "" {
+					label = l.URL
+				}
+				fmt.Fprintf(b, "  - [%s](%s)\n", escapeMarkdown(label), markdownURLEscaper.Replace(l.URL))
+			}
+		}
+	}
+}
+
+// markdownMatches collects the matches of the errors in the chain, each description is listed once with its best match
+func markdownMatches(chain []*JavaError, byError map[*JavaError]*ErrorResult) (matched []SolutionPossibility) {
+	for _, je := range chain {
+		res, ok := byError[je]
+		if !ok {
+			continue
+		}
+		for _, m := range res.Matched {
+			if i := slices.IndexFunc(matched, func(o SolutionPossibility) bool { return o.ErrorDesc == m.ErrorDesc }); i >= 0 {
+				matched[i].Match = max(matched[i].Match, m.Match)
+				continue
+			}
+			matched = append(matched, m)
+		}
+	}
+	slices.SortStableFunc(matched, func(x, y SolutionPossibility) int {
+		return cmp.Compare(y.Match, x.Match)
+	})
+	return
+}
+
+// describeMatch returns the localized description of the match, or the pattern of its ErrorDesc if it does not have a description
+func describeMatch(m SolutionPossibility) string {
+	if m.Description != "" {
+		return m.Description
+	}
+	e := m.ErrorDesc
+	if e.Description != "" {
+		return e.Description
+	}
+	text := e.Error
+	if e.Message != "" {
+		if text != "" {
+			text += ": "
+		}
+		text += e.Message
+	}
+	return text
+}
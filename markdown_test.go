@@ -0,0 +1,78 @@
+package mcla_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+func TestFormatMarkdown(t *testing.T) {
+	cause := &JavaError{
+		Class:   "java.lang.ClassNotFoundException",
+		Message: "net.minecraft.client.Foo_Bar",
+		LineNo:  4,
+	}
+	jerr := &JavaError{
+		Class:   "java.lang.RuntimeException",
+		Message: "Failed to load *mixins* from [examplemod]",
+		Stacktrace: Stacktrace{
+			{Class: "com.example.mod.Loader", Method: "load"},
+		},
+		CausedBy: cause,
+		LineNo:   2,
+	}
+	low := &ErrorDesc{Error: "java.lang.RuntimeException", Message: "Failed to load", Solutions: []int{3}}
+	high := &ErrorDesc{Error: "java.lang.ClassNotFoundException", Description: "A client class is loaded on the server"}
+	results := []*ErrorResult{
+		{Error: jerr, Primary: true, Matched: []SolutionPossibility{{ErrorDesc: low, Match: 0.5}}},
+		{Error: cause, Matched: []SolutionPossibility{{ErrorDesc: high, Match: 0.9}, {ErrorDesc: low, Match: 0.7}}},
+	}
+	md := FormatMarkdown(results)
+	for _, expect := range []string{
+		"### 1. java\\.lang\\.RuntimeException (primary)\n",
+		"> Failed to load \\*mixins\\* from \\[examplemod\\]\n",
+		"1. java\\.lang\\.ClassNotFoundException: net\\.minecraft\\.client\\.Foo\\_Bar\n",
+		"- **Suspected mod:** ",
+		"- **90%** A client class is loaded on the server\n",
+		"- **70%** java\\.lang\\.RuntimeException: Failed to load (solutions #3)\n",
+	} {
+		if !strings.Contains(md, expect) {
+			t.Errorf("Expect the report contains %q, got:\n%s", expect, md)
+		}
+	}
+	if strings.Contains(md, "### 2.") {
+		t.Errorf("Expect the cause is not rendered as a top level error, got:\n%s", md)
+	}
+	if strings.Index(md, "**90%**") > strings.Index(md, "**70%**") {
+		t.Errorf("Expect the solutions are sorted by the match, got:\n%s", md)
+	}
+
+	if md := FormatMarkdown(nil); md != "No error is found.\n" {
+		t.Errorf("Expect no error report, got %q", md)
+	}
+}
+
+func TestFormatAnalysisMarkdown(t *testing.T) {
+	analysis := &LogAnalysis{
+		CrashReport: &CrashReport{
+			Environment: &Environment{
+				MinecraftVersion: "1.20.1",
+				Loader:           "Forge",
+				LoaderVersion:    "47.2.0",
+				Mods:             []ModInfo{{ID: "examplemod"}},
+			},
+		},
+	}
+	md := FormatAnalysisMarkdown(analysis)
+	for _, expect := range []string{
+		"- **Minecraft:** 1\\.20\\.1\n",
+		"- **Loader:** Forge 47\\.2\\.0\n",
+		"- **Mods:** 1 loaded\n",
+		"No error is found.\n",
+	} {
+		if !strings.Contains(md, expect) {
+			t.Errorf("Expect the report contains %q, got:\n%s", expect, md)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package mcla
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// hasMessage reports whether the description has any message to match
+func (e *ErrorDesc) hasMessage() bool {
+	return e.Message != "" || len(e.Messages) > 0
+}
+
+// bestMessageMatch returns the best score of Message and its alternatives in Messages, the empty ones are skipped.
+// It's the max instead of the average, so one good alternative wins
+func (e *ErrorDesc) bestMessageMatch(score func(message string) float32) (best float32) {
+	if e.Message != "" {
+		best = score(e.Message)
+	}
+	for _, m := range e.Messages {
+		if best >= 1 {
+			break
+		}
+		if m != "" {
+			best = max(best, score(m))
+		}
+	}
+	return
+}
+
+// UnmarshalJSON decodes the description, the message can be a string or an array of the alternatives.
+// For an array, the first message is Message, and the others are prepended to Messages
+func (e *ErrorDesc) UnmarshalJSON(data []byte) (err error) {
+	type errorDesc ErrorDesc
+	var v struct {
+		*errorDesc
+		Message json.RawMessage `json:"message"`
+	}
+	v.errorDesc = (*errorDesc)(e)
+	if err = json.Unmarshal(data, &v); err != nil {
+		return
+	}
+	if len(v.Message) == 0 || string(v.Message) == "null" {
+		return
+	}
+	if v.Message[0] != '[' {
+		return json.Unmarshal(v.Message, &e.Message)
+	}
+	var messages []string
+	if err = json.Unmarshal(v.Message, &messages); err != nil {
+		return fmt.Errorf("mcla: message must be a string or an array of strings: %w", err)
+	}
+	if len(messages) > 0 {
+		e.Message = messages[0]
+		e.Messages = append(messages[1:], e.Messages...)
+	}
+	return
+}
@@ -0,0 +1,89 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"encoding/json"
+	"slices"
+)
+
+func TestAlternativeMessages(t *testing.T) {
+	const missingEntry = "Missing key in ResourceKey[minecraft:root / minecraft:block]: examplemod:copper_lamp"
+	desc := &ErrorDesc{
+		Error:    "java.lang.IllegalStateException",
+		Message:  "Unknown registry key in ResourceKey[minecraft:root / minecraft:block]: examplemod:copper_lamp",
+		Messages: []string{"Some completely unrelated wording of another error", missingEntry},
+	}
+	single := &ErrorDesc{
+		Error:   "java.lang.IllegalArgumentException",
+		Message: missingEntry,
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc, single}})
+	matched, err := analyzer.DoError(&JavaError{
+		Class:   "java.lang.IllegalStateException",
+		Message: missingEntry,
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) == 0 || !sameDesc(matched[0].ErrorDesc, desc) {
+		t.Fatalf("Expect the description with the alternatives to be the best match, got %#v", matched)
+	}
+	if matched[0].Match != 1 {
+		t.Errorf("Expect the best alternative to score 1, got %v", matched[0].Match)
+	}
+
+	regex := &ErrorDesc{
+		Error:          "java.lang.ClassNotFoundException",
+		Message:        `Could not find class (?P<class>[\w.$]+)`,
+		Messages:       []string{`(?P<class>[\w.$]+) \(\w+\)`},
+		MessageIsRegex: true,
+	}
+	analyzer = NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{regex}})
+	matched, err = analyzer.DoError(&JavaError{
+		Class:   "java.lang.ClassNotFoundException",
+		Message: "net.minecraft.client.renderer.RenderType (client)",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 1 || matched[0].Match != 1 {
+		t.Fatalf("Expect the regex alternative to match, got %#v", matched)
+	}
+	if expect := "net.minecraft.client.renderer.RenderType"; matched[0].Captures["class"] != expect {
+		t.Errorf("Expect captures[class] == %q, got %q", expect, matched[0].Captures["class"])
+	}
+}
+
+func TestErrorDescUnmarshalMessages(t *testing.T) {
+	datas := []struct {
+		JSON     string
+		Message  string
+		Messages []string
+	}{
+		{`{"error":"a.B","message":"one"}`, "one", nil},
+		{`{"error":"a.B","message":["one","two"]}`, "one", []string{"two"}},
+		{`{"error":"a.B","message":["one","two"],"messages":["three"]}`, "one", []string{"two", "three"}},
+		{`{"error":"a.B","messages":["two"]}`, "", []string{"two"}},
+		{`{"error":"a.B","message":null}`, "", nil},
+		{`{"error":"a.B","message":[]}`, "", nil},
+	}
+	for _, d := range datas {
+		var desc ErrorDesc
+		if err := json.Unmarshal([]byte(d.JSON), &desc); err != nil {
+			t.Errorf("Unmarshal %s: %v", d.JSON, err)
+			continue
+		}
+		if desc.Error != "a.B" {
+			t.Errorf("Expect error == %q for %s, got %q", "a.B", d.JSON, desc.Error)
+		}
+		if desc.Message != d.Message || !slices.Equal(desc.Messages, d.Messages) {
+			t.Errorf("Expect message == %q and messages == %q for %s, got %q and %q", d.Message, d.Messages, d.JSON, desc.Message, desc.Messages)
+		}
+	}
+	var desc ErrorDesc
+	if err := json.Unmarshal([]byte(`{"message":1}`), &desc); err == nil {
+		t.Errorf("Expect an error for a number message")
+	}
+}
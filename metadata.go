@@ -0,0 +1,111 @@
+package mcla
+
+import (
+	"strconv"
+	"strings"
+)
+
+type ModdedState string
+
+const (
+	ModdedUnknown ModdedState = ""
+	ModdedYes     ModdedState = "yes"
+	ModdedNo      ModdedState = "no"
+)
+
+// LogMetadata is the information about the game environment that produced the log
+type LogMetadata struct {
+	Modded          ModdedState `json:"modded,omitempty"`
+	ModdedReason    string      `json:"moddedReason,omitempty"`    // e.g. "Client brand changed to 'forge'"
+	LaunchedVersion string      `json:"launchedVersion,omitempty"` // the version name given by the launcher
+	Launcher        string      `json:"launcher,omitempty"`        // the known launcher name, empty if cannot tell
+	Type            string      `json:"type,omitempty"`            // e.g. "Client (map_client.txt)"
+	JavaVersion     int         `json:"javaVersion,omitempty"`     // the major version of the running Java, zero if unknown
+}
+
+// knownLaunchers are matched case-insensitively against the launched version
+var knownLaunchers = []string{
+	"HMCL", "PCL", "MultiMC", "PrismLauncher", "Prism", "ATLauncher", "CurseForge",
+	"GDLauncher", "TLauncher", "SKlauncher", "BakaXL", "Modrinth",
+}
+
+// parseModdedState parses the `Is Modded` field of the crash report, e.g.
+// `Definitely; Client brand changed to 'forge'`, `Probably not. Client jar signature and brand is untouched`
+func parseModdedState(value string) (state ModdedState, reason string) {
+	if value == "" {
+		return ModdedUnknown, ""
+	}
+	verdict, reason := value, ""
+	if i := strings.IndexAny(value, ";."); i >= 0 {
+		verdict, reason = value[:i], strings.TrimSpace(value[i+1:])
+	}
+	switch strings.ToLower(strings.TrimSpace(verdict)) {
+	case "definitely", "very likely", "probably", "yes":
+		state = ModdedYes
+	case "probably not", "no":
+		state = ModdedNo
+	default:
+		state = ModdedUnknown
+	}
+	return
+}
+
+func detectLauncher(launchedVersion string) string {
+	lower := strings.ToLower(launchedVersion)
+	for _, name := range knownLaunchers {
+		if strings.Contains(lower, strings.ToLower(name)) {
+			return name
+		}
+	}
+	return ""
+}
+
+// Metadata extracts the metadata from the `-- System Details --` section
+func (report *CrashReport) Metadata() (meta *LogMetadata) {
+	details := report.GetDetails("System Details").Details
+	meta = new(LogMetadata)
+	meta.Modded, meta.ModdedReason = parseModdedState(details.Get("Is Modded"))
+	meta.LaunchedVersion = details.Get("Launched Version")
+	meta.Launcher = detectLauncher(meta.LaunchedVersion)
+	meta.Type = details.Get("Type")
+	meta.JavaVersion = parseJavaVersion(details.Get("Java Version"))
+	return
+}
+
+// parseJavaVersion parses the major version from `17.0.8, Eclipse Adoptium` or `1.8.0_381, Oracle Corporation`,
+// it returns zero if the version is unknown
+func parseJavaVersion(value string) int {
+	value = strings.TrimSpace(value)
+	end := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end >= 0 {
+		value = value[:end]
+	}
+	major, minor, _ := strings.Cut(value, ".")
+	if major == "1" { // the versions before Java 9 are like 1.8
+		major, _, _ = strings.Cut(minor, ".")
+	}
+	v, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// metadataAllows checks the scope of the description, the unknown metadata will not exclude any description
+func metadataAllows(e *ErrorDesc, meta *LogMetadata) bool {
+	if meta == nil {
+		return true
+	}
+	if e.Modded != ModdedUnknown && meta.Modded != ModdedUnknown && e.Modded != meta.Modded {
+		return false
+	}
+	if len(e.Launchers) > 0 && meta.Launcher != "" {
+		for _, l := range e.Launchers {
+			if strings.EqualFold(l, meta.Launcher) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package mcla
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MixinSource is the mod which registered the failing mixin, found by the name of its mixin config or refmap
+type MixinSource struct {
+	Mod    string `json:"mod"`
+	Config string `json:"config"`          // the mixin config or refmap name, e.g. `mymod.mixins.json`
+	Mixin  string `json:"mixin,omitempty"` // the simple name of the mixin class, if known
+}
+
+var (
+	mixinLogRe = regexp.MustCompile(`^\[[^\]]*\]\s*\[[^\]]*\]\s*\[mixin/[^\]]*\]:\s*(.+)$`)
+	// mixinMessageLogRe matches the mixin failures logged without the mixin logger name,
+	// e.g. `[12:00:00] [main/ERROR]: Mixin apply for mod examplemod failed ...`
+	mixinMessageLogRe = regexp.MustCompile(`^\[[^\]]*\]\s*\[[^\]]*\]:\s*((?:Mixin apply |Method overwrite conflict |@\w+ conflict|Critical problem: |Mixing ).+)$`)
+	mixinConfigRe     = regexp.MustCompile(`[\w\-]+(?:\.[\w\-]+)*\.json(?::([\w$]+(?:\.[\w$]+)*))?`)
+	// mixinTargetRe matches the target class of a mixin log, e.g. `-> net.minecraft.client.Minecraft`
+	mixinTargetRe = regexp.MustCompile(`->\s*([\w$]+(?:[./][\w$]+)+)`)
+)
+
+// DefaultMixinLogPatterns matches the mixin log lines of Forge, e.g. `[12:00:00] [main/INFO] [mixin/]: Loaded 12 mixins`,
+// and the mixin failures logged without the mixin logger name, e.g. by Fabric
+var DefaultMixinLogPatterns = []*regexp.Regexp{mixinLogRe, mixinMessageLogRe}
+
+// mixinConfigSides are the suffixes of the mixin configs which are split by the side or the loader
+var mixinConfigSides = []string{"-common", "-client", "-server", "-forge", "-fabric", "-neoforge", "_common", "_client", "_server"}
+
+// findMixinConfig returns the first mixin config or refmap named in the text
+func findMixinConfig(text string) (config string, mixin string, ok bool) {
+	for _, matches := range mixinConfigRe.FindAllStringSubmatch(text, -1) {
+		name, _, _ := strings.Cut(matches[0], ":")
+		if lname := strings.ToLower(name); strings.Contains(lname, "mixin") || strings.Contains(lname, "refmap") {
+			mixin = matches[1]
+			if i := strings.LastIndexByte(mixin, '.'); i >= 0 {
+				mixin = mixin[i+1:]
+			}
+			return name, mixin, true
+		}
+	}
+	return
+}
+
+// modOfMixinConfig guesses the mod id from the config name,
+// e.g. `mymod.mixins.json`, `mixins.mymod.json`, `mymod-common.mixins.json` and `mymod-refmap.json` are all `mymod`
+func modOfMixinConfig(config string) string {
+	for _, part := range strings.Split(strings.TrimSuffix(config, ".json"), ".") {
+		switch strings.ToLower(part) {
+		case "mixin", "mixins", "refmap":
+			continue
+		}
+		for _, suffix := range []string{"-refmap", "_refmap", "-mixins", "_mixins"} {
+			part = strings.TrimSuffix(part, suffix)
+		}
+		for _, suffix := range mixinConfigSides {
+			part = strings.TrimSuffix(part, suffix)
+		}
+		if part != "" {
+			return part
+		}
+	}
+	return ""
+}
+
+func isMixinError(jerr *JavaError) bool {
+	for _, je := range errorChain(jerr) {
+		if strings.HasPrefix(je.Class, "org.spongepowered.asm.mixin.") || strings.Contains(strings.ToLower(je.Message), "mixin") {
+			return true
+		}
+	}
+	return false
+}
+
+// attributeMixin finds the mod which registered the failing mixin of a mixin error.
+// The config named in the error itself is preferred, then the recent mixin log which mentions the same mixin
+// or target class as the error, then the recent mixin log which reports a failure.
+// The logs are a snapshot copied under the lock of the stream's recent logs, so the scanner can keep recording
+func (a *Analyzer) attributeMixin(jerr *JavaError, logs logSnapshot) *MixinSource {
+	if !isMixinError(jerr) {
+		return nil
+	}
+	var text strings.Builder
+	for _, je := range errorChain(jerr) {
+		text.WriteString(je.Message)
+		text.WriteByte('\n')
+	}
+	messages := text.String()
+	if config, mixin, ok := findMixinConfig(messages); ok {
+		return newMixinSource(config, mixin)
+	}
+	dotted := strings.ReplaceAll(messages, "/", ".")
+	var fallback *MixinSource
+	for _, line := range slices.Backward(logs[LogCategoryMixin]) {
+		config, mixin, ok := findMixinConfig(line)
+		if !ok {
+			continue
+		}
+		if mixin != "" && strings.Contains(messages, mixin) {
+			return newMixinSource(config, mixin)
+		}
+		if matches := mixinTargetRe.FindStringSubmatch(line); matches != nil && strings.Contains(dotted, strings.ReplaceAll(matches[1], "/", ".")) {
+			return newMixinSource(config, mixin)
+		}
+		if fallback == nil {
+			if lline := strings.ToLower(line); strings.Contains(lline, "fail") || strings.Contains(lline, "error") {
+				fallback = newMixinSource(config, mixin)
+			}
+		}
+	}
+	return fallback
+}
+
+func newMixinSource(config string, mixin string) *MixinSource {
+	mod := modOfMixinConfig(config)
+	if mod == "" {
+		return nil
+	}
+	return &MixinSource{
+		Mod:    mod,
+		Config: config,
+		Mixin:  mixin,
+	}
+}
@@ -0,0 +1,174 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func TestMixinAttribution(t *testing.T) {
+	const log = `[12:00:00] [main/INFO] [mixin/]: Compatibility level set to JAVA_17
+[12:00:01] [main/WARN] [mixin/]: Error loading class: com/othermod/Missing (java.lang.ClassNotFoundException: com.othermod.Missing)
+[12:00:02] [main/ERROR] [mixin/]: Mixin apply for mod examplemod failed examplemod-common.mixins.json:MixinLevelRenderer from mod examplemod -> net.minecraft.client.renderer.LevelRenderer: org.spongepowered.asm.mixin.injection.throwables.InvalidInjectionException Critical injection failure
+[12:00:03] [main/INFO] [mixin/]: Loaded 12 mixins from othermod.mixins.json
+[12:00:04] [main/FATAL]: Unreported exception thrown!
+java.lang.RuntimeException: Mixin transformation of net.minecraft.client.renderer.LevelRenderer failed
+	at net.minecraftforge.fml.loading.FMLLoader.transform(FMLLoader.java:10)
+Caused by: org.spongepowered.asm.mixin.throwables.MixinApplyError: Mixin [MixinLevelRenderer] from phase [DEFAULT] in config [unknown] FAILED during APPLY
+	at org.spongepowered.asm.mixin.transformer.MixinProcessor.handleMixinError(MixinProcessor.java:636)
+	... 1 more
+[12:00:05] [Render thread/ERROR]: Something else went wrong
+java.lang.IllegalStateException: Not a valid state
+	at com.example.mod.Foo.bar(Foo.java:42)
+[12:00:06] [main/FATAL]: Unreported exception thrown!
+org.spongepowered.asm.mixin.transformer.throwables.MixinTransformerError: An unexpected critical error was encountered
+	at org.spongepowered.asm.mixin.transformer.MixinProcessor.applyMixins(MixinProcessor.java:392)
+Caused by: org.spongepowered.asm.mixin.throwables.MixinApplyError: Mixin [sodium.mixins.json:core.MixinWindow] from phase [DEFAULT] in config [sodium.mixins.json] FAILED during APPLY
+	at org.spongepowered.asm.mixin.transformer.MixinProcessor.handleMixinError(MixinProcessor.java:636)
+	... 1 more
+`
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 5 {
+		t.Fatalf("Expect 5 results, got %d", len(analysis.Results))
+	}
+	datas := []struct {
+		Mod    string
+		Config string
+		Mixin  string
+	}{
+		{"examplemod", "examplemod-common.mixins.json", "MixinLevelRenderer"},
+		{"examplemod", "examplemod-common.mixins.json", "MixinLevelRenderer"},
+		{},
+		{"sodium", "sodium.mixins.json", "MixinWindow"},
+		{"sodium", "sodium.mixins.json", "MixinWindow"},
+	}
+	for i, d := range datas {
+		m := analysis.Results[i].Mixin
+		if d.Mod == "" {
+			if m != nil {
+				t.Errorf("Expect result %d to have no mixin source, got %#v", i, m)
+			}
+			continue
+		}
+		if m == nil {
+			t.Errorf("Expect result %d to be attributed to %q, got nil", i, d.Mod)
+			continue
+		}
+		if m.Mod != d.Mod || m.Config != d.Config || m.Mixin != d.Mixin {
+			t.Errorf("Expect result %d to be attributed to %q (%s:%s), got %q (%s:%s)", i, d.Mod, d.Config, d.Mixin, m.Mod, m.Config, m.Mixin)
+		}
+	}
+}
+
+func TestMixinAttributionConcurrent(t *testing.T) {
+	const log = `[12:00:00] [main/INFO] [mixin/]: Compatibility level set to JAVA_17
+[12:00:01] [main/ERROR] [mixin/]: Mixin apply for mod MOD failed MOD.mixins.json:MixinLevelRenderer from mod MOD -> net.minecraft.client.renderer.LevelRenderer: org.spongepowered.asm.mixin.injection.throwables.InvalidInjectionException Critical injection failure
+[12:00:02] [main/FATAL]: Unreported exception thrown!
+java.lang.RuntimeException: Mixin transformation of net.minecraft.client.renderer.LevelRenderer failed
+	at net.minecraftforge.fml.loading.FMLLoader.transform(FMLLoader.java:10)
+[12:00:03] [main/INFO] [mixin/]: Loaded 12 mixins from othermod.mixins.json
+`
+	analyzer := NewAnalyzer(&memErrorDB{})
+	var wg sync.WaitGroup
+	for i := range 8 {
+		mod := "examplemod" + strconv.Itoa(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(strings.ReplaceAll(log, "MOD", mod)))
+			if err != nil {
+				t.Errorf("AnalyzeLog: %v", err)
+				return
+			}
+			if len(analysis.Results) != 1 {
+				t.Errorf("Expect 1 result, got %d", len(analysis.Results))
+				return
+			}
+			if m := analysis.Results[0].Mixin; m == nil || m.Mod != mod {
+				t.Errorf("Expect the error to be attributed to %q, got %#v", mod, m)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestResultMixinLogs(t *testing.T) {
+	const log = `[12:00:00] [main/INFO] [mixin/]: Compatibility level set to JAVA_17
+[12:00:01] [main/INFO] [mixin/]: Loaded 12 mixins from examplemod.mixins.json
+[12:00:02] [main/ERROR]: Something went wrong
+java.lang.IllegalStateException: Not a valid state
+	at com.example.mod.Foo.bar(Foo.java:42)
+[12:00:03] [main/INFO] [mixin/]: Loaded 3 mixins from othermod.mixins.json
+[12:00:04] [main/ERROR]: Something else went wrong
+java.lang.IllegalArgumentException: Not a valid argument
+	at com.example.mod.Foo.baz(Foo.java:43)
+[12:00:05] [main/INFO] [mixin/]: Loaded 5 mixins from lastmod.mixins.json
+`
+	analyzer := NewAnalyzer(&memErrorDB{})
+	resCh, ctx := analyzer.DoLogStream(context.Background(), strings.NewReader(log))
+	var results []*ErrorResult
+	for res := range resCh {
+		results = append(results, res)
+	}
+	if err := context.Cause(ctx); err != nil && err != context.Canceled {
+		t.Fatalf("DoLogStream: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expect 2 results, got %d", len(results))
+	}
+	datas := [][]string{
+		{"Compatibility level set to JAVA_17", "Loaded 12 mixins from examplemod.mixins.json"},
+		{"Compatibility level set to JAVA_17", "Loaded 12 mixins from examplemod.mixins.json", "Loaded 3 mixins from othermod.mixins.json"},
+	}
+	for i, d := range datas {
+		if logs := results[i].MixinLogs; !slices.Equal(logs, d) {
+			t.Errorf("Expect result %d MixinLogs == %q, got %q", i, d, logs)
+		}
+	}
+	recent := analyzer.RecentMixinLogs()
+	if len(recent) != 4 || recent[3] != "Loaded 5 mixins from lastmod.mixins.json" {
+		t.Errorf("Expect RecentMixinLogs to have all 4 lines, got %q", recent)
+	}
+}
+
+func TestMixinLogPatterns(t *testing.T) {
+	const log = `[12:00:00] [main/INFO] (mixin) Loaded 12 mixins from examplemod.mixins.json
+[12:00:01] [main/INFO] [mixin/]: Loaded 3 mixins from othermod.mixins.json
+[12:00:02] [main/INFO] (FabricLoader/Mixin) Compatibility level set to JAVA_17
+[12:00:03] [main/INFO] (Minecraft) Setting user: Player
+`
+	fabricMixinLogRe := regexp.MustCompile(`^\[[^\]]*\]\s*\[[^\]]*\]\s*\((?:[^)]*/)?[Mm]ixin\)\s*(.+)$`)
+	datas := []struct {
+		Name     string
+		Patterns []*regexp.Regexp
+		Expect   []string
+	}{
+		{"default", nil, []string{"Loaded 3 mixins from othermod.mixins.json"}},
+		{"fabric", []*regexp.Regexp{fabricMixinLogRe}, []string{"Loaded 12 mixins from examplemod.mixins.json", "Compatibility level set to JAVA_17"}},
+		{"both", append(slices.Clone(DefaultMixinLogPatterns), fabricMixinLogRe), []string{
+			"Loaded 12 mixins from examplemod.mixins.json", "Loaded 3 mixins from othermod.mixins.json", "Compatibility level set to JAVA_17",
+		}},
+		{"no group", []*regexp.Regexp{regexp.MustCompile(`Setting user: \w+`)}, []string{"Setting user: Player"}},
+		{"disabled", []*regexp.Regexp{}, nil},
+	}
+	for _, d := range datas {
+		analyzer := NewAnalyzer(&memErrorDB{})
+		analyzer.MixinLogPatterns = d.Patterns
+		if _, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log)); err != nil {
+			t.Fatalf("%s: AnalyzeLog: %v", d.Name, err)
+		}
+		if logs := analyzer.RecentMixinLogs(); !slices.Equal(logs, d.Expect) {
+			t.Errorf("%s: Expect RecentMixinLogs == %q, got %q", d.Name, d.Expect, logs)
+		}
+	}
+}
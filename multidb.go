@@ -0,0 +1,119 @@
+package mcla
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// multiErrorDB is the ErrorDB returned by NewMultiErrorDB
+type multiErrorDB struct {
+	sources []ErrorDB
+}
+
+var _ ContextErrorDB = (*multiErrorDB)(nil)
+
+// multiSolutionIDRange is the size of the solution id range of each source of NewMultiErrorDB,
+// the ids of the source i > 0 are mapped into [i*multiSolutionIDRange, (i+1)*multiSolutionIDRange)
+const multiSolutionIDRange = 1 << 20
+
+// NewMultiErrorDB returns an ErrorDB which layers the sources, e.g. a private database over the github database.
+// The descriptions of all sources are yielded in the order of the sources. The descriptions of a source override
+// all descriptions of the earlier sources which have the same Error, and the first of them is yielded in place of
+// the first overridden one. The descriptions of the same source never override each other.
+// The descriptions of each source are ordered by their Source before they are merged, so the result does not depend on
+// the order a source yields them, e.g. ghdb.ErrDB. The descriptions without a Source keep the order they are yielded.
+//
+// The yielded descriptions are copies, since the solution ids of the sources after the first one are remapped
+// so the sources do not collide: the solution n of the source i > 0 becomes i*(1<<20)+n, and GetSolution maps it back.
+// The ids of the first source are kept, so the ids used by the hard coded checks, e.g. ModConflictSolutionID,
+// resolve to the first source, and the ids of all sources must be in [0, 1<<20).
+// The descriptions of the sources after the first one without an explicit ID get the DefaultDescID prefixed
+// by the index of the source, e.g. `1/12`, so the database files with the same name do not collide either.
+// An error of any source is returned before any description is yielded
+func NewMultiErrorDB(sources ...ErrorDB) ErrorDB {
+	return &multiErrorDB{
+		sources: sources,
+	}
+}
+
+func (db *multiErrorDB) ForEachErrors(callback func(*ErrorDesc) error) (err error) {
+	return db.ForEachErrorsContext(context.Background(), callback)
+}
+
+func (db *multiErrorDB) ForEachErrorsContext(ctx context.Context, callback func(*ErrorDesc) error) (err error) {
+	type entry struct {
+		desc   *ErrorDesc
+		source int
+	}
+	var entries []*entry            // the overridden entries are nil
+	index := make(map[string][]int) // the positions of the entries by their Error
+	for i, source := range db.sources {
+		var descs []*ErrorDesc
+		if err = forEachErrors(ctx, source, func(e *ErrorDesc) error {
+			desc := *e
+			if i > 0 && desc.Solutions != nil {
+				desc.Solutions = make([]int, len(e.Solutions))
+				for j, id := range e.Solutions {
+					desc.Solutions[j] = i*multiSolutionIDRange + id
+				}
+			}
+			if i > 0 && desc.ID == "" {
+				desc.ID = strconv.Itoa(i) + "/" + DefaultDescID(e)
+			}
+			descs = append(descs, &desc)
+			return nil
+		}); err != nil {
+			return
+		}
+		// a source may yield in any order, e.g. ghdb.ErrDB yields the files as they are fetched,
+		// so the descriptions are sorted by their files to override the same descriptions every time
+		slices.SortStableFunc(descs, func(a, b *ErrorDesc) int {
+			return strings.Compare(a.Source, b.Source)
+		})
+		for _, desc := range descs {
+			key := desc.Error
+			replaced := false
+			positions := index[key]
+			kept := positions[:0]
+			for _, p := range positions {
+				if entries[p].source == i {
+					kept = append(kept, p)
+				} else if !replaced {
+					entries[p] = &entry{desc, i}
+					kept = append(kept, p)
+					replaced = true
+				} else {
+					entries[p] = nil
+				}
+			}
+			if !replaced {
+				kept = append(kept, len(entries))
+				entries = append(entries, &entry{desc, i})
+			}
+			index[key] = kept
+		}
+	}
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		if err = callback(e.desc); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// GetSolution maps the remapped id back to the solution of its source,
+// the ids out of the remapped ranges are passed to the first source unchanged
+func (db *multiErrorDB) GetSolution(id int) (sol *SolutionDesc, err error) {
+	if len(db.sources) == 0 {
+		return nil, nil
+	}
+	if i := id / multiSolutionIDRange; id >= multiSolutionIDRange && i < len(db.sources) {
+		return db.sources[i].GetSolution(id - i*multiSolutionIDRange)
+	}
+	return db.sources[0].GetSolution(id)
+}
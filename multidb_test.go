@@ -0,0 +1,159 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func TestMultiErrorDB(t *testing.T) {
+	public := &memErrorDB{
+		errors: []*ErrorDesc{
+			{Error: "java.lang.NullPointerException", Message: "Cannot read field \"value\" because \"x\" is null", Solutions: []int{1}, Source: "errors/1.json"},
+			{Error: "java.io.FileNotFoundException", Message: "config/foo.toml (No such file or directory)", Solutions: []int{2}, Source: "errors/2.json"},
+			{Error: "java.lang.NullPointerException", Message: "", Source: "errors/3.json"},
+		},
+		solutions: []*SolutionDesc{
+			{Description: "Update the mod"},
+			{Description: "Delete the broken config file"},
+		},
+	}
+	private := &memErrorDB{
+		errors: []*ErrorDesc{
+			{Error: "java.lang.IllegalStateException", Message: "Not a valid state", Solutions: []int{1}, Source: "errors/1.json"},
+			{Error: "java.io.FileNotFoundException", Message: "config/foo.toml (No such file or directory)", Solutions: []int{2}, Source: "errors/2.json"},
+		},
+		solutions: []*SolutionDesc{
+			{Description: "Remove the broken mod from the modpack"},
+			{Description: "Restore the config of the modpack"},
+		},
+	}
+	db := NewMultiErrorDB(public, private)
+	errs, err := ExportErrors(db)
+	if err != nil {
+		t.Fatalf("ExportErrors: %v", err)
+	}
+	var classes []string
+	for _, e := range errs {
+		classes = append(classes, e.Error)
+	}
+	expect := []string{"java.lang.NullPointerException", "java.io.FileNotFoundException", "java.lang.NullPointerException", "java.lang.IllegalStateException"}
+	if !slices.Equal(classes, expect) {
+		t.Fatalf("Expect errors == %q, got %q", expect, classes)
+	}
+	if public.errors[1].Solutions[0] != 2 {
+		t.Errorf("Expect the source descriptions not to be modified")
+	}
+
+	analyzer := NewAnalyzer(db)
+	matched, err := analyzer.DoError(&JavaError{Class: "java.io.FileNotFoundException", Message: "config/foo.toml (No such file or directory)"})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) == 0 || matched[0].Match != 1 {
+		t.Fatalf("Expect an exact match, got %v", matched)
+	}
+	if expect := "1/2"; matched[0].ID != expect {
+		t.Errorf("Expect the overriding ID == %q, got %q", expect, matched[0].ID)
+	}
+	datas := []struct {
+		Desc   *ErrorDesc
+		Expect string
+	}{
+		{matched[0].ErrorDesc, "Restore the config of the modpack"},
+		{errs[0], "Update the mod"},
+		{errs[3], "Remove the broken mod from the modpack"},
+	}
+	for _, d := range datas {
+		sol, err := db.GetSolution(d.Desc.Solutions[0])
+		if err != nil {
+			t.Fatalf("GetSolution: %v", err)
+		}
+		if sol == nil || sol.Description != d.Expect {
+			t.Errorf("Expect the solution of %s == %q, got %#v", d.Desc.Error, d.Expect, sol)
+		}
+	}
+}
+
+func TestMultiErrorDBOverrideByError(t *testing.T) {
+	public := &memErrorDB{
+		errors: []*ErrorDesc{
+			{Error: "java.lang.NullPointerException", Message: "Cannot read field \"value\" because \"x\" is null", Source: "errors/1.json"},
+			{Error: "java.lang.IllegalStateException", Message: "Not a valid state", Source: "errors/2.json"},
+			{Error: "java.lang.NullPointerException", Message: "", Source: "errors/3.json"},
+		},
+	}
+	private := &memErrorDB{
+		errors: []*ErrorDesc{
+			{Error: "java.lang.NullPointerException", Message: "Cannot invoke \"Object.toString()\"", Source: "errors/1.json"},
+			{Error: "java.lang.NullPointerException", Message: "", Source: "errors/2.json"},
+		},
+	}
+	errs, err := ExportErrors(NewMultiErrorDB(public, private))
+	if err != nil {
+		t.Fatalf("ExportErrors: %v", err)
+	}
+	var ids []string
+	for _, e := range errs {
+		ids = append(ids, e.Source+" "+e.Message)
+	}
+	expect := []string{
+		"errors/1.json Cannot invoke \"Object.toString()\"",
+		"errors/2.json Not a valid state",
+		"errors/2.json ",
+	}
+	if !slices.Equal(ids, expect) {
+		t.Errorf("Expect errors == %q, got %q", expect, ids)
+	}
+
+	// the result does not depend on the order a source yields its descriptions, e.g. ghdb.ErrDB
+	reversed := &memErrorDB{errors: slices.Clone(private.errors)}
+	slices.Reverse(reversed.errors)
+	if errs, err = ExportErrors(NewMultiErrorDB(public, reversed)); err != nil {
+		t.Fatalf("ExportErrors: %v", err)
+	}
+	ids = ids[:0]
+	for _, e := range errs {
+		ids = append(ids, e.Source+" "+e.Message)
+	}
+	if !slices.Equal(ids, expect) {
+		t.Errorf("Expect errors == %q for the reversed source, got %q", expect, ids)
+	}
+}
+
+func TestMultiErrorDBHardCodedSolution(t *testing.T) {
+	const log = `[16:20:56] [pool-4-thread-1/WARN] [mixin/]: @Redirect conflict. Skipping tfc.mixins.json:BiomeMixin->@Redirect::shouldFreezeWithClimate(Lnet/minecraft/world/level/biome/Biome;)Z with priority 1000, already redirected by sereneseasons.mixins.json:MixinBiome->@Redirect::onShouldFreeze(Lnet/minecraft/world/level/biome/Biome;)Z with priority 1000
+[16:20:57] [main/FATAL]: Unreported exception thrown!
+org.spongepowered.asm.mixin.injection.throwables.InjectionError: Critical injection failure: Redirector shouldFreezeWithClimate(Lnet/minecraft/world/level/biome/Biome;)Z in tfc.mixins.json:BiomeMixin failed injection check, (0/1) succeeded. Scanned 1 target(s).
+	at org.spongepowered.asm.mixin.injection.struct.InjectionInfo.postInject(InjectionInfo.java:1)
+`
+	public := &memErrorDB{solutions: make([]*SolutionDesc, ModConflictSolutionID)}
+	for i := range public.solutions {
+		public.solutions[i] = &SolutionDesc{Description: "Public solution " + strconv.Itoa(i+1)}
+	}
+	private := &memErrorDB{solutions: []*SolutionDesc{{Description: "Private solution 1"}}}
+	db := NewMultiErrorDB(public, private)
+	analyzer := NewAnalyzer(db)
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 || len(analysis.Results[0].Matched) != 1 {
+		t.Fatalf("Expect one hard coded match, got %#v", analysis.Results)
+	}
+	desc := analysis.Results[0].Matched[0].ErrorDesc
+	if desc.ID != "hardcoded.redirectConflict" {
+		t.Fatalf("Expect the redirect conflict, got %q", desc.ID)
+	}
+	sol, err := db.GetSolution(desc.Solutions[0])
+	if err != nil {
+		t.Fatalf("GetSolution: %v", err)
+	}
+	if expect := "Public solution 12"; sol == nil || sol.Description != expect {
+		t.Errorf("Expect the hard coded solution == %q, got %#v", expect, sol)
+	}
+}
@@ -0,0 +1,102 @@
+package mcla
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// jvmFatalErrorClass is the class of the synthetic errors for the `# A fatal error has been detected` blocks
+	jvmFatalErrorClass = "native/JVMFatalError"
+	// processExitClass is the class of the synthetic errors for the `Process crashed with exit code` lines
+	processExitClass = "native/ProcessExit"
+)
+
+var (
+	jvmFatalErrorHeaderRe = regexp.MustCompile(`^\s*#\s*A fatal error has been detected by the Java Runtime Environment`)
+	// matches `Process crashed with exit code -1073741819` of the launchers and `Process exited with code 1.`
+	processExitRe = regexp.MustCompile(`(?i)\bprocess (crashed|exited) with (?:exit ?)?code:? (-?\d+)`)
+	// matches the native frame `C  [atio6axx.dll+0x1a2b]`
+	nativeFrameLibraryRe = regexp.MustCompile(`^[CV]\s+\[([^+\]]+)`)
+)
+
+// scanNativeCrash recognizes the crashes which do not print a Java exception at the current line:
+// the fatal error block of the JVM, which is read until its last line, and the exit code printed by the launchers.
+// It returns nil if the line is neither of them.
+// The end of the returned error is set, and it's truncated if the fatal error block reaches the end of the input
+func scanNativeCrash(sc *lineScanner, line string) (je *JavaError) {
+	if jvmFatalErrorHeaderRe.MatchString(line) {
+		return scanJVMFatalError(sc)
+	}
+	if matches := processExitRe.FindStringSubmatch(line); matches != nil {
+		code, err := strconv.ParseInt(matches[2], 10, 32)
+		if err != nil || code == 0 {
+			return nil
+		}
+		je = &JavaError{
+			Class:   processExitClass,
+			Message: fmt.Sprintf("Process %s with exit code %d", strings.ToLower(matches[1]), code),
+		}
+		if code < 0 { // a Windows NTSTATUS, e.g. 0xC0000005 for the access violation
+			je.Message += fmt.Sprintf(" (0x%08X)", (uint32)(code))
+		}
+		je.EndLineNo, je.EndOffset = sc.Count(), sc.next
+		return
+	}
+	return nil
+}
+
+// scanJVMFatalError reads the `#` lines after the header of a fatal error block, e.g.
+// ```
+// #
+// # A fatal error has been detected by the Java Runtime Environment:
+// #
+// #  EXCEPTION_ACCESS_VIOLATION (0xc0000005) at pc=0x00007ffb8f4c1a2b, pid=1234, tid=5678
+// #
+// # JRE version: OpenJDK Runtime Environment (17.0.8+7) (build 17.0.8+7)
+// # Problematic frame:
+// # C  [atio6axx.dll+0x1a2b]
+// #
+// # An error report file with more information is saved as:
+// # C:\Users\Steve\AppData\Roaming\.minecraft\hs_err_pid1234.log
+// ```
+// The message is the signal line, followed by the problematic frame and the report file if they are present
+func scanJVMFatalError(sc *lineScanner) (je *JavaError) {
+	var signal, frame, report, label string
+	for {
+		next, ok := sc.peek()
+		if !ok || !bytes.HasPrefix(bytes.TrimSpace(next), ([]byte)("#")) {
+			je = &JavaError{Class: jvmFatalErrorClass, Truncated: !ok}
+			break
+		}
+		sc.Scan()
+		text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(sc.Text()), "#"))
+		switch {
+		case text == "":
+			label = ""
+		case strings.HasSuffix(text, ":"):
+			label = text
+		case label == "Problematic frame:" && frame == "":
+			frame = text
+		case strings.HasPrefix(label, "An error report file") && report == "":
+			report = text
+		case signal == "" && label == "" && strings.Contains(text, " at pc="):
+			signal = text
+		}
+	}
+	if signal == "" {
+		signal = "A fatal error has been detected by the Java Runtime Environment"
+	}
+	je.Message = signal
+	if frame != "" {
+		je.Message += "\nProblematic frame: " + frame
+	}
+	if report != "" {
+		je.Message += "\nError report: " + report
+	}
+	je.EndLineNo, je.EndOffset = sc.Count(), sc.next
+	return
+}
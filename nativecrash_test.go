@@ -0,0 +1,132 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+)
+
+const jvmFatalErrorLog = `[12:00:00] [Render thread/INFO]: Reloading ResourceManager: vanilla
+#
+# A fatal error has been detected by the Java Runtime Environment:
+#
+#  EXCEPTION_ACCESS_VIOLATION (0xc0000005) at pc=0x00007ffb8f4c1a2b, pid=1234, tid=5678
+#
+# JRE version: OpenJDK Runtime Environment Microsoft-8035246 (17.0.8+7) (build 17.0.8+7-LTS)
+# Java VM: OpenJDK 64-Bit Server VM Microsoft-8035246 (17.0.8+7-LTS, mixed mode, tiered, compressed oops, g1 gc, windows-amd64)
+# Problematic frame:
+# C  [atio6axx.dll+0x1a2b]
+#
+# No core dump will be written. Minidumps are not enabled by default on client versions of Windows
+#
+# An error report file with more information is saved as:
+# C:\Users\Steve\AppData\Roaming\.minecraft\hs_err_pid1234.log
+#
+# If you would like to submit a bug report, please visit:
+#   https://github.com/microsoft/openjdk/issues
+# The crash happened outside the Java Virtual Machine in native code.
+# See problematic frame for where to report the bug.
+#
+Process crashed with exit code -1073741819 (0xc0000005)
+`
+
+func TestScanJVMFatalError(t *testing.T) {
+	res, err := ScanJavaErrors(strings.NewReader(jvmFatalErrorLog))
+	if err != nil {
+		t.Fatalf("ScanJavaErrors: %v", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("Found %d errors, but expect only the fatal error", len(res))
+	}
+	je := res[0]
+	expect := "EXCEPTION_ACCESS_VIOLATION (0xc0000005) at pc=0x00007ffb8f4c1a2b, pid=1234, tid=5678\n" +
+		"Problematic frame: C  [atio6axx.dll+0x1a2b]\n" +
+		`Error report: C:\Users\Steve\AppData\Roaming\.minecraft\hs_err_pid1234.log`
+	if je.Class != "native/JVMFatalError" || je.Message != expect {
+		t.Errorf("Expect native/JVMFatalError: %q, got %s: %q", expect, je.Class, je.Message)
+	}
+	if je.LineNo != 3 || je.EndLineNo != 21 || je.Truncated {
+		t.Errorf("Expect the error spans lines 3-21, got %d-%d, truncated %v", je.LineNo, je.EndLineNo, je.Truncated)
+	}
+	if end := (int64)(strings.Index(jvmFatalErrorLog, "Process crashed")); je.EndOffset != end {
+		t.Errorf("Expect je.EndOffset == %d, got %d", end, je.EndOffset)
+	}
+
+	analysis, err := NewAnalyzer(&memErrorDB{}).AnalyzeLog(context.Background(), strings.NewReader(jvmFatalErrorLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 || len(analysis.Results[0].Matched) != 1 {
+		t.Fatalf("Expect one result with one match, got %v", analysis.Results)
+	}
+	r := analysis.Results[0]
+	m := r.Matched[0]
+	if m.ID != "hardcoded.jvmFatalError" || m.ErrorDesc.Data["vendor"] != "AMD" || m.ErrorDesc.Data["library"] != "atio6axx.dll" {
+		t.Errorf("Expect the AMD driver crash, got %s %v", m.ID, m.ErrorDesc.Data)
+	}
+	if r.Category != CategoryNativeCrash {
+		t.Errorf("Expect r.Category == %q, got %q", CategoryNativeCrash, r.Category)
+	}
+}
+
+func TestScanProcessExit(t *testing.T) {
+	for _, tc := range []struct {
+		log    string
+		expect string // the message of the exit code, empty if it's not reported
+	}{
+		{"[12:00:00] [main/INFO]: Loading\nProcess crashed with exit code -1073741819\n", "Process crashed with exit code -1073741819 (0xC0000005)"},
+		{"[12:00:00] [main/INFO]: Stopping!\nProcess exited with code 0.\n", ""},
+		{"Process exited with code 1.\n", "Process exited with exit code 1"},
+		{"java.lang.IllegalStateException: Not building!\n\tat a.b.C.d(C.java:1)\nProcess exited with code 1.\n", ""},
+	} {
+		res, err := ScanJavaErrors(strings.NewReader(tc.log))
+		if err != nil {
+			t.Fatalf("ScanJavaErrors: %v", err)
+		}
+		var got string
+		for _, je := range res {
+			if je.Class == "native/ProcessExit" {
+				got = je.Message
+			}
+		}
+		if got != tc.expect {
+			t.Errorf("Expect the exit code %q in %q, got %q", tc.expect, tc.log, got)
+		}
+	}
+
+	matched, err := NewAnalyzer(&memErrorDB{}).DoError(&JavaError{
+		Class:   "native/ProcessExit",
+		Message: "Process crashed with exit code -1073741819 (0xC0000005)",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 1 || matched[0].ID != "hardcoded.processExit" || matched[0].ErrorDesc.Data["code"] != -1073741819 {
+		t.Errorf("Expect hardcoded.processExit with the exit code, got %v", matched)
+	}
+}
+
+func TestIncrementalJVMFatalError(t *testing.T) {
+	ia := NewAnalyzer(&memErrorDB{}).NewIncrementalAnalysis()
+	half := strings.Index(jvmFatalErrorLog, "# Problematic frame:")
+	results, err := ia.Append(([]byte)(jvmFatalErrorLog[:half]))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expect the incomplete fatal error is held, got %d results", len(results))
+	}
+	if results, err = ia.Append(([]byte)(jvmFatalErrorLog[half:])); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	more, err := ia.Finish()
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	results = append(results, more...)
+	if len(results) != 1 || !strings.Contains(results[0].Error.Message, "atio6axx.dll") {
+		t.Errorf("Expect the whole fatal error only, got %v", results)
+	}
+}
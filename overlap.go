@@ -0,0 +1,120 @@
+package mcla
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+)
+
+// DescOverlap is a pair of descriptions which matched the same errors with close scores,
+// they are ambiguous and should be disambiguated or merged
+type DescOverlap struct {
+	A        *ErrorDesc `json:"a"`
+	B        *ErrorDesc `json:"b"`
+	Count    int        `json:"count"`    // how many errors were matched closely by both descriptions
+	MeanDiff float32    `json:"meanDiff"` // the mean score difference of those matches
+}
+
+type OverlapOptions struct {
+	// MinMatch is the score both descriptions must reach, default is 0.5
+	MinMatch float32
+	// MaxDiff is the max score difference to be treated as ambiguous, default is 0.1
+	MaxDiff float32
+	// MinCount is how many times a pair must co-match to be reported, default is 1
+	MinCount int
+}
+
+// FindOverlaps analyzes the sample logs with the analyzer's database and reports the description pairs
+// which frequently match the same errors with close scores. It's a data quality tool for database maintainers,
+// the result is sorted by Count in descending order
+func (a *Analyzer) FindOverlaps(ctx context.Context, corpus []io.Reader, opts OverlapOptions) (overlaps []DescOverlap, err error) {
+	if opts.MinMatch <= 0 {
+		opts.MinMatch = 0.5
+	}
+	if opts.MaxDiff <= 0 {
+		opts.MaxDiff = 0.1
+	}
+	if opts.MinCount <= 0 {
+		opts.MinCount = 1
+	}
+	index := make(map[*ErrorDesc]int)
+	for i, e := range a.getErrors(ctx) {
+		index[e] = i
+	}
+	type pairKey struct{ a, b int }
+	type pairStat struct {
+		a, b    *ErrorDesc
+		count   int
+		diffSum float32
+	}
+	pairs := make(map[pairKey]*pairStat)
+	for _, r := range corpus {
+		var analysis *LogAnalysis
+		if analysis, err = a.AnalyzeLog(ctx, r); err != nil {
+			if !errors.Is(err, ErrCrashReportIncomplete) {
+				return
+			}
+			err = nil // the partial results are still counted
+		}
+		for _, res := range analysis.Results {
+			for i, m1 := range res.Matched {
+				i1, ok := index[m1.ErrorDesc]
+				if !ok || m1.Match < opts.MinMatch {
+					continue
+				}
+				for _, m2 := range res.Matched[i+1:] {
+					i2, ok := index[m2.ErrorDesc]
+					if !ok || m2.Match < opts.MinMatch {
+						continue
+					}
+					diff := m1.Match - m2.Match
+					if diff < 0 {
+						diff = -diff
+					}
+					if diff > opts.MaxDiff {
+						continue
+					}
+					key, d1, d2 := pairKey{i1, i2}, m1.ErrorDesc, m2.ErrorDesc
+					if i2 < i1 {
+						key, d1, d2 = pairKey{i2, i1}, d2, d1
+					}
+					stat := pairs[key]
+					if stat == nil {
+						stat = &pairStat{a: d1, b: d2}
+						pairs[key] = stat
+					}
+					stat.count++
+					stat.diffSum += diff
+				}
+			}
+		}
+	}
+	keys := make([]pairKey, 0, len(pairs))
+	for k, stat := range pairs {
+		if stat.count >= opts.MinCount {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		si, sj := pairs[keys[i]], pairs[keys[j]]
+		if si.count != sj.count {
+			return si.count > sj.count
+		}
+		if keys[i].a != keys[j].a {
+			return keys[i].a < keys[j].a
+		}
+		return keys[i].b < keys[j].b
+	})
+	overlaps = make([]DescOverlap, len(keys))
+	for i, k := range keys {
+		stat := pairs[k]
+		overlaps[i] = DescOverlap{
+			A:        stat.a,
+			B:        stat.b,
+			Count:    stat.count,
+			MeanDiff: stat.diffSum / (float32)(stat.count),
+		}
+	}
+	return
+}
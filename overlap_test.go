@@ -0,0 +1,51 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"io"
+	"strings"
+)
+
+func TestFindOverlaps(t *testing.T) {
+	exact := &ErrorDesc{
+		Error:   "java.lang.IllegalStateException",
+		Message: "Failed to create model for block minecraft:stone",
+	}
+	prefix := &ErrorDesc{
+		Error:   "java.lang.IllegalStateException",
+		Message: "Failed to create model for block *",
+	}
+	unrelated := &ErrorDesc{
+		Error:   "java.lang.OutOfMemoryError",
+		Message: "Java heap space",
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{exact, unrelated, prefix}})
+	const sample = `[12:00:00] [Render thread/ERROR]: Failed to load models
+java.lang.IllegalStateException: Failed to create model for block minecraft:stone
+	at net.minecraft.client.resources.model.ModelBakery.loadModel(ModelBakery.java:300)
+`
+	overlaps, err := analyzer.FindOverlaps(context.Background(), []io.Reader{
+		strings.NewReader(sample),
+		strings.NewReader(sample),
+	}, OverlapOptions{})
+	if err != nil {
+		t.Fatalf("FindOverlaps: %v", err)
+	}
+	if len(overlaps) != 1 {
+		t.Fatalf("Expect 1 overlap, got %d", len(overlaps))
+	}
+	o := overlaps[0]
+	if !sameDesc(o.A, exact) || !sameDesc(o.B, prefix) {
+		t.Errorf("Expect the overlap between the exact and the prefix description, got %#v and %#v", o.A, o.B)
+	}
+	if o.Count != 2 {
+		t.Errorf("Expect o.Count == 2, got %d", o.Count)
+	}
+	// the prefix description is a bit less specific than the exact one
+	if o.MeanDiff <= 0 || o.MeanDiff > 0.05 {
+		t.Errorf("Expect 0 < o.MeanDiff <= 0.05, got %v", o.MeanDiff)
+	}
+}
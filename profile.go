@@ -0,0 +1,120 @@
+package mcla
+
+import (
+	"regexp"
+	"time"
+)
+
+// AnalyzerOptions are the options of an Analyzer, see the fields of Analyzer with the same names for their meanings.
+// The zero value means the defaults of all options.
+//
+// The profiles are AnalyzerOptions values, so a profile can be copied and overridden, e.g.
+// ```
+// opts := ProfileWebUI
+// opts.StopOnConfidence = 0.95
+// analyzer := NewAnalyzerWithOptions(db, opts)
+// ```
+type AnalyzerOptions struct {
+	PrimarySelector       PrimarySelector
+	CorrelationWindow     time.Duration
+	MinFuzzyLength        int
+	CollapseRepeatedLines bool
+	SkipFramePrefixes     []string
+	StopOnConfidence      float32
+	RecoveredLevels       []string
+	DescID                DescIDFunc
+	KeepDuplicateCauses   bool
+	StopWords             []string
+	OnErrorsRefresh       func(delta *ErrorsDelta)
+	ResultFilter          func(res *ErrorResult) bool
+	ResultCacheSize       int
+	SimilarityCacheSize   int
+	UnmatchedThreshold    float32
+	Anonymize             bool
+	ReadBufferSize        int
+	ResultBufferSize      int
+	DropPolicy            DropPolicy
+	CacheTTL              time.Duration
+	FuzzyMessageMatch     bool
+	OnInvalidDesc         func(e *ErrorDesc, err error)
+	MatchWeights          MatchWeights
+	MatchWorkers          int
+	DedupErrors           bool
+	MinMatch              float32
+	MaxResults            int
+	Tokenizer             Tokenizer
+	Locale                string
+	OnProgress            func(p Progress)
+	KeepANSI              bool
+	ExplainMatches        bool
+	MixinLogPatterns      []*regexp.Regexp
+	LogRules              []LogRule
+}
+
+var (
+	// ProfileFast is for the quick answers, e.g. a chat bot.
+	// The analysis stops at the first confident result, and the repeated lines, errors and similarities are not processed twice
+	ProfileFast = AnalyzerOptions{
+		CollapseRepeatedLines: true,
+		StopOnConfidence:      0.9,
+		ResultCacheSize:       256,
+		SimilarityCacheSize:   4096,
+	}
+	// ProfileAccurate is for the complete reports, e.g. a batch job.
+	// The whole log is analyzed, the duplicate causes are kept, and the descriptions are not reloaded during the job
+	ProfileAccurate = AnalyzerOptions{
+		KeepDuplicateCauses: true,
+		SimilarityCacheSize: 4096,
+		UnmatchedThreshold:  0.6,
+		CacheTTL:            -1,
+	}
+	// ProfileWebUI is for the logs pasted to a public web page.
+	// The personal data is redacted, and more results are buffered so a busy page does not block the analysis
+	ProfileWebUI = AnalyzerOptions{
+		CollapseRepeatedLines: true,
+		ResultCacheSize:       256,
+		SimilarityCacheSize:   4096,
+		Anonymize:             true,
+		ResultBufferSize:      32,
+	}
+)
+
+// NewAnalyzerWithOptions creates an Analyzer with the options, e.g. one of the profiles
+func NewAnalyzerWithOptions(db ErrorDB, opts AnalyzerOptions) (a *Analyzer) {
+	a = NewAnalyzer(db)
+	a.PrimarySelector = opts.PrimarySelector
+	a.CorrelationWindow = opts.CorrelationWindow
+	a.MinFuzzyLength = opts.MinFuzzyLength
+	a.CollapseRepeatedLines = opts.CollapseRepeatedLines
+	a.SkipFramePrefixes = opts.SkipFramePrefixes
+	a.StopOnConfidence = opts.StopOnConfidence
+	a.RecoveredLevels = opts.RecoveredLevels
+	a.DescID = opts.DescID
+	a.KeepDuplicateCauses = opts.KeepDuplicateCauses
+	a.StopWords = opts.StopWords
+	a.OnErrorsRefresh = opts.OnErrorsRefresh
+	a.ResultFilter = opts.ResultFilter
+	a.ResultCacheSize = opts.ResultCacheSize
+	a.SimilarityCacheSize = opts.SimilarityCacheSize
+	a.UnmatchedThreshold = opts.UnmatchedThreshold
+	a.Anonymize = opts.Anonymize
+	a.ReadBufferSize = opts.ReadBufferSize
+	a.ResultBufferSize = opts.ResultBufferSize
+	a.DropPolicy = opts.DropPolicy
+	a.CacheTTL = opts.CacheTTL
+	a.FuzzyMessageMatch = opts.FuzzyMessageMatch
+	a.OnInvalidDesc = opts.OnInvalidDesc
+	a.MatchWeights = opts.MatchWeights
+	a.MatchWorkers = opts.MatchWorkers
+	a.DedupErrors = opts.DedupErrors
+	a.MinMatch = opts.MinMatch
+	a.MaxResults = opts.MaxResults
+	a.Tokenizer = opts.Tokenizer
+	a.Locale = opts.Locale
+	a.OnProgress = opts.OnProgress
+	a.KeepANSI = opts.KeepANSI
+	a.ExplainMatches = opts.ExplainMatches
+	a.MixinLogPatterns = opts.MixinLogPatterns
+	a.LogRules = opts.LogRules
+	return
+}
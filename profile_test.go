@@ -0,0 +1,63 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"reflect"
+)
+
+func TestAnalyzerProfiles(t *testing.T) {
+	db := &memErrorDB{}
+	fast := NewAnalyzerWithOptions(db, ProfileFast)
+	if !fast.CollapseRepeatedLines || fast.StopOnConfidence != 0.9 || fast.SimilarityCacheSize != 4096 {
+		t.Errorf("ProfileFast: unexpected options %#v", fast)
+	}
+	if fast.DB != db {
+		t.Errorf("Expect the analyzer to use the database")
+	}
+	accurate := NewAnalyzerWithOptions(db, ProfileAccurate)
+	if !accurate.KeepDuplicateCauses || accurate.StopOnConfidence != 0 || accurate.CollapseRepeatedLines || accurate.UnmatchedThreshold != 0.6 || accurate.CacheTTL >= 0 {
+		t.Errorf("ProfileAccurate: unexpected options %#v", accurate)
+	}
+	webui := NewAnalyzerWithOptions(db, ProfileWebUI)
+	if !webui.Anonymize || webui.ResultBufferSize != 32 || !webui.CollapseRepeatedLines || webui.DropPolicy != DropNone {
+		t.Errorf("ProfileWebUI: unexpected options %#v", webui)
+	}
+
+	opts := ProfileWebUI
+	opts.Anonymize = false
+	opts.StopOnConfidence = 0.95
+	opts.PrimarySelector = SelectLastError
+	a := NewAnalyzerWithOptions(db, opts)
+	if a.Anonymize || a.StopOnConfidence != 0.95 || a.PrimarySelector == nil {
+		t.Errorf("Expect the overrides to win, got %#v", a)
+	}
+	if a.ResultBufferSize != 32 || !a.CollapseRepeatedLines {
+		t.Errorf("Expect the other options of the profile to be kept, got %#v", a)
+	}
+	if !ProfileWebUI.Anonymize {
+		t.Errorf("Expect the profile itself to be unchanged")
+	}
+}
+
+// every option must be a field of Analyzer, so the options do not fall behind the analyzer
+func TestAnalyzerOptionsFields(t *testing.T) {
+	ot, at := reflect.TypeFor[AnalyzerOptions](), reflect.TypeFor[Analyzer]()
+	for i := 0; i < ot.NumField(); i++ {
+		f := ot.Field(i)
+		af, ok := at.FieldByName(f.Name)
+		if !ok || af.Type != f.Type {
+			t.Errorf("Expect Analyzer to have the option %s %v", f.Name, f.Type)
+		}
+	}
+	for i := 0; i < at.NumField(); i++ {
+		f := at.Field(i)
+		if !f.IsExported() || f.Name == "DB" {
+			continue
+		}
+		if _, ok := ot.FieldByName(f.Name); !ok {
+			t.Errorf("Expect AnalyzerOptions to have the option %s", f.Name)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package mcla
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Progress is how much of the log a stream has read, see Analyzer.OnProgress
+type Progress struct {
+	// Read is the count of the bytes read from the log
+	Read int64 `json:"read"`
+	// Total is the size of the log, it's -1 if the size is unknown
+	Total int64 `json:"total"`
+}
+
+// Percent returns the progress in range [0, 100], or -1 if the size of the log is unknown
+func (p Progress) Percent() float64 {
+	if p.Total < 0 {
+		return -1
+	}
+	if p.Total == 0 || p.Read >= p.Total {
+		return 100
+	}
+	return (float64)(p.Read) * 100 / (float64)(p.Total)
+}
+
+// progressStep is how many bytes are read between two progress reports
+const progressStep = 64 * 1024
+
+// readerSize returns the remaining size of the reader, or -1 if it's unknown.
+// A reader can provide the size hint by a `Size() int64` method
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }: // bytes.Reader, strings.Reader and bytes.Buffer
+		return (int64)(v.Len())
+	case interface{ Size() int64 }: // io.SectionReader
+		return v.Size()
+	case io.Seeker: // os.File
+		cur, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err = v.Seek(cur, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - cur
+	}
+	return -1
+}
+
+// progressReader counts the bytes read from the log and reports them every progressStep bytes
+type progressReader struct {
+	r        io.Reader
+	total    int64
+	read     atomic.Int64
+	reported int64 // only accessed by the reading goroutine
+	report   func(Progress)
+}
+
+func newProgressReader(r io.Reader, report func(Progress)) *progressReader {
+	return &progressReader{
+		r:      r,
+		total:  readerSize(r),
+		report: report,
+	}
+}
+
+func (r *progressReader) Read(buf []byte) (n int, err error) {
+	n, err = r.r.Read(buf)
+	if n > 0 {
+		read := r.read.Add((int64)(n))
+		if read-r.reported >= progressStep {
+			r.reported = read
+			r.report(Progress{Read: read, Total: r.total})
+		}
+	}
+	return
+}
+
+// done reports the final progress after the whole log is read, the total is the bytes read
+func (r *progressReader) done() {
+	read := r.read.Load()
+	r.report(Progress{Read: read, Total: read})
+}
@@ -0,0 +1,168 @@
+package mcla
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/kmcsr/go-ringbuf"
+)
+
+// LogRule records the log lines which match Pattern in the recent logs of Category,
+// the first capture group is recorded, or the whole match if the pattern has no group
+type LogRule struct {
+	Category string         `json:"category"`
+	Pattern  *regexp.Regexp `json:"pattern"`
+}
+
+// The categories of the recent logs
+const (
+	LogCategoryMixin      = "mixin"
+	LogCategoryWarn       = "warn"
+	LogCategoryError      = "error"
+	LogCategoryModLoading = "modLoading"
+	LogCategoryDatapack   = "datapack"
+)
+
+// recentLogsSize is how many lines of each category are kept
+const recentLogsSize = 64
+
+// logRuleRe matches the log lines of the levels whose message matches the pattern, the message is the first group.
+// The patterns are anchored at the log header, since an unanchored pattern is much slower on the unmatched lines
+func logRuleRe(levels string, message string) *regexp.Regexp {
+	return regexp.MustCompile(`^\[(?:[^\]]*\]\s*\[(?:[^\]]*/)?|\d{1,2}:\d{2}:\d{2}(?:[.,]\d+)?\s+)(?:` + levels +
+		`)\](?:\s*\[[^\]]*\]|\s*\([^)]*\))?:?\s*(` + message + `)$`)
+}
+
+// WarnLogRule and ErrorLogRule record all warnings and errors. They are not in DefaultLogRules,
+// since they record most lines of a noisy log into every result, add them if they are wanted, e.g.
+//
+//	a.LogRules = append(slices.Clone(DefaultLogRules), WarnLogRule, ErrorLogRule)
+var (
+	WarnLogRule  = LogRule{LogCategoryWarn, logRuleRe(`WARN|WARNING`, `.+`)}
+	ErrorLogRule = LogRule{LogCategoryError, logRuleRe(`ERROR|SEVERE|FATAL`, `.+`)}
+)
+
+// DefaultLogRules record the warnings or errors about loading the mods and the data packs
+var DefaultLogRules = []LogRule{
+	{LogCategoryModLoading, logRuleRe(`WARN|WARNING|ERROR|SEVERE|FATAL`, `(?:Exception|Error) (?:loading|constructing) mod\b.*|Caught exception from .+|Failed to (?:load|create) mod\b.*`)},
+	{LogCategoryDatapack, logRuleRe(`WARN|WARNING|ERROR|SEVERE|FATAL`, `(?:Couldn't|Failed to) (?:load|parse|read) (?:tags?|data file|functions?|recipes?|loot tables?|advancements?|data ?packs?)\b.*|Errors in currently selected data packs.*`)},
+}
+
+// recordedLog is a recorded log line and where it is in the log
+type recordedLog struct {
+	lineNo int
+	text   string
+}
+
+// logRules returns the mixin rules followed by LogRules
+func (a *Analyzer) logRules() (rules []LogRule) {
+	patterns := a.MixinLogPatterns
+	if patterns == nil {
+		patterns = DefaultMixinLogPatterns
+	}
+	others := a.LogRules
+	if others == nil {
+		others = DefaultLogRules
+	}
+	rules = make([]LogRule, 0, len(patterns)+len(others))
+	for _, re := range patterns {
+		rules = append(rules, LogRule{LogCategoryMixin, re})
+	}
+	return append(rules, others...)
+}
+
+// recentLogs are the recent log lines of a stream by their categories.
+// They are recorded by the scanner and read by the analysis at the same time
+type recentLogs struct {
+	mux  sync.Mutex
+	bufs map[string]*ringbuf.RingBuffer[recordedLog]
+}
+
+func (l *recentLogs) push(category string, rl recordedLog) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	buf := l.bufs[category]
+	if buf == nil {
+		if l.bufs == nil {
+			l.bufs = make(map[string]*ringbuf.RingBuffer[recordedLog])
+		}
+		buf = ringbuf.NewRingBuffer[recordedLog](recentLogsSize)
+		l.bufs[category] = buf
+	}
+	buf.Push(rl)
+}
+
+// lines returns the log lines of the category before the line, oldest first.
+// Since the scanner reads ahead, the lines after the error may have been recorded when it's analyzed.
+// Zero means all lines
+func (l *recentLogs) lines(category string, lineNo int) []string {
+	if l == nil {
+		return nil
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return recentLogsBefore(l.bufs[category], lineNo)
+}
+
+// before returns the log lines of all categories before the line, it's nil if there are none
+func (l *recentLogs) before(lineNo int) (snap logSnapshot) {
+	if l == nil {
+		return nil
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	for category, buf := range l.bufs {
+		if lines := recentLogsBefore(buf, lineNo); len(lines) > 0 {
+			if snap == nil {
+				snap = make(logSnapshot)
+			}
+			snap[category] = lines
+		}
+	}
+	return
+}
+
+// logSnapshot are the recent log lines before an error by their categories, oldest first
+type logSnapshot map[string][]string
+
+// others returns the log lines of the categories other than mixin, it's nil if there are none
+func (s logSnapshot) others() (logs map[string][]string) {
+	for category, lines := range s {
+		if category == LogCategoryMixin {
+			continue
+		}
+		if logs == nil {
+			logs = make(map[string][]string)
+		}
+		logs[category] = lines
+	}
+	return
+}
+
+// RecentLogs returns the log lines of the category recorded by the last started stream, oldest first.
+// Only the last 64 lines of each category are kept
+func (a *Analyzer) RecentLogs(category string) []string {
+	a.logsMux.Lock()
+	logs := a.lastLogs
+	a.logsMux.Unlock()
+	return logs.lines(category, 0)
+}
+
+// RecentMixinLogs returns the mixin log lines recorded by the last started stream, oldest first.
+// Only the last 64 lines are kept
+func (a *Analyzer) RecentMixinLogs() []string {
+	return a.RecentLogs(LogCategoryMixin)
+}
+
+func recentLogsBefore(buf *ringbuf.RingBuffer[recordedLog], lineNo int) (logs []string) {
+	if buf == nil {
+		return
+	}
+	for l := range buf.Iter() {
+		if lineNo > 0 && l.lineNo >= lineNo {
+			break
+		}
+		logs = append(logs, l.text)
+	}
+	return
+}
@@ -0,0 +1,164 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const recentLogsLog = `[12:00:00] [main/INFO] [mixin/]: Loaded 12 mixins from examplemod.mixins.json
+[12:00:01] [main/WARN] [mixin/]: Reference map 'examplemod.refmap.json' could not be read
+[12:00:02] [main/ERROR] [net.minecraftforge.fml.ModLoader/]: Exception loading mod examplemod
+[12:00:03] [Server thread/ERROR]: Couldn't load tag minecraft:logs as it is missing following references: examplemod:log
+[12:00:04] [Server thread/INFO]: Preparing level "world"
+[12:00:05] [Server thread/FATAL]: Unreported exception thrown!
+java.lang.IllegalStateException: Not a valid state
+	at com.example.mod.Foo.bar(Foo.java:42)
+[12:00:06] [main/WARN] (FabricLoader) Mod examplemod uses a deprecated API
+`
+
+func TestRecentLogs(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analyzer.LogRules = append(slices.Clone(DefaultLogRules), WarnLogRule, ErrorLogRule)
+	resCh, ctx := analyzer.DoLogStream(context.Background(), strings.NewReader(recentLogsLog))
+	var results []*ErrorResult
+	for res := range resCh {
+		results = append(results, res)
+	}
+	if err := context.Cause(ctx); err != nil && err != context.Canceled {
+		t.Fatalf("DoLogStream: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(results))
+	}
+	datas := []struct {
+		Category string
+		Before   []string
+		Recent   []string
+	}{
+		{LogCategoryWarn, []string{"Reference map 'examplemod.refmap.json' could not be read"},
+			[]string{"Reference map 'examplemod.refmap.json' could not be read", "Mod examplemod uses a deprecated API"}},
+		{LogCategoryError, []string{
+			"Exception loading mod examplemod",
+			"Couldn't load tag minecraft:logs as it is missing following references: examplemod:log",
+			"Unreported exception thrown!",
+		}, nil},
+		{LogCategoryModLoading, []string{"Exception loading mod examplemod"}, nil},
+		{LogCategoryDatapack, []string{"Couldn't load tag minecraft:logs as it is missing following references: examplemod:log"}, nil},
+	}
+	for _, d := range datas {
+		if logs := results[0].Logs[d.Category]; !slices.Equal(logs, d.Before) {
+			t.Errorf("Expect result Logs[%q] == %q, got %q", d.Category, d.Before, logs)
+		}
+		recent := d.Recent
+		if recent == nil {
+			recent = d.Before
+		}
+		if logs := analyzer.RecentLogs(d.Category); !slices.Equal(logs, recent) {
+			t.Errorf("Expect RecentLogs(%q) == %q, got %q", d.Category, recent, logs)
+		}
+	}
+	if _, ok := results[0].Logs[LogCategoryMixin]; ok {
+		t.Errorf("Expect the mixin logs to be only in MixinLogs")
+	}
+	expect := []string{"Loaded 12 mixins from examplemod.mixins.json", "Reference map 'examplemod.refmap.json' could not be read"}
+	if logs := results[0].MixinLogs; !slices.Equal(logs, expect) {
+		t.Errorf("Expect result MixinLogs == %q, got %q", expect, logs)
+	}
+}
+
+func TestLogRules(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analyzer.LogRules = []LogRule{
+		{Category: "level", Pattern: regexp.MustCompile(`Preparing level "(\w+)"`)},
+	}
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(recentLogsLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+	}
+	if expect, logs := []string{"world"}, analysis.Results[0].Logs["level"]; len(analysis.Results[0].Logs) != 1 || !slices.Equal(logs, expect) {
+		t.Errorf("Expect result Logs == {level: %q}, got %q", expect, analysis.Results[0].Logs)
+	}
+	if logs := analyzer.RecentLogs(LogCategoryWarn); logs != nil {
+		t.Errorf("Expect the default rules to be replaced, got %q", logs)
+	}
+	if logs := analyzer.RecentMixinLogs(); len(logs) != 2 {
+		t.Errorf("Expect the mixin logs to be still recorded, got %q", logs)
+	}
+
+	analyzer.LogRules = []LogRule{}
+	analysis, err = analyzer.AnalyzeLog(context.Background(), strings.NewReader(recentLogsLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if logs := analysis.Results[0].Logs; logs != nil {
+		t.Errorf("Expect no logs when the rules are disabled, got %q", logs)
+	}
+}
+
+func TestDefaultLogRules(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(recentLogsLog))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	if len(analysis.Results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+	}
+	logs := analysis.Results[0].Logs
+	if _, ok := logs[LogCategoryWarn]; ok {
+		t.Errorf("Expect the warnings not to be recorded by default, got %q", logs[LogCategoryWarn])
+	}
+	if _, ok := logs[LogCategoryError]; ok {
+		t.Errorf("Expect the errors not to be recorded by default, got %q", logs[LogCategoryError])
+	}
+	if expect := []string{"Exception loading mod examplemod"}; !slices.Equal(logs[LogCategoryModLoading], expect) {
+		t.Errorf("Expect Logs[%q] == %q, got %q", LogCategoryModLoading, expect, logs[LogCategoryModLoading])
+	}
+}
+
+func TestRecentLogsConcurrentStreams(t *testing.T) {
+	const logTemplate = `[12:00:00] [main/INFO] [mixin/]: Loaded 12 mixins from MOD.mixins.json
+[12:00:01] [main/ERROR] [mixin/]: Mixin apply failed MOD.mixins.json:MixinLevelRenderer -> net.minecraft.client.renderer.LevelRenderer: failed
+[12:00:02] [main/FATAL]: Unreported exception thrown!
+org.spongepowered.asm.mixin.throwables.MixinApplyError: Mixin [MixinLevelRenderer] from phase [DEFAULT] in config [unknown] FAILED during APPLY
+	at org.spongepowered.asm.mixin.transformer.MixinProcessor.handleMixinError(MixinProcessor.java:636)
+`
+	analyzer := NewAnalyzer(&memErrorDB{})
+	mods := []string{"moda", "modb", "modc", "modd"}
+	var wg sync.WaitGroup
+	for range 8 {
+		for _, mod := range mods {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				log := strings.Repeat("[11:59:59] [main/INFO]: Loading\n", 200) + strings.ReplaceAll(logTemplate, "MOD", mod)
+				analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log))
+				if err != nil {
+					t.Errorf("AnalyzeLog: %v", err)
+					return
+				}
+				if len(analysis.Results) != 1 {
+					t.Errorf("Expect 1 result, got %d", len(analysis.Results))
+					return
+				}
+				res := analysis.Results[0]
+				if res.Mixin == nil || res.Mixin.Mod != mod {
+					t.Errorf("Expect the mixin to be attributed to %q, got %#v", mod, res.Mixin)
+				}
+				if len(res.MixinLogs) != 2 || !strings.Contains(res.MixinLogs[0], mod) {
+					t.Errorf("Expect the mixin logs of %q, got %q", mod, res.MixinLogs)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
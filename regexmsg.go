@@ -0,0 +1,57 @@
+package mcla
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// messagePattern anchors the message of a description, so it must match the whole message
+func messagePattern(message string) string {
+	return `^(?:` + message + `)$`
+}
+
+// regexMatchPercent is 1 if the regular expression matches the whole message, otherwise 0
+func regexMatchPercent(text, pattern string) float32 {
+	re, err := compilePattern(messagePattern(pattern))
+	if err != nil || !re.MatchString(text) {
+		return 0
+	}
+	return 1
+}
+
+// messageCaptures returns the groups captured by the description's message from the first line of the error message,
+// it's nil if the message is not a regular expression or there is no group.
+// The first message of Message and Messages which matches captures the groups
+func messageCaptures(jerr *JavaError, e *ErrorDesc) (captures map[string]string) {
+	if !e.MessageIsRegex {
+		return
+	}
+	jemsg, _ := split(jerr.Message, '\n')
+	var (
+		re      *regexp.Regexp
+		matches []string
+	)
+	for _, message := range append([]string{e.Message}, e.Messages...) {
+		if message == "" {
+			continue
+		}
+		var err error
+		if re, err = compilePattern(messagePattern(message)); err != nil {
+			continue
+		}
+		if matches = re.FindStringSubmatch(jemsg); matches != nil {
+			break
+		}
+	}
+	if matches == nil || re.NumSubexp() == 0 {
+		return
+	}
+	captures = make(map[string]string, len(matches)-1)
+	for i, name := range re.SubexpNames()[1:] {
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		captures[name] = matches[i+1]
+	}
+	return
+}
@@ -0,0 +1,75 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func TestRegexMessage(t *testing.T) {
+	desc := &ErrorDesc{
+		Error:          "java.lang.ClassNotFoundException",
+		Message:        `(?P<class>[\w$]+(?:\.[\w$]+)+) \((\w+)\)`,
+		MessageIsRegex: true,
+	}
+	invalid := &ErrorDesc{
+		Error:          "java.lang.ClassNotFoundException",
+		Message:        `(unclosed`,
+		MessageIsRegex: true,
+	}
+	literal := &ErrorDesc{
+		Error:   "java.lang.ClassNotFoundException",
+		Message: `(unclosed`,
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc, invalid, literal}})
+	var skipped []*ErrorDesc
+	analyzer.OnInvalidDesc = func(e *ErrorDesc, err error) {
+		skipped = append(skipped, e)
+	}
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	if len(skipped) != 1 || !sameDesc(skipped[0], invalid) {
+		t.Errorf("Expect only the invalid description to be skipped, got %#v", skipped)
+	}
+
+	matched, err := analyzer.DoError(&JavaError{
+		Class:   "java.lang.ClassNotFoundException",
+		Message: "net.minecraft.client.renderer.RenderType (client)\nmore details",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	var found *SolutionPossibility
+	for i, m := range matched {
+		if sameDesc(m.ErrorDesc, invalid) {
+			t.Errorf("Expect the invalid description not to match")
+		}
+		if sameDesc(m.ErrorDesc, desc) {
+			found = &matched[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("Expect the regex description to match, got %#v", matched)
+	}
+	if found.Match != 1 {
+		t.Errorf("Expect match == 1, got %v", found.Match)
+	}
+	if expect := "net.minecraft.client.renderer.RenderType"; found.Captures["class"] != expect {
+		t.Errorf("Expect captures[class] == %q, got %q", expect, found.Captures["class"])
+	}
+	if expect := "client"; found.Captures["2"] != expect {
+		t.Errorf("Expect captures[2] == %q, got %q", expect, found.Captures["2"])
+	}
+
+	// the expression must match the whole message
+	matched, err = analyzer.DoError(&JavaError{
+		Class:   "java.lang.ClassNotFoundException",
+		Message: "Could not find net.minecraft.client.renderer.RenderType (client)",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if m, ok := findMatch(matched, desc); ok && m >= 0.5 {
+		t.Errorf("Expect a partial match to score low, got %v", m)
+	}
+}
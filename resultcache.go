@@ -0,0 +1,130 @@
+package mcla
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// resultKey identifies the errors which match the descriptions in the same way, see matchSignature
+type resultKey struct {
+	signature string
+	meta      LogMetadata
+	hasMeta   bool
+}
+
+type resultEntry struct {
+	key     resultKey
+	matched []SolutionPossibility
+}
+
+// resultCache memoizes the matches of the descriptions from DB, see Analyzer.ResultCacheSize.
+// The least recently used entry is evicted when it's full, and all entries are dropped when the descriptions are reloaded.
+// The zero value is an empty cache
+type resultCache struct {
+	mux     sync.Mutex
+	gen     uint64 // the count of the clears, so the matches of the replaced descriptions are not stored
+	entries map[resultKey]*list.Element
+	order   list.List // the most recently used entry is at the front
+}
+
+func (c *resultCache) generation() uint64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.gen
+}
+
+// get returns a copy of the cached matches, so the caller can sort and modify it
+func (c *resultCache) get(key resultKey) (matched []SolutionPossibility, ok bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.order.MoveToFront(el)
+	return slices.Clone(el.Value.(*resultEntry).matched), true
+}
+
+// put stores a copy of the matches unless the cache is cleared after gen is read
+func (c *resultCache) put(key resultKey, gen uint64, size int, matched []SolutionPossibility) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if gen != c.gen {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[resultKey]*list.Element)
+	}
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	for c.order.Len() >= size {
+		delete(c.entries, c.order.Remove(c.order.Back()).(*resultEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&resultEntry{key: key, matched: slices.Clone(matched)})
+}
+
+func (c *resultCache) clear() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.gen++
+	clear(c.entries)
+	c.order.Init()
+}
+
+// matchSignature is what the matching of the descriptions reads from the error: the class, the message,
+// the stacktrace and the context lines, the surrounding spaces of them are ignored.
+// Unlike errorSignature of DedupErrors, nothing is masked, since the captures and the scores depend on the exact message.
+// The timestamps of the context lines are removed, so the same error logged at different times has the same signature
+func matchSignature(jerr *JavaError) string {
+	var b strings.Builder
+	b.WriteString(jerr.Class)
+	b.WriteByte(0)
+	b.WriteString(strings.TrimSpace(jerr.Message))
+	for _, s := range jerr.Stacktrace {
+		b.WriteByte(0)
+		b.WriteString(strings.TrimSpace(s.Raw))
+		b.WriteByte(0)
+		b.WriteString(s.Class)
+		b.WriteByte('.')
+		b.WriteString(s.Method)
+		b.WriteByte('(')
+		b.WriteString(s.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(s.Line))
+		b.WriteByte(')')
+	}
+	b.WriteByte(1)
+	for _, line := range jerr.Context {
+		b.WriteByte(0)
+		b.WriteString(strings.TrimSpace(trimLogTime(line)))
+	}
+	return b.String()
+}
+
+// matchDescsCached is same as matchDescs, but reuses the matches of an error with the same signature.
+// The returned matches are not shared with the cache
+func (a *Analyzer) matchDescsCached(ctx context.Context, jerr *JavaError, meta *LogMetadata, descs []*ErrorDesc, gen uint64, cache *similarityCache) (matched []SolutionPossibility, err error) {
+	if a.ResultCacheSize <= 0 {
+		return a.matchDescs(ctx, jerr, meta, descs, cache)
+	}
+	key := resultKey{signature: matchSignature(jerr)}
+	if meta != nil {
+		key.meta, key.hasMeta = *meta, true
+	}
+	if matched, ok := a.results.get(key); ok {
+		return matched, nil
+	}
+	if matched, err = a.matchDescs(ctx, jerr, meta, descs, cache); err != nil {
+		return nil, err
+	}
+	if len(descs) > 0 {
+		a.results.put(key, gen, a.ResultCacheSize, matched)
+	}
+	return
+}
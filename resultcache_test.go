@@ -0,0 +1,113 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+// countingTokenizer counts how many messages are compared
+type countingTokenizer struct {
+	calls atomic.Int64
+}
+
+func (t *countingTokenizer) Tokenize(text string) []string {
+	t.calls.Add(1)
+	return RuneTokenizer{}.Tokenize(text)
+}
+
+func TestResultCache(t *testing.T) {
+	db := similarityBenchDB()
+	tokenizer := new(countingTokenizer)
+	analyzer := NewAnalyzer(db)
+	analyzer.Tokenizer = tokenizer
+	analyzer.ResultCacheSize = 1
+
+	jerr := &JavaError{
+		Class:   "java.io.FileNotFoundException",
+		Message: "config/mod_7/settings.toml (The system cannot find the file specified)",
+		Context: []string{"[12:00:00] [Render thread/ERROR]: Failed to load the config"},
+	}
+	doError := func(jerr *JavaError) []SolutionPossibility {
+		t.Helper()
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		return matched
+	}
+	// doErrorCalls returns how many messages are compared by DoError
+	doErrorCalls := func(jerr *JavaError) int64 {
+		t.Helper()
+		before := tokenizer.calls.Load()
+		doError(jerr)
+		return tokenizer.calls.Load() - before
+	}
+
+	expect := doError(jerr)
+	if len(expect) == 0 || expect[0].ErrorDesc.Message != jerr.Message {
+		t.Fatalf("Expect the exact description matches first, got %v", expect)
+	}
+	expect[0].Match = 0 // the cache must not share the slice with the caller
+	later := *jerr
+	later.Context = []string{"[12:34:56] [Render thread/ERROR]: Failed to load the config"}
+	if calls := doErrorCalls(&later); calls != 0 {
+		t.Errorf("Expect the same error logged later is cached, but %d messages are compared", calls)
+	}
+	if matched := doError(jerr); len(matched) == 0 || matched[0].Match != 1 {
+		t.Errorf("Expect the cached matches are not modified by the caller, got %v", matched)
+	}
+
+	other := *jerr
+	other.Message = "config/mod_8/settings.toml (The system cannot find the file specified)"
+	if calls := doErrorCalls(&other); calls == 0 {
+		t.Errorf("Expect a different message is matched again")
+	}
+	if calls := doErrorCalls(jerr); calls == 0 {
+		t.Errorf("Expect the least recently used error is evicted")
+	}
+	if calls := doErrorCalls(jerr); calls != 0 {
+		t.Errorf("Expect the error is cached again, but %d messages are compared", calls)
+	}
+	if err := analyzer.UpdateErrors(); err != nil {
+		t.Fatalf("UpdateErrors: %v", err)
+	}
+	if calls := doErrorCalls(jerr); calls == 0 {
+		t.Errorf("Expect the cache is cleared when the descriptions are reloaded")
+	}
+}
+
+func TestResultCacheStream(t *testing.T) {
+	log := repeatedErrorsLog(20)
+	db := similarityBenchDB()
+	expect, err := NewAnalyzer(db).AnalyzeLog(context.Background(), strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	analyzer := NewAnalyzer(db)
+	analyzer.ResultCacheSize = 8
+	analyzer.Anonymize = true
+	for range 2 {
+		got, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log))
+		if err != nil {
+			t.Fatalf("AnalyzeLog: %v", err)
+		}
+		if len(got.Results) != len(expect.Results) {
+			t.Fatalf("Expect %d results, got %d", len(expect.Results), len(got.Results))
+		}
+		for i, res := range got.Results {
+			want := expect.Results[i].Matched
+			if len(res.Matched) != len(want) {
+				t.Fatalf("Expect %d matches for result %d, got %d", len(want), i, len(res.Matched))
+			}
+			for j, m := range res.Matched {
+				if m.ID != want[j].ID || m.Match != want[j].Match {
+					t.Errorf("Expect cached match %s=%v, got %s=%v", want[j].ID, want[j].Match, m.ID, m.Match)
+				}
+			}
+		}
+	}
+}
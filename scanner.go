@@ -2,31 +2,221 @@ package mcla
 
 import (
 	"bufio"
+	"bytes"
 	"io"
+	"sync/atomic"
+	"time"
 )
 
+const maxLineSize = 1024 * 1024 // 1MB per line, large enough?
+
 type lineScanner struct {
 	count int
 	*bufio.Scanner
+	offset  int64 // the byte offset of the current line
+	next    int64 // the byte offset after the current line and its line break
+	advance int   // the bytes consumed by the last token, including the line break
+
+	collapse  bool          // skip the lines which are same as the previous line
+	collapsed *atomic.Int64 // counts the skipped lines, can be nil
+	last      []byte
+	hasLast   bool
+	eof       bool // the last Scan returned false
+
+	keepANSI bool   // do not strip the ANSI escape sequences
+	line     []byte // the current line, without the ANSI escape sequences unless keepANSI
+
+	held *lineState // the line read ahead by peek, it's returned by the next Scan
+}
+
+// lineState is the position of a lineScanner
+type lineState struct {
+	line         []byte
+	count        int
+	offset, next int64
+	eof          bool
+}
+
+type scanOptions struct {
+	// collapseRepeated skips the consecutive identical lines, the line numbers are still counted
+	collapseRepeated bool
+	// collapsed counts the lines skipped by collapseRepeated, it can be nil
+	collapsed *atomic.Int64
+	// holdAtEOF stops scanning with an *incompleteErr when an error reaches the end of the input,
+	// since more lines of it may be appended later
+	holdAtEOF bool
+	// state is used as the initial state, and is set to the state where the scanning can be resumed.
+	// It can be nil
+	state *scanState
+	// summary is called with the `Description:` and `Reason:` lines outside of the errors, it can be nil
+	summary func(s summaryLine)
+	// checkCrashReport returns ErrCrashReportIncomplete when the log ends in a crash report,
+	// i.e. the crash report header is found but its System Details section is not
+	checkCrashReport bool
+	// keepANSI does not strip the ANSI escape sequences from the lines, for the logs which are known clean
+	keepANSI bool
+}
+
+// scanState is what the scanner remembers from the previous lines
+type scanState struct {
+	context []string
+	time    time.Time
+	level   string
+	found   bool // if any error is found, see scanNativeCrash
+}
+
+func newLineScannerWithOptions(r io.Reader, opts *scanOptions) *lineScanner {
+	s := newLineScanner(r)
+	if opts != nil {
+		s.collapse = opts.collapseRepeated
+		s.collapsed = opts.collapsed
+		s.keepANSI = opts.keepANSI
+	}
+	return s
 }
 
 func newLineScanner(r io.Reader) *lineScanner {
 	bs := bufio.NewScanner(r)
-	bs.Buffer(make([]byte, 16*1024), 1024*1024) // 1MB per line, large enough?
-	return &lineScanner{
+	bs.Buffer(make([]byte, 16*1024), maxLineSize)
+	s := &lineScanner{
 		count:   0,
 		Scanner: bs,
 	}
+	bs.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = bufio.ScanLines(data, atEOF)
+		if token != nil {
+			s.advance = advance
+		}
+		return
+	})
+	return s
 }
 
 func (s *lineScanner) Scan() bool {
-	if !s.Scanner.Scan() {
+	if h := s.held; h != nil {
+		s.held = nil
+		s.line, s.count, s.offset, s.next, s.eof = h.line, h.count, h.offset, h.next, h.eof
+		return !h.eof
+	}
+	for {
+		if !s.Scanner.Scan() {
+			s.eof, s.line = true, nil
+			return false
+		}
+		s.count++
+		s.offset, s.next = s.next, s.next+(int64)(s.advance)
+		line := s.Scanner.Bytes()
+		if !s.keepANSI {
+			line = stripANSI(line)
+		}
+		s.line = line
+		if !s.collapse {
+			return true
+		}
+		if s.hasLast && bytes.Equal(line, s.last) {
+			if s.collapsed != nil {
+				s.collapsed.Add(1)
+			}
+			continue
+		}
+		s.last = append(s.last[:0], line...)
+		s.hasLast = true
+		return true
+	}
+}
+
+// peek returns the next line without moving to it, ok is false if there is no next line
+func (s *lineScanner) peek() (line []byte, ok bool) {
+	if s.held == nil {
+		cur := lineState{bytes.Clone(s.line), s.count, s.offset, s.next, s.eof}
+		s.Scan()
+		s.held = &lineState{s.line, s.count, s.offset, s.next, s.eof}
+		s.line, s.count, s.offset, s.next, s.eof = cur.line, cur.count, cur.offset, cur.next, cur.eof
+	}
+	return s.held.line, !s.held.eof
+}
+
+// isContinuation reports whether the line continues an exception, i.e. a frame, `... n more`, `Caused by: ` or `Suppressed: `
+func isContinuation(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	if isNestedError(line) {
+		return true
+	}
+	if n, ok := bytes.CutPrefix(line, ([]byte)("... ")); ok && bytes.HasSuffix(n, ([]byte)(" more")) {
+		return true
+	}
+	return bytes.HasPrefix(line, ([]byte)("at ")) && stackInfoMatcher.Match(line)
+}
+
+// isNestedError reports whether the line starts a cause or a suppressed error
+func isNestedError(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	return bytes.HasPrefix(line, ([]byte)("Caused by: ")) || bytes.HasPrefix(line, ([]byte)("Suppressed: "))
+}
+
+// skipInterleaved moves to the next line if the current line is a log event of another thread printed
+// in the middle of an exception, i.e. it starts with a timestamp and the next line satisfies continues.
+// Only one interleaved line is skipped, so the exception still ends at a real log event
+func (s *lineScanner) skipInterleaved(continues func(line []byte) bool) bool {
+	if _, ok := parseLogTime((string)(s.line)); !ok {
+		return false
+	}
+	if next, ok := s.peek(); !ok || !continues(next) {
 		return false
 	}
-	s.count++
-	return true
+	return s.Scan()
+}
+
+// Bytes returns the current line, the ANSI escape sequences are stripped unless keepANSI.
+// Like bufio.Scanner, the returned slice may be overwritten by the next Scan
+func (s *lineScanner) Bytes() []byte {
+	return s.line
+}
+
+func (s *lineScanner) Text() string {
+	return (string)(s.line)
 }
 
 func (s *lineScanner) Count() int {
 	return s.count
 }
+
+// Offset returns the byte offset of the current line
+func (s *lineScanner) Offset() int64 {
+	return s.offset
+}
+
+// spanEnd returns the last line number and the end byte offset of the lines before the current line,
+// or of all lines if the scanner reached the end
+func (s *lineScanner) spanEnd() (lineNo int, offset int64) {
+	if s.eof {
+		return s.count, s.next
+	}
+	return s.count - 1, s.offset
+}
+
+func (o *scanOptions) getState() *scanState {
+	if o == nil {
+		return nil
+	}
+	return o.state
+}
+
+func (o *scanOptions) onSummary(sc *lineScanner, line string) {
+	if o == nil || o.summary == nil {
+		return
+	}
+	if text, ok := parseSummaryLine(line); ok {
+		o.summary(summaryLine{LineNo: sc.Count(), Offset: sc.offset, EndOffset: sc.next, Text: text})
+	}
+}
+
+func (o *scanOptions) saveState(context []string, t time.Time, level string, found bool) {
+	if o == nil || o.state == nil {
+		return
+	}
+	o.state.context = append(o.state.context[:0], context...)
+	o.state.time = t
+	o.state.level = level
+	o.state.found = found
+}
@@ -0,0 +1,29 @@
+package mcla
+
+// ResultSchemaVersion is the version of the JSON format of ErrorResult, it's encoded as the `schemaVersion` field of each result.
+// The minor version is bumped when a field is added, so a consumer which knows the major version can read the output
+// by ignoring the unknown fields. The major version is bumped when a field is removed or renamed, or its type or meaning changes.
+//
+// The format covers the result and all objects nested in it:
+//
+//	ErrorResult:         schemaVersion, error, matched, file, primary, location, recovered, category, summaryOnly,
+//	                     mixin, mixinLogs, logs, startLine, endLine, startOffset, endOffset, incomplete, count, lastLine
+//	JavaError:           class, message, stacktrace, elided, causedBy, suppressed, lineNo, endLineNo, offset, endOffset,
+//	                     time, context, level, truncated. The causedBy and suppressed errors are JavaError too
+//	StackInfo:           raw, class, method, file, line, jar, jarVersion
+//	SolutionPossibility: errorDesc, match, source, id, captures, description, severity, links, detail
+//	MatchDetail:         class, messageSimilarity, message, wildcard, specificity
+//	ErrorDesc:           id, error, message, messages, solutions, data, messageIsRegex, mustNotMatch, description,
+//	                     descriptions, source, modded, launchers, context, signals, category, severity, links
+//	Signal:              pattern, weight
+//	CrashLocation:       class, method, file, line
+//	MixinSource:         mod, config, mixin
+//	Link:                url, label
+//
+// The fields tagged with omitempty in the Go types are omitted when they are empty
+const ResultSchemaVersion = "1.6"
+
+// MarshalJSON encodes the result with its schemaVersion, see ResultSchemaVersion
+func (r *ErrorResult) MarshalJSON() ([]byte, error) {
+	return r.appendJSON(nil)
+}
@@ -0,0 +1,85 @@
+package mcla_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+// resultSchema is the contract of ResultSchemaVersion, a change here must bump the version and update its document
+var resultSchema = map[reflect.Type][]string{
+	reflect.TypeFor[ErrorResult](): {"error", "matched", "file", "primary", "location", "recovered", "category", "summaryOnly",
+		"mixin", "mixinLogs", "logs", "startLine", "endLine", "startOffset", "endOffset", "incomplete", "count", "lastLine"},
+	reflect.TypeFor[JavaError](): {"class", "message", "stacktrace", "elided", "causedBy", "suppressed", "lineNo", "endLineNo",
+		"offset", "endOffset", "time", "context", "level", "truncated"},
+	reflect.TypeFor[StackInfo]():           {"raw", "class", "method", "file", "line", "jar", "jarVersion"},
+	reflect.TypeFor[SolutionPossibility](): {"errorDesc", "match", "source", "id", "captures", "description", "severity", "links", "detail"},
+	reflect.TypeFor[MatchDetail]():         {"class", "messageSimilarity", "message", "wildcard", "specificity"},
+	reflect.TypeFor[ErrorDesc](): {"id", "error", "message", "messages", "solutions", "data", "messageIsRegex", "mustNotMatch", "description",
+		"descriptions", "source", "modded", "launchers", "context", "signals", "category", "severity", "links"},
+	reflect.TypeFor[Signal]():        {"pattern", "weight"},
+	reflect.TypeFor[CrashLocation](): {"class", "method", "file", "line"},
+	reflect.TypeFor[MixinSource]():   {"mod", "config", "mixin"},
+	reflect.TypeFor[Link]():          {"url", "label"},
+}
+
+func jsonFieldNames(typ reflect.Type) (names []string) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+func TestResultSchema(t *testing.T) {
+	for typ, expect := range resultSchema {
+		if got := jsonFieldNames(typ); !slices.Equal(got, expect) {
+			t.Errorf("The JSON fields of %s are changed, bump ResultSchemaVersion %s and update its document\nexpect %v\ngot    %v",
+				typ.Name(), ResultSchemaVersion, expect, got)
+		}
+	}
+
+	res := &ErrorResult{
+		Error: &JavaError{
+			Class:    "java.lang.RuntimeException",
+			CausedBy: &JavaError{Class: "java.lang.NullPointerException"},
+		},
+		Matched: []SolutionPossibility{},
+	}
+	buf, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var decoded struct {
+		SchemaVersion string `json:"schemaVersion"`
+		Error         struct {
+			CausedBy map[string]any `json:"causedBy"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(buf, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if decoded.SchemaVersion != ResultSchemaVersion {
+		t.Errorf("Expect schemaVersion == %q, got %q", ResultSchemaVersion, decoded.SchemaVersion)
+	}
+	if decoded.Error.CausedBy["class"] != "java.lang.NullPointerException" {
+		t.Errorf("Expect the nested causedBy error, got %v", decoded.Error.CausedBy)
+	}
+	if _, ok := decoded.Error.CausedBy["schemaVersion"]; ok {
+		t.Errorf("Expect schemaVersion only on the result, got it on the nested error")
+	}
+}
@@ -0,0 +1,218 @@
+package mcla
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// SessionOptions are the analyzer options recorded in a Session
+type SessionOptions struct {
+	// PrimarySelector is the name of a builtin selector, see Session for details
+	PrimarySelector       string        `json:"primarySelector,omitempty"`
+	CorrelationWindow     time.Duration `json:"correlationWindow,omitempty"`
+	MinFuzzyLength        int           `json:"minFuzzyLength,omitempty"`
+	CollapseRepeatedLines bool          `json:"collapseRepeatedLines,omitempty"`
+	SkipFramePrefixes     []string      `json:"skipFramePrefixes"`
+	StopOnConfidence      float32       `json:"stopOnConfidence,omitempty"`
+	RecoveredLevels       []string      `json:"recoveredLevels"`
+	KeepDuplicateCauses   bool          `json:"keepDuplicateCauses,omitempty"`
+	StopWords             []string      `json:"stopWords"`
+	UnmatchedThreshold    float32       `json:"unmatchedThreshold,omitempty"`
+	Anonymize             bool          `json:"anonymize,omitempty"`
+	FuzzyMessageMatch     bool          `json:"fuzzyMessageMatch,omitempty"`
+	MatchWeights          MatchWeights  `json:"matchWeights"` // the zero weights are omitted, see MarshalJSON
+	DedupErrors           bool          `json:"dedupErrors,omitempty"`
+	MinMatch              float32       `json:"minMatch,omitempty"`
+	MaxResults            int           `json:"maxResults,omitempty"`
+	// Tokenizer is the name of a builtin tokenizer, e.g. "ngram:2"
+	Tokenizer string `json:"tokenizer,omitempty"`
+	Locale    string `json:"locale,omitempty"`
+	KeepANSI  bool   `json:"keepANSI,omitempty"`
+
+	ExplainMatches bool `json:"explainMatches,omitempty"`
+	// MixinLogPatterns are the sources of the patterns
+	MixinLogPatterns []string  `json:"mixinLogPatterns"`
+	LogRules         []LogRule `json:"logRules"`
+}
+
+// MarshalJSON encodes the options and omits the zero MatchWeights, which means the default weights.
+// The omitzero tag option is not used since it requires go1.24
+func (o SessionOptions) MarshalJSON() ([]byte, error) {
+	type plain SessionOptions
+	v := struct {
+		plain
+		MatchWeights *MatchWeights `json:"matchWeights,omitempty"`
+	}{plain: (plain)(o)}
+	if o.MatchWeights != (MatchWeights{}) {
+		v.MatchWeights = &o.MatchWeights
+	}
+	return json.Marshal(v)
+}
+
+// Session is a bundle of everything an analysis depends on: the log, a snapshot of the database and the analyzer options.
+// It can be serialized as JSON and replayed later with Replay to reproduce the same results.
+// Only the builtin primary selectors and tokenizers can be recorded, a custom one is replayed as the default.
+// The rules added by Analyzer.AddHardCodedRule are not recorded either
+type Session struct {
+	Log       string                `json:"log"`
+	Errors    []*ErrorDesc          `json:"errors"`
+	Solutions map[int]*SolutionDesc `json:"solutions,omitempty"`
+	Options   SessionOptions        `json:"options"`
+}
+
+var builtinSelectors = map[string]PrimarySelector{
+	"first":     SelectFirstError,
+	"last":      SelectLastError,
+	"bestMatch": SelectBestMatch,
+	"rootCause": SelectRootCause,
+}
+
+func selectorName(selector PrimarySelector) string {
+	if selector == nil {
+		return ""
+	}
+	ptr := reflect.ValueOf(selector).Pointer()
+	for name, s := range builtinSelectors {
+		if reflect.ValueOf(s).Pointer() == ptr {
+			return name
+		}
+	}
+	return ""
+}
+
+// RecordSession reads the whole log, analyzes it, and records a Session which can reproduce the analysis
+// If the log is truncated, ErrCrashReportIncomplete is returned together with the analysis and the session
+func (a *Analyzer) RecordSession(c context.Context, r io.Reader) (analysis *LogAnalysis, session *Session, err error) {
+	log, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	db := a.currentDB()
+	errs, err := ExportErrors(db)
+	if err != nil {
+		return
+	}
+	session = &Session{
+		Log:    (string)(log),
+		Errors: errs,
+		Options: SessionOptions{
+			PrimarySelector:       selectorName(a.PrimarySelector),
+			CorrelationWindow:     a.CorrelationWindow,
+			MinFuzzyLength:        a.MinFuzzyLength,
+			CollapseRepeatedLines: a.CollapseRepeatedLines,
+			SkipFramePrefixes:     a.SkipFramePrefixes,
+			StopOnConfidence:      a.StopOnConfidence,
+			RecoveredLevels:       a.RecoveredLevels,
+			KeepDuplicateCauses:   a.KeepDuplicateCauses,
+			StopWords:             a.StopWords,
+			UnmatchedThreshold:    a.UnmatchedThreshold,
+			Anonymize:             a.Anonymize,
+			FuzzyMessageMatch:     a.FuzzyMessageMatch,
+			MatchWeights:          a.MatchWeights,
+			DedupErrors:           a.DedupErrors,
+			MinMatch:              a.MinMatch,
+			MaxResults:            a.MaxResults,
+			Tokenizer:             tokenizerName(a.Tokenizer),
+			Locale:                a.Locale,
+			KeepANSI:              a.KeepANSI,
+			ExplainMatches:        a.ExplainMatches,
+			MixinLogPatterns:      patternSources(a.MixinLogPatterns),
+			LogRules:              a.LogRules,
+		},
+	}
+	for _, e := range errs {
+		for _, id := range e.Solutions {
+			if _, ok := session.Solutions[id]; ok {
+				continue
+			}
+			var sol *SolutionDesc
+			if sol, err = db.GetSolution(id); err != nil {
+				return nil, nil, err
+			}
+			if sol != nil {
+				if session.Solutions == nil {
+					session.Solutions = make(map[int]*SolutionDesc)
+				}
+				session.Solutions[id] = sol
+			}
+		}
+	}
+	if analysis, err = a.AnalyzeLog(c, bytes.NewReader(log)); err != nil && !errors.Is(err, ErrCrashReportIncomplete) {
+		return nil, nil, err
+	}
+	return
+}
+
+func patternSources(patterns []*regexp.Regexp) (sources []string) {
+	if patterns == nil {
+		return nil
+	}
+	sources = make([]string, len(patterns))
+	for i, re := range patterns {
+		sources[i] = re.String()
+	}
+	return
+}
+
+// Replay analyzes the recorded log again with the recorded database snapshot and options
+func Replay(c context.Context, session *Session) (*LogAnalysis, error) {
+	a := NewAnalyzer(sessionDB{session})
+	opts := session.Options
+	a.PrimarySelector = builtinSelectors[opts.PrimarySelector]
+	a.CorrelationWindow = opts.CorrelationWindow
+	a.MinFuzzyLength = opts.MinFuzzyLength
+	a.CollapseRepeatedLines = opts.CollapseRepeatedLines
+	a.SkipFramePrefixes = opts.SkipFramePrefixes
+	a.StopOnConfidence = opts.StopOnConfidence
+	a.RecoveredLevels = opts.RecoveredLevels
+	a.KeepDuplicateCauses = opts.KeepDuplicateCauses
+	a.StopWords = opts.StopWords
+	a.UnmatchedThreshold = opts.UnmatchedThreshold
+	a.Anonymize = opts.Anonymize
+	a.FuzzyMessageMatch = opts.FuzzyMessageMatch
+	a.MatchWeights = opts.MatchWeights
+	a.DedupErrors = opts.DedupErrors
+	a.MinMatch = opts.MinMatch
+	a.MaxResults = opts.MaxResults
+	a.Tokenizer = tokenizerByName(opts.Tokenizer)
+	a.Locale = opts.Locale
+	a.KeepANSI = opts.KeepANSI
+	a.ExplainMatches = opts.ExplainMatches
+	if opts.MixinLogPatterns != nil {
+		a.MixinLogPatterns = make([]*regexp.Regexp, len(opts.MixinLogPatterns))
+		for i, pattern := range opts.MixinLogPatterns {
+			var err error
+			if a.MixinLogPatterns[i], err = regexp.Compile(pattern); err != nil {
+				return nil, err
+			}
+		}
+	}
+	a.LogRules = opts.LogRules
+	return a.AnalyzeLog(c, bytes.NewReader(([]byte)(session.Log)))
+}
+
+// sessionDB serves the database snapshot of a session
+type sessionDB struct {
+	s *Session
+}
+
+var _ ErrorDB = sessionDB{}
+
+func (db sessionDB) ForEachErrors(callback func(*ErrorDesc) error) (err error) {
+	for _, e := range db.s.Errors {
+		if err = callback(e); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (db sessionDB) GetSolution(id int) (sol *SolutionDesc, err error) {
+	return db.s.Solutions[id], nil
+}
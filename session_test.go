@@ -0,0 +1,85 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+func TestRecordAndReplaySession(t *testing.T) {
+	db := &memErrorDB{
+		errors: []*ErrorDesc{
+			{
+				Error:     "java.io.FileNotFoundException",
+				Message:   "config/foo.toml (No such file or directory)",
+				Solutions: []int{1},
+			},
+			{
+				Error:   "java.lang.NullPointerException",
+				Message: "Cannot read field \"value\" because \"x\" is null",
+			},
+		},
+		solutions: []*SolutionDesc{
+			{Description: "Delete the broken config file"},
+		},
+	}
+	analyzer := NewAnalyzer(db)
+	analyzer.PrimarySelector = SelectBestMatch
+	analyzer.MinFuzzyLength = 4
+	analysis, session, err := analyzer.RecordSession(context.Background(), strings.NewReader(multiErrorLog))
+	if err != nil {
+		t.Fatalf("RecordSession: %v", err)
+	}
+	bundle, err := json.Marshal(session)
+	if err != nil {
+		t.Fatalf("Marshal session: %v", err)
+	}
+
+	var replayed Session
+	if err = json.Unmarshal(bundle, &replayed); err != nil {
+		t.Fatalf("Unmarshal session: %v", err)
+	}
+	if sol := replayed.Solutions[1]; sol == nil || sol.Description != "Delete the broken config file" {
+		t.Errorf("Expect the referenced solution to be recorded, got %#v", sol)
+	}
+	replay, err := Replay(context.Background(), &replayed)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	expect, _ := json.Marshal(analysis)
+	got, _ := json.Marshal(replay)
+	if !bytes.Equal(expect, got) {
+		t.Errorf("Expect replay to reproduce the results\nexpect: %s\ngot:    %s", expect, got)
+	}
+	if replay.Primary == nil || replay.Primary.Error.Class != "java.io.FileNotFoundException" {
+		t.Errorf("Expect the recorded primary selector to be used, got %#v", replay.Primary)
+	}
+}
+
+func TestSessionOptionsMatchWeights(t *testing.T) {
+	options, err := json.Marshal(SessionOptions{MinFuzzyLength: 4})
+	if err != nil {
+		t.Fatalf("Marshal options: %v", err)
+	}
+	if strings.Contains(string(options), "matchWeights") || !strings.Contains(string(options), `"minFuzzyLength":4`) {
+		t.Errorf("Expect the zero match weights to be omitted, got %s", options)
+	}
+
+	weights := MatchWeights{ExactClass: 0.2, ClassName: 0.1, Message: 0.7}
+	options, err = json.Marshal(&SessionOptions{MatchWeights: weights})
+	if err != nil {
+		t.Fatalf("Marshal options: %v", err)
+	}
+	var decoded SessionOptions
+	if err = json.Unmarshal(options, &decoded); err != nil {
+		t.Fatalf("Unmarshal options: %v", err)
+	}
+	if decoded.MatchWeights != weights {
+		t.Errorf("Expect the match weights to be recorded, got %#v from %s", decoded.MatchWeights, options)
+	}
+}
@@ -0,0 +1,21 @@
+package mcla
+
+// Severity is how serious a matched error is, it's set by the error description
+type Severity string
+
+const (
+	// SeverityUnknown is used when the description does not set its severity, e.g. the older databases
+	SeverityUnknown Severity = "unknown"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+	SeverityFatal   Severity = "fatal"
+)
+
+// severityOf returns the severity of the description, SeverityUnknown if it's not set
+func severityOf(e *ErrorDesc) Severity {
+	if e.Severity == "" {
+		return SeverityUnknown
+	}
+	return e.Severity
+}
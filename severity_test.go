@@ -0,0 +1,36 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"testing/fstest"
+)
+
+func TestSeverity(t *testing.T) {
+	fsys := fstest.MapFS{
+		"errors/1.json": &fstest.MapFile{
+			Data: ([]byte)(`{"error":"java.io.IOException","message":"Connection reset by peer","solutions":[],"severity":"warning"}`),
+		},
+		"errors/2.json": &fstest.MapFile{
+			Data: ([]byte)(`{"error":"java.io.IOException","message":"Connection reset","solutions":[]}`),
+		},
+	}
+	analyzer := NewAnalyzer(NewFileDB(fsys))
+	matched, err := analyzer.DoError(&JavaError{Class: "java.io.IOException", Message: "Connection reset by peer"})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 2 {
+		t.Fatalf("Expect 2 matches, got %d", len(matched))
+	}
+	for _, m := range matched {
+		expect := SeverityUnknown
+		if m.Source == "errors/1.json" {
+			expect = SeverityWarning
+		}
+		if m.Severity != expect {
+			t.Errorf("Expect the severity of %s == %q, got %q", m.Source, expect, m.Severity)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package mcla
+
+// Signal is an additional evidence of an error description.
+// Pattern is a regular expression tested against the error message, every stacktrace line and the context lines,
+// a hit provides a confidence of Weight in [0, 1]
+type Signal struct {
+	Pattern string  `json:"pattern"`
+	Weight  float32 `json:"weight"`
+}
+
+// combineNoisyOR combines independent confidences p1...pn as 1 - (1 - p1)(1 - p2)...(1 - pn).
+// The result is never less than the largest one, each extra confidence moves it towards 1 by its own fraction
+// of the remaining distance, and it never exceeds 1. The values are clamped into [0, 1] first
+func combineNoisyOR(ps ...float32) float32 {
+	rest := (float32)(1)
+	for _, p := range ps {
+		rest *= 1 - min(max(p, 0), 1)
+	}
+	return 1 - rest
+}
+
+func signalHits(jerr *JavaError, pattern string) bool {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+	if re.MatchString(jerr.Message) {
+		return true
+	}
+	for _, s := range jerr.Stacktrace {
+		if re.MatchString(s.Raw) {
+			return true
+		}
+	}
+	for _, line := range jerr.Context {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySignals combines the hit signals with the score from the error type and message.
+// When the description has an error type or message, the signals only reinforce a non-zero score
+func applySignals(jerr *JavaError, e *ErrorDesc, match float32) float32 {
+	if len(e.Signals) == 0 {
+		return match
+	}
+	hasBase := e.hasMessage() || (e.Error != "" && e.Error != "*" && e.Error != "*.*")
+	if hasBase && match == 0 {
+		return 0
+	}
+	ps := make([]float32, 0, len(e.Signals)+1)
+	if hasBase {
+		ps = append(ps, match)
+	}
+	for _, s := range e.Signals {
+		if signalHits(jerr, s.Pattern) {
+			ps = append(ps, s.Weight)
+		}
+	}
+	return combineNoisyOR(ps...)
+}
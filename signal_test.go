@@ -0,0 +1,104 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func TestDoErrorSignalsCombine(t *testing.T) {
+	desc := &ErrorDesc{
+		Error: "java.lang.NullPointerException",
+		Signals: []Signal{
+			{Pattern: `com\.example\.mod\.`, Weight: 0.3},
+			{Pattern: `examplemod-[\d.]+\.jar`, Weight: 0.3},
+		},
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+
+	approx := func(a, b float32) bool {
+		return a-b < 1e-6 && b-a < 1e-6
+	}
+	matchOf := func(jerr *JavaError) float32 {
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		match, _ := findMatch(matched, desc)
+		return match
+	}
+
+	typeOnly := matchOf(&JavaError{
+		Class: "java.lang.NullPointerException",
+		Stacktrace: Stacktrace{
+			{Raw: "at net.minecraft.world.level.Level.tick(Level.java:100) ~[client-1.20.1.jar%23156!/:?]"},
+		},
+	})
+	frameOnly := matchOf(&JavaError{
+		Class: "java.lang.IllegalStateException",
+		Stacktrace: Stacktrace{
+			{Raw: "at com.example.mod.Foo.bar(Foo.java:42) ~[otherjar.jar%23120!/:?]"},
+		},
+	})
+	typeAndFrame := matchOf(&JavaError{
+		Class: "java.lang.NullPointerException",
+		Stacktrace: Stacktrace{
+			{Raw: "at com.example.mod.Foo.bar(Foo.java:42) ~[otherjar.jar%23120!/:?]"},
+		},
+	})
+	all := matchOf(&JavaError{
+		Class: "java.lang.NullPointerException",
+		Stacktrace: Stacktrace{
+			{Raw: "at com.example.mod.Foo.bar(Foo.java:42) ~[examplemod-1.0.0.jar%23120!/:?]"},
+		},
+	})
+	// a bare error type is generic, the score is bounded by its specificity, which is raised by the signals
+	const specificity = 0.9
+	if !approx(typeOnly, specificity) {
+		t.Errorf("Expect the error type alone to provide the whole score when the message is empty, got %v", typeOnly)
+	}
+	if frameOnly != 0 {
+		t.Errorf("Expect the signals to not match an error of another type, got %v", frameOnly)
+	}
+	if !approx(typeAndFrame, specificity) || !approx(all, specificity) {
+		t.Errorf("Expect the combined score to be bounded at 1, got %v and %v", typeAndFrame, all)
+	}
+
+	weak := &ErrorDesc{
+		Error: "*",
+		Signals: []Signal{
+			{Pattern: `java\.lang\.NullPointerException|because "\w+" is null`, Weight: 0.4},
+			{Pattern: `com\.example\.mod\.`, Weight: 0.4},
+			{Pattern: `examplemod-[\d.]+\.jar`, Weight: 0.4},
+			{Pattern: `never matches`, Weight: 1},
+		},
+	}
+	analyzer = NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{weak}})
+	desc = weak
+	one := matchOf(&JavaError{
+		Class:   "java.lang.NullPointerException",
+		Message: `Cannot invoke "Object.toString()" because "value" is null`,
+	})
+	two := matchOf(&JavaError{
+		Class:   "java.lang.NullPointerException",
+		Message: `Cannot invoke "Object.toString()" because "value" is null`,
+		Stacktrace: Stacktrace{
+			{Raw: "at com.example.mod.Foo.bar(Foo.java:42) ~[otherjar.jar%23120!/:?]"},
+		},
+	})
+	three := matchOf(&JavaError{
+		Class:   "java.lang.NullPointerException",
+		Message: `Cannot invoke "Object.toString()" because "value" is null`,
+		Stacktrace: Stacktrace{
+			{Raw: "at com.example.mod.Foo.bar(Foo.java:42) ~[examplemod-1.0.0.jar%23120!/:?]"},
+		},
+	})
+	if !approx(one, 0.4) {
+		t.Errorf("Expect a single signal to score its weight 0.4, got %v", one)
+	}
+	if !(one < two && two < three && three < 1) {
+		t.Errorf("Expect each agreeing signal to increase the score below 1, got %v, %v, %v", one, two, three)
+	}
+	if expect := (float32)(1 - 0.6*0.6*0.6); !approx(three, expect) {
+		t.Errorf("Expect three signals of 0.4 to combine to %v, got %v", expect, three)
+	}
+}
@@ -0,0 +1,52 @@
+package mcla
+
+import (
+	"sync"
+)
+
+// similarityKey identifies a message comparison, the message is the first line of the error message
+type similarityKey struct {
+	message string
+	descID  string
+}
+
+// similarityCache memoizes the message similarities during a single stream run.
+// It's shared by the workers of the parallel matching, and it's cleared when it reaches the size limit
+type similarityCache struct {
+	size   int
+	mux    sync.Mutex
+	scores map[similarityKey]float32
+}
+
+// newSimilarityCache returns nil if the size is not positive, and a nil cache caches nothing
+func newSimilarityCache(size int) *similarityCache {
+	if size <= 0 {
+		return nil
+	}
+	return &similarityCache{
+		size:   size,
+		scores: make(map[similarityKey]float32),
+	}
+}
+
+func (c *similarityCache) get(message string, e *ErrorDesc) (score float32, ok bool) {
+	if c == nil || e.ID == "" {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	score, ok = c.scores[similarityKey{message, e.ID}]
+	return
+}
+
+func (c *similarityCache) put(message string, e *ErrorDesc, score float32) {
+	if c == nil || e.ID == "" {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if len(c.scores) >= c.size {
+		clear(c.scores)
+	}
+	c.scores[similarityKey{message, e.ID}] = score
+}
@@ -0,0 +1,83 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"fmt"
+	"strings"
+)
+
+func repeatedErrorsLog(n int) string {
+	var log strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&log, "[12:00:%02d] [Worker-Main-%d/ERROR]: Failed to load texture\n", i%60, i%4)
+		log.WriteString("java.io.FileNotFoundException: minecraft:textures/block/missing_texture.png (No such file or directory)\n")
+		log.WriteString("\tat net.minecraft.server.packs.VanillaPackResources.getResource(VanillaPackResources.java:95)\n")
+	}
+	return log.String()
+}
+
+func similarityBenchDB() *memErrorDB {
+	db := &memErrorDB{}
+	for i := 0; i < 50; i++ {
+		db.errors = append(db.errors, &ErrorDesc{
+			Error:   "java.io.FileNotFoundException",
+			Message: fmt.Sprintf("config/mod_%d/settings.toml (The system cannot find the file specified)", i),
+		})
+	}
+	return db
+}
+
+func TestSimilarityCache(t *testing.T) {
+	log := repeatedErrorsLog(20)
+	db := similarityBenchDB()
+	analyzer := NewAnalyzer(db)
+	expect, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("AnalyzeLog: %v", err)
+	}
+	for _, size := range []int{1, 1000} {
+		analyzer := NewAnalyzer(db)
+		analyzer.SimilarityCacheSize = size
+		got, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log))
+		if err != nil {
+			t.Fatalf("AnalyzeLog: %v", err)
+		}
+		if len(got.Results) != len(expect.Results) {
+			t.Fatalf("Expect %d results, got %d", len(expect.Results), len(got.Results))
+		}
+		for i, res := range got.Results {
+			want := expect.Results[i].Matched
+			if len(res.Matched) != len(want) {
+				t.Fatalf("Expect %d matches for result %d, got %d", len(want), i, len(res.Matched))
+			}
+			for j, m := range res.Matched {
+				if m.ID != want[j].ID || m.Match != want[j].Match {
+					t.Errorf("Expect cached match %s=%v, got %s=%v", want[j].ID, want[j].Match, m.ID, m.Match)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSimilarityCache(b *testing.B) {
+	log := repeatedErrorsLog(200)
+	db := similarityBenchDB()
+	for _, size := range []int{0, 4096} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			analyzer := NewAnalyzer(db)
+			analyzer.SimilarityCacheSize = size
+			if err := analyzer.UpdateErrors(); err != nil {
+				b.Fatalf("UpdateErrors: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log)); err != nil {
+					b.Fatalf("AnalyzeLog: %v", err)
+				}
+			}
+		})
+	}
+}
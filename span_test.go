@@ -0,0 +1,86 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+)
+
+const spanLog = "[12:00:00] [main/INFO] [mixin/]: Loaded 12 mixins from examplemod.mixins.json\r\n" +
+	"[12:00:01] [main/ERROR]: Something went wrong\n" +
+	"java.lang.IllegalStateException: Not a valid state\n" +
+	"\tat com.example.mod.Foo.bar(Foo.java:42)\n" +
+	"\tat com.example.mod.Foo.bar(Foo.java:42)\n" +
+	"\tat com.example.mod.Foo.baz(Foo.java:43)\n" +
+	"Caused by: java.lang.NullPointerException: null\r\n" +
+	"\tat com.example.mod.Foo.qux(Foo.java:44)\n" +
+	"\t... 3 more\n" +
+	"[12:00:02] [main/INFO]: Continuing\n" +
+	"java.lang.RuntimeException: The last one\n" +
+	"\tat com.example.mod.Foo.end(Foo.java:50)"
+
+func TestResultSpan(t *testing.T) {
+	datas := []struct {
+		Class     string
+		StartLine int
+		EndLine   int
+		Text      string
+	}{
+		{"java.lang.IllegalStateException", 3, 9, "java.lang.IllegalStateException: Not a valid state\n"},
+		{"java.lang.NullPointerException", 7, 9, "Caused by: java.lang.NullPointerException: null\r\n"},
+		{"java.lang.RuntimeException", 11, 12, "java.lang.RuntimeException: The last one\n"},
+	}
+	for _, collapse := range []bool{false, true} {
+		analyzer := NewAnalyzer(&memErrorDB{})
+		analyzer.CollapseRepeatedLines = collapse
+		resCh, _ := analyzer.DoLogStream(context.Background(), strings.NewReader(spanLog))
+		var results []*ErrorResult
+		for res := range resCh {
+			results = append(results, res)
+		}
+		if len(results) != len(datas) {
+			t.Fatalf("Expect %d results, got %d", len(datas), len(results))
+		}
+		for i, d := range datas {
+			res := results[i]
+			if res.Error.Class != d.Class {
+				t.Errorf("Expect result %d class == %q, got %q", i, d.Class, res.Error.Class)
+			}
+			if res.StartLine != d.StartLine || res.EndLine != d.EndLine {
+				t.Errorf("Expect result %d at lines %d-%d, got %d-%d", i, d.StartLine, d.EndLine, res.StartLine, res.EndLine)
+			}
+			span := spanLog[res.StartOffset:res.EndOffset]
+			if !strings.HasPrefix(span, d.Text) {
+				t.Errorf("Expect result %d span starts with %q, got %q", i, d.Text, span)
+			}
+			if lines := strings.Count(strings.TrimSuffix(span, "\n"), "\n") + 1; lines != d.EndLine-d.StartLine+1 {
+				t.Errorf("Expect result %d span has %d lines, got %d: %q", i, d.EndLine-d.StartLine+1, lines, span)
+			}
+		}
+	}
+}
+
+func TestIncrementalResultSpan(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	ia := analyzer.NewIncrementalAnalysis()
+	for i := 0; i < len(spanLog); i += 37 {
+		if _, err := ia.Append(([]byte)(spanLog[i:min(i+37, len(spanLog))])); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if _, err := ia.Finish(); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	results := ia.Results()
+	if len(results) != 3 {
+		t.Fatalf("Expect 3 results, got %d", len(results))
+	}
+	for i, res := range results {
+		span := spanLog[res.StartOffset:res.EndOffset]
+		if first, _, _ := strings.Cut(span, "\n"); !strings.HasSuffix(strings.TrimSuffix(first, "\r"), res.Error.Message) {
+			t.Errorf("Expect result %d span starts with the error, got %q", i, span)
+		}
+	}
+}
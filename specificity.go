@@ -0,0 +1,77 @@
+package mcla
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// bareTypeSpecificity is the specificity of a description which only has the error type
+	bareTypeSpecificity = 0.8
+	// prefixMinSpecificity is the specificity of a message which is an empty prefix, a longer prefix is more specific
+	prefixMinSpecificity = 0.9
+	// prefixFullLength is the prefix length in characters which is considered as specific as a full message
+	prefixFullLength = 40
+)
+
+// descSpecificity approximates how specific the description is, in range (0, 1].
+// The score of a description is multiplied by it, so a generic description cannot outrank a specific one
+// on an error both of them match.
+//
+// It works like the inverse document frequency: a description which matches a large fraction of all errors
+// tells less about the error. Instead of counting the matches over a sample corpus,
+// the fraction is approximated by the structure of the description:
+//
+//   - A bare error type without a message matches every error of that type, so it's penalized the most.
+//   - A message which is only a prefix (ends with " *") matches every message with that prefix,
+//     the penalty is smaller for a longer prefix.
+//   - The context pattern, the signals and the MustNotMatch pattern narrow the description down,
+//     each of them halves the penalty.
+//   - The alternative messages can match any of them, so the most generic one decides.
+func descSpecificity(e *ErrorDesc) float32 {
+	var specificity float32 = 1
+	if !e.hasMessage() {
+		if e.Error == "" || e.Error == "*" || e.Error == "*.*" {
+			// it cannot match anything without signals, the signals decide the score
+			return 1
+		}
+		specificity = bareTypeSpecificity
+	} else {
+		if e.Message != "" {
+			specificity = messageSpecificity(e.Message)
+		}
+		for _, m := range e.Messages {
+			if m != "" {
+				specificity = min(specificity, messageSpecificity(m))
+			}
+		}
+	}
+	penalty := 1 - specificity
+	if e.Context != "" {
+		penalty /= 2
+	}
+	if len(e.Signals) > 0 {
+		penalty /= 2
+	}
+	if e.MustNotMatch != "" {
+		penalty /= 2
+	}
+	return 1 - penalty
+}
+
+// messageSpecificity is 1 for a full message, and less for a prefix which ends with " *"
+func messageSpecificity(message string) float32 {
+	prefix, ok := strings.CutSuffix(message, " *")
+	if !ok {
+		return 1
+	}
+	n := min(utf8.RuneCountInString(prefix), prefixFullLength)
+	return prefixMinSpecificity + (1-prefixMinSpecificity)*(float32)(n)/prefixFullLength
+}
+
+// errorTypeSpecificity tells how specific the error type of a description is, it only breaks the ties of the matches.
+// An exact class is more specific than any pattern with `*`, and a pattern with more literal characters is more specific
+func errorTypeSpecificity(pattern string) (exact bool, literal int) {
+	wildcards := strings.Count(pattern, "*")
+	return pattern != "" && wildcards == 0, len(pattern) - wildcards
+}
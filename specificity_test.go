@@ -0,0 +1,52 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func TestGenericDescriptionPenalty(t *testing.T) {
+	generic := &ErrorDesc{
+		Error: "java.lang.NullPointerException",
+	}
+	prefix := &ErrorDesc{
+		Error:   "java.lang.NullPointerException",
+		Message: "Cannot invoke *",
+	}
+	specific := &ErrorDesc{
+		Error:   "java.lang.NullPointerException",
+		Message: `Cannot invoke "net.minecraft.world.entity.Entity.getId()" because "entity" is null`,
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{generic, prefix, specific}})
+	matched, err := analyzer.DoError(&JavaError{
+		Class:   "java.lang.NullPointerException",
+		Message: `Cannot invoke "net.minecraft.world.entity.Entity.getId()" because "entity" is null`,
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	genericMatch, ok1 := findMatch(matched, generic)
+	prefixMatch, ok2 := findMatch(matched, prefix)
+	specificMatch, ok3 := findMatch(matched, specific)
+	if !ok1 || !ok2 || !ok3 {
+		t.Fatalf("Expect all descriptions to match, got %#v", matched)
+	}
+	if !(genericMatch < prefixMatch && prefixMatch < specificMatch) {
+		t.Errorf("Expect generic < prefix < specific, got %v, %v, %v", genericMatch, prefixMatch, specificMatch)
+	}
+	if specificMatch != 1 {
+		t.Errorf("Expect the exact description to keep the full score, got %v", specificMatch)
+	}
+
+	// a generic description still gets a high score when nothing more specific matches
+	matched, _ = analyzer.DoError(&JavaError{
+		Class:   "java.lang.NullPointerException",
+		Message: "Something else is null",
+	})
+	if m, ok := findMatch(matched, generic); !ok || m < 0.5 {
+		t.Errorf("Expect the generic description to match, got %v", m)
+	}
+	if m, _ := findMatch(matched, specific); m >= genericMatch {
+		t.Errorf("Expect the unrelated specific description to rank lower than the generic one, got %v >= %v", m, genericMatch)
+	}
+}
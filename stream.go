@@ -0,0 +1,143 @@
+package mcla
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// DropPolicy decides what a stream does when its result channel is full, see Analyzer.DropPolicy
+type DropPolicy int
+
+const (
+	// DropNone blocks the analysis until the consumer receives a result, nothing is lost
+	DropNone DropPolicy = iota
+	// DropOldest removes the oldest result in the channel to make room for the new one,
+	// so the consumer always sees the latest results
+	DropOldest
+	// DropNewest discards the new result, so the consumer sees the earliest results
+	DropNewest
+)
+
+// LogStream is the handle of a running log analysis
+type LogStream struct {
+	// Results will be closed when the analysis is done or cancelled
+	Results <-chan *ErrorResult
+
+	ctx   context.Context
+	state *streamState
+}
+
+// StartLogStream starts analyzing the log like DoLogStream, but returns a handle
+// which can pause and resume the analysis
+func (a *Analyzer) StartLogStream(c context.Context, r io.Reader) *LogStream {
+	state := &streamState{
+		gate: new(pauseGate),
+	}
+	results, ctx := a.doLogStream(c, r, state)
+	return &LogStream{
+		Results: results,
+		ctx:     ctx,
+		state:   state,
+	}
+}
+
+// Context returns the context of the analysis, the cause will be set if any error occurred
+func (s *LogStream) Context() context.Context {
+	return s.ctx
+}
+
+// Pause stops reading the log and producing new results until Resume is called.
+// The results which are already in the channel can still be received.
+// Cancelling the context will stop a paused stream as well
+func (s *LogStream) Pause() {
+	s.state.gate.Pause()
+}
+
+func (s *LogStream) Resume() {
+	s.state.gate.Resume()
+}
+
+func (s *LogStream) Paused() bool {
+	return s.state.gate.Paused()
+}
+
+// DroppedResults returns how many results have been dropped by Analyzer.DropPolicy
+func (s *LogStream) DroppedResults() int64 {
+	return s.state.dropped.Load()
+}
+
+// UpdateRepeatCounts sets ErrorResult.Count and ErrorResult.LastLine of the sent results to the repeats seen so far,
+// see Analyzer.DedupErrors. The counts are final after Results is closed. It does nothing if the dedup is not enabled
+func (s *LogStream) UpdateRepeatCounts() {
+	if s.state.dedup != nil {
+		s.state.dedup.update()
+	}
+}
+
+// CollapsedLines returns how many repeated lines have been skipped, see Analyzer.CollapseRepeatedLines
+func (s *LogStream) CollapsedLines() int64 {
+	return s.state.collapsed.Load()
+}
+
+type pauseGate struct {
+	mux    sync.Mutex
+	paused bool
+	resume chan struct{} // will be closed when resumed
+}
+
+func (g *pauseGate) Pause() {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if !g.paused {
+		g.paused = true
+		g.resume = make(chan struct{})
+	}
+}
+
+func (g *pauseGate) Resume() {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if g.paused {
+		g.paused = false
+		close(g.resume)
+	}
+}
+
+func (g *pauseGate) Paused() bool {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	return g.paused
+}
+
+// wait blocks while the gate is paused, it's safe to call on a nil gate
+func (g *pauseGate) wait(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+	g.mux.Lock()
+	paused, resume := g.paused, g.resume
+	g.mux.Unlock()
+	if !paused {
+		return nil
+	}
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+type gatedReader struct {
+	r    io.Reader
+	gate *pauseGate
+	ctx  context.Context
+}
+
+func (r *gatedReader) Read(buf []byte) (int, error) {
+	if err := r.gate.wait(r.ctx); err != nil {
+		return 0, err
+	}
+	return r.r.Read(buf)
+}
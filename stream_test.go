@@ -0,0 +1,356 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type countingReader struct {
+	r    io.Reader
+	read atomic.Int64
+}
+
+func (r *countingReader) Read(buf []byte) (n int, err error) {
+	// read a few bytes at a time so the analysis can be paused in the middle
+	if len(buf) > 64 {
+		buf = buf[:64]
+	}
+	n, err = r.r.Read(buf)
+	r.read.Add((int64)(n))
+	return
+}
+
+func drainResults(ch <-chan *ErrorResult, wait time.Duration) (n int, closed bool) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case res, ok := <-ch:
+			if !ok {
+				return n, true
+			}
+			if res != nil {
+				n++
+			}
+		case <-timer.C:
+			return n, false
+		}
+	}
+}
+
+func TestLogStreamPauseResume(t *testing.T) {
+	const errorCount = 50
+	var log strings.Builder
+	for i := 0; i < errorCount; i++ {
+		log.WriteString("[12:00:00] [Server thread/ERROR]: Something failed\n")
+		log.WriteString("java.lang.IllegalStateException: Something went wrong\n")
+		log.WriteString("\tat net.minecraft.server.level.ServerLevel.tick(ServerLevel.java:100)\n")
+		log.WriteString("\tat net.minecraft.server.MinecraftServer.tickChildren(MinecraftServer.java:900)\n")
+	}
+	log.WriteString("[12:00:01] [Server thread/INFO]: Done\n")
+
+	r := &countingReader{r: strings.NewReader(log.String())}
+	analyzer := NewAnalyzer(&memErrorDB{})
+	stream := analyzer.StartLogStream(context.Background(), r)
+
+	if res := <-stream.Results; res == nil {
+		t.Fatalf("Expect the first result, got nil")
+	}
+	stream.Pause()
+	if !stream.Paused() {
+		t.Fatalf("Expect the stream to be paused")
+	}
+	// the results produced before pause are still in the buffer
+	got, closed := drainResults(stream.Results, 50*time.Millisecond)
+	if closed {
+		t.Fatalf("Expect the stream is not finished while paused")
+	}
+	read := r.read.Load()
+	n, closed := drainResults(stream.Results, 100*time.Millisecond)
+	if n != 0 || closed {
+		t.Errorf("Expect no result produced while paused, got %d (closed=%v)", n, closed)
+	}
+	if now := r.read.Load(); now != read {
+		t.Errorf("Expect the log is not read while paused, read %d bytes more", now-read)
+	}
+
+	stream.Resume()
+	n, closed = drainResults(stream.Results, 5*time.Second)
+	if !closed {
+		t.Fatalf("Expect the stream to finish after resumed")
+	}
+	if err := context.Cause(stream.Context()); err != nil {
+		t.Fatalf("Stream error: %v", err)
+	}
+	if total := 1 + got + n; total != errorCount {
+		t.Errorf("Expect %d results in total, got %d", errorCount, total)
+	}
+}
+
+func TestLogStreamCancelWhilePaused(t *testing.T) {
+	r := &countingReader{r: strings.NewReader(strings.Repeat("java.lang.IllegalStateException: x\n\tat a.b.C.d(C.java:1)\n", 100))}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := NewAnalyzer(&memErrorDB{}).StartLogStream(ctx, r)
+	<-stream.Results
+	stream.Pause()
+	cancel()
+	if _, closed := drainResults(stream.Results, 5*time.Second); !closed {
+		t.Errorf("Expect the paused stream to stop after cancelled")
+	}
+}
+
+func TestCollapseRepeatedLines(t *testing.T) {
+	const repeat = 10000
+	var log strings.Builder
+	for i := 0; i < repeat; i++ {
+		log.WriteString("[12:00:00] [Server thread/WARN]: Can't keep up! Is the server overloaded?\n")
+	}
+	log.WriteString("java.lang.StackOverflowError: null\n")
+	for i := 0; i < repeat; i++ {
+		log.WriteString("\tat com.example.mod.Foo.recurse(Foo.java:10)\n")
+	}
+	log.WriteString("[12:00:01] [Server thread/INFO]: Stopping server\n")
+
+	for _, collapse := range []bool{false, true} {
+		analyzer := NewAnalyzer(&memErrorDB{})
+		analyzer.CollapseRepeatedLines = collapse
+		analysis, err := analyzer.AnalyzeLog(context.Background(), strings.NewReader(log.String()))
+		if err != nil {
+			t.Fatalf("AnalyzeLog: %v", err)
+		}
+		if len(analysis.Results) != 1 {
+			t.Fatalf("Expect 1 result, got %d", len(analysis.Results))
+		}
+		je := analysis.Results[0].Error
+		if expect := repeat + 1; je.LineNo != expect {
+			t.Errorf("Expect je.LineNo == %d, got %d", expect, je.LineNo)
+		}
+		var expectCollapsed int64
+		expectFrames := 1024 // limited by the max stack frames
+		if collapse {
+			expectCollapsed = 2 * (repeat - 1)
+			expectFrames = 1
+		}
+		if analysis.CollapsedLines != expectCollapsed {
+			t.Errorf("Expect %d lines collapsed, got %d", expectCollapsed, analysis.CollapsedLines)
+		}
+		if len(je.Stacktrace) != expectFrames {
+			t.Errorf("Expect %d stack frames, got %d", expectFrames, len(je.Stacktrace))
+		}
+	}
+}
+
+type readCallCounter struct {
+	r     io.Reader
+	calls int
+}
+
+func (r *readCallCounter) Read(buf []byte) (int, error) {
+	r.calls++
+	return r.r.Read(buf)
+}
+
+func BenchmarkLogStreamReadBuffer(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&sb, "[12:00:%02d] [Server thread/INFO]: Loaded chunk %d in region r.%d.%d.mca\n", i%60, i, i%32, i%17)
+		if i%1000 == 0 {
+			sb.WriteString("[12:00:00] [Server thread/ERROR]: Encountered an unexpected exception\n")
+			sb.WriteString("java.lang.RuntimeException: Something went wrong\n\tat com.example.mod.Foo.bar(Foo.java:42)\n")
+		}
+	}
+	name := filepath.Join(b.TempDir(), "latest.log")
+	if err := os.WriteFile(name, []byte(sb.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	for _, size := range []int{-1, 0} {
+		b.Run(fmt.Sprintf("buffer=%d", size), func(b *testing.B) {
+			analyzer := NewAnalyzer(&memErrorDB{})
+			analyzer.ReadBufferSize = size
+			b.SetBytes((int64)(sb.Len()))
+			calls := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				fd, err := os.Open(name) // an *os.File is not buffered, each read is a syscall
+				if err != nil {
+					b.Fatal(err)
+				}
+				r := &readCallCounter{r: fd}
+				if _, err := analyzer.AnalyzeLog(context.Background(), r); err != nil {
+					b.Fatalf("AnalyzeLog: %v", err)
+				}
+				fd.Close()
+				calls += r.calls
+			}
+			b.ReportMetric((float64)(calls)/(float64)(b.N), "reads/op")
+		})
+	}
+}
+
+func TestLogStreamReadBuffer(t *testing.T) {
+	log := strings.Repeat("[12:00:00] [Server thread/INFO]: Loaded a chunk\n", 10000)
+	reads := make(map[int]int)
+	for _, size := range []int{-1, 0} {
+		analyzer := NewAnalyzer(&memErrorDB{})
+		analyzer.ReadBufferSize = size
+		r := &readCallCounter{r: strings.NewReader(log)}
+		if _, err := analyzer.AnalyzeLog(context.Background(), r); err != nil {
+			t.Fatalf("AnalyzeLog: %v", err)
+		}
+		reads[size] = r.calls
+	}
+	if reads[0] >= reads[-1] {
+		t.Errorf("Expect the buffered stream to read less often, got %d reads buffered and %d unbuffered", reads[0], reads[-1])
+	}
+}
+
+func TestLogStreamDropPolicy(t *testing.T) {
+	var log strings.Builder
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&log, "[12:00:00] [Server thread/ERROR]: Error %d\njava.lang.RuntimeException: Error %d\n\tat com.example.mod.Foo.bar(Foo.java:42)\n", i, i)
+	}
+	datas := []struct {
+		Name    string
+		Policy  DropPolicy
+		Expect  []string
+		Dropped int64
+	}{
+		{"DropNone", DropNone, nil, 0},
+		{"DropNewest", DropNewest, []string{"Error 0", "Error 1"}, 18},
+		{"DropOldest", DropOldest, []string{"Error 18", "Error 19"}, 18},
+	}
+	for _, d := range datas {
+		analyzer := NewAnalyzer(&memErrorDB{})
+		analyzer.ResultBufferSize = 2
+		analyzer.DropPolicy = d.Policy
+		stream := analyzer.StartLogStream(context.Background(), strings.NewReader(log.String()))
+		// the consumer does not receive anything until the analysis has nothing left to do
+		deadline := time.Now().Add(5 * time.Second)
+		for stream.DroppedResults() < d.Dropped && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		var messages []string
+		for res := range stream.Results {
+			messages = append(messages, res.Error.Message)
+			time.Sleep(time.Millisecond) // a slow consumer
+		}
+		if n := stream.DroppedResults(); n != d.Dropped {
+			t.Errorf("%s: Expect %d dropped results, got %d", d.Name, d.Dropped, n)
+		}
+		if d.Expect == nil {
+			if len(messages) != 20 {
+				t.Errorf("%s: Expect all 20 results, got %d", d.Name, len(messages))
+			}
+			continue
+		}
+		if strings.Join(messages, ",") != strings.Join(d.Expect, ",") {
+			t.Errorf("%s: Expect results %q, got %q", d.Name, d.Expect, messages)
+		}
+	}
+}
+
+func TestLogStreamTruncated(t *testing.T) {
+	datas := []struct {
+		Log        string
+		Classes    []string
+		Incomplete []bool
+		Cause      error
+	}{
+		{
+			"[12:00:00] [main/ERROR]: Something went wrong\n" +
+				"java.lang.IllegalStateException: Not a valid state\n" +
+				"\tat com.example.mod.Foo.bar(Foo.java:42)\n" +
+				"[12:00:01] [main/INFO]: Continuing\n" +
+				"java.lang.RuntimeException: Cut off\n" +
+				"\tat com.example.mod.Foo.baz(Foo.java:43)\n" +
+				"Caused by: java.lang.NullPointerException: null\n" +
+				"\tat com.example.mod.Foo.q",
+			[]string{"java.lang.IllegalStateException", "java.lang.RuntimeException", "java.lang.NullPointerException"},
+			[]bool{false, true, true},
+			nil,
+		},
+		{
+			"---- Minecraft Crash Report ----\n" +
+				"Description: Ticking entity\n" +
+				"\n" +
+				"java.lang.NullPointerException: Cannot invoke \"net.minecraft.world.entity.Entity.getId()\"",
+			[]string{"java.lang.NullPointerException"},
+			[]bool{true},
+			ErrCrashReportIncomplete,
+		},
+	}
+	for i, d := range datas {
+		analyzer := NewAnalyzer(&memErrorDB{})
+		resCh, ctx := analyzer.DoLogStream(context.Background(), strings.NewReader(d.Log))
+		var results []*ErrorResult
+		for res := range resCh {
+			results = append(results, res)
+		}
+		if len(results) != len(d.Classes) {
+			t.Fatalf("Expect log %d has %d results, got %d", i, len(d.Classes), len(results))
+		}
+		for j, res := range results {
+			if res.Error.Class != d.Classes[j] || res.Incomplete != d.Incomplete[j] {
+				t.Errorf("Expect log %d result %d == %s (incomplete: %v), got %s (incomplete: %v)", i, j, d.Classes[j], d.Incomplete[j], res.Error.Class, res.Incomplete)
+			}
+		}
+		if cause := context.Cause(ctx); !errors.Is(cause, d.Cause) {
+			t.Errorf("Expect log %d cause == %v, got %v", i, d.Cause, cause)
+		}
+	}
+}
+
+func TestLogStreamProgress(t *testing.T) {
+	var log strings.Builder
+	for i := 0; log.Len() < 300*1024; i++ {
+		fmt.Fprintf(&log, "[12:00:00] [Server thread/INFO]: Preparing spawn area: %d%%\n", i%100)
+	}
+	log.WriteString("java.lang.IllegalStateException: Not building!\n\tat com.example.mod.Renderer.render(Renderer.java:10)\n")
+	size := (int64)(log.Len())
+
+	for _, d := range []struct {
+		Name   string
+		Reader io.Reader
+		Total  int64
+	}{
+		{"known size", strings.NewReader(log.String()), size},
+		{"unknown size", io.MultiReader(strings.NewReader(log.String())), -1},
+	} {
+		var events []Progress
+		analyzer := NewAnalyzer(&memErrorDB{})
+		analyzer.OnProgress = func(p Progress) {
+			events = append(events, p)
+		}
+		result, ctx := analyzer.DoLogStream(context.Background(), d.Reader)
+		for range result {
+		}
+		if err := context.Cause(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			t.Fatalf("%s: stream failed: %v", d.Name, err)
+		}
+		if len(events) < 3 {
+			t.Fatalf("%s: Expect several progress events, got %v", d.Name, events)
+		}
+		for i, p := range events[:len(events)-1] {
+			if p.Total != d.Total {
+				t.Errorf("%s: Expect Total == %d, got %d", d.Name, d.Total, p.Total)
+			}
+			if i > 0 && p.Read <= events[i-1].Read {
+				t.Errorf("%s: Expect the progress to increase, got %v", d.Name, events)
+			}
+		}
+		if last := events[len(events)-1]; last.Read != size || last.Total != size || last.Percent() != 100 {
+			t.Errorf("%s: Expect the final event at 100%% of %d bytes, got %+v", d.Name, size, last)
+		}
+	}
+}
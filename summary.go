@@ -0,0 +1,57 @@
+package mcla
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+var (
+	// matches the `Description: Ticking entity` line of a crash report and the `Reason: ...` line of a loading error
+	summaryLineRe = regexp.MustCompile(`^\s*(?i:Description|Reason):\s*(.+)$`)
+)
+
+// summaryLine is a `Description:` or `Reason:` line which summarizes the crash
+type summaryLine struct {
+	LineNo    int
+	Offset    int64
+	EndOffset int64
+	Text      string
+}
+
+func parseSummaryLine(line string) (text string, ok bool) {
+	matches := summaryLineRe.FindStringSubmatch(line)
+	if matches == nil {
+		return
+	}
+	if text = strings.TrimSpace(matches[1]); text == "" {
+		return
+	}
+	return text, true
+}
+
+// summaryResults analyzes the summary lines as messages,
+// it's used when the log does not contain any structured exception
+func (a *Analyzer) summaryResults(ctx context.Context, summaries []summaryLine, cache *similarityCache, logs *recentLogs) (results []*ErrorResult, err error) {
+	for _, s := range summaries {
+		jerr := &JavaError{
+			Message:   s.Text,
+			LineNo:    s.LineNo,
+			EndLineNo: s.LineNo,
+			Offset:    s.Offset,
+			EndOffset: s.EndOffset,
+		}
+		var res *ErrorResult
+		if res, err = a.analyzeError(ctx, jerr, nil, cache, logs); err != nil {
+			return
+		}
+		res.SummaryOnly = true
+		if a.Anonymize {
+			an := newAnonymizer(jerr)
+			an.anonymizeError(jerr)
+			an.anonymizeResult(res)
+		}
+		results = append(results, res)
+	}
+	return
+}
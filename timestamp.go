@@ -0,0 +1,85 @@
+package mcla
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	logTimeRe = regexp.MustCompile(`^\[(\d{1,2}):(\d{2}):(\d{2})(?:[.,](\d{1,3}))?\]`)
+	// matches `[2024-01-01 12:34:56]`, `2024-01-01 12:34:56,789` and `[2024-01-01T12:34:56.789+08:00]`
+	logDateTimeRe = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2})[T ](\d{1,2}:\d{2}:\d{2}(?:[.,]\d{1,9})?)(Z|[+-]\d{2}:?\d{2})?\]?`)
+	// matches `[01Jan2024 12:34:56.789]`, which is used by the Forge debug.log
+	logForgeTimeRe = regexp.MustCompile(`^\[(\d{2}[A-Z][a-z]{2}\d{4} \d{1,2}:\d{2}:\d{2}(?:[.,]\d{1,9})?)\]`)
+)
+
+// parseLogTime parses the timestamp prefix of a log line.
+// For the lines like `[12:34:56]` which do not have a date, the date of the returned time is always January 1, year 0,
+// so the midnight is not the zero time.
+// The time without a zone is in UTC
+func parseLogTime(line string) (t time.Time, ok bool) {
+	if matches := logTimeRe.FindStringSubmatch(line); matches != nil {
+		return parseClockTime(matches)
+	}
+	if matches := logDateTimeRe.FindStringSubmatch(line); matches != nil {
+		value, layout := matches[1]+" "+matches[2], "2006-01-02 15:04:05"
+		switch zone := matches[3]; {
+		case zone == "Z":
+			value += "Z"
+			layout += "Z07:00"
+		case len(zone) == 6:
+			value += zone
+			layout += "-07:00"
+		case zone != "":
+			value += zone
+			layout += "-0700"
+		}
+		return parseTimeValue(layout, value)
+	}
+	if matches := logForgeTimeRe.FindStringSubmatch(line); matches != nil {
+		return parseTimeValue("02Jan2006 15:04:05", matches[1])
+	}
+	return
+}
+
+// trimLogTime removes the timestamp prefix recognized by parseLogTime from the line
+func trimLogTime(line string) string {
+	for _, re := range []*regexp.Regexp{logTimeRe, logDateTimeRe, logForgeTimeRe} {
+		if loc := re.FindStringIndex(line); loc != nil {
+			return line[loc[1]:]
+		}
+	}
+	return line
+}
+
+// clockTimeYear is the year of the log times which do not have a date
+const clockTimeYear = 0
+
+func parseClockTime(matches []string) (t time.Time, ok bool) {
+	hour, _ := strconv.Atoi(matches[1])
+	minute, _ := strconv.Atoi(matches[2])
+	second, _ := strconv.Atoi(matches[3])
+	var nsec int
+	if ms := matches[4]; ms != "" {
+		nsec, _ = strconv.Atoi(ms)
+		for i := len(ms); i < 3; i++ {
+			nsec *= 10
+		}
+		nsec *= (int)(time.Millisecond)
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return
+	}
+	return time.Date(clockTimeYear, time.January, 1, hour, minute, second, nsec, time.UTC), true
+}
+
+func parseTimeValue(layout, value string) (t time.Time, ok bool) {
+	// the fractional seconds after the seconds field are accepted even if the layout does not have them
+	t, err := time.Parse(layout, strings.Replace(value, ",", ".", 1))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
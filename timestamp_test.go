@@ -0,0 +1,42 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"strings"
+	"time"
+)
+
+func TestErrorTime(t *testing.T) {
+	datas := []struct {
+		Line   string
+		Expect time.Time
+	}{
+		{"[12:34:56] [Render thread/ERROR]: Failed to load texture", time.Date(0, time.January, 1, 12, 34, 56, 0, time.UTC)},
+		{"[12:34:56.789] [Render thread/ERROR]: Failed to load texture", time.Date(0, time.January, 1, 12, 34, 56, 789e6, time.UTC)},
+		{"[00:00:00] [Render thread/ERROR]: Failed to load texture", time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"[2024-03-05 12:34:56] [Render thread/ERROR]: Failed to load texture", time.Date(2024, time.March, 5, 12, 34, 56, 0, time.UTC)},
+		{"2024-03-05 12:34:56,789 ERROR Failed to load texture", time.Date(2024, time.March, 5, 12, 34, 56, 789e6, time.UTC)},
+		{"[2024-03-05T12:34:56.789Z] [Render thread/ERROR]: Failed to load texture", time.Date(2024, time.March, 5, 12, 34, 56, 789e6, time.UTC)},
+		{"[2024-03-05T20:34:56+08:00] [Render thread/ERROR]: Failed to load texture", time.Date(2024, time.March, 5, 12, 34, 56, 0, time.UTC)},
+		{"[05Mar2024 12:34:56.789] [Render thread/ERROR] [net.minecraft.client.Minecraft/]: Failed to load texture", time.Date(2024, time.March, 5, 12, 34, 56, 789e6, time.UTC)},
+		{"[Render thread/ERROR]: Failed to load texture", time.Time{}},
+		{"[25:00:00] [Render thread/ERROR]: Failed to load texture", time.Time{}},
+		{"[2024-13-05 12:34:56] [Render thread/ERROR]: Failed to load texture", time.Time{}},
+	}
+	for _, d := range datas {
+		log := d.Line + "\njava.io.FileNotFoundException: minecraft:textures/foo.png\n" +
+			"\tat net.minecraft.client.renderer.texture.SimpleTexture.load(SimpleTexture.java:40)\n"
+		errs, err := ScanJavaErrors(strings.NewReader(log))
+		if err != nil {
+			t.Fatalf("ScanJavaErrors: %v", err)
+		}
+		if len(errs) != 1 {
+			t.Fatalf("Expect 1 error for %q, got %d", d.Line, len(errs))
+		}
+		if got := errs[0].Time; !got.Equal(d.Expect) || got.IsZero() != d.Expect.IsZero() {
+			t.Errorf("Expect time of %q == %v, got %v", d.Line, d.Expect, got)
+		}
+	}
+}
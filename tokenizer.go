@@ -0,0 +1,133 @@
+package mcla
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Tokenizer splits a message into the tokens compared by the message matching, see Analyzer.Tokenizer.
+// The similarity is the longest common subsequence of the tokens divided by the token count of the longer message,
+// so it does not depend on what a token is
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// RuneTokenizer makes each character a token, it's the default tokenizer
+type RuneTokenizer struct{}
+
+func (RuneTokenizer) Tokenize(text string) []string {
+	tokens := make([]string, 0, len(text))
+	for _, r := range text {
+		tokens = append(tokens, string(r))
+	}
+	return tokens
+}
+
+// NGramTokenizer is for the Chinese, Japanese and Korean messages, which are not separated by spaces.
+// The runs of CJK characters are split into the overlapping n-grams, a run shorter than N is a single token,
+// and the other text is split into the words by spaces, so an embedded id like `examplemod-1.2.jar` is only one token.
+// The spaces and the CJK punctuations separate the tokens and are not tokens themselves
+type NGramTokenizer struct {
+	// N is the length of the grams, not positive means 2
+	N int
+}
+
+func (t NGramTokenizer) Tokenize(text string) (tokens []string) {
+	n := t.N
+	if n <= 0 {
+		n = 2
+	}
+	var run []rune // the current CJK run
+	flushRun := func() {
+		if len(run) <= n {
+			if len(run) > 0 {
+				tokens = append(tokens, string(run))
+			}
+		} else {
+			for i := 0; i+n <= len(run); i++ {
+				tokens = append(tokens, string(run[i:i+n]))
+			}
+		}
+		run = run[:0]
+	}
+	word := -1 // the start of the current word
+	for i, r := range text {
+		cjk, sep := isCJK(r), isTokenSeparator(r)
+		if word >= 0 && (cjk || sep) {
+			tokens = append(tokens, text[word:i])
+			word = -1
+		}
+		if cjk {
+			run = append(run, r)
+			continue
+		}
+		flushRun()
+		if !sep && word < 0 {
+			word = i
+		}
+	}
+	if word >= 0 {
+		tokens = append(tokens, text[word:])
+	}
+	flushRun()
+	return
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// isTokenSeparator reports the spaces and the CJK punctuations, e.g. `，` and `：`
+func isTokenSeparator(r rune) bool {
+	return unicode.IsSpace(r) || (r >= 0x3000 && unicode.IsPunct(r))
+}
+
+// tokenizedMatchPercent compares the messages by the tokens, a nil tokenizer compares the characters like RuneTokenizer.
+// The trailing ` *` of the match is the prefix wildcard for any tokenizer
+func tokenizedMatchPercent(text, match string, tokenizer Tokenizer) float32 {
+	switch t := tokenizer.(type) {
+	case nil, RuneTokenizer, *RuneTokenizer:
+		return lineMatchPercent(text, match)
+	case *NGramTokenizer:
+		if t == nil {
+			return lineMatchPercent(text, match)
+		}
+	}
+	if prefix, ok := strings.CutSuffix(match, " *"); ok {
+		if strings.HasPrefix(text, prefix) {
+			return 1.0
+		}
+	}
+	return lcsPercent(tokenizer.Tokenize(text), tokenizer.Tokenize(match))
+}
+
+const ngramTokenizerPrefix = "ngram:"
+
+// tokenizerName is the name of a builtin tokenizer recorded in a Session, a custom tokenizer does not have a name
+func tokenizerName(tokenizer Tokenizer) string {
+	switch t := tokenizer.(type) {
+	case RuneTokenizer, *RuneTokenizer:
+		return "rune"
+	case NGramTokenizer:
+		return ngramTokenizerPrefix + strconv.Itoa(t.N)
+	case *NGramTokenizer:
+		if t != nil {
+			return ngramTokenizerPrefix + strconv.Itoa(t.N)
+		}
+	}
+	return ""
+}
+
+// tokenizerByName is the reverse of tokenizerName, it returns nil for an unknown name
+func tokenizerByName(name string) Tokenizer {
+	if name == "rune" {
+		return RuneTokenizer{}
+	}
+	if s, ok := strings.CutPrefix(name, ngramTokenizerPrefix); ok {
+		if n, err := strconv.Atoi(s); err == nil {
+			return NGramTokenizer{N: n}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package mcla_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/GlobeMC/mcla"
+)
+
+func TestNGramTokenizer(t *testing.T) {
+	tokens := NGramTokenizer{}.Tokenize("无法加载模组 examplemod-1.2.jar，配置")
+	expect := []string{"无法", "法加", "加载", "载模", "模组", "examplemod-1.2.jar", "配置"}
+	if !slices.Equal(tokens, expect) {
+		t.Errorf("Expect tokens %q, got %q", expect, tokens)
+	}
+	if tokens := (NGramTokenizer{N: 3}).Tokenize("模组 a"); !slices.Equal(tokens, []string{"模组", "a"}) {
+		t.Errorf("Expect a short run is a single token, got %q", tokens)
+	}
+}
+
+func TestTokenizerCJKMatch(t *testing.T) {
+	const threshold = 0.7
+	desc := &ErrorDesc{Error: "java.lang.IllegalStateException", Message: "无法加载模组 examplemod-forge-1.20.1-4.5.6.jar 的配置文件"}
+	jerr := &JavaError{
+		Class:   "java.lang.IllegalStateException",
+		Message: "无法加载模组 anothermod-fabric-1.19.2-10.0.jar 的配置文件",
+	}
+	score := func(tokenizer Tokenizer) float32 {
+		analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+		analyzer.Tokenizer = tokenizer
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("DoError: %v", err)
+		}
+		match, _ := findMatch(matched, desc)
+		return match
+	}
+	if match := score(nil); match >= threshold {
+		t.Errorf("Expect the default tokenizer scores below %v, got %v", threshold, match)
+	}
+	if match := score(NGramTokenizer{}); match < threshold {
+		t.Errorf("Expect the n-gram tokenizer scores at least %v, got %v", threshold, match)
+	}
+	if a, b := score(nil), score(RuneTokenizer{}); a != b {
+		t.Errorf("Expect RuneTokenizer scores same as the default, got %v and %v", b, a)
+	}
+}
+
+func TestTokenizerPrefixMatch(t *testing.T) {
+	desc := &ErrorDesc{Error: "java.lang.IllegalStateException", Message: "无法加载模组 *"}
+	jerr := &JavaError{
+		Class:   "java.lang.IllegalStateException",
+		Message: "无法加载模组 examplemod-forge-1.20.1-4.5.6.jar 的配置文件",
+	}
+	score := func(tokenizer Tokenizer) float32 {
+		analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{desc}})
+		analyzer.Tokenizer = tokenizer
+		matched, err := analyzer.DoError(jerr)
+		if err != nil {
+			t.Fatalf("%T: DoError: %v", tokenizer, err)
+		}
+		match, _ := findMatch(matched, desc)
+		return match
+	}
+	expect := score(nil)
+	desc.Message = "无法加载模组"
+	if match := score(nil); match >= expect {
+		t.Fatalf("Expect the prefix wildcard scores above %v, got %v", match, expect)
+	}
+	desc.Message = "无法加载模组 *"
+	for _, tokenizer := range []Tokenizer{RuneTokenizer{}, NGramTokenizer{}, (*NGramTokenizer)(nil)} {
+		if match := score(tokenizer); match != expect {
+			t.Errorf("%T: Expect the prefix wildcard scores %v, got %v", tokenizer, expect, match)
+		}
+	}
+}
+
+func TestRecordSessionTokenizer(t *testing.T) {
+	analyzer := NewAnalyzer(&memErrorDB{})
+	analyzer.Tokenizer = NGramTokenizer{N: 3}
+	_, session, err := analyzer.RecordSession(context.Background(), strings.NewReader("[12:00:00] [main/INFO]: Done\n"))
+	if err != nil {
+		t.Fatalf("RecordSession: %v", err)
+	}
+	if expect := "ngram:3"; session.Options.Tokenizer != expect {
+		t.Errorf("Expect Options.Tokenizer == %q, got %q", expect, session.Options.Tokenizer)
+	}
+}
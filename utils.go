@@ -1,7 +1,12 @@
 package mcla
 
 import (
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 func rsplit(line string, b byte) (left, right string) {
@@ -82,3 +87,61 @@ func lineMatchPercent(text, match string) float32 {
 
 	return lcsPercent(([]rune)(text), ([]rune)(match))
 }
+
+// substringMatchPercent only matches when one string contains the other,
+// the score is the length ratio of the shorter one to the longer one
+func substringMatchPercent(text, match string) float32 {
+	if prefix, ok := strings.CutSuffix(match, " *"); ok {
+		if strings.HasPrefix(text, prefix) {
+			return 1.0
+		}
+	}
+	long, short := text, match
+	if len(short) > len(long) {
+		long, short = short, long
+	}
+	if len(short) == 0 || !strings.Contains(long, short) {
+		return 0.0
+	}
+	return (float32)(utf8.RuneCountInString(short)) / (float32)(utf8.RuneCountInString(long))
+}
+
+var compiledPatterns sync.Map // map[string]*regexp.Regexp
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := compiledPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	compiledPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// DefaultStopWords are the words appear in most of the Java and Minecraft log messages
+var DefaultStopWords = []string{
+	"a", "an", "the", "to", "of", "in", "on", "at", "for", "with", "by", "from", "and", "or",
+	"is", "are", "was", "were", "be", "been", "this", "that", "it", "while", "during",
+	"failed", "failure", "error", "exception",
+}
+
+// removeStopWords removes the words which are in stopWords, the other words are joined by a single space.
+// The punctuations around a word are ignored when comparing it with stopWords
+func removeStopWords(text string, stopWords []string) string {
+	var b strings.Builder
+	for _, word := range strings.Fields(text) {
+		w := strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if slices.ContainsFunc(stopWords, func(s string) bool { return strings.EqualFold(s, w) }) {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
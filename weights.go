@@ -0,0 +1,42 @@
+package mcla
+
+// MatchWeights are the weights of the error type and the message when matching an error with a description.
+// The zero value means DefaultMatchWeights, and the weights must not be negative.
+//
+// ExactClass and Message are normalized so their sum is 1, thus a score is always in [0, 1],
+// and ClassName is capped at ExactClass.
+// When a description has no message, the error type provides the whole score:
+// it is 1 for the exact class, and ClassName / ExactClass for the same class name in another package.
+// When a description or the error has no type, e.g. a summary, the message provides the whole score
+type MatchWeights struct {
+	// ExactClass is the weight when the class names and the packages are same, or the package is `*`
+	ExactClass float32 `json:"exactClass"`
+	// ClassName is the weight when only the class names are same, or the class is in the package of a `pkg.*` wildcard
+	ClassName float32 `json:"className"`
+	// Message is the weight of the message similarity
+	Message float32 `json:"message"`
+}
+
+// DefaultMatchWeights are the default of Analyzer.MatchWeights
+var DefaultMatchWeights = MatchWeights{
+	ExactClass: 0.1,
+	ClassName:  0.05,
+	Message:    0.9,
+}
+
+// normalized returns the weights that ExactClass + Message == 1 and ClassName <= ExactClass
+func (w MatchWeights) normalized() MatchWeights {
+	if w == (MatchWeights{}) {
+		return DefaultMatchWeights
+	}
+	w.ExactClass, w.ClassName, w.Message = max(w.ExactClass, 0), max(w.ClassName, 0), max(w.Message, 0)
+	if sum := w.ExactClass + w.Message; sum > 0 {
+		w.ExactClass /= sum
+		w.ClassName /= sum
+		w.Message /= sum
+	} else {
+		w.Message = 1
+	}
+	w.ClassName = min(w.ClassName, w.ExactClass)
+	return w
+}
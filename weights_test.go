@@ -0,0 +1,65 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func TestMatchWeights(t *testing.T) {
+	sameClass := &ErrorDesc{
+		Error:   "java.lang.IllegalStateException",
+		Message: "Failed to load the registry of examplemod",
+	}
+	sameMessage := &ErrorDesc{
+		Error:   "java.lang.RuntimeException",
+		Message: "Failed to load the config file of examplemod",
+	}
+	exact := &ErrorDesc{
+		Error:   "java.lang.IllegalStateException",
+		Message: "Failed to load the config file of examplemod",
+	}
+	otherPackage := &ErrorDesc{
+		Error: "com.example.IllegalStateException",
+	}
+	jerr := &JavaError{
+		Class:   "java.lang.IllegalStateException",
+		Message: "Failed to load the config file of examplemod",
+	}
+	analyzer := NewAnalyzer(&memErrorDB{errors: []*ErrorDesc{sameClass, sameMessage, exact, otherPackage}})
+
+	matched, err := analyzer.DoError(jerr)
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	m1, _ := findMatch(matched, sameClass)
+	m2, _ := findMatch(matched, sameMessage)
+	if m1 >= m2 {
+		t.Errorf("Expect the same message to outrank the same class by default, got %v >= %v", m1, m2)
+	}
+	if m, _ := findMatch(matched, exact); m != 1 {
+		t.Errorf("Expect the exact description to match 1, got %v", m)
+	}
+	defaultOther, _ := findMatch(matched, otherPackage)
+
+	analyzer.MatchWeights = MatchWeights{ExactClass: 3, ClassName: 3, Message: 3}
+	if matched, err = analyzer.DoError(jerr); err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	m1, _ = findMatch(matched, sameClass)
+	m2, _ = findMatch(matched, sameMessage)
+	if m1 <= m2 {
+		t.Errorf("Expect the same class to outrank the same message when the class is weighted more, got %v <= %v", m1, m2)
+	}
+	for _, m := range matched {
+		if m.Match < 0 || m.Match > 1 {
+			t.Errorf("Expect the match of %q in [0, 1], got %v", m.ErrorDesc.Message, m.Match)
+		}
+	}
+	if m, _ := findMatch(matched, exact); m != 1 {
+		t.Errorf("Expect the exact description to match 1, got %v", m)
+	}
+	// the type only description scores ClassName / ExactClass
+	if m, _ := findMatch(matched, otherPackage); m <= defaultOther {
+		t.Errorf("Expect the type only description to score higher when ClassName == ExactClass, got %v <= %v", m, defaultOther)
+	}
+}